@@ -23,7 +23,8 @@ var addCmd = &cobra.Command{
 Examples:
   position add harper --lat 41.8781 --lng -87.6298
   position add harper --lat 41.8781 --lng -87.6298 --label chicago
-  position add harper --lat 41.8781 --lng -87.6298 -l chicago --at 2024-12-14T15:00:00Z`,
+  position add harper --lat 41.8781 --lng -87.6298 -l chicago --at 2024-12-14T15:00:00Z
+  position add phone --lat 41.8781 --lng -87.6298 --accuracy 12 --battery 80 --source gps`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		name := args[0]
@@ -35,17 +36,14 @@ Examples:
 			return err
 		}
 
-		// Get or create item
-		item, err := db.GetItemByName(name)
+		telemetry := telemetryFromFlags(cmd)
+		if err := telemetry.Validate(); err != nil {
+			return err
+		}
+
+		item, err := getOrCreateItem(name)
 		if err != nil {
-			if errors.Is(err, storage.ErrNotFound) {
-				item = models.NewItem(name)
-				if err := db.CreateItem(item); err != nil {
-					return fmt.Errorf("failed to create item: %w", err)
-				}
-			} else {
-				return fmt.Errorf("failed to get item: %w", err)
-			}
+			return err
 		}
 
 		// Parse optional flags
@@ -64,16 +62,18 @@ Examples:
 		} else {
 			pos = models.NewPosition(item.ID, lat, lng, label)
 		}
+		pos.Telemetry = telemetry
 
 		if err := db.CreatePosition(pos); err != nil {
 			return fmt.Errorf("failed to create position: %w", err)
 		}
 
+		// The label may have been filled in from a named place
 		color.Green("Position set for %s", name)
-		if label != nil {
+		if pos.Label != nil {
 			fmt.Printf("  %s @ %s (%.4f, %.4f)\n",
 				color.New(color.Faint).Sprint(pos.ID.String()[:6]),
-				*label, lat, lng)
+				*pos.Label, lat, lng)
 		} else {
 			fmt.Printf("  %s @ (%.4f, %.4f)\n",
 				color.New(color.Faint).Sprint(pos.ID.String()[:6]),
@@ -84,11 +84,59 @@ Examples:
 	},
 }
 
+// telemetryFromFlags reads the optional telemetry flags. Flags that were not
+// given are left nil rather than recorded as zero.
+func telemetryFromFlags(cmd *cobra.Command) models.Telemetry {
+	var t models.Telemetry
+	floatFlag := func(name string) *float64 {
+		if !cmd.Flags().Changed(name) {
+			return nil
+		}
+		v, _ := cmd.Flags().GetFloat64(name)
+		return &v
+	}
+	t.Accuracy = floatFlag("accuracy")
+	t.Altitude = floatFlag("altitude")
+	t.Speed = floatFlag("speed")
+	t.Heading = floatFlag("heading")
+	if cmd.Flags().Changed("battery") {
+		v, _ := cmd.Flags().GetInt("battery")
+		t.Battery = &v
+	}
+	if v, _ := cmd.Flags().GetString("source"); v != "" {
+		t.Source = &v
+	}
+	return t
+}
+
+// getOrCreateItem looks up an item by name, creating it if it doesn't exist.
+func getOrCreateItem(name string) (*models.Item, error) {
+	item, err := db.GetItemByName(name)
+	if err == nil {
+		return item, nil
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get item: %w", err)
+	}
+
+	item = models.NewItem(name)
+	if err := db.CreateItem(item); err != nil {
+		return nil, fmt.Errorf("failed to create item: %w", err)
+	}
+	return item, nil
+}
+
 func init() {
 	addCmd.Flags().Float64("lat", 0, "latitude coordinate (-90 to 90)")
 	addCmd.Flags().Float64("lng", 0, "longitude coordinate (-180 to 180)")
 	addCmd.Flags().StringP("label", "l", "", "location label (e.g., 'chicago')")
 	addCmd.Flags().String("at", "", "recorded time (RFC3339, e.g., 2024-12-14T15:00:00Z)")
+	addCmd.Flags().Float64("accuracy", 0, "horizontal accuracy in meters")
+	addCmd.Flags().Float64("altitude", 0, "altitude in meters above sea level")
+	addCmd.Flags().Float64("speed", 0, "speed in meters per second")
+	addCmd.Flags().Float64("heading", 0, "heading in degrees clockwise from north")
+	addCmd.Flags().Int("battery", 0, "device battery percentage (0-100)")
+	addCmd.Flags().String("source", "", "origin of the fix (e.g., 'gps', 'manual')")
 
 	_ = addCmd.MarkFlagRequired("lat")
 	_ = addCmd.MarkFlagRequired("lng")
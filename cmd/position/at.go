@@ -0,0 +1,81 @@
+// ABOUTME: Position at command
+// ABOUTME: Shows where an item was at a given time, optionally interpolating between fixes
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/harper/position/internal/storage"
+	"github.com/harper/position/internal/track"
+	"github.com/harper/position/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var atCmd = &cobra.Command{
+	Use:   "at <name> <time>",
+	Short: "Show where an item was at a given time",
+	Long: `Show the position in effect at a time: the latest position recorded at or
+before it. With --interpolate, the location is estimated along the great circle
+between the surrounding positions instead. The output reports how far apart
+those positions were, as a measure of confidence. With --clean, positions
+flagged suspect by the outlier filter are skipped.
+
+The time is RFC3339, YYYY-MM-DD (midnight UTC), or relative (e.g., 3h, 2d).
+
+Examples:
+  position at van 2024-12-14T14:00:00-06:00
+  position at van 3h --interpolate
+  position at van 3h --interpolate --clean`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		at, err := parseAtTime(args[1])
+		if err != nil {
+			return err
+		}
+
+		item, err := db.GetItemByName(name)
+		if err != nil {
+			return fmt.Errorf("item '%s' not found", name)
+		}
+
+		positions, err := db.GetTimeline(item.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get timeline: %w", err)
+		}
+		if clean, _ := cmd.Flags().GetBool("clean"); clean {
+			positions = storage.Clean(positions)
+		}
+
+		interpolate, _ := cmd.Flags().GetBool("interpolate")
+		fix, ok := track.At(positions, at, interpolate)
+		if !ok {
+			return fmt.Errorf("no position for '%s' at or before %s", name, at.Local().Format("Jan 2, 3:04 PM"))
+		}
+
+		fmt.Printf("%s @ %s\n", color.GreenString(name), ui.FormatFix(fix))
+		return nil
+	},
+}
+
+// parseAtTime parses an absolute date or time, or a relative duration
+// meaning that long ago.
+func parseAtTime(s string) (time.Time, error) {
+	if t, err := parseDate(s); err == nil {
+		return t, nil
+	}
+	if t, err := parseDuration(s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use RFC3339, YYYY-MM-DD, or e.g. 3h, 2d)", s)
+}
+
+func init() {
+	atCmd.Flags().Bool("interpolate", false, "estimate the location between the surrounding positions")
+	atCmd.Flags().Bool("clean", false, "skip positions flagged suspect by the outlier filter")
+	rootCmd.AddCommand(atCmd)
+}
@@ -16,7 +16,8 @@ import (
 var backupCmd = &cobra.Command{
 	Use:   "backup",
 	Short: "Create a YAML backup of all data",
-	Long: `Create a YAML backup file containing all items and positions.
+	Long: `Create a YAML backup file containing all items, positions, named places,
+and geofences with their recorded events.
 
 The backup file can be used to:
 - Migrate data between machines
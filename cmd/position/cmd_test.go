@@ -4,6 +4,10 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
 	"os"
 	"path/filepath"
 	"strings"
@@ -11,8 +15,12 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/harper/position/internal/config"
+	"github.com/harper/position/internal/geocode"
+	"github.com/harper/position/internal/geojson"
 	"github.com/harper/position/internal/models"
 	"github.com/harper/position/internal/storage"
+	"github.com/harper/position/internal/track"
 )
 
 // testDB creates a temporary database for testing and sets the global db variable.
@@ -1594,7 +1602,7 @@ func TestGetPositionsForItem_NoFilter(t *testing.T) {
 // Tests for exportGeoJSON function
 
 func TestExportGeoJSON_EmptyPositions(t *testing.T) {
-	err := exportGeoJSON([]*models.Position{}, "points", nil, "")
+	err := exportGeoJSON([]*models.Position{}, "points", track.Reduction{}, nil, "")
 	if err == nil {
 		t.Error("expected error for empty positions")
 	}
@@ -1609,7 +1617,7 @@ func TestExportGeoJSON_LineGeometry(t *testing.T) {
 		models.NewPosition(uuid.New(), 42.0, -88.0, nil),
 	}
 
-	err := exportGeoJSON(positions, "line", nil, "")
+	err := exportGeoJSON(positions, "line", track.Reduction{}, nil, "")
 	if err != nil {
 		t.Fatalf("exportGeoJSON failed: %v", err)
 	}
@@ -1620,7 +1628,7 @@ func TestExportGeoJSON_PointsGeometry(t *testing.T) {
 		models.NewPosition(uuid.New(), 41.0, -87.0, nil),
 	}
 
-	err := exportGeoJSON(positions, "points", nil, "")
+	err := exportGeoJSON(positions, "points", track.Reduction{}, nil, "")
 	if err != nil {
 		t.Fatalf("exportGeoJSON failed: %v", err)
 	}
@@ -1717,6 +1725,1596 @@ func TestParseDuration_Week(t *testing.T) {
 	}
 }
 
+// Tests for GPX export and import
+
+func TestExportCmd_GPX(t *testing.T) {
+	testDB(t)
+
+	label := "chicago"
+	item := models.NewItem("harper")
+	_ = db.CreateItem(item)
+	_ = db.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 41.8781, -87.6298, &label, time.Now().Add(-time.Hour)))
+	_ = db.CreatePosition(models.NewPosition(item.ID, 42.0, -88.0, nil))
+
+	tmpDir := t.TempDir()
+	outputPath := filepath.Join(tmpDir, "track.gpx")
+
+	exportCmd.Flags().Set("format", "gpx")
+	exportCmd.Flags().Set("output", outputPath)
+	defer func() {
+		exportCmd.Flags().Set("format", "geojson")
+		exportCmd.Flags().Set("output", "")
+	}()
+
+	err := exportCmd.RunE(exportCmd, []string{"harper"})
+	if err != nil {
+		t.Fatalf("exportCmd failed: %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("export file not created: %v", err)
+	}
+	content := string(data)
+	if !strings.Contains(content, "<trk>") || !strings.Contains(content, "<name>harper</name>") {
+		t.Errorf("expected harper track in GPX, got:\n%s", content)
+	}
+	if !strings.Contains(content, "<wpt") || !strings.Contains(content, "<name>chicago</name>") {
+		t.Errorf("expected chicago waypoint in GPX, got:\n%s", content)
+	}
+}
+
+func TestExportCmd_GPXNoPositions(t *testing.T) {
+	testDB(t)
+
+	exportCmd.Flags().Set("format", "gpx")
+	defer exportCmd.Flags().Set("format", "geojson")
+
+	err := exportCmd.RunE(exportCmd, []string{})
+	if err == nil {
+		t.Error("expected error when no positions")
+	}
+}
+
+func TestImportCmd_GPXRoundTrip(t *testing.T) {
+	testDB(t)
+
+	label := "home"
+	item := models.NewItem("harper")
+	_ = db.CreateItem(item)
+	_ = db.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 41.0, -87.0, &label, time.Now().Add(-2*time.Hour)))
+	_ = db.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 42.0, -88.0, nil, time.Now().Add(-time.Hour)))
+
+	tmpDir := t.TempDir()
+	gpxPath := filepath.Join(tmpDir, "track.gpx")
+
+	exportCmd.Flags().Set("format", "gpx")
+	exportCmd.Flags().Set("output", gpxPath)
+	defer func() {
+		exportCmd.Flags().Set("format", "geojson")
+		exportCmd.Flags().Set("output", "")
+	}()
+	if err := exportCmd.RunE(exportCmd, []string{"harper"}); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+
+	_ = db.Reset()
+
+	importCmd.Flags().Set("confirm", "true")
+	importCmd.Flags().Set("format", "gpx")
+	defer func() {
+		importCmd.Flags().Set("confirm", "false")
+		importCmd.Flags().Set("format", "yaml")
+	}()
+
+	if err := importCmd.RunE(importCmd, []string{gpxPath}); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+
+	imported, err := db.GetItemByName("harper")
+	if err != nil {
+		t.Fatalf("item not imported: %v", err)
+	}
+	positions, _ := db.GetTimeline(imported.ID)
+	if len(positions) != 2 {
+		t.Fatalf("expected 2 positions, got %d", len(positions))
+	}
+	// Oldest position should carry the waypoint label
+	oldest := positions[1]
+	if oldest.Label == nil || *oldest.Label != "home" {
+		t.Errorf("expected label 'home' on oldest position, got %v", oldest.Label)
+	}
+}
+
+func TestImportCmd_GPXNameOverride(t *testing.T) {
+	testDB(t)
+
+	gpxPath := filepath.Join(t.TempDir(), "ride.gpx")
+	content := `<?xml version="1.0"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1">
+  <wpt lat="41.95" lon="-87.65"><time>2024-12-14T09:00:00Z</time><name>cafe</name></wpt>
+  <trk><trkseg>
+    <trkpt lat="41.9" lon="-87.6"><time>2024-12-14T08:00:00Z</time></trkpt>
+    <trkpt lat="41.91" lon="-87.61"></trkpt>
+  </trkseg></trk>
+</gpx>`
+	if err := os.WriteFile(gpxPath, []byte(content), 0644); err != nil {
+		t.Fatalf("write gpx: %v", err)
+	}
+
+	importCmd.Flags().Set("confirm", "true")
+	importCmd.Flags().Set("format", "gpx")
+	importCmd.Flags().Set("name", "bike")
+	defer func() {
+		importCmd.Flags().Set("confirm", "false")
+		importCmd.Flags().Set("format", "yaml")
+		importCmd.Flags().Set("name", "")
+	}()
+
+	if err := importCmd.RunE(importCmd, []string{gpxPath}); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+
+	item, err := db.GetItemByName("bike")
+	if err != nil {
+		t.Fatalf("item not created: %v", err)
+	}
+	positions, _ := db.GetTimeline(item.ID)
+	// One timed track point plus the orphan waypoint; the untimed point is skipped
+	if len(positions) != 2 {
+		t.Fatalf("expected 2 positions, got %d", len(positions))
+	}
+	if positions[0].Label == nil || *positions[0].Label != "cafe" {
+		t.Errorf("expected newest position labeled 'cafe', got %v", positions[0].Label)
+	}
+}
+
+func TestImportCmd_GPXUnnamedTrack(t *testing.T) {
+	testDB(t)
+
+	gpxPath := filepath.Join(t.TempDir(), "ride.gpx")
+	content := `<gpx version="1.1"><trk><trkseg><trkpt lat="1" lon="1"><time>2024-12-14T08:00:00Z</time></trkpt></trkseg></trk></gpx>`
+	_ = os.WriteFile(gpxPath, []byte(content), 0644)
+
+	importCmd.Flags().Set("confirm", "true")
+	importCmd.Flags().Set("format", "gpx")
+	defer func() {
+		importCmd.Flags().Set("confirm", "false")
+		importCmd.Flags().Set("format", "yaml")
+	}()
+
+	if err := importCmd.RunE(importCmd, []string{gpxPath}); err == nil {
+		t.Error("expected error for unnamed track without --name")
+	}
+}
+
+func TestImportGPX_NamesAndDuplicates(t *testing.T) {
+	testDB(t)
+
+	content := `<gpx version="1.1">
+  <wpt lat="41.95" lon="-87.65"><time>2024-12-14T09:00:00Z</time><name>cafe</name><desc>   </desc></wpt>
+  <trk><name>bike</name><trkseg>
+    <trkpt lat="41.9" lon="-87.6"><time>2024-12-14T08:00:00Z</time></trkpt>
+    <trkpt lat="41.9" lon="-87.6"><time>2024-12-14T08:01:00Z</time></trkpt>
+    <trkpt lat="41.91" lon="-87.61"><time>2024-12-14T08:02:00Z</time></trkpt>
+  </trkseg></trk>
+</gpx>`
+	summary, err := importGPX([]byte(content), "")
+	if err != nil {
+		t.Fatalf("importGPX failed: %v", err)
+	}
+	if summary.Imported != 2 || summary.Duplicates != 1 || summary.Skipped != 1 {
+		t.Errorf("expected 2 imported, 1 duplicate, 1 skipped, got %+v", summary)
+	}
+	if items, _ := db.ListItems(); len(items) != 1 {
+		t.Errorf("expected only the bike item, got %d items", len(items))
+	}
+
+	long := strings.Repeat("x", 256)
+	content = `<gpx version="1.1"><trk><name>` + long + `</name><trkseg><trkpt lat="1" lon="1"><time>2024-12-14T08:00:00Z</time></trkpt></trkseg></trk></gpx>`
+	if _, err := importGPX([]byte(content), ""); err == nil {
+		t.Error("expected error for a track name the CLI rejects")
+	}
+	if _, err := db.GetItemByName(long); err == nil {
+		t.Error("expected no item created for the invalid track name")
+	}
+}
+
+func TestImportCmd_InvalidFormat(t *testing.T) {
+	testDB(t)
+
+	importCmd.Flags().Set("format", "invalid")
+	defer importCmd.Flags().Set("format", "yaml")
+
+	if err := importCmd.RunE(importCmd, []string{"whatever"}); err == nil {
+		t.Error("expected error for invalid format")
+	}
+}
+
+// Tests for KML/KMZ export
+
+func TestExportCmd_KML(t *testing.T) {
+	testDB(t)
+
+	item := models.NewItem("harper")
+	_ = db.CreateItem(item)
+	_ = db.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 41.0, -87.0, nil, time.Now().Add(-time.Hour)))
+	_ = db.CreatePosition(models.NewPosition(item.ID, 42.0, -88.0, nil))
+
+	outputPath := filepath.Join(t.TempDir(), "positions.kml")
+
+	exportCmd.Flags().Set("format", "kml")
+	exportCmd.Flags().Set("output", outputPath)
+	defer func() {
+		exportCmd.Flags().Set("format", "geojson")
+		exportCmd.Flags().Set("output", "")
+	}()
+
+	if err := exportCmd.RunE(exportCmd, []string{"harper"}); err != nil {
+		t.Fatalf("exportCmd failed: %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("export file not created: %v", err)
+	}
+	if !strings.Contains(string(data), "<gx:Track>") {
+		t.Error("expected gx:Track in KML output")
+	}
+}
+
+func TestExportCmd_KMZ(t *testing.T) {
+	testDB(t)
+
+	item := models.NewItem("harper")
+	_ = db.CreateItem(item)
+	_ = db.CreatePosition(models.NewPosition(item.ID, 41.0, -87.0, nil))
+
+	outputPath := filepath.Join(t.TempDir(), "positions.kmz")
+
+	exportCmd.Flags().Set("format", "kmz")
+	exportCmd.Flags().Set("output", outputPath)
+	defer func() {
+		exportCmd.Flags().Set("format", "geojson")
+		exportCmd.Flags().Set("output", "")
+	}()
+
+	if err := exportCmd.RunE(exportCmd, []string{}); err != nil {
+		t.Fatalf("exportCmd failed: %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("export file not created: %v", err)
+	}
+	// KMZ is a zip archive
+	if !strings.HasPrefix(string(data), "PK") {
+		t.Error("expected zip archive for KMZ output")
+	}
+}
+
+// Tests for CSV export and import
+
+func TestExportCmd_CSV(t *testing.T) {
+	testDB(t)
+
+	item := models.NewItem("harper")
+	_ = db.CreateItem(item)
+	_ = db.CreatePosition(models.NewPosition(item.ID, 41.0, -87.0, nil))
+
+	outputPath := filepath.Join(t.TempDir(), "positions.csv")
+
+	exportCmd.Flags().Set("format", "csv")
+	exportCmd.Flags().Set("output", outputPath)
+	defer func() {
+		exportCmd.Flags().Set("format", "geojson")
+		exportCmd.Flags().Set("output", "")
+	}()
+
+	if err := exportCmd.RunE(exportCmd, []string{"harper"}); err != nil {
+		t.Fatalf("exportCmd failed: %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("export file not created: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "name,latitude,longitude,label,recorded_at\nharper,41,-87,") {
+		t.Errorf("unexpected CSV output:\n%s", data)
+	}
+}
+
+func TestImportCmd_CSVWithMapping(t *testing.T) {
+	testDB(t)
+
+	csvPath := filepath.Join(t.TempDir(), "fleet.csv")
+	content := "device,latitude,lon,timestamp,place\n" +
+		"van,41.0,-87.0,1734188400,depot\n" +
+		"van,not-a-number,-87.0,1734188460,\n" +
+		"truck,42.0,-88.0,1734188400,\n" +
+		"van,41.5,-87.5,1734192000,\n"
+	if err := os.WriteFile(csvPath, []byte(content), 0644); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+
+	importCmd.Flags().Set("confirm", "true")
+	importCmd.Flags().Set("format", "csv")
+	importCmd.Flags().Set("map", "name=device,lat=latitude,lng=lon,at=timestamp,label=place")
+	importCmd.Flags().Set("time-layout", "unix")
+	defer func() {
+		importCmd.Flags().Set("confirm", "false")
+		importCmd.Flags().Set("format", "yaml")
+		importCmd.Flags().Set("map", "")
+		importCmd.Flags().Set("time-layout", time.RFC3339)
+	}()
+
+	if err := importCmd.RunE(importCmd, []string{csvPath}); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+
+	van, err := db.GetItemByName("van")
+	if err != nil {
+		t.Fatalf("van not created: %v", err)
+	}
+	positions, _ := db.GetTimeline(van.ID)
+	if len(positions) != 2 {
+		t.Fatalf("expected 2 van positions (bad row skipped), got %d", len(positions))
+	}
+	if positions[1].Label == nil || *positions[1].Label != "depot" {
+		t.Errorf("expected oldest van position labeled 'depot', got %v", positions[1].Label)
+	}
+
+	if _, err := db.GetItemByName("truck"); err != nil {
+		t.Errorf("truck not created: %v", err)
+	}
+}
+
+func TestImportCmd_CSVInvalidMap(t *testing.T) {
+	testDB(t)
+
+	importCmd.Flags().Set("format", "csv")
+	importCmd.Flags().Set("map", "speed=kmh")
+	defer func() {
+		importCmd.Flags().Set("format", "yaml")
+		importCmd.Flags().Set("map", "")
+	}()
+
+	if err := importCmd.RunE(importCmd, []string{"whatever.csv"}); err == nil {
+		t.Error("expected error for invalid column map")
+	}
+}
+
+func TestImportCmd_CSVMissingName(t *testing.T) {
+	testDB(t)
+
+	csvPath := filepath.Join(t.TempDir(), "points.csv")
+	_ = os.WriteFile(csvPath, []byte("latitude,longitude\n41.0,-87.0\n"), 0644)
+
+	importCmd.Flags().Set("confirm", "true")
+	importCmd.Flags().Set("format", "csv")
+	defer func() {
+		importCmd.Flags().Set("confirm", "false")
+		importCmd.Flags().Set("format", "yaml")
+	}()
+
+	if err := importCmd.RunE(importCmd, []string{csvPath}); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	items, _ := db.ListItems()
+	if len(items) != 0 {
+		t.Errorf("expected rows without an item name to be skipped, got %d items", len(items))
+	}
+}
+
+// Tests for Google Takeout import
+
+func TestImportCmd_Takeout(t *testing.T) {
+	testDB(t)
+
+	path := filepath.Join(t.TempDir(), "location-history.json")
+	content := `[
+		{"startTime": "2024-01-01T08:00:00.000-05:00", "endTime": "2024-01-01T09:00:00.000-05:00",
+		 "visit": {"topCandidate": {"semanticType": "Home", "placeLocation": "geo:41.878100,-87.629800"}}},
+		{"startTime": "2024-01-01T09:00:00.000-05:00", "endTime": "2024-01-01T09:30:00.000-05:00",
+		 "activity": {"start": "geo:41.878100,-87.629800", "end": "geo:41.900000,-87.650000"}}
+	]`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write takeout: %v", err)
+	}
+
+	importCmd.Flags().Set("confirm", "true")
+	importCmd.Flags().Set("format", "takeout")
+	importCmd.Flags().Set("name", "harper")
+	defer func() {
+		importCmd.Flags().Set("confirm", "false")
+		importCmd.Flags().Set("format", "yaml")
+		importCmd.Flags().Set("name", "")
+	}()
+
+	if err := importCmd.RunE(importCmd, []string{path}); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+
+	item, err := db.GetItemByName("harper")
+	if err != nil {
+		t.Fatalf("item not created: %v", err)
+	}
+	positions, _ := db.GetTimeline(item.ID)
+	// Activity start duplicates the visit location and is deduplicated
+	if len(positions) != 2 {
+		t.Fatalf("expected 2 positions, got %d", len(positions))
+	}
+	oldest := positions[len(positions)-1]
+	if oldest.Label == nil || *oldest.Label != "home" {
+		t.Errorf("expected oldest position labeled 'home', got %v", oldest.Label)
+	}
+}
+
+func TestImportTakeout_SanitizesEachReading(t *testing.T) {
+	testDB(t)
+
+	// A negative speed is Google's "unknown"; the other readings are fine
+	content := `{"locations": [{"latitudeE7": 418781000, "longitudeE7": -876298000,
+		"timestamp": "2024-01-01T08:00:00Z", "accuracy": 12, "altitude": 180, "velocity": -1, "heading": 90}]}`
+	summary, err := importTakeout([]byte(content), "harper")
+	if err != nil {
+		t.Fatalf("importTakeout failed: %v", err)
+	}
+	if summary.Imported != 1 {
+		t.Fatalf("expected 1 imported, got %+v", summary)
+	}
+
+	item, _ := db.GetItemByName("harper")
+	pos, err := db.GetCurrentPosition(item.ID)
+	if err != nil {
+		t.Fatalf("GetCurrentPosition failed: %v", err)
+	}
+	if pos.Speed != nil {
+		t.Errorf("expected the invalid speed dropped, got %v", *pos.Speed)
+	}
+	if pos.Accuracy == nil || *pos.Accuracy != 12 || pos.Altitude == nil || *pos.Altitude != 180 ||
+		pos.Heading == nil || *pos.Heading != 90 {
+		t.Errorf("expected the valid readings kept, got %+v", pos.Telemetry)
+	}
+}
+
+func TestImportCmd_TakeoutRequiresName(t *testing.T) {
+	testDB(t)
+
+	importCmd.Flags().Set("format", "takeout")
+	defer importCmd.Flags().Set("format", "yaml")
+
+	if err := importCmd.RunE(importCmd, []string{"Records.json"}); err == nil {
+		t.Error("expected error when --name is missing")
+	}
+}
+
+func TestParseAliases(t *testing.T) {
+	aliases, err := parseAliases([]string{"ph=harper", " pixel = hiromi "})
+	if err != nil {
+		t.Fatalf("parseAliases failed: %v", err)
+	}
+	if aliases["ph"] != "harper" || aliases["pixel"] != "hiromi" {
+		t.Errorf("unexpected aliases: %v", aliases)
+	}
+
+	for _, bad := range []string{"harper", "=harper", "ph="} {
+		if _, err := parseAliases([]string{bad}); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestServeCmd_InvalidAlias(t *testing.T) {
+	testDB(t)
+
+	serveAliases = []string{"nope"}
+	defer func() { serveAliases = nil }()
+
+	if err := serveCmd.RunE(serveCmd, []string{}); err == nil {
+		t.Error("expected error for invalid alias")
+	}
+}
+
+func TestLoadMCPToken(t *testing.T) {
+	t.Setenv(mcpTokenEnv, " from-env ")
+
+	file := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(file, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, token, file, want string
+	}{
+		{"flag", "from-flag", "", "from-flag"},
+		{"file", "", file, "from-file"},
+		{"env", "", "", "from-env"},
+	}
+	for _, tt := range tests {
+		got, err := loadMCPToken(tt.token, tt.file)
+		if err != nil {
+			t.Fatalf("%s: loadMCPToken failed: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+
+	if _, err := loadMCPToken("a", file); err == nil {
+		t.Error("expected error for both --token and --token-file")
+	}
+	empty := filepath.Join(t.TempDir(), "empty")
+	if err := os.WriteFile(empty, []byte("\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadMCPToken("", empty); err == nil {
+		t.Error("expected error for an empty token file")
+	}
+}
+
+func TestMCPCmd_TokenRequiresHTTP(t *testing.T) {
+	testDB(t)
+
+	mcpToken = "secret"
+	defer func() { mcpToken = "" }()
+
+	if err := mcpCmd.RunE(mcpCmd, []string{}); err == nil {
+		t.Error("expected error for --token without --http")
+	}
+}
+
+func TestMCPPermissions(t *testing.T) {
+	configured := &config.Config{MCP: &config.MCPPermissions{Tools: []string{"list_items", "get_current"}}}
+
+	perms := mcpPermissions(configured, false, nil)
+	if perms.ReadOnly || len(perms.Tools) != 2 {
+		t.Errorf("expected the configured permissions, got %+v", perms)
+	}
+
+	perms = mcpPermissions(configured, true, []string{"get_timeline"})
+	if !perms.ReadOnly || len(perms.Tools) != 1 || perms.Tools[0] != "get_timeline" {
+		t.Errorf("expected flags to apply, got %+v", perms)
+	}
+
+	// A read-only config can't be loosened from the command line
+	perms = mcpPermissions(&config.Config{MCP: &config.MCPPermissions{ReadOnly: true}}, false, nil)
+	if !perms.ReadOnly {
+		t.Error("expected read-only from config to stick")
+	}
+
+	if perms := mcpPermissions(nil, false, nil); perms.ReadOnly || perms.Tools != nil {
+		t.Errorf("expected no restrictions without config, got %+v", perms)
+	}
+}
+
+func TestMCPCmd_InvalidTools(t *testing.T) {
+	testDB(t)
+
+	mcpTools = []string{"drop_everything"}
+	defer func() { mcpTools = nil }()
+
+	err := mcpCmd.RunE(mcpCmd, []string{})
+	if err == nil || !strings.Contains(err.Error(), "drop_everything") {
+		t.Errorf("expected unknown tool error, got %v", err)
+	}
+}
+
+// resetFenceAddFlags clears values and Changed state set by fence add tests.
+func resetFenceAddFlags() {
+	for _, name := range []string{"lat", "lng", "radius", "polygon"} {
+		f := fenceAddCmd.Flags().Lookup(name)
+		_ = f.Value.Set(f.DefValue)
+		f.Changed = false
+	}
+}
+
+func TestFenceCmd_AddListEvents(t *testing.T) {
+	testDB(t)
+	defer resetFenceAddFlags()
+
+	fenceAddCmd.Flags().Set("lat", "41.8781")
+	fenceAddCmd.Flags().Set("lng", "-87.6298")
+	fenceAddCmd.Flags().Set("radius", "100")
+	if err := fenceAddCmd.RunE(fenceAddCmd, []string{"garage"}); err != nil {
+		t.Fatalf("fence add failed: %v", err)
+	}
+	resetFenceAddFlags()
+
+	fenceAddCmd.Flags().Set("polygon", "41,-88; 41,-87; 42,-87")
+	if err := fenceAddCmd.RunE(fenceAddCmd, []string{"park"}); err != nil {
+		t.Fatalf("fence add polygon failed: %v", err)
+	}
+
+	fences, _ := db.ListGeofences()
+	if len(fences) != 2 {
+		t.Fatalf("expected 2 geofences, got %d", len(fences))
+	}
+	if err := fenceListCmd.RunE(fenceListCmd, []string{}); err != nil {
+		t.Errorf("fence list failed: %v", err)
+	}
+
+	item := models.NewItem("car")
+	_ = db.CreateItem(item)
+	_ = db.CreatePosition(models.NewPosition(item.ID, 41.8781, -87.6298, nil))
+
+	fenceEventsCmd.Flags().Set("item", "car")
+	fenceEventsCmd.Flags().Set("fence", "garage")
+	defer func() {
+		fenceEventsCmd.Flags().Set("item", "")
+		fenceEventsCmd.Flags().Set("fence", "")
+	}()
+	if err := fenceEventsCmd.RunE(fenceEventsCmd, []string{}); err != nil {
+		t.Errorf("fence events failed: %v", err)
+	}
+
+	events, _ := db.ListGeofenceEvents(item.ID, fences[0].ID)
+	if len(events) != 1 || events[0].Type != models.GeofenceEnter {
+		t.Errorf("expected enter event for garage, got %v", events)
+	}
+
+	fenceEventsCmd.Flags().Set("fence", "nowhere")
+	if err := fenceEventsCmd.RunE(fenceEventsCmd, []string{}); err == nil {
+		t.Error("expected error for unknown geofence")
+	}
+}
+
+func TestFenceCmd_AddValidation(t *testing.T) {
+	testDB(t)
+	defer resetFenceAddFlags()
+
+	// Missing radius
+	fenceAddCmd.Flags().Set("lat", "41.0")
+	fenceAddCmd.Flags().Set("lng", "-87.0")
+	if err := fenceAddCmd.RunE(fenceAddCmd, []string{"garage"}); err == nil {
+		t.Error("expected error when --radius is missing")
+	}
+
+	// Circle and polygon together
+	fenceAddCmd.Flags().Set("radius", "50")
+	fenceAddCmd.Flags().Set("polygon", "41,-88;41,-87;42,-87")
+	if err := fenceAddCmd.RunE(fenceAddCmd, []string{"garage"}); err == nil {
+		t.Error("expected error when mixing circle and polygon flags")
+	}
+	resetFenceAddFlags()
+
+	// Too few vertices
+	fenceAddCmd.Flags().Set("polygon", "41,-88;41,-87")
+	if err := fenceAddCmd.RunE(fenceAddCmd, []string{"park"}); err == nil {
+		t.Error("expected error for 2-vertex polygon")
+	}
+}
+
+func TestFenceCmd_Remove(t *testing.T) {
+	testDB(t)
+
+	_ = db.CreateGeofence(models.NewCircleGeofence("garage", 41.0, -87.0, 50))
+
+	if err := fenceRemoveCmd.RunE(fenceRemoveCmd, []string{"garage"}); err != nil {
+		t.Fatalf("fence remove failed: %v", err)
+	}
+	if _, err := db.GetGeofenceByName("garage"); err == nil {
+		t.Error("expected geofence to be removed")
+	}
+	if err := fenceRemoveCmd.RunE(fenceRemoveCmd, []string{"garage"}); err == nil {
+		t.Error("expected error removing unknown geofence")
+	}
+}
+
+// resetPlaceAddFlags clears values and Changed state set by place add tests.
+func resetPlaceAddFlags() {
+	for _, name := range []string{"lat", "lng", "radius", "polygon"} {
+		f := placeAddCmd.Flags().Lookup(name)
+		_ = f.Value.Set(f.DefValue)
+		f.Changed = false
+	}
+}
+
+func TestPlaceCmd_AddListRemove(t *testing.T) {
+	testDB(t)
+	defer resetPlaceAddFlags()
+
+	_ = placeAddCmd.Flags().Set("lat", "41.8781")
+	_ = placeAddCmd.Flags().Set("lng", "-87.6298")
+	if err := placeAddCmd.RunE(placeAddCmd, []string{"home"}); err != nil {
+		t.Fatalf("place add failed: %v", err)
+	}
+	resetPlaceAddFlags()
+
+	_ = placeAddCmd.Flags().Set("polygon", "41,-88; 41,-87; 42,-87")
+	if err := placeAddCmd.RunE(placeAddCmd, []string{"park"}); err != nil {
+		t.Fatalf("place add polygon failed: %v", err)
+	}
+
+	out := captureStdout(t, func() {
+		if err := placeListCmd.RunE(placeListCmd, []string{}); err != nil {
+			t.Errorf("place list failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, "home") || !strings.Contains(out, "100m") || !strings.Contains(out, "3 vertices") {
+		t.Errorf("unexpected place list output:\n%s", out)
+	}
+
+	if err := placeRemoveCmd.RunE(placeRemoveCmd, []string{"home"}); err != nil {
+		t.Fatalf("place rm failed: %v", err)
+	}
+	if _, err := db.GetPlaceByName("home"); err == nil {
+		t.Error("expected place to be removed")
+	}
+	if err := placeRemoveCmd.RunE(placeRemoveCmd, []string{"home"}); err == nil {
+		t.Error("expected error removing unknown place")
+	}
+}
+
+func TestPlaceCmd_AddValidation(t *testing.T) {
+	testDB(t)
+	defer resetPlaceAddFlags()
+
+	// Missing center
+	if err := placeAddCmd.RunE(placeAddCmd, []string{"home"}); err == nil {
+		t.Error("expected error when --lat and --lng are missing")
+	}
+
+	// Circle and polygon together
+	_ = placeAddCmd.Flags().Set("lat", "41.0")
+	_ = placeAddCmd.Flags().Set("polygon", "41,-88;41,-87;42,-87")
+	if err := placeAddCmd.RunE(placeAddCmd, []string{"home"}); err == nil {
+		t.Error("expected error when mixing circle and polygon flags")
+	}
+	resetPlaceAddFlags()
+
+	// Non-positive radius
+	_ = placeAddCmd.Flags().Set("lat", "41.0")
+	_ = placeAddCmd.Flags().Set("lng", "-87.0")
+	_ = placeAddCmd.Flags().Set("radius", "0")
+	if err := placeAddCmd.RunE(placeAddCmd, []string{"home"}); err == nil {
+		t.Error("expected error for zero radius")
+	}
+}
+
+func TestAddCmd_PlaceLabel(t *testing.T) {
+	testDB(t)
+	defer resetAddTelemetryFlags()
+
+	_ = db.CreatePlace(models.NewPlace("home", 41.8781, -87.6298, 100))
+	_ = addCmd.Flags().Set("lat", "41.8782")
+	_ = addCmd.Flags().Set("lng", "-87.6298")
+	out := captureStdout(t, func() {
+		if err := addCmd.RunE(addCmd, []string{"harper"}); err != nil {
+			t.Fatalf("addCmd failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, "@ home") {
+		t.Errorf("expected the place label in add output, got %q", out)
+	}
+
+	item, _ := db.GetItemByName("harper")
+	pos, err := db.GetCurrentPosition(item.ID)
+	if err != nil {
+		t.Fatalf("GetCurrentPosition failed: %v", err)
+	}
+	if pos.Label == nil || *pos.Label != "home" {
+		t.Errorf("expected position labeled home, got %v", pos.Label)
+	}
+}
+
+func TestParsePolygon(t *testing.T) {
+	vertices, err := parsePolygon("41.0,-88.0; 41.0,-87.0;42.0,-87.0;")
+	if err != nil {
+		t.Fatalf("parsePolygon failed: %v", err)
+	}
+	if len(vertices) != 3 || vertices[2].Latitude != 42.0 || vertices[2].Longitude != -87.0 {
+		t.Errorf("unexpected vertices: %v", vertices)
+	}
+
+	for _, bad := range []string{"41.0", "north,-87", "41,east"} {
+		if _, err := parsePolygon(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+// resetNearFlags clears values and Changed state set by near tests.
+func resetNearFlags() {
+	for _, name := range []string{"lat", "lng", "radius", "bbox", "since", "from", "to"} {
+		f := nearCmd.Flags().Lookup(name)
+		_ = f.Value.Set(f.DefValue)
+		f.Changed = false
+	}
+}
+
+func TestNearCmd_Radius(t *testing.T) {
+	testDB(t)
+	defer resetNearFlags()
+
+	harper := models.NewItem("harper")
+	_ = db.CreateItem(harper)
+	_ = db.CreatePosition(models.NewPosition(harper.ID, 41.8781, -87.6298, nil))
+	car := models.NewItem("car")
+	_ = db.CreateItem(car)
+	_ = db.CreatePosition(models.NewPosition(car.ID, 40.7128, -74.0060, nil))
+
+	nearCmd.Flags().Set("lat", "41.8785")
+	nearCmd.Flags().Set("lng", "-87.6298")
+	nearCmd.Flags().Set("radius", "500")
+	nearCmd.Flags().Set("since", "1d")
+
+	output := captureStdout(t, func() {
+		if err := nearCmd.RunE(nearCmd, []string{}); err != nil {
+			t.Fatalf("near failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "harper") || strings.Contains(output, "car") {
+		t.Errorf("expected only harper nearby, got:\n%s", output)
+	}
+}
+
+func TestNearCmd_BBox(t *testing.T) {
+	testDB(t)
+	defer resetNearFlags()
+
+	item := models.NewItem("harper")
+	_ = db.CreateItem(item)
+	_ = db.CreatePosition(models.NewPosition(item.ID, 41.8781, -87.6298, nil))
+
+	nearCmd.Flags().Set("bbox", "41.8,-87.7,41.9,-87.6")
+	output := captureStdout(t, func() {
+		if err := nearCmd.RunE(nearCmd, []string{}); err != nil {
+			t.Fatalf("near --bbox failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "harper") {
+		t.Errorf("expected harper inside box, got:\n%s", output)
+	}
+}
+
+func TestNearCmd_InvalidFlags(t *testing.T) {
+	testDB(t)
+	defer resetNearFlags()
+
+	// Neither point nor box
+	if err := nearCmd.RunE(nearCmd, []string{}); err == nil {
+		t.Error("expected error without --lat/--lng or --bbox")
+	}
+
+	// Both point and box
+	nearCmd.Flags().Set("lat", "41")
+	nearCmd.Flags().Set("lng", "-87")
+	nearCmd.Flags().Set("bbox", "41,-88,42,-87")
+	if err := nearCmd.RunE(nearCmd, []string{}); err == nil {
+		t.Error("expected error when mixing point and box")
+	}
+	resetNearFlags()
+
+	// Bad radius
+	nearCmd.Flags().Set("lat", "41")
+	nearCmd.Flags().Set("lng", "-87")
+	nearCmd.Flags().Set("radius", "0")
+	if err := nearCmd.RunE(nearCmd, []string{}); err == nil {
+		t.Error("expected error for zero radius")
+	}
+}
+
+func TestParseBBox(t *testing.T) {
+	minLat, minLng, maxLat, maxLng, err := parseBBox("41.8, -87.7, 41.9, -87.6")
+	if err != nil {
+		t.Fatalf("parseBBox failed: %v", err)
+	}
+	if minLat != 41.8 || minLng != -87.7 || maxLat != 41.9 || maxLng != -87.6 {
+		t.Errorf("unexpected box: %v %v %v %v", minLat, minLng, maxLat, maxLng)
+	}
+
+	// Antimeridian boxes are allowed
+	if _, _, _, _, err := parseBBox("-18,179,-16,-179"); err != nil {
+		t.Errorf("expected antimeridian box to parse: %v", err)
+	}
+
+	for _, bad := range []string{"1,2,3", "a,b,c,d", "42,-88,41,-87", "91,0,92,1"} {
+		if _, _, _, _, err := parseBBox(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+// Tests for distanceCmd
+
+func TestDistanceCmd_Items(t *testing.T) {
+	testDB(t)
+
+	harper := models.NewItem("harper")
+	car := models.NewItem("car")
+	_ = db.CreateItem(harper)
+	_ = db.CreateItem(car)
+	_ = db.CreatePosition(models.NewPosition(harper.ID, 41.8781, -87.6298, nil))
+	_ = db.CreatePosition(models.NewPosition(car.ID, 41.8871, -87.6298, nil))
+
+	out := captureStdout(t, func() {
+		if err := distanceCmd.RunE(distanceCmd, []string{"harper", "car"}); err != nil {
+			t.Fatalf("distanceCmd failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, "1.0 km") {
+		t.Errorf("expected ~1 km distance, got %q", out)
+	}
+	if !strings.Contains(out, "0° N") {
+		t.Errorf("expected northward bearing, got %q", out)
+	}
+}
+
+func TestDistanceCmd_Coordinates(t *testing.T) {
+	testDB(t)
+
+	harper := models.NewItem("harper")
+	_ = db.CreateItem(harper)
+	_ = db.CreatePosition(models.NewPosition(harper.ID, 0, 0, nil))
+
+	out := captureStdout(t, func() {
+		if err := distanceCmd.RunE(distanceCmd, []string{"harper", "0,1"}); err != nil {
+			t.Fatalf("distanceCmd failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, "111.3 km") || !strings.Contains(out, "90° E") {
+		t.Errorf("unexpected output: %q", out)
+	}
+}
+
+func TestDistanceCmd_NegativeLatitude(t *testing.T) {
+	testDB(t)
+	defer func() {
+		_ = distanceCmd.Flags().Set("from", "")
+		_ = distanceCmd.Flags().Set("to", "")
+	}()
+
+	if err := distanceCmd.ParseFlags([]string{"--from", "-33.8688,151.2093", "--to", "40.7128,-74.0060"}); err != nil {
+		t.Fatalf("parse flags: %v", err)
+	}
+	out := captureStdout(t, func() {
+		if err := distanceCmd.RunE(distanceCmd, distanceCmd.Flags().Args()); err != nil {
+			t.Fatalf("distanceCmd failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, "-33.8688,151.2093") || !strings.Contains(out, "15988") {
+		t.Errorf("expected Sydney to New York distance, got %q", out)
+	}
+}
+
+func TestDistanceCmd_Errors(t *testing.T) {
+	testDB(t)
+
+	item := models.NewItem("empty")
+	_ = db.CreateItem(item)
+
+	for _, args := range [][]string{
+		{"missing", "0,0"},
+		{"empty", "0,0"},
+		{"0,0", "91,0"},
+		{"0,0"},
+		{"0,0", "1,1", "2,2"},
+	} {
+		if err := distanceCmd.RunE(distanceCmd, args); err == nil {
+			t.Errorf("expected error for %v", args)
+		}
+	}
+}
+
+func TestTimelineCmd_Verbose(t *testing.T) {
+	testDB(t)
+	defer func() { _ = timelineCmd.Flags().Set("verbose", "false") }()
+
+	item := models.NewItem("car")
+	_ = db.CreateItem(item)
+	base := time.Date(2024, 12, 14, 8, 0, 0, 0, time.UTC)
+	_ = db.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 0, 0, nil, base))
+	_ = db.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 0.1, 0, nil, base.Add(15*time.Minute)))
+
+	_ = timelineCmd.Flags().Set("verbose", "true")
+	out := captureStdout(t, func() {
+		if err := timelineCmd.RunE(timelineCmd, []string{"car"}); err != nil {
+			t.Fatalf("timelineCmd failed: %v", err)
+		}
+	})
+	for _, want := range []string{"+11.1 km", "0° N", "44.5 km/h", "total 11.1 km over 15m0s"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in verbose timeline, got:\n%s", want, out)
+		}
+	}
+}
+
+// Tests for visitsCmd
+
+func resetVisitsFlags() {
+	for _, name := range []string{"radius", "min-duration", "since", "from", "to"} {
+		f := visitsCmd.Flags().Lookup(name)
+		_ = f.Value.Set(f.DefValue)
+		f.Changed = false
+	}
+}
+
+func TestVisitsCmd_Success(t *testing.T) {
+	testDB(t)
+	defer resetVisitsFlags()
+
+	item := models.NewItem("harper")
+	_ = db.CreateItem(item)
+	home := "home"
+	base := time.Date(2024, 12, 14, 18, 0, 0, 0, time.Local)
+	_ = db.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 41.9500, -87.6500, &home, base))
+	_ = db.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 41.9501, -87.6500, nil, base.Add(20*time.Minute)))
+	_ = db.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 41.8781, -87.6298, nil, base.Add(time.Hour)))
+
+	out := captureStdout(t, func() {
+		if err := visitsCmd.RunE(visitsCmd, []string{"harper"}); err != nil {
+			t.Fatalf("visitsCmd failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, "home Dec 14, 6:00 PM – 6:20 PM") || !strings.Contains(out, "(20m)") {
+		t.Errorf("unexpected visits output: %q", out)
+	}
+
+	_ = visitsCmd.Flags().Set("min-duration", "30m")
+	out = captureStdout(t, func() {
+		if err := visitsCmd.RunE(visitsCmd, []string{"harper"}); err != nil {
+			t.Fatalf("visitsCmd failed: %v", err)
+		}
+	})
+	if strings.Contains(out, "home") {
+		t.Errorf("expected the home visit to be dropped with 30m minimum, got %q", out)
+	}
+
+	// The last stay runs to the end of the requested range
+	_ = visitsCmd.Flags().Set("to", "2024-12-14")
+	out = captureStdout(t, func() {
+		if err := visitsCmd.RunE(visitsCmd, []string{"harper"}); err != nil {
+			t.Fatalf("visitsCmd failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, "7:00 PM – 11:59 PM") {
+		t.Errorf("expected the last visit to run to the end of the range, got %q", out)
+	}
+}
+
+func TestVisitsCmd_Errors(t *testing.T) {
+	testDB(t)
+	defer resetVisitsFlags()
+
+	if err := visitsCmd.RunE(visitsCmd, []string{"missing"}); err == nil {
+		t.Error("expected error for nonexistent item")
+	}
+
+	_ = db.CreateItem(models.NewItem("harper"))
+	_ = visitsCmd.Flags().Set("radius", "0")
+	if err := visitsCmd.RunE(visitsCmd, []string{"harper"}); err == nil {
+		t.Error("expected error for zero radius")
+	}
+
+	resetVisitsFlags()
+	_ = visitsCmd.Flags().Set("since", "bogus")
+	if err := visitsCmd.RunE(visitsCmd, []string{"harper"}); err == nil {
+		t.Error("expected error for invalid --since")
+	}
+}
+
+// Tests for tripsCmd
+
+func resetTripsFlags() {
+	for _, name := range []string{"radius", "min-duration", "format", "output", "since", "from", "to", "smooth"} {
+		f := tripsCmd.Flags().Lookup(name)
+		_ = f.Value.Set(f.DefValue)
+		f.Changed = false
+	}
+}
+
+// seedCommute creates a van that stays at home, drives to the office, and stays there.
+func seedCommute(t *testing.T) {
+	t.Helper()
+	item := models.NewItem("van")
+	_ = db.CreateItem(item)
+	home := "home"
+	office := "office"
+	base := time.Date(2024, 12, 14, 8, 0, 0, 0, time.Local)
+	for _, p := range []struct {
+		lat, lng float64
+		label    *string
+		minutes  int
+	}{
+		{41.9500, -87.6500, &home, 0},
+		{41.9501, -87.6500, &home, 30},
+		{41.9200, -87.6400, nil, 40},
+		{41.8781, -87.6298, &office, 50},
+		{41.8782, -87.6298, &office, 80},
+	} {
+		pos := models.NewPositionWithRecordedAt(item.ID, p.lat, p.lng, p.label, base.Add(time.Duration(p.minutes)*time.Minute))
+		if err := db.CreatePosition(pos); err != nil {
+			t.Fatalf("CreatePosition failed: %v", err)
+		}
+	}
+}
+
+func TestTripsCmd_Text(t *testing.T) {
+	testDB(t)
+	defer resetTripsFlags()
+	seedCommute(t)
+
+	out := captureStdout(t, func() {
+		if err := tripsCmd.RunE(tripsCmd, []string{"van"}); err != nil {
+			t.Fatalf("tripsCmd failed: %v", err)
+		}
+	})
+	for _, want := range []string{"home → office", "Dec 14, 8:30 AM – 8:50 AM", "(20m)", "total 1 trip,"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in trips output, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestTripsCmd_GeoJSON(t *testing.T) {
+	testDB(t)
+	defer resetTripsFlags()
+	seedCommute(t)
+
+	outPath := filepath.Join(t.TempDir(), "trips.geojson")
+	_ = tripsCmd.Flags().Set("format", "geojson")
+	_ = tripsCmd.Flags().Set("output", outPath)
+	if err := tripsCmd.RunE(tripsCmd, []string{"van"}); err != nil {
+		t.Fatalf("tripsCmd failed: %v", err)
+	}
+
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	var fc geojson.FeatureCollection
+	if err := json.Unmarshal(data, &fc); err != nil {
+		t.Fatalf("invalid GeoJSON: %v", err)
+	}
+	if len(fc.Features) != 1 || fc.Features[0].Geometry.Type != "LineString" {
+		t.Fatalf("expected one LineString trip, got %+v", fc.Features)
+	}
+	props := fc.Features[0].Properties
+	if props["from"] != "home" || props["to"] != "office" || props["distance_meters"].(float64) < 8000 {
+		t.Errorf("unexpected trip properties: %v", props)
+	}
+}
+
+func TestTripsCmd_Errors(t *testing.T) {
+	testDB(t)
+	defer resetTripsFlags()
+
+	if err := tripsCmd.RunE(tripsCmd, []string{"missing"}); err == nil {
+		t.Error("expected error for nonexistent item")
+	}
+
+	_ = db.CreateItem(models.NewItem("van"))
+	_ = tripsCmd.Flags().Set("format", "gpx")
+	if err := tripsCmd.RunE(tripsCmd, []string{"van"}); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+
+	resetTripsFlags()
+	_ = tripsCmd.Flags().Set("radius", "-5")
+	if err := tripsCmd.RunE(tripsCmd, []string{"van"}); err == nil {
+		t.Error("expected error for negative radius")
+	}
+}
+
+// Tests for telemetry flags
+
+func resetAddTelemetryFlags() {
+	for _, name := range []string{"lat", "lng", "accuracy", "altitude", "speed", "heading", "battery", "source"} {
+		f := addCmd.Flags().Lookup(name)
+		_ = f.Value.Set(f.DefValue)
+		f.Changed = false
+	}
+}
+
+func TestAddCmd_Telemetry(t *testing.T) {
+	testDB(t)
+	defer resetAddTelemetryFlags()
+
+	_ = addCmd.Flags().Set("lat", "41.8781")
+	_ = addCmd.Flags().Set("lng", "-87.6298")
+	_ = addCmd.Flags().Set("accuracy", "12.5")
+	_ = addCmd.Flags().Set("battery", "0")
+	_ = addCmd.Flags().Set("source", "gps")
+	if err := addCmd.RunE(addCmd, []string{"phone"}); err != nil {
+		t.Fatalf("addCmd failed: %v", err)
+	}
+
+	item, _ := db.GetItemByName("phone")
+	pos, err := db.GetCurrentPosition(item.ID)
+	if err != nil {
+		t.Fatalf("position not created: %v", err)
+	}
+	if pos.Accuracy == nil || *pos.Accuracy != 12.5 || pos.Source == nil || *pos.Source != "gps" {
+		t.Errorf("unexpected telemetry: %+v", pos.Telemetry)
+	}
+	// An explicit zero is recorded; flags that weren't given stay unset
+	if pos.Battery == nil || *pos.Battery != 0 {
+		t.Errorf("expected battery 0 to be recorded, got %v", pos.Battery)
+	}
+	if pos.Altitude != nil || pos.Speed != nil || pos.Heading != nil {
+		t.Errorf("expected unset telemetry to stay nil, got %+v", pos.Telemetry)
+	}
+}
+
+func TestAddCmd_InvalidTelemetry(t *testing.T) {
+	testDB(t)
+	defer resetAddTelemetryFlags()
+
+	_ = addCmd.Flags().Set("lat", "41.8781")
+	_ = addCmd.Flags().Set("lng", "-87.6298")
+	_ = addCmd.Flags().Set("heading", "400")
+	if err := addCmd.RunE(addCmd, []string{"phone"}); err == nil {
+		t.Error("expected error for heading out of range")
+	}
+	if _, err := db.GetItemByName("phone"); err == nil {
+		t.Error("expected no item to be created for invalid input")
+	}
+}
+
+// Tests for atCmd
+
+func TestAtCmd_Success(t *testing.T) {
+	testDB(t)
+	defer func() { _ = atCmd.Flags().Set("interpolate", "false") }()
+
+	item := models.NewItem("van")
+	_ = db.CreateItem(item)
+	office := "office"
+	base := time.Date(2024, 12, 14, 13, 52, 0, 0, time.UTC)
+	_ = db.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 41.8781, -87.6298, &office, base))
+	_ = db.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 41.8781, -87.6098, nil, base.Add(20*time.Minute)))
+
+	out := captureStdout(t, func() {
+		if err := atCmd.RunE(atCmd, []string{"van", "2024-12-14T14:02:00Z"}); err != nil {
+			t.Fatalf("atCmd failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, "office") || !strings.Contains(out, "(10m earlier)") {
+		t.Errorf("unexpected output: %q", out)
+	}
+
+	_ = atCmd.Flags().Set("interpolate", "true")
+	out = captureStdout(t, func() {
+		if err := atCmd.RunE(atCmd, []string{"van", "2024-12-14T14:02:00Z"}); err != nil {
+			t.Fatalf("atCmd failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, "(41.8781, -87.6198)") || !strings.Contains(out, "(20m apart)") {
+		t.Errorf("unexpected interpolated output: %q", out)
+	}
+}
+
+func TestAtCmd_Clean(t *testing.T) {
+	testDB(t)
+	defer func() { _ = atCmd.Flags().Set("clean", "false") }()
+	db.(*storage.SQLiteDB).SetFilter(storage.FilterConfig{FilterPolicy: storage.FilterPolicy{MaxSpeedKmh: 200}})
+
+	item := models.NewItem("phone")
+	_ = db.CreateItem(item)
+	base := time.Date(2024, 12, 14, 12, 0, 0, 0, time.UTC)
+	_ = db.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 41.8781, -87.6298, nil, base))
+	// O'Hare a minute later: a cell-tower jump, stored flagged suspect
+	_ = db.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 41.9786, -87.9048, nil, base.Add(time.Minute)))
+
+	at := func() string {
+		t.Helper()
+		return captureStdout(t, func() {
+			if err := atCmd.RunE(atCmd, []string{"phone", "2024-12-14T12:01:30Z"}); err != nil {
+				t.Fatalf("atCmd failed: %v", err)
+			}
+		})
+	}
+	if out := at(); !strings.Contains(out, "41.9786") {
+		t.Errorf("expected the jump without --clean, got %q", out)
+	}
+	_ = atCmd.Flags().Set("clean", "true")
+	if out := at(); !strings.Contains(out, "41.8781") {
+		t.Errorf("expected --clean to skip the jump, got %q", out)
+	}
+}
+
+func TestAtCmd_Errors(t *testing.T) {
+	testDB(t)
+
+	if err := atCmd.RunE(atCmd, []string{"missing", "2024-12-14"}); err == nil {
+		t.Error("expected error for nonexistent item")
+	}
+
+	item := models.NewItem("van")
+	_ = db.CreateItem(item)
+	_ = db.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 41.0, -87.0, nil, time.Date(2024, 12, 14, 12, 0, 0, 0, time.UTC)))
+	if err := atCmd.RunE(atCmd, []string{"van", "yesterday-ish"}); err == nil {
+		t.Error("expected error for invalid time")
+	}
+	if err := atCmd.RunE(atCmd, []string{"van", "2024-12-13"}); err == nil {
+		t.Error("expected error for time before any position")
+	}
+}
+
+func resetExportReductionFlags() {
+	for _, name := range []string{"simplify", "simplify-method", "resample", "geometry", "format", "smooth"} {
+		f := exportCmd.Flags().Lookup(name)
+		_ = f.Value.Set(f.DefValue)
+		f.Changed = false
+	}
+}
+
+func TestExportCmd_Simplify(t *testing.T) {
+	testDB(t)
+	defer resetExportReductionFlags()
+
+	item := models.NewItem("phone")
+	_ = db.CreateItem(item)
+	base := time.Date(2024, 12, 14, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 12; i++ {
+		// A straight eastward drive, one fix a minute
+		_ = db.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 41.8781, -87.6298+float64(i)*0.001, nil, base.Add(time.Duration(i)*time.Minute)))
+	}
+
+	_ = exportCmd.Flags().Set("geometry", "line")
+	_ = exportCmd.Flags().Set("simplify", "10m")
+	out := captureStdout(t, func() {
+		if err := exportCmd.RunE(exportCmd, []string{"phone"}); err != nil {
+			t.Fatalf("exportCmd failed: %v", err)
+		}
+	})
+	var fc geojson.FeatureCollection
+	if err := json.Unmarshal([]byte(out), &fc); err != nil {
+		t.Fatalf("invalid GeoJSON: %v", err)
+	}
+	if len(fc.Features) != 1 || fc.Features[0].Properties["removed_count"] != float64(10) {
+		t.Errorf("expected one line with 10 points removed, got %+v", fc.Features)
+	}
+
+	// Points are resampled too: one per five minutes plus the last
+	resetExportReductionFlags()
+	_ = exportCmd.Flags().Set("resample", "5m")
+	out = captureStdout(t, func() {
+		if err := exportCmd.RunE(exportCmd, []string{"phone"}); err != nil {
+			t.Fatalf("exportCmd failed: %v", err)
+		}
+	})
+	if err := json.Unmarshal([]byte(out), &fc); err != nil {
+		t.Fatalf("invalid GeoJSON: %v", err)
+	}
+	if len(fc.Features) != 4 {
+		t.Errorf("expected 4 resampled points, got %d", len(fc.Features))
+	}
+}
+
+func TestExportCmd_ReductionErrors(t *testing.T) {
+	testDB(t)
+	defer resetExportReductionFlags()
+
+	item := models.NewItem("phone")
+	_ = db.CreateItem(item)
+	_ = db.CreatePosition(models.NewPosition(item.ID, 41.0, -87.0, nil))
+
+	tests := []struct {
+		flag, value string
+	}{
+		{"simplify", "ten"},
+		{"simplify", "-5m"},
+		{"simplify-method", "spline"},
+		{"resample", "-5m"},
+	}
+	for _, tt := range tests {
+		resetExportReductionFlags()
+		_ = exportCmd.Flags().Set(tt.flag, tt.value)
+		if err := exportCmd.RunE(exportCmd, []string{"phone"}); err == nil {
+			t.Errorf("expected error for --%s %s", tt.flag, tt.value)
+		}
+	}
+
+	resetExportReductionFlags()
+	_ = exportCmd.Flags().Set("format", "yaml")
+	_ = exportCmd.Flags().Set("simplify", "10m")
+	if err := exportCmd.RunE(exportCmd, []string{}); err == nil {
+		t.Error("expected error simplifying a YAML backup")
+	}
+}
+
+func TestParseMeters(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+	}{
+		{"10", 10},
+		{"10m", 10},
+		{"2.5m", 2.5},
+		{"1.5km", 1500},
+	}
+	for _, tt := range tests {
+		got, err := parseMeters(tt.input)
+		if err != nil || got != tt.want {
+			t.Errorf("parseMeters(%q) = %v, %v; want %v", tt.input, got, err, tt.want)
+		}
+	}
+	if _, err := parseMeters("10mi"); err == nil {
+		t.Error("expected error for unsupported unit")
+	}
+}
+
+func TestFilterCmd_AndClean(t *testing.T) {
+	testDB(t)
+
+	item := models.NewItem("phone")
+	_ = db.CreateItem(item)
+	base := time.Date(2024, 12, 14, 12, 0, 0, 0, time.UTC)
+	_ = db.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 41.8781, -87.6298, nil, base))
+	// O'Hare a minute later: a cell-tower jump
+	_ = db.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 41.9786, -87.9048, nil, base.Add(time.Minute)))
+	_ = db.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 41.8791, -87.6298, nil, base.Add(2*time.Minute)))
+
+	db.(*storage.SQLiteDB).SetFilter(storage.FilterConfig{FilterPolicy: storage.FilterPolicy{MaxSpeedKmh: 200}})
+	out := captureStdout(t, func() {
+		if err := filterCmd.RunE(filterCmd, []string{}); err != nil {
+			t.Fatalf("filterCmd failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, "1 flagged suspect, 0 cleared") {
+		t.Errorf("unexpected filter output: %q", out)
+	}
+
+	defer func() {
+		f := timelineCmd.Flags().Lookup("clean")
+		_ = f.Value.Set(f.DefValue)
+		f.Changed = false
+	}()
+	out = captureStdout(t, func() {
+		if err := timelineCmd.RunE(timelineCmd, []string{"phone"}); err != nil {
+			t.Fatalf("timelineCmd failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, "(suspect)") {
+		t.Errorf("expected the jump marked suspect, got %q", out)
+	}
+
+	_ = timelineCmd.Flags().Set("clean", "true")
+	out = captureStdout(t, func() {
+		if err := timelineCmd.RunE(timelineCmd, []string{"phone"}); err != nil {
+			t.Fatalf("timelineCmd failed: %v", err)
+		}
+	})
+	if strings.Contains(out, "41.9786") {
+		t.Errorf("expected --clean to hide the jump, got %q", out)
+	}
+
+	if err := filterCmd.RunE(filterCmd, []string{"missing"}); err == nil {
+		t.Error("expected error for nonexistent item")
+	}
+}
+
+// seedZigzagRide creates a bike that waits at home, rides east for ten minutes
+// with fixes every 10 seconds alternating 8 m either side of the road, and
+// waits at the end.
+func seedZigzagRide(t *testing.T) {
+	t.Helper()
+	item := models.NewItem("bike")
+	_ = db.CreateItem(item)
+	base := time.Date(2024, 12, 14, 8, 0, 0, 0, time.UTC)
+	create := func(lat, lng float64, at time.Time) {
+		if err := db.CreatePosition(models.NewPositionWithRecordedAt(item.ID, lat, lng, nil, at)); err != nil {
+			t.Fatalf("CreatePosition failed: %v", err)
+		}
+	}
+
+	create(41.8781, -87.6298, base)
+	start := base.Add(15 * time.Minute)
+	var lng float64
+	for i := 0; i <= 60; i++ {
+		lat := 41.8781 + 0.000072
+		if i%2 == 1 {
+			lat = 41.8781 - 0.000072
+		}
+		lng = -87.6298 + float64(i)*0.000169
+		create(lat, lng, start.Add(time.Duration(i)*10*time.Second))
+	}
+	create(41.8781, lng, start.Add(25*time.Minute))
+}
+
+func TestTripsCmd_Smooth(t *testing.T) {
+	testDB(t)
+	defer resetTripsFlags()
+	seedZigzagRide(t)
+
+	distance := func() float64 {
+		t.Helper()
+		out := captureStdout(t, func() {
+			if err := tripsCmd.RunE(tripsCmd, []string{"bike"}); err != nil {
+				t.Fatalf("tripsCmd failed: %v", err)
+			}
+		})
+		var fc geojson.FeatureCollection
+		if err := json.Unmarshal([]byte(out), &fc); err != nil {
+			t.Fatalf("invalid GeoJSON: %v", err)
+		}
+		if len(fc.Features) != 1 {
+			t.Fatalf("expected one trip, got %d", len(fc.Features))
+		}
+		return fc.Features[0].Properties["distance_meters"].(float64)
+	}
+
+	_ = tripsCmd.Flags().Set("format", "geojson")
+	raw := distance()
+	_ = tripsCmd.Flags().Set("smooth", "true")
+	smoothed := distance()
+	if smoothed > raw*0.85 {
+		t.Errorf("expected smoothing to remove the zig-zag distance: raw %.0f m, smoothed %.0f m", raw, smoothed)
+	}
+}
+
+func TestDistanceCmd_Smooth(t *testing.T) {
+	testDB(t)
+	defer func() { _ = distanceCmd.Flags().Set("smooth", "false") }()
+
+	// A parked bike whose fixes jitter 11 m either side of where it stands
+	item := models.NewItem("bike")
+	_ = db.CreateItem(item)
+	base := time.Date(2024, 12, 14, 8, 0, 0, 0, time.UTC)
+	for i := 0; i < 20; i++ {
+		lat := 41.8781 - 0.0001
+		if i%2 == 1 {
+			lat = 41.8781 + 0.0001
+		}
+		_ = db.CreatePosition(models.NewPositionWithRecordedAt(item.ID, lat, -87.6298, nil, base.Add(time.Duration(i)*time.Minute)))
+	}
+
+	measure := func() string {
+		t.Helper()
+		return captureStdout(t, func() {
+			if err := distanceCmd.RunE(distanceCmd, []string{"bike", "41.8781,-87.6298"}); err != nil {
+				t.Fatalf("distanceCmd failed: %v", err)
+			}
+		})
+	}
+	if out := measure(); !strings.Contains(out, "11 m") {
+		t.Errorf("expected the raw fix 11 m away, got %q", out)
+	}
+	_ = distanceCmd.Flags().Set("smooth", "true")
+	if out := measure(); strings.Contains(out, "11 m") {
+		t.Errorf("expected the smoothed position nearer, got %q", out)
+	}
+}
+
+func TestExportCmd_Smooth(t *testing.T) {
+	testDB(t)
+	defer resetExportReductionFlags()
+	seedZigzagRide(t)
+
+	_ = exportCmd.Flags().Set("smooth", "true")
+	out := captureStdout(t, func() {
+		if err := exportCmd.RunE(exportCmd, []string{"bike"}); err != nil {
+			t.Fatalf("exportCmd failed: %v", err)
+		}
+	})
+	var fc geojson.FeatureCollection
+	if err := json.Unmarshal([]byte(out), &fc); err != nil {
+		t.Fatalf("invalid GeoJSON: %v", err)
+	}
+	if len(fc.Features) != 63 {
+		t.Fatalf("expected every position exported, got %d", len(fc.Features))
+	}
+	// Mid-ride fixes are drawn back toward the road
+	mid := fc.Features[31].Geometry.Coordinates.([]interface{})
+	if lat := mid[1].(float64); math.Abs(lat-41.8781) > 0.00003 {
+		t.Errorf("expected a smoothed mid-ride latitude, got %.6f", lat)
+	}
+
+	// Stored positions keep their raw coordinates
+	item, _ := db.GetItemByName("bike")
+	timeline, _ := db.GetTimeline(item.ID)
+	if lat := timeline[31].Latitude; math.Abs(lat-41.8781) < 0.00007 {
+		t.Errorf("expected raw positions unchanged, got %.6f", lat)
+	}
+
+	_ = exportCmd.Flags().Set("format", "yaml")
+	if err := exportCmd.RunE(exportCmd, []string{}); err == nil {
+		t.Error("expected error for --smooth with yaml")
+	}
+}
+
 // Helper function
 
 func contains(slice []string, item string) bool {
@@ -1727,3 +3325,59 @@ func contains(slice []string, item string) bool {
 	}
 	return false
 }
+
+// captureStdout runs fn and returns what it printed to stdout.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	orig := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	os.Stdout = w
+	defer func() { os.Stdout = orig }()
+
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+
+	fn()
+	_ = w.Close()
+	return <-done
+}
+
+func TestGeocodeCmd(t *testing.T) {
+	testDB(t)
+
+	item := models.NewItem("phone")
+	_ = db.CreateItem(item)
+	_ = db.CreatePosition(models.NewPosition(item.ID, 41.8781, -87.6298, nil))
+
+	if err := geocodeCmd.RunE(geocodeCmd, []string{}); !errors.Is(err, storage.ErrNoGeocoder) {
+		t.Fatalf("expected ErrNoGeocoder without a gazetteer, got %v", err)
+	}
+
+	chicago := geocode.City{Name: "Chicago", Region: "Illinois", Country: "US", Latitude: 41.85003, Longitude: -87.65005}
+	db.(*storage.SQLiteDB).SetGeocoder(geocode.New([]geocode.City{chicago}, 50))
+	out := captureStdout(t, func() {
+		if err := geocodeCmd.RunE(geocodeCmd, []string{"phone"}); err != nil {
+			t.Fatalf("geocodeCmd failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, "1 positions geocoded") {
+		t.Errorf("unexpected geocode output: %q", out)
+	}
+	current, err := db.GetCurrentPosition(item.ID)
+	if err != nil {
+		t.Fatalf("GetCurrentPosition failed: %v", err)
+	}
+	if current.Locality == nil || *current.Locality != "Chicago, Illinois, US" {
+		t.Errorf("expected backfilled locality, got %v", current.Locality)
+	}
+
+	if err := geocodeCmd.RunE(geocodeCmd, []string{"missing"}); err == nil {
+		t.Error("expected error for nonexistent item")
+	}
+}
@@ -0,0 +1,116 @@
+// ABOUTME: Position distance command
+// ABOUTME: Measures distance and bearing between items or coordinates
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/harper/position/internal/geo"
+	"github.com/harper/position/internal/models"
+	"github.com/harper/position/internal/track"
+	"github.com/harper/position/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var distanceCmd = &cobra.Command{
+	Use:   "distance [a] [b]",
+	Short: "Measure distance and bearing between two items or coordinates",
+	Long: `Measure the distance and initial bearing from a to b.
+
+Each argument is either an item name (its current position is used) or a
+"lat,lng" coordinate pair. Distance is computed on the WGS84 ellipsoid.
+Use --from and --to for a coordinate with a negative latitude, which would
+otherwise be read as a flag. With --smooth, an item's position is the end of
+its Kalman-smoothed track rather than its last raw fix.
+
+Examples:
+  position distance car harper
+  position distance harper 41.8781,-87.6298
+  position distance --from -33.8688,151.2093 --to 40.7128,-74.0060
+  position distance harper --to -33.8688,151.2093
+  position distance bike home-gate --smooth`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		from, _ := cmd.Flags().GetString("from")
+		to, _ := cmd.Flags().GetString("to")
+		if from == "" && len(args) > 0 {
+			from, args = args[0], args[1:]
+		}
+		if to == "" && len(args) > 0 {
+			to, args = args[0], args[1:]
+		}
+		if from == "" || to == "" || len(args) > 0 {
+			return fmt.Errorf("need exactly two points: give them as arguments or with --from/--to")
+		}
+
+		smooth, _ := cmd.Flags().GetBool("smooth")
+		fromLat, fromLng, err := resolvePoint(from, smooth)
+		if err != nil {
+			return err
+		}
+		toLat, toLng, err := resolvePoint(to, smooth)
+		if err != nil {
+			return err
+		}
+
+		meters := geo.Vincenty(fromLat, fromLng, toLat, toLng)
+		fmt.Printf("%s → %s: %s",
+			color.GreenString(from),
+			color.GreenString(to),
+			color.CyanString(ui.FormatDistance(meters)))
+		if meters > 0 {
+			fmt.Printf(" %s",
+				color.New(color.Faint).Sprintf("bearing %s",
+					ui.FormatBearing(geo.InitialBearing(fromLat, fromLng, toLat, toLng))))
+		}
+		fmt.Println()
+		return nil
+	},
+}
+
+// resolvePoint returns the coordinates for a "lat,lng" pair, or for the
+// current position of the item with that name, taken from its smoothed track
+// with smooth.
+func resolvePoint(arg string, smooth bool) (lat, lng float64, err error) {
+	if latStr, lngStr, ok := strings.Cut(arg, ","); ok {
+		var latErr, lngErr error
+		lat, latErr = strconv.ParseFloat(strings.TrimSpace(latStr), 64)
+		lng, lngErr = strconv.ParseFloat(strings.TrimSpace(lngStr), 64)
+		if latErr == nil && lngErr == nil {
+			if err := models.ValidateCoordinates(lat, lng); err != nil {
+				return 0, 0, err
+			}
+			return lat, lng, nil
+		}
+	}
+
+	item, err := db.GetItemByName(arg)
+	if err != nil {
+		return 0, 0, fmt.Errorf("item '%s' not found", arg)
+	}
+	if smooth {
+		timeline, err := db.GetTimeline(item.ID)
+		if err != nil || len(timeline) == 0 {
+			return 0, 0, fmt.Errorf("no position found for '%s'", arg)
+		}
+		smoothed := track.Smooth(timeline, track.DefaultSmoothOptions())
+		pos := smoothed[len(smoothed)-1]
+		return pos.Latitude, pos.Longitude, nil
+	}
+	pos, err := db.GetCurrentPosition(item.ID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("no position found for '%s'", arg)
+	}
+	return pos.Latitude, pos.Longitude, nil
+}
+
+func init() {
+	distanceCmd.Flags().String("from", "", "starting item name or lat,lng")
+	distanceCmd.Flags().String("to", "", "destination item name or lat,lng")
+	distanceCmd.Flags().Bool("smooth", false, "use the end of each item's Kalman-smoothed track")
+	rootCmd.AddCommand(distanceCmd)
+}
@@ -1,19 +1,25 @@
-// ABOUTME: Export command for generating GeoJSON, markdown, and YAML output
-// ABOUTME: Supports time filtering and multiple geometry types
+// ABOUTME: Export command for generating GeoJSON, GPX, KML/KMZ, CSV, markdown, and YAML output
+// ABOUTME: Supports time filtering, multiple geometry types, track smoothing, and simplification
 
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/harper/position/internal/csv"
 	"github.com/harper/position/internal/geojson"
+	"github.com/harper/position/internal/gpx"
+	"github.com/harper/position/internal/kml"
 	"github.com/harper/position/internal/models"
 	"github.com/harper/position/internal/storage"
+	"github.com/harper/position/internal/track"
 	"github.com/spf13/cobra"
 )
 
@@ -24,7 +30,7 @@ var exportCmd = &cobra.Command{
 	Use:     "export [name]",
 	Aliases: []string{"e"},
 	Short:   "Export positions in various formats",
-	Long: `Export positions as GeoJSON, Markdown, or YAML.
+	Long: `Export positions as GeoJSON, GPX, KML/KMZ, CSV, Markdown, or YAML.
 
 Examples:
   # Export all positions for an item as GeoJSON
@@ -46,13 +52,33 @@ Examples:
   # Export as LineString (path/track)
   position export harper --format geojson --geometry line
 
+  # Export as GPX (one track per item, labeled positions as waypoints)
+  position export harper --format gpx --since 7d --output track.gpx
+
+  # Export for Google Earth (folder per item, animated gx:Track)
+  position export --format kmz --since 7d --output positions.kmz
+
+  # Export as CSV for spreadsheets
+  position export --format csv --from 2024-12-01 --output positions.csv
+
+  # Leave out positions the outlier filter flagged
+  position export phone --format gpx --clean
+
+  # Smooth out GPS zig-zags on a walk or ride
+  position export bike --format gpx --clean --smooth
+
+  # Thin a month-long track for a web map
+  position export phone --geometry line --since 1m --resample 5m --simplify 10m
+
   # Save to file
   position export harper --format geojson --output map.geojson`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		format, _ := cmd.Flags().GetString("format")
-		if format != "geojson" && format != "markdown" && format != "yaml" {
-			return fmt.Errorf("unsupported format: %s (use 'geojson', 'markdown', or 'yaml')", format)
+		switch format {
+		case "geojson", "gpx", "kml", "kmz", "csv", "markdown", "yaml":
+		default:
+			return fmt.Errorf("unsupported format: %s (use 'geojson', 'gpx', 'kml', 'kmz', 'csv', 'markdown', or 'yaml')", format)
 		}
 
 		geometry, _ := cmd.Flags().GetString("geometry")
@@ -60,33 +86,21 @@ Examples:
 			return fmt.Errorf("unsupported geometry: %s (use 'points' or 'line')", geometry)
 		}
 
-		// Parse time filters
-		since, _ := cmd.Flags().GetString("since")
-		from, _ := cmd.Flags().GetString("from")
-		to, _ := cmd.Flags().GetString("to")
-
-		var sinceTime, fromTime, toTime time.Time
-		var err error
+		sinceTime, fromTime, toTime, err := parseTimeFilters(cmd)
+		if err != nil {
+			return err
+		}
 
-		if since != "" {
-			sinceTime, err = parseDuration(since)
-			if err != nil {
-				return fmt.Errorf("invalid --since value: %w", err)
-			}
+		reduction, err := parseReduction(cmd)
+		if err != nil {
+			return err
 		}
-		if from != "" {
-			fromTime, err = parseDate(from)
-			if err != nil {
-				return fmt.Errorf("invalid --from value: %w", err)
-			}
+		if reduction.Enabled() && (format == "markdown" || format == "yaml") {
+			return fmt.Errorf("--simplify and --resample do not apply to %s exports", format)
 		}
-		if to != "" {
-			toTime, err = parseDate(to)
-			if err != nil {
-				return fmt.Errorf("invalid --to value: %w", err)
-			}
-			// Set to end of day
-			toTime = toTime.Add(24*time.Hour - time.Second)
+		smooth, _ := cmd.Flags().GetBool("smooth")
+		if smooth && (format == "markdown" || format == "yaml") {
+			return fmt.Errorf("--smooth does not apply to %s exports", format)
 		}
 
 		// Build item name cache for resolving IDs to names
@@ -123,6 +137,23 @@ Examples:
 				return err
 			}
 		}
+		if clean, _ := cmd.Flags().GetBool("clean"); clean {
+			positions = storage.Clean(positions)
+		}
+		if smooth {
+			positions = track.SmoothAll(positions, track.DefaultSmoothOptions())
+		}
+
+		if reduction.Enabled() {
+			reduced := track.ReduceAll(positions, reduction)
+			fmt.Fprintf(os.Stderr, "Reduced %d positions to %d (removed %d)\n",
+				len(positions), len(reduced), len(positions)-len(reduced))
+			// Lines are reduced per item as they are built, which also
+			// records the count removed on each feature
+			if geometry != "line" || format != "geojson" {
+				positions = reduced
+			}
+		}
 
 		output, _ := cmd.Flags().GetString("output")
 
@@ -132,20 +163,26 @@ Examples:
 			return exportMarkdown(args, output)
 		case "yaml":
 			return exportYAML(output)
+		case "gpx":
+			return exportGPX(positions, nameResolver, output)
+		case "kml", "kmz":
+			return exportKML(positions, nameResolver, format == "kmz", output)
+		case "csv":
+			return exportCSV(positions, nameResolver, output)
 		default:
-			return exportGeoJSON(positions, geometry, nameResolver, output)
+			return exportGeoJSON(positions, geometry, reduction, nameResolver, output)
 		}
 	},
 }
 
-func exportGeoJSON(positions []*models.Position, geometry string, nameResolver func(string) string, output string) error {
+func exportGeoJSON(positions []*models.Position, geometry string, reduction track.Reduction, nameResolver func(string) string, output string) error {
 	if len(positions) == 0 {
 		return fmt.Errorf("no positions found")
 	}
 
 	var fc *geojson.FeatureCollection
 	if geometry == "line" {
-		fc = geojson.ToLineFeatureCollection(positions, nameResolver)
+		fc = geojson.ToLineFeatureCollection(positions, nameResolver, reduction)
 	} else {
 		fc = geojson.ToPointsFeatureCollection(positions, nameResolver)
 	}
@@ -167,6 +204,80 @@ func exportGeoJSON(positions []*models.Position, geometry string, nameResolver f
 	return nil
 }
 
+func exportGPX(positions []*models.Position, nameResolver func(string) string, output string) error {
+	if len(positions) == 0 {
+		return fmt.Errorf("no positions found")
+	}
+
+	xmlBytes, err := gpx.FromPositions(positions, nameResolver).ToXML()
+	if err != nil {
+		return fmt.Errorf("failed to generate GPX: %w", err)
+	}
+
+	if output != "" {
+		if err := os.WriteFile(output, xmlBytes, 0644); err != nil { //nolint:gosec // 0644 is intentional for data export files
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %d positions to %s\n", len(positions), output)
+	} else {
+		fmt.Print(string(xmlBytes))
+	}
+
+	return nil
+}
+
+func exportKML(positions []*models.Position, nameResolver func(string) string, zipped bool, output string) error {
+	if len(positions) == 0 {
+		return fmt.Errorf("no positions found")
+	}
+
+	doc := kml.FromPositions(positions, nameResolver)
+
+	var data []byte
+	var err error
+	if zipped {
+		data, err = doc.ToKMZ()
+	} else {
+		data, err = doc.ToXML()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to generate KML: %w", err)
+	}
+
+	if output != "" {
+		if err := os.WriteFile(output, data, 0644); err != nil { //nolint:gosec // 0644 is intentional for data export files
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %d positions to %s\n", len(positions), output)
+	} else {
+		_, _ = os.Stdout.Write(data)
+	}
+
+	return nil
+}
+
+func exportCSV(positions []*models.Position, nameResolver func(string) string, output string) error {
+	if len(positions) == 0 {
+		return fmt.Errorf("no positions found")
+	}
+
+	var buf bytes.Buffer
+	if err := csv.Write(&buf, positions, nameResolver); err != nil {
+		return fmt.Errorf("failed to generate CSV: %w", err)
+	}
+
+	if output != "" {
+		if err := os.WriteFile(output, buf.Bytes(), 0644); err != nil { //nolint:gosec // 0644 is intentional for data export files
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %d positions to %s\n", len(positions), output)
+	} else {
+		fmt.Print(buf.String())
+	}
+
+	return nil
+}
+
 func exportMarkdown(args []string, output string) error {
 	var itemID *uuid.UUID
 	if len(args) == 1 {
@@ -275,6 +386,73 @@ func parseDuration(s string) (time.Time, error) {
 	return time.Now().Add(-duration), nil
 }
 
+// parseTimeFilters reads the --since, --from, and --to flags. Unset flags
+// yield zero times; --to is extended to the end of the given day.
+func parseTimeFilters(cmd *cobra.Command) (since, from, to time.Time, err error) {
+	sinceStr, _ := cmd.Flags().GetString("since")
+	fromStr, _ := cmd.Flags().GetString("from")
+	toStr, _ := cmd.Flags().GetString("to")
+
+	if sinceStr != "" {
+		since, err = parseDuration(sinceStr)
+		if err != nil {
+			return since, from, to, fmt.Errorf("invalid --since value: %w", err)
+		}
+	}
+	if fromStr != "" {
+		from, err = parseDate(fromStr)
+		if err != nil {
+			return since, from, to, fmt.Errorf("invalid --from value: %w", err)
+		}
+	}
+	if toStr != "" {
+		to, err = parseDate(toStr)
+		if err != nil {
+			return since, from, to, fmt.Errorf("invalid --to value: %w", err)
+		}
+		// Set to end of day
+		to = to.Add(24*time.Hour - time.Second)
+	}
+	return since, from, to, nil
+}
+
+// parseReduction reads the --simplify, --simplify-method, and --resample flags.
+func parseReduction(cmd *cobra.Command) (track.Reduction, error) {
+	var r track.Reduction
+	r.Interval, _ = cmd.Flags().GetDuration("resample")
+	method, _ := cmd.Flags().GetString("simplify-method")
+	r.Method = track.SimplifyMethod(method)
+
+	if s, _ := cmd.Flags().GetString("simplify"); s != "" {
+		tolerance, err := parseMeters(s)
+		if err != nil {
+			return r, fmt.Errorf("invalid --simplify value: %w", err)
+		}
+		r.Tolerance = tolerance
+	}
+	if err := r.Validate(); err != nil {
+		return r, err
+	}
+	return r, nil
+}
+
+// parseMeters parses distances like "10", "10m", or "1.5km" into meters.
+func parseMeters(s string) (float64, error) {
+	scale := 1.0
+	num := s
+	switch {
+	case strings.HasSuffix(s, "km"):
+		scale, num = 1000, strings.TrimSuffix(s, "km")
+	case strings.HasSuffix(s, "m"):
+		num = strings.TrimSuffix(s, "m")
+	}
+	v, err := strconv.ParseFloat(num, 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid distance '%s' (use e.g., 10m, 1.5km)", s)
+	}
+	return v * scale, nil
+}
+
 // parseDate parses date strings in RFC3339 or YYYY-MM-DD format.
 func parseDate(s string) (time.Time, error) {
 	// Try RFC3339 first
@@ -289,12 +467,17 @@ func parseDate(s string) (time.Time, error) {
 }
 
 func init() {
-	exportCmd.Flags().StringP("format", "f", "geojson", "output format (geojson, markdown, yaml)")
+	exportCmd.Flags().StringP("format", "f", "geojson", "output format (geojson, gpx, kml, kmz, csv, markdown, yaml)")
 	exportCmd.Flags().StringP("geometry", "g", "points", "geometry type (points, line)")
 	exportCmd.Flags().String("since", "", "relative time filter (e.g., 24h, 7d, 1w)")
 	exportCmd.Flags().String("from", "", "start date (YYYY-MM-DD or RFC3339)")
 	exportCmd.Flags().String("to", "", "end date (YYYY-MM-DD or RFC3339)")
 	exportCmd.Flags().StringP("output", "o", "", "output file (default: stdout)")
+	exportCmd.Flags().String("simplify", "", "simplify tracks to within a distance (e.g., 10m, 1km)")
+	exportCmd.Flags().String("simplify-method", string(track.DouglasPeucker), "simplification algorithm (douglas-peucker, visvalingam)")
+	exportCmd.Flags().Duration("resample", 0, "keep at most one position per interval (e.g., 5m)")
+	exportCmd.Flags().Bool("clean", false, "exclude positions flagged suspect by the outlier filter")
+	exportCmd.Flags().Bool("smooth", false, "export a Kalman-smoothed track instead of raw fixes")
 
 	rootCmd.AddCommand(exportCmd)
 }
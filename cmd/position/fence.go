@@ -0,0 +1,187 @@
+// ABOUTME: Geofence management commands
+// ABOUTME: Adds, lists, and removes geofences and shows enter/exit events
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/google/uuid"
+	"github.com/harper/position/internal/models"
+	"github.com/harper/position/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var fenceCmd = &cobra.Command{
+	Use:     "fence",
+	Aliases: []string{"geofence"},
+	Short:   "Manage geofences and view enter/exit events",
+	Long: `Geofences are named areas. Whenever a new position moves an item into or
+out of a geofence, an enter or exit event is recorded.
+
+Examples:
+  position fence add garage --lat 41.8781 --lng -87.6298 --radius 50
+  position fence add park --polygon "41.88,-87.63;41.88,-87.62;41.87,-87.62;41.87,-87.63"
+  position fence list
+  position fence events --item car --fence garage
+  position fence remove garage`,
+}
+
+var fenceAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add a circular or polygon geofence",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		shape, err := shapeFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		fence := &models.Geofence{ID: uuid.New(), Name: name, Shape: shape, CreatedAt: time.Now()}
+		if err := fence.Validate(); err != nil {
+			return err
+		}
+		if err := db.CreateGeofence(fence); err != nil {
+			return fmt.Errorf("failed to create geofence: %w", err)
+		}
+
+		color.Green("Geofence %s added", name)
+		fmt.Printf("  %s\n", ui.FormatGeofence(fence))
+		return nil
+	},
+}
+
+var fenceListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List geofences",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fences, err := db.ListGeofences()
+		if err != nil {
+			return fmt.Errorf("failed to list geofences: %w", err)
+		}
+
+		if len(fences) == 0 {
+			fmt.Println("No geofences yet. Use 'position fence add' to add one.")
+			return nil
+		}
+
+		for _, fence := range fences {
+			fmt.Println(ui.FormatGeofence(fence))
+		}
+		return nil
+	},
+}
+
+var fenceRemoveCmd = &cobra.Command{
+	Use:     "remove <name>",
+	Aliases: []string{"rm"},
+	Short:   "Remove a geofence and its events",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		fence, err := db.GetGeofenceByName(name)
+		if err != nil {
+			return fmt.Errorf("geofence '%s' not found", name)
+		}
+
+		if err := db.DeleteGeofence(fence.ID); err != nil {
+			return fmt.Errorf("failed to remove geofence: %w", err)
+		}
+
+		color.Green("Removed geofence %s", name)
+		return nil
+	},
+}
+
+var fenceEventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Show geofence enter/exit events (newest first)",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		itemName, _ := cmd.Flags().GetString("item")
+		fenceName, _ := cmd.Flags().GetString("fence")
+
+		itemID := uuid.Nil
+		if itemName != "" {
+			item, err := db.GetItemByName(itemName)
+			if err != nil {
+				return fmt.Errorf("item '%s' not found", itemName)
+			}
+			itemID = item.ID
+		}
+		fenceID := uuid.Nil
+		if fenceName != "" {
+			fence, err := db.GetGeofenceByName(fenceName)
+			if err != nil {
+				return fmt.Errorf("geofence '%s' not found", fenceName)
+			}
+			fenceID = fence.ID
+		}
+
+		events, err := db.ListGeofenceEvents(itemID, fenceID)
+		if err != nil {
+			return fmt.Errorf("failed to list geofence events: %w", err)
+		}
+
+		if len(events) == 0 {
+			fmt.Println("No geofence events")
+			return nil
+		}
+
+		itemNames, err := itemNameLookup()
+		if err != nil {
+			return err
+		}
+		fenceNames, err := geofenceNameLookup()
+		if err != nil {
+			return err
+		}
+
+		for _, e := range events {
+			fmt.Println(ui.FormatGeofenceEvent(e, itemNames[e.ItemID], fenceNames[e.GeofenceID]))
+		}
+		return nil
+	},
+}
+
+// itemNameLookup returns a map of item IDs to names.
+func itemNameLookup() (map[uuid.UUID]string, error) {
+	items, err := db.ListItems()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list items: %w", err)
+	}
+	names := make(map[uuid.UUID]string, len(items))
+	for _, item := range items {
+		names[item.ID] = item.Name
+	}
+	return names, nil
+}
+
+// geofenceNameLookup returns a map of geofence IDs to names.
+func geofenceNameLookup() (map[uuid.UUID]string, error) {
+	fences, err := db.ListGeofences()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list geofences: %w", err)
+	}
+	names := make(map[uuid.UUID]string, len(fences))
+	for _, fence := range fences {
+		names[fence.ID] = fence.Name
+	}
+	return names, nil
+}
+
+func init() {
+	addShapeFlags(fenceAddCmd, 0)
+
+	fenceEventsCmd.Flags().String("item", "", "only show events for this item")
+	fenceEventsCmd.Flags().String("fence", "", "only show events for this geofence")
+
+	fenceCmd.AddCommand(fenceAddCmd, fenceListCmd, fenceRemoveCmd, fenceEventsCmd)
+	rootCmd.AddCommand(fenceCmd)
+}
@@ -0,0 +1,57 @@
+// ABOUTME: Position filter command
+// ABOUTME: Re-applies the outlier filter to stored positions, flagging impossible jumps as suspect
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/harper/position/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var filterCmd = &cobra.Command{
+	Use:   "filter [name]",
+	Short: "Flag suspect positions in stored history",
+	Long: `Re-apply the outlier filter from the config file to positions already
+stored, flagging those implying impossible speed or reporting poor accuracy as
+suspect and clearing flags the current settings no longer warrant. New
+positions are checked as they arrive; run this after changing the filter or
+importing old history. Suspect positions are kept; pass --clean to timeline,
+export, and the MCP tools to leave them out.
+
+Examples:
+  position filter harper
+  position filter`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var items []*models.Item
+		if len(args) == 1 {
+			item, err := db.GetItemByName(args[0])
+			if err != nil {
+				return fmt.Errorf("item '%s' not found", args[0])
+			}
+			items = append(items, item)
+		} else {
+			var err error
+			items, err = db.ListItems()
+			if err != nil {
+				return fmt.Errorf("failed to list items: %w", err)
+			}
+		}
+
+		for _, item := range items {
+			flagged, cleared, err := db.FilterPositions(item.ID)
+			if err != nil {
+				return fmt.Errorf("failed to filter %s: %w", item.Name, err)
+			}
+			fmt.Printf("%s: %d flagged suspect, %d cleared\n", color.GreenString(item.Name), flagged, cleared)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(filterCmd)
+}
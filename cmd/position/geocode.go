@@ -0,0 +1,60 @@
+// ABOUTME: Locality backfill command
+// ABOUTME: Reverse geocodes stored positions that were recorded before geocoding was configured
+
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/harper/position/internal/models"
+	"github.com/harper/position/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var geocodeCmd = &cobra.Command{
+	Use:   "geocode [name]",
+	Short: "Fill in localities for stored positions",
+	Long: `Reverse geocode positions already stored that have no locality, using the
+gazetteer from the geocode block of the config file. New positions are geocoded
+as they arrive; run this after configuring geocoding or importing old history.
+Positions that already have a locality are left alone.
+
+Examples:
+  position geocode harper
+  position geocode`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var items []*models.Item
+		if len(args) == 1 {
+			item, err := db.GetItemByName(args[0])
+			if err != nil {
+				return fmt.Errorf("item '%s' not found", args[0])
+			}
+			items = append(items, item)
+		} else {
+			var err error
+			items, err = db.ListItems()
+			if err != nil {
+				return fmt.Errorf("failed to list items: %w", err)
+			}
+		}
+
+		for _, item := range items {
+			geocoded, err := db.GeocodePositions(item.ID)
+			if errors.Is(err, storage.ErrNoGeocoder) {
+				return fmt.Errorf("%w: add a geocode block with an existing gazetteer to the config file", err)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to geocode %s: %w", item.Name, err)
+			}
+			fmt.Printf("%s: %d positions geocoded\n", color.GreenString(item.Name), geocoded)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(geocodeCmd)
+}
@@ -1,36 +1,90 @@
-// ABOUTME: Import command for restoring data from YAML backup
-// ABOUTME: Supports importing backup files created by the backup command
+// ABOUTME: Import command for restoring backups and loading external track files
+// ABOUTME: Supports YAML backups, GPX, column-mapped CSV, and Google Takeout location history
 
 package main
 
 import (
 	"bufio"
+	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
+	"github.com/harper/position/internal/csv"
+	"github.com/harper/position/internal/gpx"
+	"github.com/harper/position/internal/models"
 	"github.com/harper/position/internal/storage"
+	"github.com/harper/position/internal/takeout"
 	"github.com/spf13/cobra"
 )
 
 var importCmd = &cobra.Command{
 	Use:   "import <file>",
-	Short: "Import data from a YAML backup",
-	Long: `Import items and positions from a YAML backup file.
+	Short: "Import data from a YAML backup, GPX, CSV, or Google Takeout file",
+	Long: `Import items and positions from a YAML backup, a GPX file, a CSV file,
+or a Google Takeout location history export.
 
-This restores data from a backup created with 'position backup'.
+With --format yaml (default) this restores data from a backup created
+with 'position backup'.
+
+With --format gpx each track becomes an item named after the track
+(or --name), and each track point becomes a position. Waypoints label
+the track point recorded at the same time, or are added as labeled
+positions for the item named in their description (or --name).
+
+With --format csv each row becomes a position. --map maps position fields
+(name, lat, lng, at, label) to CSV column names; unmapped fields default to
+the columns written by 'position export --format csv'. A mapped column that
+isn't in the header is an error, while unmapped name, at, and label columns
+are used only if present. Items are created on demand. Rows that fail to
+parse are reported and skipped.
+
+With --format takeout the file may be Records.json, the on-device
+Timeline.json (Android) or location-history.json (iOS), or a monthly
+Semantic Location History file. All points go to the item named by --name.
+Semantic place visits become labeled positions (e.g. "home", "work").
 
 WARNING: This will add to existing data, not replace it.
 Use 'position reset' first if you want a clean import.
 
 Examples:
   position import positions.yaml
-  position import ~/backups/positions-20241214.yaml`,
+  position import ~/backups/positions-20241214.yaml
+  position import --format gpx ride.gpx
+  position import --format gpx --name bike ride.gpx
+  position import --format csv export.csv
+  position import --format csv --map name=device,lat=latitude,lng=lon,at=timestamp,label=place fleet.csv
+  position import --format csv --name van --map lat=y,lng=x,at=ts --time-layout unix van.csv
+  position import --format takeout --name harper Records.json
+  position import --format takeout --name harper Timeline.json`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		filename := args[0]
 
+		format, _ := cmd.Flags().GetString("format")
+		switch format {
+		case "yaml", "gpx", "csv":
+		case "takeout":
+			if name, _ := cmd.Flags().GetString("name"); name == "" {
+				return fmt.Errorf("--name is required for takeout imports")
+			}
+		default:
+			return fmt.Errorf("unsupported format: %s (use 'yaml', 'gpx', 'csv', or 'takeout')", format)
+		}
+
+		var columns csv.ColumnMap
+		if format == "csv" {
+			mapStr, _ := cmd.Flags().GetString("map")
+			var err error
+			columns, err = csv.ParseColumnMap(mapStr)
+			if err != nil {
+				return fmt.Errorf("invalid --map value: %w", err)
+			}
+		}
+
 		data, err := os.ReadFile(filename)
 		if err != nil {
 			return fmt.Errorf("failed to read file: %w", err)
@@ -48,6 +102,33 @@ Examples:
 			}
 		}
 
+		if format != "yaml" {
+			name, _ := cmd.Flags().GetString("name")
+			var summary *importSummary
+			switch format {
+			case "gpx":
+				summary, err = importGPX(data, name)
+			case "csv":
+				layout, _ := cmd.Flags().GetString("time-layout")
+				summary, err = importCSV(data, columns, layout, name)
+			default:
+				summary, err = importTakeout(data, name)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to import: %w", err)
+			}
+			color.Green("Import complete")
+			fmt.Printf("  %d positions imported", summary.Imported)
+			if summary.Duplicates > 0 {
+				fmt.Printf(", %d duplicates skipped", summary.Duplicates)
+			}
+			if summary.Skipped > 0 {
+				fmt.Printf(", %d invalid skipped", summary.Skipped)
+			}
+			fmt.Println()
+			return nil
+		}
+
 		if err := storage.ImportBackup(db, data); err != nil {
 			return fmt.Errorf("failed to import: %w", err)
 		}
@@ -62,8 +143,233 @@ Examples:
 	},
 }
 
+// importSummary holds counts for imports of external formats.
+type importSummary struct {
+	Imported   int
+	Duplicates int // repeats dropped by the dedup policy
+	Skipped    int // unusable records
+}
+
+// create stores pos, counting it as imported or, when the dedup policy
+// dropped it as a repeat, as a duplicate.
+func (s *importSummary) create(pos *models.Position) error {
+	if err := db.CreatePosition(pos); err != nil {
+		return fmt.Errorf("failed to create position: %w", err)
+	}
+	_, err := db.GetPosition(pos.ID)
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		s.Duplicates++
+	case err != nil:
+		return fmt.Errorf("failed to check position: %w", err)
+	default:
+		s.Imported++
+	}
+	return nil
+}
+
+// importGPX imports GPX tracks and waypoints, creating items as needed.
+// If name is non-empty it overrides the track and waypoint item names.
+// Waypoints without a valid item name are reported on stderr and counted
+// as skipped.
+func importGPX(data []byte, name string) (*importSummary, error) {
+	if name != "" {
+		if err := models.ValidateName(name); err != nil {
+			return nil, err
+		}
+	}
+	doc, err := gpx.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &importSummary{}
+
+	// Index waypoint labels by item and time so they can be attached to track points
+	type wptKey struct {
+		item string
+		at   int64
+	}
+	labels := make(map[wptKey]string)
+	var orphans []gpx.Point
+	for _, wpt := range doc.Waypoints {
+		itemName := wpt.Desc
+		if name != "" {
+			itemName = name
+		}
+		at, err := wpt.RecordedAt()
+		if err != nil || itemName == "" || wpt.Name == "" {
+			summary.Skipped++
+			continue
+		}
+		if err := models.ValidateName(itemName); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: skipped waypoint %q: %v\n", wpt.Name, err)
+			summary.Skipped++
+			continue
+		}
+		wpt.Desc = itemName
+		labels[wptKey{itemName, at.Unix()}] = wpt.Name
+		orphans = append(orphans, wpt)
+	}
+	used := make(map[wptKey]bool)
+
+	for _, trk := range doc.Tracks {
+		itemName := trk.Name
+		if name != "" {
+			itemName = name
+		}
+		if strings.TrimSpace(itemName) == "" {
+			return nil, fmt.Errorf("track has no name; use --name to set the item")
+		}
+		if err := models.ValidateName(itemName); err != nil {
+			return nil, fmt.Errorf("track %q: %w; use --name to set the item", itemName, err)
+		}
+		item, err := getOrCreateItem(itemName)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, seg := range trk.Segments {
+			for _, pt := range seg.Points {
+				at, err := pt.RecordedAt()
+				if err != nil || models.ValidateCoordinates(pt.Latitude, pt.Longitude) != nil {
+					summary.Skipped++
+					continue
+				}
+
+				var label *string
+				key := wptKey{itemName, at.Unix()}
+				if l, ok := labels[key]; ok {
+					label = &l
+					used[key] = true
+				}
+
+				pos := models.NewPositionWithRecordedAt(item.ID, pt.Latitude, pt.Longitude, label, at)
+				pos.Altitude = pt.Elevation
+				if err := summary.create(pos); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	// Waypoints that didn't match a track point become labeled positions
+	for _, wpt := range orphans {
+		at, _ := wpt.RecordedAt()
+		if used[wptKey{wpt.Desc, at.Unix()}] {
+			continue
+		}
+		if models.ValidateCoordinates(wpt.Latitude, wpt.Longitude) != nil {
+			summary.Skipped++
+			continue
+		}
+		item, err := getOrCreateItem(wpt.Desc)
+		if err != nil {
+			return nil, err
+		}
+		label := wpt.Name
+		pos := models.NewPositionWithRecordedAt(item.ID, wpt.Latitude, wpt.Longitude, &label, at)
+		if err := summary.create(pos); err != nil {
+			return nil, err
+		}
+	}
+
+	return summary, nil
+}
+
+// importCSV imports CSV rows as positions, creating items as needed.
+// If name is non-empty it is used for every row instead of the name column.
+// Bad rows are reported on stderr and counted as skipped.
+func importCSV(data []byte, columns csv.ColumnMap, layout, name string) (*importSummary, error) {
+	records, rowErrs, err := csv.Read(bytes.NewReader(data), columns, layout)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &importSummary{Skipped: len(rowErrs)}
+	for _, rowErr := range rowErrs {
+		fmt.Fprintf(os.Stderr, "warning: skipped %v\n", rowErr)
+	}
+
+	items := make(map[string]*models.Item)
+	for _, rec := range records {
+		itemName := rec.Name
+		if name != "" {
+			itemName = name
+		}
+		if err := models.ValidateName(itemName); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: skipped line %d: %v\n", rec.Line, err)
+			summary.Skipped++
+			continue
+		}
+
+		item, ok := items[itemName]
+		if !ok {
+			item, err = getOrCreateItem(itemName)
+			if err != nil {
+				return nil, err
+			}
+			items[itemName] = item
+		}
+
+		var pos *models.Position
+		if rec.RecordedAt.IsZero() {
+			pos = models.NewPosition(item.ID, rec.Latitude, rec.Longitude, rec.Label)
+		} else {
+			pos = models.NewPositionWithRecordedAt(item.ID, rec.Latitude, rec.Longitude, rec.Label, rec.RecordedAt)
+		}
+		if err := summary.create(pos); err != nil {
+			return nil, fmt.Errorf("line %d: %w", rec.Line, err)
+		}
+	}
+
+	return summary, nil
+}
+
+// importTakeout imports Google Takeout location history for a single item.
+func importTakeout(data []byte, name string) (*importSummary, error) {
+	if err := models.ValidateName(name); err != nil {
+		return nil, err
+	}
+
+	points, err := takeout.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := getOrCreateItem(name)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &importSummary{}
+	for _, pt := range points {
+		if models.ValidateCoordinates(pt.Latitude, pt.Longitude) != nil {
+			summary.Skipped++
+			continue
+		}
+		pos := models.NewPositionWithRecordedAt(item.ID, pt.Latitude, pt.Longitude, pt.Label, pt.RecordedAt)
+		// Keep the fix and its other readings even if Google reported an odd one
+		pos.Telemetry = models.Telemetry{
+			Accuracy: pt.Accuracy,
+			Altitude: pt.Altitude,
+			Speed:    pt.Speed,
+			Heading:  pt.Heading,
+		}.Sanitize()
+		if err := summary.create(pos); err != nil {
+			return nil, err
+		}
+	}
+
+	return summary, nil
+}
+
 func init() {
 	importCmd.Flags().Bool("confirm", false, "skip confirmation prompt")
+	importCmd.Flags().StringP("format", "f", "yaml", "input format (yaml, gpx, csv, takeout)")
+	importCmd.Flags().String("name", "", "item name for imported positions (default: from file)")
+	importCmd.Flags().String("map", "", "CSV column mapping (e.g., name=device,lat=latitude,lng=lon,at=timestamp,label=place)")
+	importCmd.Flags().String("time-layout", time.RFC3339, "CSV timestamp layout (Go layout, 'unix', or 'unixms')")
 
 	rootCmd.AddCommand(importCmd)
 }
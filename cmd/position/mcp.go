@@ -1,26 +1,72 @@
 // ABOUTME: MCP serve command
-// ABOUTME: Starts the MCP server for AI agent integration
+// ABOUTME: Starts the MCP server over stdio or streamable HTTP for AI agent integration
 
 package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
+	"github.com/harper/position/internal/config"
 	"github.com/harper/position/internal/mcp"
 	"github.com/spf13/cobra"
 )
 
+// mcpTokenEnv holds the bearer token when neither --token nor --token-file is
+// given, keeping it out of the process list.
+const mcpTokenEnv = "POSITION_MCP_TOKEN"
+
+var (
+	mcpHTTPAddr  string
+	mcpToken     string
+	mcpTokenFile string
+	mcpReadOnly  bool
+	mcpTools     []string
+)
+
 var mcpCmd = &cobra.Command{
 	Use:   "mcp",
 	Short: "Start MCP server for AI agents",
+	Long: `Start the MCP server. By default it speaks over stdio, for agents that
+launch position themselves.
+
+With --http it serves the MCP streamable HTTP transport instead, so agents on
+other hosts can connect (and reconnect) directly at http://<addr>/. Requests
+must carry "Authorization: Bearer <token>" when a token is set with --token,
+--token-file, or the POSITION_MCP_TOKEN environment variable. Each request is
+logged to stderr. Serve TLS from a reverse proxy in front of it.
+
+--read-only hides add_position and remove_item and refuses any other write.
+--tools limits agents to the listed tools. Both can also be set in the "mcp"
+block of config.json; --read-only adds to it and --tools replaces its list.
+
+Examples:
+  position mcp
+  position mcp --read-only
+  position mcp --tools list_items,get_current,get_timeline
+  position mcp --http 127.0.0.1:8787
+  position mcp --http :8787 --token-file ~/.config/position/mcp-token`,
+	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if mcpHTTPAddr == "" && (mcpToken != "" || mcpTokenFile != "") {
+			return fmt.Errorf("--token and --token-file require --http")
+		}
+
 		server, err := mcp.NewServer(db)
 		if err != nil {
 			return err
 		}
+		if err := server.SetPermissions(mcpPermissions(appConfig, mcpReadOnly, mcpTools)); err != nil {
+			return fmt.Errorf("invalid MCP permissions: %w", err)
+		}
 
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
@@ -32,10 +78,94 @@ var mcpCmd = &cobra.Command{
 			cancel()
 		}()
 
-		return server.Serve(ctx)
+		if mcpHTTPAddr == "" {
+			return server.Serve(ctx)
+		}
+
+		token, err := loadMCPToken(mcpToken, mcpTokenFile)
+		if err != nil {
+			return err
+		}
+		return serveMCPHTTP(ctx, server, mcpHTTPAddr, token)
 	},
 }
 
+// mcpPermissions combines the config's mcp block with the flags. --read-only
+// can only add the restriction; --tools replaces the configured allowlist.
+func mcpPermissions(cfg *config.Config, readOnly bool, tools []string) config.MCPPermissions {
+	var perms config.MCPPermissions
+	if cfg != nil && cfg.MCP != nil {
+		perms = *cfg.MCP
+	}
+	perms.ReadOnly = perms.ReadOnly || readOnly
+	if len(tools) > 0 {
+		perms.Tools = tools
+	}
+	return perms
+}
+
+// loadMCPToken resolves the bearer token from the flag, the token file, or
+// the environment, in that order. An empty result means no auth.
+func loadMCPToken(token, file string) (string, error) {
+	if token != "" && file != "" {
+		return "", fmt.Errorf("use either --token or --token-file, not both")
+	}
+	if file != "" {
+		data, err := os.ReadFile(config.ExpandPath(file))
+		if err != nil {
+			return "", fmt.Errorf("failed to read token file: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+		if token == "" {
+			return "", fmt.Errorf("token file %s is empty", file)
+		}
+		return token, nil
+	}
+	if token != "" {
+		return token, nil
+	}
+	return strings.TrimSpace(os.Getenv(mcpTokenEnv)), nil
+}
+
+// serveMCPHTTP runs the streamable HTTP transport until ctx is cancelled.
+func serveMCPHTTP(ctx context.Context, server *mcp.Server, addr, token string) error {
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           server.HTTPHandler(mcp.HTTPOptions{Token: token, Logger: logger}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go server.WatchChanges(ctx)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+	fmt.Fprintf(os.Stderr, "MCP listening on http://%s\n", addr)
+	if token == "" {
+		fmt.Fprintln(os.Stderr, "Warning: no bearer token set; anyone who can reach this address has full access")
+	}
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("shutdown: %w", err)
+	}
+	return nil
+}
+
 func init() {
+	mcpCmd.Flags().StringVar(&mcpHTTPAddr, "http", "", "serve streamable HTTP on this address instead of stdio (e.g. :8787)")
+	mcpCmd.Flags().StringVar(&mcpToken, "token", "", "bearer token required on HTTP requests")
+	mcpCmd.Flags().StringVar(&mcpTokenFile, "token-file", "", "read the bearer token from this file")
+	mcpCmd.Flags().BoolVar(&mcpReadOnly, "read-only", false, "hide tools that write and refuse writes")
+	mcpCmd.Flags().StringSliceVar(&mcpTools, "tools", nil, "only allow these tools (comma-separated)")
 	rootCmd.AddCommand(mcpCmd)
 }
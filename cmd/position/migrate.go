@@ -116,6 +116,8 @@ func runMigrate(cmd *cobra.Command, args []string) error {
 	color.Green("Migration complete!")
 	fmt.Printf("  Items:     %d\n", summary.Items)
 	fmt.Printf("  Positions: %d\n", summary.Positions)
+	fmt.Printf("  Geofences: %d\n", summary.Geofences)
+	fmt.Printf("  Places:    %d\n", summary.Places)
 	fmt.Println()
 	color.Yellow("Note: config.json was NOT updated. To switch to the new backend, edit:")
 	fmt.Printf("  %s\n", config.GetConfigPath())
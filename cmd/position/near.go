@@ -0,0 +1,160 @@
+// ABOUTME: Spatial query command
+// ABOUTME: Finds which items have been near a point or inside a bounding box
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/harper/position/internal/geo"
+	"github.com/harper/position/internal/models"
+	"github.com/harper/position/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var nearCmd = &cobra.Command{
+	Use:   "near",
+	Short: "Find items that have been near a point or inside a box",
+	Long: `Find positions across all items within a radius of a point, or inside a
+bounding box, optionally limited to a time window. Results are grouped by item,
+newest first.
+
+Examples:
+  # Who has been within 500m of the office this week?
+  position near --lat 41.8781 --lng -87.6298 --radius 500 --since 7d
+
+  # Everything inside a box during December
+  position near --bbox 41.8,-87.7,41.9,-87.6 --from 2024-12-01 --to 2024-12-31`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bboxStr, _ := cmd.Flags().GetString("bbox")
+		hasPoint := cmd.Flags().Changed("lat") || cmd.Flags().Changed("lng")
+		if bboxStr != "" && hasPoint {
+			return fmt.Errorf("use either --lat/--lng or --bbox, not both")
+		}
+		if bboxStr == "" && (!cmd.Flags().Changed("lat") || !cmd.Flags().Changed("lng")) {
+			return fmt.Errorf("--lat and --lng are required (or use --bbox)")
+		}
+
+		since, from, to, err := parseTimeFilters(cmd)
+		if err != nil {
+			return err
+		}
+		if !since.IsZero() {
+			from = since
+		}
+
+		var positions []*models.Position
+		var describe string
+		var distanceFrom func(pos *models.Position) float64
+
+		if bboxStr != "" {
+			minLat, minLng, maxLat, maxLng, err := parseBBox(bboxStr)
+			if err != nil {
+				return err
+			}
+			positions, err = db.GetPositionsInBBox(minLat, minLng, maxLat, maxLng, from, to)
+			if err != nil {
+				return fmt.Errorf("failed to query positions: %w", err)
+			}
+			describe = fmt.Sprintf("inside (%.4f, %.4f) - (%.4f, %.4f)", minLat, minLng, maxLat, maxLng)
+		} else {
+			lat, _ := cmd.Flags().GetFloat64("lat")
+			lng, _ := cmd.Flags().GetFloat64("lng")
+			radius, _ := cmd.Flags().GetFloat64("radius")
+			if err := models.ValidateCoordinates(lat, lng); err != nil {
+				return err
+			}
+			if radius <= 0 {
+				return fmt.Errorf("--radius must be greater than 0")
+			}
+			positions, err = db.GetPositionsNear(lat, lng, radius, from, to)
+			if err != nil {
+				return fmt.Errorf("failed to query positions: %w", err)
+			}
+			describe = fmt.Sprintf("within %s of (%.4f, %.4f)", ui.FormatDistance(radius), lat, lng)
+			distanceFrom = func(pos *models.Position) float64 {
+				return geo.Distance(lat, lng, pos.Latitude, pos.Longitude)
+			}
+		}
+
+		if len(positions) == 0 {
+			fmt.Printf("No positions %s\n", describe)
+			return nil
+		}
+
+		itemNames, err := itemNameLookup()
+		if err != nil {
+			return err
+		}
+
+		// Group by item, keeping items ordered by their most recent match
+		var order []string
+		grouped := make(map[string][]*models.Position)
+		for _, pos := range positions {
+			name := itemNames[pos.ItemID]
+			if _, ok := grouped[name]; !ok {
+				order = append(order, name)
+			}
+			grouped[name] = append(grouped[name], pos)
+		}
+
+		fmt.Printf("%d items %s:\n", len(order), describe)
+		for _, name := range order {
+			matches := grouped[name]
+			fmt.Printf("%s - %d positions, last %s\n",
+				color.GreenString(name), len(matches),
+				color.New(color.Faint).Sprint(ui.FormatRelativeTime(matches[0].RecordedAt)))
+			for _, pos := range matches {
+				line := ui.FormatPositionForTimeline(pos)
+				if distanceFrom != nil {
+					line += color.New(color.Faint).Sprintf(" (%s away)", ui.FormatDistance(distanceFrom(pos)))
+				}
+				fmt.Println(line)
+			}
+		}
+		return nil
+	},
+}
+
+// parseBBox parses "minLat,minLng,maxLat,maxLng". A minLng greater than maxLng
+// selects a box crossing the antimeridian.
+func parseBBox(s string) (minLat, minLng, maxLat, maxLng float64, err error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return 0, 0, 0, 0, fmt.Errorf("invalid --bbox %q (use minLat,minLng,maxLat,maxLng)", s)
+	}
+	var v [4]float64
+	for i, p := range parts {
+		v[i], err = strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return 0, 0, 0, 0, fmt.Errorf("invalid --bbox value %q", p)
+		}
+	}
+	minLat, minLng, maxLat, maxLng = v[0], v[1], v[2], v[3]
+	if err := models.ValidateCoordinates(minLat, minLng); err != nil {
+		return 0, 0, 0, 0, err
+	}
+	if err := models.ValidateCoordinates(maxLat, maxLng); err != nil {
+		return 0, 0, 0, 0, err
+	}
+	if minLat > maxLat {
+		return 0, 0, 0, 0, fmt.Errorf("--bbox minLat must not exceed maxLat")
+	}
+	return minLat, minLng, maxLat, maxLng, nil
+}
+
+func init() {
+	nearCmd.Flags().Float64("lat", 0, "latitude of the search center")
+	nearCmd.Flags().Float64("lng", 0, "longitude of the search center")
+	nearCmd.Flags().Float64("radius", 500, "search radius in meters")
+	nearCmd.Flags().String("bbox", "", "bounding box as minLat,minLng,maxLat,maxLng")
+	nearCmd.Flags().String("since", "", "relative time filter (e.g., 24h, 7d, 1w)")
+	nearCmd.Flags().String("from", "", "start date (YYYY-MM-DD or RFC3339)")
+	nearCmd.Flags().String("to", "", "end date (YYYY-MM-DD or RFC3339)")
+
+	rootCmd.AddCommand(nearCmd)
+}
@@ -0,0 +1,106 @@
+// ABOUTME: Named place management commands
+// ABOUTME: Adds, lists, and removes the places used to label new positions automatically
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/google/uuid"
+	"github.com/harper/position/internal/models"
+	"github.com/harper/position/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var placeCmd = &cobra.Command{
+	Use:   "place",
+	Short: "Manage named places used to label positions",
+	Long: `Places are named locations like "home" or "office". A position added
+without a label inside a place is labeled with the place's name; where places
+overlap, the smallest wins. Existing positions are not relabeled.
+
+Examples:
+  position place add home --lat 41.8781 --lng -87.6298 --radius 100
+  position place add campus --polygon "41.88,-87.63;41.88,-87.62;41.87,-87.62;41.87,-87.63"
+  position place list
+  position place rm home`,
+}
+
+var placeAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add a circular or polygon place",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		shape, err := shapeFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		place := &models.Place{ID: uuid.New(), Name: name, Shape: shape, CreatedAt: time.Now()}
+		if err := place.Validate(); err != nil {
+			return err
+		}
+		if err := db.CreatePlace(place); err != nil {
+			return fmt.Errorf("failed to create place: %w", err)
+		}
+
+		color.Green("Place %s added", name)
+		fmt.Printf("  %s\n", ui.FormatPlace(place))
+		return nil
+	},
+}
+
+var placeListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List places",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		places, err := db.ListPlaces()
+		if err != nil {
+			return fmt.Errorf("failed to list places: %w", err)
+		}
+
+		if len(places) == 0 {
+			fmt.Println("No places yet. Use 'position place add' to add one.")
+			return nil
+		}
+
+		for _, place := range places {
+			fmt.Println(ui.FormatPlace(place))
+		}
+		return nil
+	},
+}
+
+var placeRemoveCmd = &cobra.Command{
+	Use:     "rm <name>",
+	Aliases: []string{"remove"},
+	Short:   "Remove a place (positions keep their labels)",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		place, err := db.GetPlaceByName(name)
+		if err != nil {
+			return fmt.Errorf("place '%s' not found", name)
+		}
+
+		if err := db.DeletePlace(place.ID); err != nil {
+			return fmt.Errorf("failed to remove place: %w", err)
+		}
+
+		color.Green("Removed place %s", name)
+		return nil
+	},
+}
+
+func init() {
+	addShapeFlags(placeAddCmd, 100)
+
+	placeCmd.AddCommand(placeAddCmd, placeListCmd, placeRemoveCmd)
+	rootCmd.AddCommand(placeCmd)
+}
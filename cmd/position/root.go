@@ -13,6 +13,9 @@ import (
 
 var db storage.Repository
 
+// appConfig is the config loaded for the current command.
+var appConfig *config.Config
+
 var rootCmd = &cobra.Command{
 	Use:   "position",
 	Short: "Simple location tracking for items",
@@ -36,6 +39,7 @@ Examples:
 		if err != nil {
 			return fmt.Errorf("failed to load config: %w", err)
 		}
+		appConfig = cfg
 		db, err = cfg.OpenStorage()
 		if err != nil {
 			return fmt.Errorf("failed to open storage: %w", err)
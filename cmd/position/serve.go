@@ -0,0 +1,107 @@
+// ABOUTME: HTTP ingestion server command
+// ABOUTME: Receives OwnTracks, Overland, and GPSLogger/OsmAnd reports and records positions
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/harper/position/internal/ingest"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveListen  string
+	serveAliases []string
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run an HTTP server that receives locations from phone apps",
+	Long: `Run an HTTP listener that records positions sent by tracking apps.
+
+Endpoints:
+  POST /owntracks           OwnTracks HTTP mode (_type: location)
+  POST /overland            Overland GeoJSON batches
+  GET|POST /gpslogger       GPSLogger custom URL (lat, lon, time, device)
+  GET|POST /osmand          OsmAnd / Traccar protocol (id, lat, lon, timestamp)
+
+Each device (OwnTracks tid or X-Limit-D, Overland device_id, OsmAnd id) becomes
+an item, created on first report. Add ?name=<item> to any endpoint URL to pick the
+item explicitly, or map device identifiers with --alias.
+
+There is no authentication; keep the default loopback address or put the server
+behind a reverse proxy that handles TLS and auth.
+
+Examples:
+  position serve
+  position serve --listen 0.0.0.0:8080 --alias ph=harper --alias pixel=hiromi`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		aliases, err := parseAliases(serveAliases)
+		if err != nil {
+			return err
+		}
+
+		handler := ingest.NewHandler(db, aliases)
+		handler.Logger = log.New(os.Stderr, "", log.LstdFlags)
+
+		srv := &http.Server{
+			Addr:              serveListen,
+			Handler:           handler,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- srv.ListenAndServe()
+		}()
+		fmt.Fprintf(os.Stderr, "Listening on http://%s\n", serveListen)
+
+		select {
+		case err := <-errCh:
+			return fmt.Errorf("server failed: %w", err)
+		case <-ctx.Done():
+		}
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("shutdown: %w", err)
+		}
+		return nil
+	},
+}
+
+// parseAliases parses repeated "device=item" flags into a map.
+func parseAliases(values []string) (map[string]string, error) {
+	aliases := make(map[string]string, len(values))
+	for _, v := range values {
+		device, name, ok := strings.Cut(v, "=")
+		device = strings.TrimSpace(device)
+		name = strings.TrimSpace(name)
+		if !ok || device == "" || name == "" {
+			return nil, fmt.Errorf("invalid alias %q (use device=item)", v)
+		}
+		aliases[device] = name
+	}
+	return aliases, nil
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveListen, "listen", "127.0.0.1:8080", "address to listen on")
+	serveCmd.Flags().StringArrayVar(&serveAliases, "alias", nil, "map a device identifier to an item name (device=item, repeatable)")
+	rootCmd.AddCommand(serveCmd)
+}
@@ -0,0 +1,75 @@
+// ABOUTME: Shape flags shared by the geofence and place commands
+// ABOUTME: Parses a circle from --lat/--lng/--radius or a polygon from --polygon
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/harper/position/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// addShapeFlags registers the flags read by shapeFromFlags. A zero
+// defaultRadius means circles must pass --radius.
+func addShapeFlags(cmd *cobra.Command, defaultRadius float64) {
+	cmd.Flags().Float64("lat", 0, "center latitude for a circle")
+	cmd.Flags().Float64("lng", 0, "center longitude for a circle")
+	cmd.Flags().Float64("radius", defaultRadius, "circle radius in meters")
+	cmd.Flags().String("polygon", "", "polygon vertices as \"lat,lng;lat,lng;lat,lng\"")
+}
+
+// shapeFromFlags reads a circle or a polygon from the flags registered by
+// addShapeFlags. The shape still needs validating.
+func shapeFromFlags(cmd *cobra.Command) (models.Shape, error) {
+	flags := cmd.Flags()
+	polygonStr, _ := flags.GetString("polygon")
+	if polygonStr != "" {
+		if flags.Changed("lat") || flags.Changed("lng") || flags.Changed("radius") {
+			return models.Shape{}, fmt.Errorf("use either --polygon or --lat/--lng/--radius, not both")
+		}
+		vertices, err := parsePolygon(polygonStr)
+		if err != nil {
+			return models.Shape{}, err
+		}
+		return models.PolygonShape(vertices), nil
+	}
+
+	if !flags.Changed("lat") || !flags.Changed("lng") {
+		return models.Shape{}, fmt.Errorf("circles require --lat and --lng (or use --polygon)")
+	}
+	radius, _ := flags.GetFloat64("radius")
+	if radius == 0 && !flags.Changed("radius") {
+		return models.Shape{}, fmt.Errorf("circles require --radius (or use --polygon)")
+	}
+	lat, _ := flags.GetFloat64("lat")
+	lng, _ := flags.GetFloat64("lng")
+	return models.CircleShape(lat, lng, radius), nil
+}
+
+// parsePolygon parses "lat,lng;lat,lng;..." into polygon vertices.
+func parsePolygon(s string) ([]models.Coordinate, error) {
+	var vertices []models.Coordinate
+	for _, pair := range strings.Split(s, ";") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		latStr, lngStr, ok := strings.Cut(pair, ",")
+		if !ok {
+			return nil, fmt.Errorf("invalid polygon vertex %q (use lat,lng)", pair)
+		}
+		lat, err := strconv.ParseFloat(strings.TrimSpace(latStr), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid polygon latitude %q", latStr)
+		}
+		lng, err := strconv.ParseFloat(strings.TrimSpace(lngStr), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid polygon longitude %q", lngStr)
+		}
+		vertices = append(vertices, models.Coordinate{Latitude: lat, Longitude: lng})
+	}
+	return vertices, nil
+}
@@ -5,8 +5,11 @@ package main
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/fatih/color"
+	"github.com/harper/position/internal/geo"
+	"github.com/harper/position/internal/storage"
 	"github.com/harper/position/internal/ui"
 	"github.com/spf13/cobra"
 )
@@ -28,15 +31,33 @@ var timelineCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("failed to get timeline: %w", err)
 		}
+		if clean, _ := cmd.Flags().GetBool("clean"); clean {
+			positions = storage.Clean(positions)
+		}
 
 		if len(positions) == 0 {
 			fmt.Printf("%s has no position history\n", color.GreenString(name))
 			return nil
 		}
 
+		verbose, _ := cmd.Flags().GetBool("verbose")
+
 		fmt.Printf("%s timeline:\n", color.GreenString(name))
-		for _, pos := range positions {
-			fmt.Println(ui.FormatPositionForTimeline(pos))
+		for i, pos := range positions {
+			line := ui.FormatPositionForTimeline(pos)
+			// Positions are newest first, so each leg arrives from the next entry
+			if verbose && i+1 < len(positions) {
+				line += "  " + ui.FormatLeg(geo.NewLeg(positions[i+1], pos))
+			}
+			fmt.Println(line)
+		}
+
+		if verbose && len(positions) > 1 {
+			oldest := positions[len(positions)-1]
+			fmt.Printf("  %s %s over %s\n",
+				color.New(color.Faint).Sprint("total"),
+				ui.FormatDistance(geo.PathLength(positions)),
+				positions[0].RecordedAt.Sub(oldest.RecordedAt).Round(time.Minute))
 		}
 
 		return nil
@@ -44,5 +65,7 @@ var timelineCmd = &cobra.Command{
 }
 
 func init() {
+	timelineCmd.Flags().BoolP("verbose", "v", false, "show distance, bearing, and speed for each leg")
+	timelineCmd.Flags().Bool("clean", false, "exclude positions flagged suspect by the outlier filter")
 	rootCmd.AddCommand(timelineCmd)
 }
@@ -0,0 +1,123 @@
+// ABOUTME: Position trips command
+// ABOUTME: Splits an item's history into trips between stays with mileage and speed stats
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/harper/position/internal/geojson"
+	"github.com/harper/position/internal/track"
+	"github.com/harper/position/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var tripsCmd = &cobra.Command{
+	Use:   "trips <name>",
+	Short: "Split an item's history into trips between stays",
+	Long: `Split an item's history into trips bounded by stationary periods (see
+'position visits'), reporting start and end place, duration, distance, and
+average and maximum speed for each trip.
+
+Raw GPS zig-zags inflate distance on slow walking and cycling tracks; pass
+--smooth to measure a Kalman-smoothed track instead. Stored positions are
+not changed.
+
+Formats:
+  text    - one trip per entry (default)
+  geojson - FeatureCollection with one LineString per trip
+
+Examples:
+  position trips van --since 7d
+  position trips bike --since 7d --smooth
+  position trips van --from 2024-12-01 --to 2024-12-31 --format geojson --output december.geojson`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		format, _ := cmd.Flags().GetString("format")
+		if format != "text" && format != "geojson" {
+			return fmt.Errorf("unknown format: %s (use text or geojson)", format)
+		}
+
+		item, err := db.GetItemByName(name)
+		if err != nil {
+			return fmt.Errorf("item '%s' not found", name)
+		}
+
+		since, from, to, err := parseTimeFilters(cmd)
+		if err != nil {
+			return err
+		}
+
+		opts, err := visitOptionsFromFlags(cmd, to)
+		if err != nil {
+			return err
+		}
+
+		positions, err := getPositionsForItem(item, since, from, to)
+		if err != nil {
+			return fmt.Errorf("failed to get positions: %w", err)
+		}
+		if smooth, _ := cmd.Flags().GetBool("smooth"); smooth {
+			positions = track.Smooth(positions, track.DefaultSmoothOptions())
+		}
+
+		trips := track.Trips(positions, opts)
+		output, _ := cmd.Flags().GetString("output")
+
+		if format == "geojson" {
+			return exportTripsGeoJSON(trips, name, output)
+		}
+
+		if len(trips) == 0 {
+			fmt.Printf("%s has no trips\n", color.GreenString(name))
+			return nil
+		}
+
+		var total float64
+		fmt.Printf("%s trips:\n", color.GreenString(name))
+		for _, trip := range trips {
+			fmt.Println(ui.FormatTrip(trip))
+			total += trip.Distance
+		}
+		noun := "trips"
+		if len(trips) == 1 {
+			noun = "trip"
+		}
+		fmt.Printf("  %s %d %s, %s\n",
+			color.New(color.Faint).Sprint("total"), len(trips), noun, ui.FormatDistance(total))
+		return nil
+	},
+}
+
+func exportTripsGeoJSON(trips []track.Trip, name, output string) error {
+	jsonBytes, err := geojson.ToTripsFeatureCollection(trips, name).ToJSONIndent()
+	if err != nil {
+		return fmt.Errorf("failed to generate GeoJSON: %w", err)
+	}
+
+	if output != "" {
+		if err := os.WriteFile(output, jsonBytes, 0644); err != nil { //nolint:gosec // 0644 is intentional for data export files
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %d trips to %s\n", len(trips), output)
+	} else {
+		fmt.Println(string(jsonBytes))
+	}
+	return nil
+}
+
+func init() {
+	tripsCmd.Flags().Float64("radius", track.DefaultVisitRadius, "maximum distance in meters for a stationary period")
+	tripsCmd.Flags().Duration("min-duration", track.DefaultVisitMinDuration, "shortest stop that ends a trip")
+	tripsCmd.Flags().StringP("format", "f", "text", "output format (text, geojson)")
+	tripsCmd.Flags().StringP("output", "o", "", "output file for geojson (default: stdout)")
+	tripsCmd.Flags().String("since", "", "relative time filter (e.g., 24h, 7d, 1w)")
+	tripsCmd.Flags().String("from", "", "start date (YYYY-MM-DD or RFC3339)")
+	tripsCmd.Flags().String("to", "", "end date (YYYY-MM-DD or RFC3339)")
+	tripsCmd.Flags().Bool("smooth", false, "measure distances along a Kalman-smoothed track")
+	rootCmd.AddCommand(tripsCmd)
+}
@@ -0,0 +1,91 @@
+// ABOUTME: Position visits command
+// ABOUTME: Summarizes an item's history as stays at places rather than raw pings
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/harper/position/internal/track"
+	"github.com/harper/position/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var visitsCmd = &cobra.Command{
+	Use:   "visits <name>",
+	Short: "Show places an item stayed, with arrival and departure times",
+	Long: `Cluster an item's positions into visits: consecutive positions that stay
+within --radius meters of each other for at least --min-duration. A visit
+lasts until the first position elsewhere, so a stationary device that logged
+a single fix still shows its whole stay; the last visit runs to the end of
+the range (--to, or now).
+
+Examples:
+  position visits harper --since 7d
+  position visits harper --from 2024-12-01 --to 2024-12-07
+  position visits car --radius 50 --min-duration 5m`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		item, err := db.GetItemByName(name)
+		if err != nil {
+			return fmt.Errorf("item '%s' not found", name)
+		}
+
+		since, from, to, err := parseTimeFilters(cmd)
+		if err != nil {
+			return err
+		}
+
+		opts, err := visitOptionsFromFlags(cmd, to)
+		if err != nil {
+			return err
+		}
+
+		positions, err := getPositionsForItem(item, since, from, to)
+		if err != nil {
+			return fmt.Errorf("failed to get positions: %w", err)
+		}
+
+		visits := track.Visits(positions, opts)
+		if len(visits) == 0 {
+			fmt.Printf("%s has no visits\n", color.GreenString(name))
+			return nil
+		}
+
+		fmt.Printf("%s visits:\n", color.GreenString(name))
+		for _, v := range visits {
+			fmt.Println(ui.FormatVisit(v))
+		}
+		return nil
+	},
+}
+
+// visitOptionsFromFlags reads --radius and --min-duration. A stay still
+// going at the last position lasts to to, the end of the range, or to now
+// when the range is open or runs into the future.
+func visitOptionsFromFlags(cmd *cobra.Command, to time.Time) (track.VisitOptions, error) {
+	opts := track.DefaultVisitOptions()
+	opts.RadiusMeters, _ = cmd.Flags().GetFloat64("radius")
+	opts.MinDuration, _ = cmd.Flags().GetDuration("min-duration")
+	if opts.RadiusMeters <= 0 {
+		return opts, fmt.Errorf("--radius must be greater than 0")
+	}
+	opts.Until = to
+	if opts.Until.IsZero() || opts.Until.After(time.Now()) {
+		opts.Until = time.Now()
+	}
+	return opts, nil
+}
+
+func init() {
+	visitsCmd.Flags().Float64("radius", track.DefaultVisitRadius, "maximum distance in meters from the visit center")
+	visitsCmd.Flags().Duration("min-duration", track.DefaultVisitMinDuration, "shortest stay counted as a visit")
+	visitsCmd.Flags().String("since", "", "relative time filter (e.g., 24h, 7d, 1w)")
+	visitsCmd.Flags().String("from", "", "start date (YYYY-MM-DD or RFC3339)")
+	visitsCmd.Flags().String("to", "", "end date (YYYY-MM-DD or RFC3339)")
+	rootCmd.AddCommand(visitsCmd)
+}
@@ -5,11 +5,14 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/harper/position/internal/geocode"
 	"github.com/harper/position/internal/storage"
 	"github.com/harperreed/mdstore"
 )
@@ -23,6 +26,35 @@ type Config struct {
 	// SQLite puts position.db here. Markdown puts _items.yaml and item folders here.
 	// Supports ~ expansion for home directory. Defaults to ~/.local/share/position.
 	DataDir string `json:"data_dir,omitempty"`
+
+	// Dedup controls when a new position repeats the previous one and is
+	// skipped. Nil skips only exact repeats.
+	Dedup *storage.DedupConfig `json:"dedup,omitempty"`
+
+	// Filter controls when a new position is flagged suspect, such as a
+	// jump at impossible speed. Nil flags nothing.
+	Filter *storage.FilterConfig `json:"filter,omitempty"`
+
+	// Geocode points at a local GeoNames gazetteer used to fill in the
+	// locality of new positions. Nil, or a gazetteer file that doesn't
+	// exist, leaves localities empty.
+	Geocode *geocode.Config `json:"geocode,omitempty"`
+
+	// MCP limits what agents connected through `position mcp` may do, such
+	// as read-only access or an allowlist of tools. Nil allows everything.
+	MCP *MCPPermissions `json:"mcp,omitempty"`
+}
+
+// MCPPermissions limits what agents connected to the MCP server may do. The
+// zero value allows every tool.
+type MCPPermissions struct {
+	// ReadOnly hides the tools that write (add_position, remove_item) and
+	// makes any write that slips through fail with storage.ErrReadOnly.
+	ReadOnly bool `json:"read_only,omitempty"`
+
+	// Tools lists the only tools agents may see and call. Empty allows every
+	// tool. Resources stay readable either way.
+	Tools []string `json:"tools,omitempty"`
 }
 
 // defaultDBFilename is the SQLite database filename used for existing-user detection.
@@ -94,12 +126,57 @@ func (c *Config) OpenStorage() (storage.Repository, error) {
 	backend := c.GetBackend()
 	dataDir := c.GetDataDir()
 
+	var dedup storage.DedupConfig
+	if c.Dedup != nil {
+		if err := c.Dedup.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid dedup config: %w", err)
+		}
+		dedup = *c.Dedup
+	}
+	var filter storage.FilterConfig
+	if c.Filter != nil {
+		if err := c.Filter.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid filter config: %w", err)
+		}
+		filter = *c.Filter
+	}
+	var geocoder storage.Geocoder
+	if c.Geocode != nil {
+		cfg := *c.Geocode
+		cfg.Gazetteer = ExpandPath(cfg.Gazetteer)
+		g, err := geocode.Open(cfg)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			// A gazetteer not yet downloaded, or on an unmounted drive,
+			// shouldn't stop every command
+			fmt.Fprintf(os.Stderr, "warning: gazetteer %s not found; reverse geocoding is off\n", cfg.Gazetteer)
+		case err != nil:
+			return nil, fmt.Errorf("invalid geocode config: %w", err)
+		default:
+			geocoder = g
+		}
+	}
+
 	switch backend {
 	case "sqlite":
 		dbPath := filepath.Join(dataDir, "position.db")
-		return storage.NewSQLiteDB(dbPath)
+		db, err := storage.NewSQLiteDB(dbPath)
+		if err != nil {
+			return nil, err
+		}
+		db.SetDedup(dedup)
+		db.SetFilter(filter)
+		db.SetGeocoder(geocoder)
+		return db, nil
 	case "markdown":
-		return storage.NewMarkdownStore(dataDir)
+		store, err := storage.NewMarkdownStore(dataDir)
+		if err != nil {
+			return nil, err
+		}
+		store.SetDedup(dedup)
+		store.SetFilter(filter)
+		store.SetGeocoder(geocoder)
+		return store, nil
 	default:
 		return nil, fmt.Errorf("unknown backend: %q", backend)
 	}
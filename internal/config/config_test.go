@@ -5,10 +5,16 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/harper/position/internal/geocode"
+	"github.com/harper/position/internal/models"
+	"github.com/harper/position/internal/storage"
 )
 
 func TestGetConfigPath(t *testing.T) {
@@ -415,3 +421,237 @@ func TestSaveToUnwritableDirectory(t *testing.T) {
 		t.Error("Expected error when saving to unwritable directory")
 	}
 }
+
+func TestLoadDedupConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tmpDir)
+
+	configPath := GetConfigPath()
+	if err := os.MkdirAll(filepath.Dir(configPath), 0750); err != nil {
+		t.Fatalf("failed to create config dir: %v", err)
+	}
+	data := `{"backend": "sqlite", "dedup": {"distance_m": 25, "min_gap": "15m", "keep_labeled": true,
+		"items": {"car": {"distance_m": 50, "accuracy_overlap": true}}}}`
+	if err := os.WriteFile(configPath, []byte(data), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Dedup == nil {
+		t.Fatal("expected dedup config to be loaded")
+	}
+	if cfg.Dedup.DistanceMeters != 25 || time.Duration(cfg.Dedup.MinGap) != 15*time.Minute || !cfg.Dedup.KeepLabeled {
+		t.Errorf("unexpected default policy: %+v", cfg.Dedup.DedupPolicy)
+	}
+	if car := cfg.Dedup.Items["car"]; car.DistanceMeters != 50 || !car.AccuracyOverlap {
+		t.Errorf("unexpected car policy: %+v", car)
+	}
+}
+
+func TestLoadMCPConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tmpDir)
+
+	configPath := GetConfigPath()
+	if err := os.MkdirAll(filepath.Dir(configPath), 0750); err != nil {
+		t.Fatalf("failed to create config dir: %v", err)
+	}
+	data := `{"backend": "sqlite", "mcp": {"read_only": true, "tools": ["list_items", "get_current"]}}`
+	if err := os.WriteFile(configPath, []byte(data), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.MCP == nil {
+		t.Fatal("expected mcp config to be loaded")
+	}
+	if !cfg.MCP.ReadOnly || len(cfg.MCP.Tools) != 2 || cfg.MCP.Tools[1] != "get_current" {
+		t.Errorf("unexpected mcp config: %+v", cfg.MCP)
+	}
+}
+
+func TestOpenStorageAppliesDedup(t *testing.T) {
+	for _, backend := range []string{"sqlite", "markdown"} {
+		t.Run(backend, func(t *testing.T) {
+			cfg := &Config{
+				Backend: backend,
+				DataDir: t.TempDir(),
+				Dedup:   &storage.DedupConfig{DedupPolicy: storage.DedupPolicy{DistanceMeters: 50}},
+			}
+			store, err := cfg.OpenStorage()
+			if err != nil {
+				t.Fatalf("OpenStorage failed: %v", err)
+			}
+			defer store.Close()
+
+			item := models.NewItem("phone")
+			if err := store.CreateItem(item); err != nil {
+				t.Fatalf("CreateItem failed: %v", err)
+			}
+			_ = store.CreatePosition(models.NewPosition(item.ID, 41.8781, -87.6298, nil))
+			_ = store.CreatePosition(models.NewPosition(item.ID, 41.8782, -87.6298, nil)) // ~11m away
+
+			timeline, err := store.GetTimeline(item.ID)
+			if err != nil {
+				t.Fatalf("GetTimeline failed: %v", err)
+			}
+			if len(timeline) != 1 {
+				t.Errorf("expected nearby position deduplicated, got %d positions", len(timeline))
+			}
+		})
+	}
+}
+
+func TestOpenStorageInvalidDedup(t *testing.T) {
+	cfg := &Config{
+		Backend: "sqlite",
+		DataDir: t.TempDir(),
+		Dedup:   &storage.DedupConfig{DedupPolicy: storage.DedupPolicy{DistanceMeters: -1}},
+	}
+	if _, err := cfg.OpenStorage(); err == nil {
+		t.Fatal("expected error for negative dedup distance")
+	}
+}
+
+func TestOpenStorageAppliesFilter(t *testing.T) {
+	for _, backend := range []string{"sqlite", "markdown"} {
+		t.Run(backend, func(t *testing.T) {
+			cfg := &Config{
+				Backend: backend,
+				DataDir: t.TempDir(),
+				Filter: &storage.FilterConfig{
+					Types: map[string]storage.FilterPolicy{"person": {MaxSpeedKmh: 200}},
+					Items: map[string]string{"phone": "person"},
+				},
+			}
+			store, err := cfg.OpenStorage()
+			if err != nil {
+				t.Fatalf("OpenStorage failed: %v", err)
+			}
+			defer store.Close()
+
+			item := models.NewItem("phone")
+			if err := store.CreateItem(item); err != nil {
+				t.Fatalf("CreateItem failed: %v", err)
+			}
+			now := time.Now()
+			_ = store.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 41.8781, -87.6298, nil, now.Add(-time.Minute)))
+			// New York a minute later
+			_ = store.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 40.7128, -74.0060, nil, now))
+
+			current, err := store.GetCurrentPosition(item.ID)
+			if err != nil {
+				t.Fatalf("GetCurrentPosition failed: %v", err)
+			}
+			if !current.Suspect {
+				t.Error("expected the jump flagged suspect")
+			}
+		})
+	}
+}
+
+func TestOpenStorageInvalidFilter(t *testing.T) {
+	cfg := &Config{
+		Backend: "sqlite",
+		DataDir: t.TempDir(),
+		Filter:  &storage.FilterConfig{Items: map[string]string{"phone": "person"}},
+	}
+	if _, err := cfg.OpenStorage(); err == nil {
+		t.Fatal("expected error for an item with an unknown type")
+	}
+}
+
+func TestOpenStorageAppliesGeocode(t *testing.T) {
+	gazetteer := filepath.Join(t.TempDir(), "cities15000.txt")
+	row := "4887398\tChicago\tChicago\t\t41.85003\t-87.65005\tP\tPPLA2\tUS\t\tIL\t031\t\t\t2720546\t\t179\tAmerica/Chicago\t2024-01-01\n"
+	if err := os.WriteFile(gazetteer, []byte(row), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, backend := range []string{"sqlite", "markdown"} {
+		t.Run(backend, func(t *testing.T) {
+			cfg := &Config{
+				Backend: backend,
+				DataDir: t.TempDir(),
+				Geocode: &geocode.Config{Gazetteer: gazetteer},
+			}
+			store, err := cfg.OpenStorage()
+			if err != nil {
+				t.Fatalf("OpenStorage failed: %v", err)
+			}
+			defer store.Close()
+
+			item := models.NewItem("phone")
+			if err := store.CreateItem(item); err != nil {
+				t.Fatalf("CreateItem failed: %v", err)
+			}
+			if err := store.CreatePosition(models.NewPosition(item.ID, 41.8781, -87.6298, nil)); err != nil {
+				t.Fatalf("CreatePosition failed: %v", err)
+			}
+
+			current, err := store.GetCurrentPosition(item.ID)
+			if err != nil {
+				t.Fatalf("GetCurrentPosition failed: %v", err)
+			}
+			if current.Locality == nil || *current.Locality != "Chicago, IL, US" {
+				t.Errorf("expected locality from the gazetteer, got %v", current.Locality)
+			}
+		})
+	}
+}
+
+func TestOpenStorageMissingGazetteer(t *testing.T) {
+	for _, backend := range []string{"sqlite", "markdown"} {
+		t.Run(backend, func(t *testing.T) {
+			cfg := &Config{
+				Backend: backend,
+				DataDir: t.TempDir(),
+				Geocode: &geocode.Config{Gazetteer: filepath.Join(t.TempDir(), "missing.txt")},
+			}
+			store, err := cfg.OpenStorage()
+			if err != nil {
+				t.Fatalf("expected a missing gazetteer to turn geocoding off, got %v", err)
+			}
+			defer store.Close()
+
+			item := models.NewItem("phone")
+			if err := store.CreateItem(item); err != nil {
+				t.Fatalf("CreateItem failed: %v", err)
+			}
+			if err := store.CreatePosition(models.NewPosition(item.ID, 41.8781, -87.6298, nil)); err != nil {
+				t.Fatalf("CreatePosition failed: %v", err)
+			}
+			current, err := store.GetCurrentPosition(item.ID)
+			if err != nil {
+				t.Fatalf("GetCurrentPosition failed: %v", err)
+			}
+			if current.Locality != nil {
+				t.Errorf("expected no locality, got %q", *current.Locality)
+			}
+			if _, err := store.GeocodePositions(item.ID); !errors.Is(err, storage.ErrNoGeocoder) {
+				t.Errorf("expected ErrNoGeocoder, got %v", err)
+			}
+		})
+	}
+}
+
+func TestOpenStorageInvalidGeocode(t *testing.T) {
+	gazetteer := filepath.Join(t.TempDir(), "cities15000.txt")
+	if err := os.WriteFile(gazetteer, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &Config{
+		Backend: "sqlite",
+		DataDir: t.TempDir(),
+		Geocode: &geocode.Config{Gazetteer: gazetteer, MaxDistanceKm: -1},
+	}
+	if _, err := cfg.OpenStorage(); err == nil {
+		t.Fatal("expected error for a negative max distance")
+	}
+}
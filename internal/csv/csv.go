@@ -0,0 +1,283 @@
+// ABOUTME: CSV export and import utilities for position data
+// ABOUTME: Writes a fixed column layout and reads arbitrary CSV via a configurable column map
+
+package csv
+
+import (
+	stdcsv "encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/harper/position/internal/models"
+)
+
+// Header is the column layout written by Write and read by DefaultColumnMap.
+var Header = []string{"name", "latitude", "longitude", "label", "recorded_at"}
+
+// ItemNameResolver is a function that resolves an item ID to its name.
+type ItemNameResolver func(itemID string) string
+
+// Write writes positions as CSV with the Header columns.
+func Write(w io.Writer, positions []*models.Position, nameResolver ItemNameResolver) error {
+	cw := stdcsv.NewWriter(w)
+	if err := cw.Write(Header); err != nil {
+		return err
+	}
+
+	for _, pos := range positions {
+		name := ""
+		if nameResolver != nil {
+			name = nameResolver(pos.ItemID.String())
+		}
+		label := ""
+		if pos.Label != nil {
+			label = *pos.Label
+		}
+		record := []string{
+			name,
+			strconv.FormatFloat(pos.Latitude, 'f', -1, 64),
+			strconv.FormatFloat(pos.Longitude, 'f', -1, 64),
+			label,
+			pos.RecordedAt.UTC().Format(time.RFC3339),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// ColumnMap maps position fields to CSV header names.
+// An empty Name, Label, or At column means the field is not present in the file.
+// Every named column must be in the header, except Name, At, and Label
+// columns left at their DefaultColumnMap names, which are used only if present.
+type ColumnMap struct {
+	Name  string
+	Lat   string
+	Lng   string
+	At    string
+	Label string
+
+	defaulted defaultedColumns
+}
+
+// defaultedColumns marks the optional columns still at their default names.
+type defaultedColumns struct {
+	name, at, label bool
+}
+
+// DefaultColumnMap returns the mapping for files produced by Write.
+func DefaultColumnMap() ColumnMap {
+	return ColumnMap{
+		Name:      "name",
+		Lat:       "latitude",
+		Lng:       "longitude",
+		At:        "recorded_at",
+		Label:     "label",
+		defaulted: defaultedColumns{name: true, at: true, label: true},
+	}
+}
+
+// ParseColumnMap parses a mapping like "name=device,lat=latitude,lng=lon,at=timestamp".
+// Keys not given keep their DefaultColumnMap value and stay optional; columns
+// given here must exist in the file. Accepted keys are name, lat, lng (or
+// lon), at (or time), and label.
+func ParseColumnMap(s string) (ColumnMap, error) {
+	m := DefaultColumnMap()
+	if strings.TrimSpace(s) == "" {
+		return m, nil
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		key, col, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		col = strings.TrimSpace(col)
+		if !ok || key == "" {
+			return ColumnMap{}, fmt.Errorf("invalid column mapping %q (use field=column)", pair)
+		}
+
+		switch key {
+		case "name":
+			m.Name = col
+			m.defaulted.name = false
+		case "lat", "latitude":
+			m.Lat = col
+		case "lng", "lon", "longitude":
+			m.Lng = col
+		case "at", "time", "recorded_at":
+			m.At = col
+			m.defaulted.at = false
+		case "label":
+			m.Label = col
+			m.defaulted.label = false
+		default:
+			return ColumnMap{}, fmt.Errorf("unknown field %q (use name, lat, lng, at, or label)", key)
+		}
+	}
+
+	if m.Lat == "" || m.Lng == "" {
+		return ColumnMap{}, fmt.Errorf("lat and lng columns are required")
+	}
+	return m, nil
+}
+
+// Record is a single parsed CSV row.
+type Record struct {
+	Line       int
+	Name       string
+	Latitude   float64
+	Longitude  float64
+	Label      *string
+	RecordedAt time.Time
+}
+
+// RowError describes a row that could not be parsed.
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// ParseTime parses a timestamp using a Go time layout or one of the
+// keywords "unix" (seconds) or "unixms" (milliseconds).
+func ParseTime(value, layout string) (time.Time, error) {
+	switch layout {
+	case "unix", "unixms":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid %s timestamp %q", layout, value)
+		}
+		if layout == "unixms" {
+			return time.UnixMilli(int64(n)).UTC(), nil
+		}
+		sec := int64(n)
+		return time.Unix(sec, int64((n-float64(sec))*1e9)).UTC(), nil
+	default:
+		return time.Parse(layout, value)
+	}
+}
+
+// Read parses all rows from r using the column map and time layout.
+// Malformed rows are returned as RowErrors rather than aborting the read, so a
+// single bad line never loses the rest of the file. A non-nil error is returned
+// only when the file itself can't be read or is missing mapped columns.
+func Read(r io.Reader, m ColumnMap, layout string) ([]Record, []*RowError, error) {
+	cr := stdcsv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, fmt.Errorf("empty file")
+		}
+		return nil, nil, fmt.Errorf("read header: %w", err)
+	}
+
+	index := make(map[string]int, len(header))
+	for i, col := range header {
+		index[strings.TrimSpace(strings.TrimPrefix(col, "\ufeff"))] = i
+	}
+
+	lookup := func(col string, required bool) (int, error) {
+		if col == "" {
+			return -1, nil
+		}
+		i, ok := index[col]
+		if !ok {
+			if required {
+				return -1, fmt.Errorf("column %q not found in header", col)
+			}
+			return -1, nil
+		}
+		return i, nil
+	}
+
+	latIdx, err := lookup(m.Lat, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	lngIdx, err := lookup(m.Lng, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	nameIdx, err := lookup(m.Name, !m.defaulted.name)
+	if err != nil {
+		return nil, nil, err
+	}
+	atIdx, err := lookup(m.At, !m.defaulted.at)
+	if err != nil {
+		return nil, nil, err
+	}
+	labelIdx, err := lookup(m.Label, !m.defaulted.label)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	field := func(row []string, i int) string {
+		if i < 0 || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	var records []Record
+	var rowErrs []*RowError
+	for {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var pe *stdcsv.ParseError
+			if errors.As(err, &pe) {
+				rowErrs = append(rowErrs, &RowError{Line: pe.StartLine, Err: pe.Err})
+				continue
+			}
+			return records, rowErrs, fmt.Errorf("read csv: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+
+		rec := Record{Line: line, Name: field(row, nameIdx)}
+
+		rec.Latitude, err = strconv.ParseFloat(field(row, latIdx), 64)
+		if err != nil {
+			rowErrs = append(rowErrs, &RowError{Line: line, Err: fmt.Errorf("invalid latitude %q", field(row, latIdx))})
+			continue
+		}
+		rec.Longitude, err = strconv.ParseFloat(field(row, lngIdx), 64)
+		if err != nil {
+			rowErrs = append(rowErrs, &RowError{Line: line, Err: fmt.Errorf("invalid longitude %q", field(row, lngIdx))})
+			continue
+		}
+		if err := models.ValidateCoordinates(rec.Latitude, rec.Longitude); err != nil {
+			rowErrs = append(rowErrs, &RowError{Line: line, Err: err})
+			continue
+		}
+
+		if atStr := field(row, atIdx); atStr != "" {
+			rec.RecordedAt, err = ParseTime(atStr, layout)
+			if err != nil {
+				rowErrs = append(rowErrs, &RowError{Line: line, Err: fmt.Errorf("invalid timestamp: %w", err)})
+				continue
+			}
+		}
+
+		if label := field(row, labelIdx); label != "" {
+			rec.Label = &label
+		}
+
+		records = append(records, rec)
+	}
+
+	return records, rowErrs, nil
+}
@@ -0,0 +1,252 @@
+// ABOUTME: Unit tests for CSV export and import
+// ABOUTME: Tests column mapping, time layouts, and per-row error reporting
+
+package csv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/harper/position/internal/models"
+)
+
+func TestWrite(t *testing.T) {
+	label := "chicago, il"
+	itemID := uuid.New()
+	positions := []*models.Position{
+		{
+			ID:         uuid.New(),
+			ItemID:     itemID,
+			Latitude:   41.8781,
+			Longitude:  -87.6298,
+			Label:      &label,
+			RecordedAt: time.Date(2024, 12, 14, 15, 0, 0, 0, time.UTC),
+		},
+	}
+
+	var buf bytes.Buffer
+	err := Write(&buf, positions, func(string) string { return "harper" })
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	want := "name,latitude,longitude,label,recorded_at\n" +
+		"harper,41.8781,-87.6298,\"chicago, il\",2024-12-14T15:00:00Z\n"
+	if buf.String() != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteRead_RoundTrip(t *testing.T) {
+	label := "home"
+	itemID := uuid.New()
+	recordedAt := time.Date(2024, 12, 14, 8, 0, 0, 0, time.UTC)
+	positions := []*models.Position{
+		{ID: uuid.New(), ItemID: itemID, Latitude: 41.0, Longitude: -87.0, Label: &label, RecordedAt: recordedAt},
+		{ID: uuid.New(), ItemID: itemID, Latitude: 42.0, Longitude: -88.0, RecordedAt: recordedAt.Add(time.Hour)},
+	}
+
+	var buf bytes.Buffer
+	_ = Write(&buf, positions, func(string) string { return "harper" })
+
+	records, rowErrs, err := Read(&buf, DefaultColumnMap(), time.RFC3339)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(rowErrs) != 0 {
+		t.Fatalf("unexpected row errors: %v", rowErrs)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	if records[0].Name != "harper" || records[0].Label == nil || *records[0].Label != "home" {
+		t.Errorf("unexpected first record: %+v", records[0])
+	}
+	if !records[0].RecordedAt.Equal(recordedAt) {
+		t.Errorf("expected %v, got %v", recordedAt, records[0].RecordedAt)
+	}
+	if records[1].Label != nil {
+		t.Errorf("expected nil label for empty cell, got %q", *records[1].Label)
+	}
+}
+
+func TestParseColumnMap(t *testing.T) {
+	m, err := ParseColumnMap("name=device,lat=latitude,lng=lon,at=timestamp,label=place")
+	if err != nil {
+		t.Fatalf("ParseColumnMap failed: %v", err)
+	}
+	want := ColumnMap{Name: "device", Lat: "latitude", Lng: "lon", At: "timestamp", Label: "place"}
+	if m != want {
+		t.Errorf("expected %+v, got %+v", want, m)
+	}
+}
+
+func TestParseColumnMap_PartialKeepsDefaults(t *testing.T) {
+	m, err := ParseColumnMap("lon=x")
+	if err != nil {
+		t.Fatalf("ParseColumnMap failed: %v", err)
+	}
+	if m.Lng != "x" || m.Lat != "latitude" || m.Name != "name" {
+		t.Errorf("unexpected map: %+v", m)
+	}
+}
+
+func TestParseColumnMap_Empty(t *testing.T) {
+	m, err := ParseColumnMap("")
+	if err != nil {
+		t.Fatalf("ParseColumnMap failed: %v", err)
+	}
+	if m != DefaultColumnMap() {
+		t.Errorf("expected default map, got %+v", m)
+	}
+}
+
+func TestParseColumnMap_Invalid(t *testing.T) {
+	tests := []string{
+		"name",
+		"speed=kmh",
+		"lat=",
+		"=lat",
+	}
+	for _, tt := range tests {
+		if _, err := ParseColumnMap(tt); err == nil {
+			t.Errorf("expected error for %q", tt)
+		}
+	}
+}
+
+func TestRead_MappedColumnMissing(t *testing.T) {
+	data := "device,lat,lon,timestamp\n" +
+		"van-1,41.8781,-87.6298,2024-12-14 15:00\n"
+
+	tests := []string{
+		"name=device,lat=lat,lng=lon,at=timestmap",
+		"name=vehicle,lat=lat,lng=lon,at=timestamp",
+		"name=device,lat=lat,lng=lon,at=timestamp,label=place",
+	}
+	for _, mapping := range tests {
+		m, err := ParseColumnMap(mapping)
+		if err != nil {
+			t.Fatalf("ParseColumnMap(%q) failed: %v", mapping, err)
+		}
+		if _, _, err := Read(strings.NewReader(data), m, "2006-01-02 15:04"); err == nil {
+			t.Errorf("%s: expected error for a mapped column missing from the header", mapping)
+		}
+	}
+
+	// Unmapped optional columns may be absent, and an empty mapping drops one
+	m, _ := ParseColumnMap("name=device,lat=lat,lng=lon,at=")
+	records, _, err := Read(strings.NewReader(data), m, "")
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(records) != 1 || records[0].Label != nil || !records[0].RecordedAt.IsZero() {
+		t.Errorf("unexpected records: %+v", records)
+	}
+}
+
+func TestRead_CustomMapping(t *testing.T) {
+	data := "device,lon,latitude,timestamp,place\n" +
+		"van-1,-87.6298,41.8781,2024-12-14 15:00,depot\n" +
+		"van-2,-88.0,42.0,2024-12-14 16:30,\n"
+
+	m, _ := ParseColumnMap("name=device,lat=latitude,lng=lon,at=timestamp,label=place")
+	records, rowErrs, err := Read(strings.NewReader(data), m, "2006-01-02 15:04")
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(rowErrs) != 0 {
+		t.Fatalf("unexpected row errors: %v", rowErrs)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	if records[0].Name != "van-1" || records[0].Latitude != 41.8781 || records[0].Longitude != -87.6298 {
+		t.Errorf("unexpected record: %+v", records[0])
+	}
+	if records[1].RecordedAt != time.Date(2024, 12, 14, 16, 30, 0, 0, time.UTC) {
+		t.Errorf("unexpected time: %v", records[1].RecordedAt)
+	}
+}
+
+func TestRead_BadRowsReportedNotFatal(t *testing.T) {
+	data := "name,latitude,longitude,recorded_at\n" +
+		"a,41.0,-87.0,2024-12-14T15:00:00Z\n" +
+		"b,north,-87.0,2024-12-14T15:00:00Z\n" +
+		"c,95.0,-87.0,2024-12-14T15:00:00Z\n" +
+		"d,41.0,-87.0,yesterday\n" +
+		"e,42.0,-88.0,2024-12-14T16:00:00Z\n"
+
+	records, rowErrs, err := Read(strings.NewReader(data), DefaultColumnMap(), time.RFC3339)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(records) != 2 {
+		t.Errorf("expected 2 good records, got %d", len(records))
+	}
+	if len(rowErrs) != 3 {
+		t.Fatalf("expected 3 row errors, got %d", len(rowErrs))
+	}
+	if rowErrs[0].Line != 3 || rowErrs[2].Line != 5 {
+		t.Errorf("unexpected error lines: %d, %d", rowErrs[0].Line, rowErrs[2].Line)
+	}
+	if !strings.Contains(rowErrs[0].Error(), "line 3") {
+		t.Errorf("expected line number in error, got %q", rowErrs[0].Error())
+	}
+}
+
+func TestRead_MissingRequiredColumn(t *testing.T) {
+	data := "name,lat,lng\nharper,41,-87\n"
+	if _, _, err := Read(strings.NewReader(data), DefaultColumnMap(), time.RFC3339); err == nil {
+		t.Error("expected error when mapped lat column is missing")
+	}
+}
+
+func TestRead_EmptyFile(t *testing.T) {
+	if _, _, err := Read(strings.NewReader(""), DefaultColumnMap(), time.RFC3339); err == nil {
+		t.Error("expected error for empty file")
+	}
+}
+
+func TestRead_OptionalColumnsAbsent(t *testing.T) {
+	data := "latitude,longitude\n41.0,-87.0\n"
+	records, _, err := Read(strings.NewReader(data), DefaultColumnMap(), time.RFC3339)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+	if records[0].Name != "" || !records[0].RecordedAt.IsZero() || records[0].Label != nil {
+		t.Errorf("expected empty optional fields, got %+v", records[0])
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		value  string
+		layout string
+		want   time.Time
+	}{
+		{"1734188400", "unix", time.Date(2024, 12, 14, 15, 0, 0, 0, time.UTC)},
+		{"1734188400000", "unixms", time.Date(2024, 12, 14, 15, 0, 0, 0, time.UTC)},
+		{"2024-12-14T15:00:00Z", time.RFC3339, time.Date(2024, 12, 14, 15, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ParseTime(tt.value, tt.layout)
+		if err != nil {
+			t.Errorf("ParseTime(%q, %q) failed: %v", tt.value, tt.layout, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseTime(%q, %q) = %v, want %v", tt.value, tt.layout, got, tt.want)
+		}
+	}
+
+	if _, err := ParseTime("soon", "unix"); err == nil {
+		t.Error("expected error for invalid unix timestamp")
+	}
+}
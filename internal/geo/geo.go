@@ -0,0 +1,240 @@
+// ABOUTME: Geographic calculations on WGS84 coordinates
+// ABOUTME: Provides distance, bearing, speed, interpolation, bounding boxes, and geofence and place containment tests
+
+package geo
+
+import (
+	"math"
+	"time"
+
+	"github.com/harper/position/internal/models"
+)
+
+// EarthRadiusMeters is the mean Earth radius used for spherical calculations.
+const EarthRadiusMeters = 6371008.8
+
+// WGS84 ellipsoid parameters used by Vincenty.
+const (
+	wgs84SemiMajor   = 6378137.0
+	wgs84Flattening  = 1 / 298.257223563
+	wgs84SemiMinor   = (1 - wgs84Flattening) * wgs84SemiMajor
+	vincentyMaxIters = 200
+)
+
+// Distance returns the great-circle distance in meters between two points
+// using the haversine formula.
+func Distance(lat1, lng1, lat2, lng2 float64) float64 {
+	phi1 := toRadians(lat1)
+	phi2 := toRadians(lat2)
+	dPhi := toRadians(lat2 - lat1)
+	dLambda := toRadians(lng2 - lng1)
+
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * EarthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// Vincenty returns the distance in meters between two points on the WGS84
+// ellipsoid using Vincenty's inverse formula. It is accurate to within a
+// millimetre but slower than Distance; for nearly antipodal points, where the
+// iteration fails to converge, it falls back to the haversine distance.
+func Vincenty(lat1, lng1, lat2, lng2 float64) float64 {
+	const a, b, f = wgs84SemiMajor, wgs84SemiMinor, wgs84Flattening
+
+	l := toRadians(lng2 - lng1)
+	sinU1, cosU1 := math.Sincos(math.Atan((1 - f) * math.Tan(toRadians(lat1))))
+	sinU2, cosU2 := math.Sincos(math.Atan((1 - f) * math.Tan(toRadians(lat2))))
+
+	lambda := l
+	for range vincentyMaxIters {
+		sinLambda, cosLambda := math.Sincos(lambda)
+		x := cosU2 * sinLambda
+		y := cosU1*sinU2 - sinU1*cosU2*cosLambda
+		sinSigma := math.Sqrt(x*x + y*y)
+		if sinSigma == 0 {
+			return 0 // coincident points
+		}
+		cosSigma := sinU1*sinU2 + cosU1*cosU2*cosLambda
+		sigma := math.Atan2(sinSigma, cosSigma)
+		sinAlpha := cosU1 * cosU2 * sinLambda / sinSigma
+		cosSqAlpha := 1 - sinAlpha*sinAlpha
+		cos2SigmaM := 0.0 // equatorial line
+		if cosSqAlpha != 0 {
+			cos2SigmaM = cosSigma - 2*sinU1*sinU2/cosSqAlpha
+		}
+		c := f / 16 * cosSqAlpha * (4 + f*(4-3*cosSqAlpha))
+
+		prev := lambda
+		lambda = l + (1-c)*f*sinAlpha*
+			(sigma+c*sinSigma*(cos2SigmaM+c*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+		if math.Abs(lambda-prev) > 1e-12 {
+			continue
+		}
+
+		uSq := cosSqAlpha * (a*a - b*b) / (b * b)
+		bigA := 1 + uSq/16384*(4096+uSq*(-768+uSq*(320-175*uSq)))
+		bigB := uSq / 1024 * (256 + uSq*(-128+uSq*(74-47*uSq)))
+		deltaSigma := bigB * sinSigma * (cos2SigmaM + bigB/4*
+			(cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)-
+				bigB/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*cos2SigmaM*cos2SigmaM)))
+		return b * bigA * (sigma - deltaSigma)
+	}
+	return Distance(lat1, lng1, lat2, lng2)
+}
+
+// InitialBearing returns the initial great-circle bearing from the first point
+// to the second in degrees clockwise from true north, in the range [0, 360).
+func InitialBearing(lat1, lng1, lat2, lng2 float64) float64 {
+	phi1 := toRadians(lat1)
+	phi2 := toRadians(lat2)
+	dLambda := toRadians(lng2 - lng1)
+
+	y := math.Sin(dLambda) * math.Cos(phi2)
+	x := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(dLambda)
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
+
+var compassPoints = []string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"}
+
+// CompassPoint returns the nearest of the eight compass points to a bearing
+// in degrees, e.g. "NE" for 40.
+func CompassPoint(degrees float64) string {
+	degrees = math.Mod(math.Mod(math.Round(degrees), 360)+360, 360)
+	return compassPoints[int(math.Mod(degrees+22.5, 360)/45)]
+}
+
+// Interpolate returns the point a fraction f of the way along the great
+// circle from the first point to the second; f = 0 is the first point and
+// f = 1 the second.
+func Interpolate(lat1, lng1, lat2, lng2, f float64) (lat, lng float64) {
+	phi1, lambda1 := toRadians(lat1), toRadians(lng1)
+	phi2, lambda2 := toRadians(lat2), toRadians(lng2)
+	delta := Distance(lat1, lng1, lat2, lng2) / EarthRadiusMeters
+	if delta == 0 {
+		return lat1, lng1
+	}
+
+	a := math.Sin((1-f)*delta) / math.Sin(delta)
+	b := math.Sin(f*delta) / math.Sin(delta)
+	x := a*math.Cos(phi1)*math.Cos(lambda1) + b*math.Cos(phi2)*math.Cos(lambda2)
+	y := a*math.Cos(phi1)*math.Sin(lambda1) + b*math.Cos(phi2)*math.Sin(lambda2)
+	z := a*math.Sin(phi1) + b*math.Sin(phi2)
+	return math.Atan2(z, math.Hypot(x, y)) * 180 / math.Pi, math.Atan2(y, x) * 180 / math.Pi
+}
+
+// Leg describes the movement between two consecutive positions.
+type Leg struct {
+	Distance float64       // meters, haversine
+	Bearing  float64       // initial bearing in degrees from north
+	Duration time.Duration // time between the two fixes
+	Speed    float64       // average meters per second; 0 when Duration <= 0
+}
+
+// NewLeg returns the leg travelled from one position to the next.
+func NewLeg(from, to *models.Position) Leg {
+	leg := Leg{
+		Distance: Distance(from.Latitude, from.Longitude, to.Latitude, to.Longitude),
+		Bearing:  InitialBearing(from.Latitude, from.Longitude, to.Latitude, to.Longitude),
+		Duration: to.RecordedAt.Sub(from.RecordedAt),
+	}
+	if leg.Duration > 0 {
+		leg.Speed = leg.Distance / leg.Duration.Seconds()
+	}
+	return leg
+}
+
+// PathLength returns the total haversine distance in meters along positions
+// in the order given.
+func PathLength(positions []*models.Position) float64 {
+	var total float64
+	for i := 1; i < len(positions); i++ {
+		total += Distance(positions[i-1].Latitude, positions[i-1].Longitude,
+			positions[i].Latitude, positions[i].Longitude)
+	}
+	return total
+}
+
+// BoundingBox returns the lat/lng box enclosing a circle of radiusMeters
+// around a point. When the circle reaches a pole or crosses the antimeridian
+// the box widens to the full longitude range rather than wrapping.
+func BoundingBox(lat, lng, radiusMeters float64) (minLat, minLng, maxLat, maxLng float64) {
+	dLat := radiusMeters / EarthRadiusMeters * 180 / math.Pi
+	minLat = math.Max(lat-dLat, -90)
+	maxLat = math.Min(lat+dLat, 90)
+
+	if minLat <= -90 || maxLat >= 90 {
+		return minLat, -180, maxLat, 180
+	}
+
+	dLng := dLat / math.Cos(toRadians(lat))
+	minLng = lng - dLng
+	maxLng = lng + dLng
+	if minLng < -180 || maxLng > 180 {
+		return minLat, -180, maxLat, 180
+	}
+	return minLat, minLng, maxLat, maxLng
+}
+
+// InPolygon reports whether a point lies inside a polygon using ray casting.
+// The polygon is treated as planar in lat/lng space, which is accurate for
+// the building- and neighbourhood-sized areas geofences describe.
+func InPolygon(lat, lng float64, polygon []models.Coordinate) bool {
+	inside := false
+	n := len(polygon)
+	for i, j := 0, n-1; i < n; j, i = i, i+1 {
+		yi, xi := polygon[i].Latitude, polygon[i].Longitude
+		yj, xj := polygon[j].Latitude, polygon[j].Longitude
+		if (yi > lat) != (yj > lat) && lng < (xj-xi)*(lat-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// InShape reports whether a point lies inside a geofence's or place's shape.
+func InShape(shape models.Shape, lat, lng float64) bool {
+	if shape.IsCircle() {
+		return shape.Center != nil && Distance(shape.Center.Latitude, shape.Center.Longitude, lat, lng) <= shape.RadiusMeters
+	}
+	return InPolygon(lat, lng, shape.Polygon)
+}
+
+// ShapeArea returns the approximate area of a shape in square meters.
+func ShapeArea(shape models.Shape) float64 {
+	if shape.IsCircle() {
+		return math.Pi * shape.RadiusMeters * shape.RadiusMeters
+	}
+	// Shoelace formula on a plane tangent at the first vertex
+	origin := shape.Polygon[0]
+	perDegree := EarthRadiusMeters * math.Pi / 180
+	lngScale := perDegree * math.Cos(toRadians(origin.Latitude))
+	var sum float64
+	n := len(shape.Polygon)
+	for i := 0; i < n; i++ {
+		a, b := shape.Polygon[i], shape.Polygon[(i+1)%n]
+		ax, ay := (a.Longitude-origin.Longitude)*lngScale, (a.Latitude-origin.Latitude)*perDegree
+		bx, by := (b.Longitude-origin.Longitude)*lngScale, (b.Latitude-origin.Latitude)*perDegree
+		sum += ax*by - bx*ay
+	}
+	return math.Abs(sum) / 2
+}
+
+// MatchPlace returns the smallest place containing a point, so a place
+// nested inside a larger one wins, or nil when none contains it.
+func MatchPlace(places []*models.Place, lat, lng float64) *models.Place {
+	var best *models.Place
+	var bestArea float64
+	for _, place := range places {
+		if !InShape(place.Shape, lat, lng) {
+			continue
+		}
+		if area := ShapeArea(place.Shape); best == nil || area < bestArea {
+			best, bestArea = place, area
+		}
+	}
+	return best
+}
+
+func toRadians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
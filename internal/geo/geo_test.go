@@ -0,0 +1,254 @@
+// ABOUTME: Unit tests for geographic calculations
+// ABOUTME: Verifies distances, bearings, interpolation, legs, and circle/polygon containment
+
+package geo
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/harper/position/internal/models"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lng1, lat2, lng2 float64
+		want                   float64
+		tolerance              float64
+	}{
+		{"same point", 41.8781, -87.6298, 41.8781, -87.6298, 0, 0.001},
+		{"chicago to new york", 41.8781, -87.6298, 40.7128, -74.0060, 1144000, 2000},
+		{"one degree latitude", 0, 0, 1, 0, 111195, 10},
+		{"antimeridian", 0, 179.5, 0, -179.5, 111195, 10},
+	}
+	for _, tt := range tests {
+		got := Distance(tt.lat1, tt.lng1, tt.lat2, tt.lng2)
+		if math.Abs(got-tt.want) > tt.tolerance {
+			t.Errorf("%s: Distance = %.1f, want %.1f ± %.1f", tt.name, got, tt.want, tt.tolerance)
+		}
+	}
+}
+
+func TestVincenty(t *testing.T) {
+	// Flinders Peak to Buninyong, the reference case from Vincenty's paper
+	got := Vincenty(-37.95103341667, 144.42486788889, -37.65282113889, 143.92649552778)
+	if math.Abs(got-54972.271) > 0.01 {
+		t.Errorf("Vincenty = %.3f, want 54972.271", got)
+	}
+
+	if got := Vincenty(41.8781, -87.6298, 41.8781, -87.6298); got != 0 {
+		t.Errorf("expected 0 for coincident points, got %v", got)
+	}
+
+	// Ellipsoidal and spherical results agree to within half a percent
+	v := Vincenty(41.8781, -87.6298, 40.7128, -74.0060)
+	h := Distance(41.8781, -87.6298, 40.7128, -74.0060)
+	if math.Abs(v-h)/v > 0.005 {
+		t.Errorf("Vincenty %.0f and haversine %.0f differ too much", v, h)
+	}
+
+	// Nearly antipodal points fall back to haversine rather than failing
+	if got := Vincenty(0, 0, 0.5, 179.7); math.IsNaN(got) || got < 19000000 {
+		t.Errorf("unexpected near-antipodal distance %v", got)
+	}
+}
+
+func TestInitialBearing(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lng1, lat2, lng2 float64
+		want                   float64
+	}{
+		{"north", 0, 0, 1, 0, 0},
+		{"east", 0, 0, 0, 1, 90},
+		{"south", 1, 0, 0, 0, 180},
+		{"west", 0, 1, 0, 0, 270},
+		{"across antimeridian", 0, 179.5, 0, -179.5, 90},
+	}
+	for _, tt := range tests {
+		got := InitialBearing(tt.lat1, tt.lng1, tt.lat2, tt.lng2)
+		if math.Abs(got-tt.want) > 0.001 {
+			t.Errorf("%s: InitialBearing = %.3f, want %.3f", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCompassPoint(t *testing.T) {
+	tests := map[float64]string{
+		0:     "N",
+		22.4:  "N",
+		22.6:  "NE",
+		135:   "SE",
+		292.5: "NW",
+		359.7: "N",
+		-90:   "W",
+	}
+	for degrees, want := range tests {
+		if got := CompassPoint(degrees); got != want {
+			t.Errorf("CompassPoint(%v) = %q, want %q", degrees, got, want)
+		}
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lng1, lat2, lng2 float64
+		f                      float64
+		wantLat, wantLng       float64
+	}{
+		{"start", 41.8781, -87.6298, 40.7128, -74.0060, 0, 41.8781, -87.6298},
+		{"end", 41.8781, -87.6298, 40.7128, -74.0060, 1, 40.7128, -74.0060},
+		{"equator midpoint", 0, 0, 0, 10, 0.5, 0, 5},
+		{"meridian quarter", 0, 0, 40, 0, 0.25, 10, 0},
+		{"antimeridian", 0, 179, 0, -179, 0.5, 0, 180},
+		{"same point", 41.0, -87.0, 41.0, -87.0, 0.5, 41.0, -87.0},
+	}
+	for _, tt := range tests {
+		lat, lng := Interpolate(tt.lat1, tt.lng1, tt.lat2, tt.lng2, tt.f)
+		if math.Abs(lat-tt.wantLat) > 1e-6 || math.Abs(math.Mod(lng-tt.wantLng+540, 360)-180) > 1e-6 {
+			t.Errorf("%s: Interpolate = (%.6f, %.6f), want (%.6f, %.6f)", tt.name, lat, lng, tt.wantLat, tt.wantLng)
+		}
+	}
+
+	// The great-circle midpoint is equidistant from both ends
+	lat, lng := Interpolate(41.8781, -87.6298, 40.7128, -74.0060, 0.5)
+	d1 := Distance(41.8781, -87.6298, lat, lng)
+	d2 := Distance(lat, lng, 40.7128, -74.0060)
+	if math.Abs(d1-d2) > 1 {
+		t.Errorf("midpoint not equidistant: %.1f vs %.1f", d1, d2)
+	}
+}
+
+func TestNewLeg(t *testing.T) {
+	base := time.Date(2024, 12, 14, 8, 0, 0, 0, time.UTC)
+	from := models.NewPositionWithRecordedAt(models.NewItem("car").ID, 0, 0, nil, base)
+	to := models.NewPositionWithRecordedAt(from.ItemID, 1, 0, nil, base.Add(time.Hour))
+
+	leg := NewLeg(from, to)
+	if math.Abs(leg.Distance-111195) > 10 || leg.Bearing != 0 || leg.Duration != time.Hour {
+		t.Errorf("unexpected leg: %+v", leg)
+	}
+	if math.Abs(leg.Speed-leg.Distance/3600) > 1e-9 {
+		t.Errorf("Speed = %v, want %v", leg.Speed, leg.Distance/3600)
+	}
+
+	// Simultaneous fixes have no speed rather than dividing by zero
+	to.RecordedAt = base
+	if leg := NewLeg(from, to); leg.Speed != 0 {
+		t.Errorf("expected zero speed for zero duration, got %v", leg.Speed)
+	}
+}
+
+func TestPathLength(t *testing.T) {
+	id := models.NewItem("car").ID
+	path := []*models.Position{
+		models.NewPosition(id, 0, 0, nil),
+		models.NewPosition(id, 1, 0, nil),
+		models.NewPosition(id, 1, 1, nil),
+	}
+	want := Distance(0, 0, 1, 0) + Distance(1, 0, 1, 1)
+	if got := PathLength(path); math.Abs(got-want) > 1e-6 {
+		t.Errorf("PathLength = %v, want %v", got, want)
+	}
+	if got := PathLength(path[:1]); got != 0 {
+		t.Errorf("expected 0 for a single position, got %v", got)
+	}
+}
+
+func TestInPolygon(t *testing.T) {
+	square := []models.Coordinate{
+		{Latitude: 41.0, Longitude: -88.0},
+		{Latitude: 41.0, Longitude: -87.0},
+		{Latitude: 42.0, Longitude: -87.0},
+		{Latitude: 42.0, Longitude: -88.0},
+	}
+	if !InPolygon(41.5, -87.5, square) {
+		t.Error("expected center to be inside")
+	}
+	if InPolygon(42.5, -87.5, square) {
+		t.Error("expected point north of square to be outside")
+	}
+	if InPolygon(41.5, -86.5, square) {
+		t.Error("expected point east of square to be outside")
+	}
+}
+
+func TestInShape(t *testing.T) {
+	circle := models.CircleShape(41.8781, -87.6298, 100)
+	if !InShape(circle, 41.8785, -87.6298) {
+		t.Error("expected point ~45m away to be inside 100m circle")
+	}
+	if InShape(circle, 41.8800, -87.6298) {
+		t.Error("expected point ~210m away to be outside 100m circle")
+	}
+
+	triangle := models.PolygonShape([]models.Coordinate{
+		{Latitude: 0, Longitude: 0},
+		{Latitude: 0, Longitude: 2},
+		{Latitude: 2, Longitude: 0},
+	})
+	if !InShape(triangle, 0.5, 0.5) {
+		t.Error("expected point inside triangle")
+	}
+	if InShape(triangle, 1.5, 1.5) {
+		t.Error("expected point outside triangle hypotenuse")
+	}
+}
+
+func TestShapeArea(t *testing.T) {
+	if got, want := ShapeArea(models.CircleShape(41.0, -87.0, 100)), math.Pi*100*100; math.Abs(got-want) > 1 {
+		t.Errorf("expected circle area %.0f, got %.0f", want, got)
+	}
+	// ~1.11 km × ~0.84 km at 41°N
+	block := models.PolygonShape([]models.Coordinate{
+		{Latitude: 41.00, Longitude: -87.00},
+		{Latitude: 41.00, Longitude: -86.99},
+		{Latitude: 41.01, Longitude: -86.99},
+		{Latitude: 41.01, Longitude: -87.00},
+	})
+	if got := ShapeArea(block); math.Abs(got-933000) > 10000 {
+		t.Errorf("expected ~933000 m² for the block, got %.0f", got)
+	}
+}
+
+func TestMatchPlace(t *testing.T) {
+	campus := models.NewPlace("campus", 41.8781, -87.6298, 2000)
+	office := models.NewPlace("office", 41.8800, -87.6300, 50)
+	places := []*models.Place{campus, office}
+
+	if got := MatchPlace(places, 41.8800, -87.6300); got != office {
+		t.Errorf("expected the smaller nested place, got %v", got)
+	}
+	if got := MatchPlace(places, 41.8781, -87.6298); got != campus {
+		t.Errorf("expected campus, got %v", got)
+	}
+	if got := MatchPlace(places, 40.0, -80.0); got != nil {
+		t.Errorf("expected no place, got %v", got)
+	}
+}
+
+func TestBoundingBox(t *testing.T) {
+	lat, lng := 41.8781, -87.6298
+	minLat, minLng, maxLat, maxLng := BoundingBox(lat, lng, 500)
+
+	// Every edge midpoint should be roughly 500m from the center
+	for _, edge := range [][2]float64{{minLat, lng}, {maxLat, lng}, {lat, minLng}, {lat, maxLng}} {
+		d := Distance(lat, lng, edge[0], edge[1])
+		if math.Abs(d-500) > 1 {
+			t.Errorf("edge %v is %.1fm from center, want ~500m", edge, d)
+		}
+	}
+
+	_, minLng, _, maxLng = BoundingBox(89.999, 0, 1000)
+	if minLng != -180 || maxLng != 180 {
+		t.Errorf("expected full longitude range near pole, got %v..%v", minLng, maxLng)
+	}
+
+	_, minLng, _, maxLng = BoundingBox(0, 179.999, 1000)
+	if minLng != -180 || maxLng != 180 {
+		t.Errorf("expected full longitude range across antimeridian, got %v..%v", minLng, maxLng)
+	}
+}
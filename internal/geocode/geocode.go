@@ -0,0 +1,255 @@
+// ABOUTME: Offline reverse geocoding from a local GeoNames gazetteer
+// ABOUTME: Resolves coordinates to the nearest city, region, and country without network access
+
+package geocode
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/harper/position/internal/geo"
+)
+
+// DefaultMaxDistanceKm is how far a point may be from the nearest city
+// before it is considered to have no locality.
+const DefaultMaxDistanceKm = 50
+
+// admin1FileName is the GeoNames file mapping region codes to names. When it
+// sits next to the cities file, regions are shown by name rather than code.
+const admin1FileName = "admin1CodesASCII.txt"
+
+// City is a populated place from the gazetteer.
+type City struct {
+	Name      string
+	Region    string // first-level division, e.g. "Illinois"; may be a code
+	Country   string // ISO 3166-1 alpha-2 code
+	Latitude  float64
+	Longitude float64
+}
+
+// String formats the city as "Name, Region, Country", leaving out empty parts.
+func (c City) String() string {
+	parts := []string{c.Name}
+	if c.Region != "" && c.Region != c.Name {
+		parts = append(parts, c.Region)
+	}
+	if c.Country != "" {
+		parts = append(parts, c.Country)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Config selects the gazetteer file and how far from a city a point may be.
+type Config struct {
+	// Gazetteer is the path to a GeoNames cities file such as
+	// cities15000.txt. Supports ~ expansion by the caller.
+	Gazetteer string `json:"gazetteer"`
+
+	// MaxDistanceKm is the search radius around each point. Zero uses
+	// DefaultMaxDistanceKm.
+	MaxDistanceKm float64 `json:"max_distance_km,omitempty"`
+}
+
+// Validate checks that the gazetteer is set and the distance is usable.
+func (c Config) Validate() error {
+	if c.Gazetteer == "" {
+		return fmt.Errorf("geocode gazetteer path is required")
+	}
+	if math.IsNaN(c.MaxDistanceKm) || math.IsInf(c.MaxDistanceKm, 0) || c.MaxDistanceKm < 0 {
+		return fmt.Errorf("geocode max_distance_km must be a non-negative number")
+	}
+	return nil
+}
+
+// cell is a 1° by 1° grid square of the index.
+type cell struct{ lat, lng int }
+
+func cellOf(lat, lng float64) cell {
+	return cell{int(math.Floor(lat)), int(math.Floor(lng))}
+}
+
+// Gazetteer reverse geocodes against a set of cities indexed on a 1° grid.
+type Gazetteer struct {
+	maxDistance float64 // meters
+
+	path string
+	once sync.Once
+	err  error
+
+	cities []City
+	grid   map[cell][]int
+}
+
+// Open returns a gazetteer for the file in cfg. The file must exist but is
+// only read on the first lookup, so commands that never geocode don't pay
+// for loading it.
+func Open(cfg Config) (*Gazetteer, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(cfg.Gazetteer); err != nil {
+		return nil, fmt.Errorf("gazetteer: %w", err)
+	}
+	maxKm := cfg.MaxDistanceKm
+	if maxKm == 0 {
+		maxKm = DefaultMaxDistanceKm
+	}
+	return &Gazetteer{path: cfg.Gazetteer, maxDistance: maxKm * 1000}, nil
+}
+
+// New returns a gazetteer over cities, matching points up to maxDistanceKm
+// away.
+func New(cities []City, maxDistanceKm float64) *Gazetteer {
+	g := &Gazetteer{maxDistance: maxDistanceKm * 1000}
+	g.once.Do(func() { g.index(cities) })
+	return g
+}
+
+// load reads the gazetteer file and any admin1 names beside it.
+func (g *Gazetteer) load() error {
+	g.once.Do(func() {
+		regions, err := readAdmin1(filepath.Join(filepath.Dir(g.path), admin1FileName))
+		if err != nil {
+			g.err = err
+			return
+		}
+		f, err := os.Open(g.path)
+		if err != nil {
+			g.err = fmt.Errorf("open gazetteer: %w", err)
+			return
+		}
+		defer func() { _ = f.Close() }()
+
+		cities, err := Parse(f, regions)
+		if err != nil {
+			g.err = fmt.Errorf("read gazetteer %s: %w", g.path, err)
+			return
+		}
+		g.index(cities)
+	})
+	return g.err
+}
+
+func (g *Gazetteer) index(cities []City) {
+	g.cities = cities
+	g.grid = make(map[cell][]int)
+	for i, c := range cities {
+		k := cellOf(c.Latitude, c.Longitude)
+		g.grid[k] = append(g.grid[k], i)
+	}
+}
+
+// Nearest returns the closest city within the search radius and its
+// distance in meters. ok is false when there is none.
+func (g *Gazetteer) Nearest(lat, lng float64) (city City, meters float64, ok bool, err error) {
+	if err := g.load(); err != nil {
+		return City{}, 0, false, err
+	}
+
+	minLat, minLng, maxLat, maxLng := geo.BoundingBox(lat, lng, g.maxDistance)
+	best := -1
+	bestDist := math.Inf(1)
+	for la := int(math.Floor(minLat)); la <= int(math.Floor(maxLat)); la++ {
+		for ln := int(math.Floor(minLng)); ln <= int(math.Floor(maxLng)); ln++ {
+			for _, i := range g.grid[cell{la, ln}] {
+				c := g.cities[i]
+				if d := geo.Distance(lat, lng, c.Latitude, c.Longitude); d <= g.maxDistance && d < bestDist {
+					best, bestDist = i, d
+				}
+			}
+		}
+	}
+	if best < 0 {
+		return City{}, 0, false, nil
+	}
+	return g.cities[best], bestDist, true, nil
+}
+
+// Locality returns "City, Region, Country" for the nearest city, or "" when
+// no city is within the search radius.
+func (g *Gazetteer) Locality(lat, lng float64) (string, error) {
+	city, _, ok, err := g.Nearest(lat, lng)
+	if err != nil || !ok {
+		return "", err
+	}
+	return city.String(), nil
+}
+
+// Parse reads cities in the GeoNames tab-separated format (as in
+// cities15000.txt). regions maps "CC.admin1" codes to names; codes without
+// a name are kept as-is. Blank lines and lines starting with # are skipped.
+func Parse(r io.Reader, regions map[string]string) ([]City, error) {
+	var cities []City
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Split(text, "\t")
+		if len(fields) < 11 {
+			return nil, fmt.Errorf("line %d: expected at least 11 fields, got %d", line, len(fields))
+		}
+		lat, err := strconv.ParseFloat(fields[4], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid latitude %q", line, fields[4])
+		}
+		lng, err := strconv.ParseFloat(fields[5], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid longitude %q", line, fields[5])
+		}
+
+		country, admin1 := fields[8], fields[10]
+		region := admin1
+		if name, ok := regions[country+"."+admin1]; ok {
+			region = name
+		}
+		cities = append(cities, City{
+			Name:      fields[1],
+			Region:    region,
+			Country:   country,
+			Latitude:  lat,
+			Longitude: lng,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return cities, nil
+}
+
+// readAdmin1 reads a GeoNames admin1CodesASCII.txt file into a map of
+// "CC.admin1" codes to names. A missing file yields an empty map.
+func readAdmin1(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open admin1 codes: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	regions := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) >= 2 {
+			regions[fields[0]] = fields[1]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read admin1 codes: %w", err)
+	}
+	return regions, nil
+}
@@ -0,0 +1,122 @@
+// ABOUTME: Tests for offline reverse geocoding
+// ABOUTME: Covers GeoNames parsing, admin1 region names, nearest-city lookup, and lazy loading
+
+package geocode
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// geonamesRows are cities in the GeoNames cities15000.txt layout.
+const geonamesRows = "4887398\tChicago\tChicago\t\t41.85003\t-87.65005\tP\tPPLA2\tUS\t\tIL\t031\t\t\t2720546\t\t179\tAmerica/Chicago\t2024-01-01\n" +
+	"4888671\tEvanston\tEvanston\t\t42.04114\t-87.69006\tP\tPPL\tUS\t\tIL\t031\t\t\t74756\t\t183\tAmerica/Chicago\t2024-01-01\n" +
+	"5128581\tNew York City\tNew York City\t\t40.71427\t-74.00597\tP\tPPL\tUS\t\tNY\t\t\t\t8804190\t\t10\tAmerica/New_York\t2024-01-01\n" +
+	"2193733\tAuckland\tAuckland\t\t-36.84853\t174.76349\tP\tPPLA\tNZ\t\tE7\t\t\t\t417910\t\t26\tPacific/Auckland\t2024-01-01\n"
+
+// writeGazetteer writes the test cities, and optionally admin1 names, to a
+// temp directory and returns the cities file path.
+func writeGazetteer(t *testing.T, withAdmin1 bool) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cities15000.txt")
+	if err := os.WriteFile(path, []byte(geonamesRows), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if withAdmin1 {
+		admin1 := "US.IL\tIllinois\tIllinois\t4896861\nUS.NY\tNew York\tNew York\t5128638\n"
+		if err := os.WriteFile(filepath.Join(dir, admin1FileName), []byte(admin1), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+func TestParse(t *testing.T) {
+	cities, err := Parse(strings.NewReader("# comment\n\n"+geonamesRows), map[string]string{"US.IL": "Illinois"})
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(cities) != 4 {
+		t.Fatalf("expected 4 cities, got %d", len(cities))
+	}
+	if got := cities[0].String(); got != "Chicago, Illinois, US" {
+		t.Errorf("expected region name from admin1, got %q", got)
+	}
+	if got := cities[2].String(); got != "New York City, NY, US" {
+		t.Errorf("expected region code without admin1 name, got %q", got)
+	}
+
+	for _, bad := range []string{"1\tShort\trow\n", "1\tX\tX\t\tnorth\t0\tP\tPPL\tUS\t\tIL\n"} {
+		if _, err := Parse(strings.NewReader(bad), nil); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestGazetteer_Locality(t *testing.T) {
+	g, err := Open(Config{Gazetteer: writeGazetteer(t, true)})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		lat, lng float64
+		want     string
+	}{
+		{"downtown", 41.8781, -87.6298, "Chicago, Illinois, US"},
+		{"nearer_evanston", 42.03, -87.68, "Evanston, Illinois, US"},
+		{"southern_hemisphere", -36.9, 174.8, "Auckland, E7, NZ"},
+		{"open_ocean", 30.0, -40.0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := g.Locality(tt.lat, tt.lng)
+			if err != nil {
+				t.Fatalf("Locality failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Locality(%v, %v) = %q, want %q", tt.lat, tt.lng, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGazetteer_MaxDistance(t *testing.T) {
+	cities, _ := Parse(strings.NewReader(geonamesRows), nil)
+	// Joliet is ~50 km from Chicago
+	if _, _, ok, _ := New(cities, 100).Nearest(41.525, -88.0817); !ok {
+		t.Error("expected Chicago within 100 km")
+	}
+	if _, _, ok, _ := New(cities, 10).Nearest(41.525, -88.0817); ok {
+		t.Error("expected nothing within 10 km")
+	}
+}
+
+func TestOpen_Errors(t *testing.T) {
+	if _, err := Open(Config{}); err == nil {
+		t.Error("expected error for missing gazetteer path")
+	}
+	if _, err := Open(Config{Gazetteer: filepath.Join(t.TempDir(), "missing.txt")}); err == nil {
+		t.Error("expected error for nonexistent gazetteer")
+	}
+	if _, err := Open(Config{Gazetteer: writeGazetteer(t, false), MaxDistanceKm: -1}); err == nil {
+		t.Error("expected error for negative distance")
+	}
+
+	// A malformed file is only noticed on the first lookup
+	path := filepath.Join(t.TempDir(), "bad.txt")
+	if err := os.WriteFile(path, []byte("not\tgeonames\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	g, err := Open(Config{Gazetteer: path})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if _, err := g.Locality(41.8781, -87.6298); err == nil {
+		t.Error("expected error looking up in a malformed gazetteer")
+	}
+}
@@ -1,5 +1,5 @@
 // ABOUTME: GeoJSON generation utilities
-// ABOUTME: Converts positions to GeoJSON FeatureCollections
+// ABOUTME: Converts positions and trips to GeoJSON FeatureCollections
 
 package geojson
 
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/harper/position/internal/models"
+	"github.com/harper/position/internal/track"
 )
 
 // FeatureCollection represents a GeoJSON FeatureCollection.
@@ -55,6 +56,13 @@ func ToPointsFeatureCollection(positions []*models.Position, nameResolver ItemNa
 		if pos.Label != nil {
 			props["label"] = *pos.Label
 		}
+		if pos.Locality != nil {
+			props["locality"] = *pos.Locality
+		}
+		addTelemetry(props, pos.Telemetry)
+		if pos.Suspect {
+			props["suspect"] = true
+		}
 
 		features = append(features, Feature{
 			Type: "Feature",
@@ -72,32 +80,53 @@ func ToPointsFeatureCollection(positions []*models.Position, nameResolver ItemNa
 	}
 }
 
-// ToLineFeatureCollection converts positions to a FeatureCollection of LineStrings.
-// Positions are grouped by item and sorted chronologically.
-func ToLineFeatureCollection(positions []*models.Position, nameResolver ItemNameResolver) *FeatureCollection {
-	// Group positions by item ID
-	byItem := make(map[string][]*models.Position)
-	for _, pos := range positions {
-		key := pos.ItemID.String()
-		byItem[key] = append(byItem[key], pos)
+// addTelemetry copies reported telemetry into feature properties.
+func addTelemetry(props map[string]interface{}, t models.Telemetry) {
+	for key, v := range map[string]*float64{
+		"accuracy": t.Accuracy,
+		"altitude": t.Altitude,
+		"speed":    t.Speed,
+		"heading":  t.Heading,
+	} {
+		if v != nil {
+			props[key] = *v
+		}
 	}
+	if t.Battery != nil {
+		props["battery"] = *t.Battery
+	}
+	if t.Source != nil {
+		props["source"] = *t.Source
+	}
+}
 
-	features := make([]Feature, 0, len(byItem))
-
-	for itemID, itemPositions := range byItem {
+// ToLineFeatureCollection converts positions to a FeatureCollection of LineStrings.
+// Positions are grouped by item into lines ordered by item name, sorted
+// chronologically, and thinned by reduce; each line reports how many points
+// the reduction removed.
+func ToLineFeatureCollection(positions []*models.Position, nameResolver ItemNameResolver, reduce track.Reduction) *FeatureCollection {
+	items := track.GroupByItem(positions, nameResolver)
+	features := make([]Feature, 0, len(items))
+	for _, item := range items {
+		itemPositions := item.Positions
 		if len(itemPositions) < 2 {
 			// Need at least 2 points for a line
 			continue
 		}
 
-		name := ""
-		if nameResolver != nil {
-			name = nameResolver(itemID)
+		name := item.Name
+		kept := track.Reduce(itemPositions, reduce)
+		coords := make(LineCoordinates, len(kept))
+		for i, pos := range kept {
+			coords[i] = PointCoordinates{pos.Longitude, pos.Latitude}
 		}
 
-		coords := make(LineCoordinates, len(itemPositions))
-		for i, pos := range itemPositions {
-			coords[i] = PointCoordinates{pos.Longitude, pos.Latitude}
+		props := map[string]interface{}{
+			"name":        name,
+			"point_count": len(kept),
+		}
+		if reduce.Enabled() {
+			props["removed_count"] = len(itemPositions) - len(kept)
 		}
 
 		features = append(features, Feature{
@@ -106,10 +135,52 @@ func ToLineFeatureCollection(positions []*models.Position, nameResolver ItemName
 				Type:        "LineString",
 				Coordinates: coords,
 			},
-			Properties: map[string]interface{}{
-				"name":        name,
-				"point_count": len(itemPositions),
+			Properties: props,
+		})
+	}
+
+	return &FeatureCollection{
+		Type:     "FeatureCollection",
+		Features: features,
+	}
+}
+
+// ToTripsFeatureCollection converts trips to a FeatureCollection with one
+// LineString per trip, carrying its times, distance, and speeds as properties.
+func ToTripsFeatureCollection(trips []track.Trip, name string) *FeatureCollection {
+	features := make([]Feature, 0, len(trips))
+
+	for i, trip := range trips {
+		coords := make(LineCoordinates, len(trip.Positions))
+		for j, pos := range trip.Positions {
+			coords[j] = PointCoordinates{pos.Longitude, pos.Latitude}
+		}
+
+		props := map[string]interface{}{
+			"name":             name,
+			"trip":             i + 1,
+			"start":            trip.Start().Format(time.RFC3339),
+			"end":              trip.End().Format(time.RFC3339),
+			"duration_seconds": trip.Duration().Seconds(),
+			"distance_meters":  trip.Distance,
+			"avg_speed_kmh":    trip.AverageSpeed() * 3.6,
+			"max_speed_kmh":    trip.MaxSpeed * 3.6,
+			"point_count":      len(trip.Positions),
+		}
+		if trip.From != nil && trip.From.Label != "" {
+			props["from"] = trip.From.Label
+		}
+		if trip.To != nil && trip.To.Label != "" {
+			props["to"] = trip.To.Label
+		}
+
+		features = append(features, Feature{
+			Type: "Feature",
+			Geometry: Geometry{
+				Type:        "LineString",
+				Coordinates: coords,
 			},
+			Properties: props,
 		})
 	}
 
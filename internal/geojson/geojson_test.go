@@ -1,5 +1,5 @@
 // ABOUTME: Unit tests for GeoJSON generation
-// ABOUTME: Tests Point and LineString feature collection builders
+// ABOUTME: Tests Point, LineString, and trip feature collection builders
 
 package geojson
 
@@ -10,6 +10,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/harper/position/internal/models"
+	"github.com/harper/position/internal/track"
 )
 
 func TestToPointsFeatureCollection(t *testing.T) {
@@ -96,7 +97,7 @@ func TestToLineFeatureCollection(t *testing.T) {
 		return ""
 	}
 
-	fc := ToLineFeatureCollection(positions, nameResolver)
+	fc := ToLineFeatureCollection(positions, nameResolver, track.Reduction{})
 
 	if fc.Type != "FeatureCollection" {
 		t.Errorf("expected FeatureCollection type, got %s", fc.Type)
@@ -121,6 +122,34 @@ func TestToLineFeatureCollection(t *testing.T) {
 	if feature.Properties["point_count"] != 2 {
 		t.Errorf("expected point_count 2, got %v", feature.Properties["point_count"])
 	}
+	if _, ok := feature.Properties["removed_count"]; ok {
+		t.Error("expected no removed_count without a reduction")
+	}
+}
+
+func TestToLineFeatureCollection_Reduced(t *testing.T) {
+	itemID := uuid.New()
+	base := time.Date(2024, 12, 14, 18, 0, 0, 0, time.UTC)
+	var positions []*models.Position
+	// A straight eastward line, newest first as storage returns it
+	for i := 0; i < 10; i++ {
+		pos := models.NewPositionWithRecordedAt(itemID, 41.8781, -87.6298+float64(i)*0.001, nil, base.Add(time.Duration(i)*time.Minute))
+		positions = append([]*models.Position{pos}, positions...)
+	}
+
+	fc := ToLineFeatureCollection(positions, nil, track.Reduction{Tolerance: 10})
+	if len(fc.Features) != 1 {
+		t.Fatalf("expected 1 feature, got %d", len(fc.Features))
+	}
+
+	props := fc.Features[0].Properties
+	if props["point_count"] != 2 || props["removed_count"] != 8 {
+		t.Errorf("expected 2 points with 8 removed, got %v / %v", props["point_count"], props["removed_count"])
+	}
+	coords := fc.Features[0].Geometry.Coordinates.(LineCoordinates)
+	if coords[0][0] != -87.6298 {
+		t.Errorf("expected the line to start at the oldest position, got %v", coords[0])
+	}
 }
 
 func TestToLineFeatureCollection_SinglePoint(t *testing.T) {
@@ -135,7 +164,7 @@ func TestToLineFeatureCollection_SinglePoint(t *testing.T) {
 		},
 	}
 
-	fc := ToLineFeatureCollection(positions, nil)
+	fc := ToLineFeatureCollection(positions, nil, track.Reduction{})
 
 	if len(fc.Features) != 0 {
 		t.Errorf("expected 0 features for single point, got %d", len(fc.Features))
@@ -195,6 +224,66 @@ func TestFeatureCollection_ToJSONIndent(t *testing.T) {
 	}
 }
 
+func TestToPointsFeatureCollection_Telemetry(t *testing.T) {
+	acc := 12.0
+	battery := 80
+	source := "overland"
+	pos := models.NewPosition(uuid.New(), 41.8781, -87.6298, nil)
+	pos.Accuracy = &acc
+	pos.Battery = &battery
+	pos.Source = &source
+
+	props := ToPointsFeatureCollection([]*models.Position{pos}, nil).Features[0].Properties
+	if props["accuracy"] != 12.0 || props["battery"] != 80 || props["source"] != "overland" {
+		t.Errorf("expected telemetry properties, got %v", props)
+	}
+	if _, ok := props["altitude"]; ok {
+		t.Error("expected unreported altitude to be omitted")
+	}
+}
+
+func TestToTripsFeatureCollection(t *testing.T) {
+	itemID := uuid.New()
+	base := time.Date(2024, 12, 14, 18, 0, 0, 0, time.UTC)
+	trip := track.Trip{
+		From: &track.Visit{Label: "home"},
+		Positions: []*models.Position{
+			models.NewPositionWithRecordedAt(itemID, 41.95, -87.65, nil, base),
+			models.NewPositionWithRecordedAt(itemID, 41.88, -87.63, nil, base.Add(30*time.Minute)),
+		},
+		Distance: 8000,
+		MaxSpeed: 20,
+	}
+
+	fc := ToTripsFeatureCollection([]track.Trip{trip}, "van")
+	if len(fc.Features) != 1 {
+		t.Fatalf("expected 1 feature, got %d", len(fc.Features))
+	}
+
+	f := fc.Features[0]
+	if f.Geometry.Type != "LineString" {
+		t.Errorf("expected LineString, got %s", f.Geometry.Type)
+	}
+	coords := f.Geometry.Coordinates.(LineCoordinates)
+	if len(coords) != 2 || coords[0][0] != -87.65 || coords[0][1] != 41.95 {
+		t.Errorf("unexpected coordinates: %v", coords)
+	}
+
+	props := f.Properties
+	if props["name"] != "van" || props["trip"] != 1 || props["from"] != "home" {
+		t.Errorf("unexpected properties: %v", props)
+	}
+	if _, ok := props["to"]; ok {
+		t.Error("expected no 'to' property for an open-ended trip")
+	}
+	if props["distance_meters"] != 8000.0 || props["duration_seconds"] != 1800.0 || props["avg_speed_kmh"] != 16.0 || props["max_speed_kmh"] != 72.0 {
+		t.Errorf("unexpected stats: %v", props)
+	}
+	if props["start"] != "2024-12-14T18:00:00Z" || props["end"] != "2024-12-14T18:30:00Z" {
+		t.Errorf("unexpected times: %v - %v", props["start"], props["end"])
+	}
+}
+
 func containsNewline(s string) bool {
 	for _, c := range s {
 		if c == '\n' {
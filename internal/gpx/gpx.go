@@ -0,0 +1,118 @@
+// ABOUTME: GPX 1.1 generation and parsing utilities
+// ABOUTME: Converts positions to tracks and waypoints and reads GPX files back
+
+package gpx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+
+	"github.com/harper/position/internal/models"
+	"github.com/harper/position/internal/track"
+)
+
+// Namespace is the GPX 1.1 XML namespace.
+const Namespace = "http://www.topografix.com/GPX/1/1"
+
+// GPX represents the root element of a GPX 1.1 document.
+type GPX struct {
+	XMLName   xml.Name `xml:"gpx"`
+	Xmlns     string   `xml:"xmlns,attr,omitempty"`
+	Version   string   `xml:"version,attr"`
+	Creator   string   `xml:"creator,attr"`
+	Waypoints []Point  `xml:"wpt"`
+	Tracks    []Track  `xml:"trk"`
+}
+
+// Track represents a GPX track (one per item).
+type Track struct {
+	Name     string    `xml:"name,omitempty"`
+	Segments []Segment `xml:"trkseg"`
+}
+
+// Segment represents a contiguous run of track points.
+type Segment struct {
+	Points []Point `xml:"trkpt"`
+}
+
+// Point represents a GPX waypoint or track point (both use wptType).
+type Point struct {
+	Latitude  float64  `xml:"lat,attr"`
+	Longitude float64  `xml:"lon,attr"`
+	Elevation *float64 `xml:"ele,omitempty"`
+	Time      string   `xml:"time,omitempty"`
+	Name      string   `xml:"name,omitempty"`
+	Desc      string   `xml:"desc,omitempty"`
+}
+
+// RecordedAt parses the point's timestamp.
+func (p *Point) RecordedAt() (time.Time, error) {
+	if p.Time == "" {
+		return time.Time{}, fmt.Errorf("point has no time")
+	}
+	return time.Parse(time.RFC3339, p.Time)
+}
+
+// ItemNameResolver is a function that resolves an item ID to its name.
+type ItemNameResolver func(itemID string) string
+
+// FromPositions converts positions to a GPX document.
+// Positions are grouped by item into one track each and sorted chronologically,
+// matching the semantics of the GeoJSON line export. Labeled positions are also
+// emitted as waypoints named after the label, with the item name in desc.
+func FromPositions(positions []*models.Position, nameResolver ItemNameResolver) *GPX {
+	doc := &GPX{
+		Xmlns:   Namespace,
+		Version: "1.1",
+		Creator: "position",
+	}
+
+	for _, item := range track.GroupByItem(positions, nameResolver) {
+		name := item.Name
+		seg := Segment{Points: make([]Point, 0, len(item.Positions))}
+		for _, pos := range item.Positions {
+			seg.Points = append(seg.Points, Point{
+				Latitude:  pos.Latitude,
+				Longitude: pos.Longitude,
+				Elevation: pos.Altitude,
+				Time:      pos.RecordedAt.UTC().Format(time.RFC3339),
+			})
+
+			if pos.Label != nil && *pos.Label != "" {
+				doc.Waypoints = append(doc.Waypoints, Point{
+					Latitude:  pos.Latitude,
+					Longitude: pos.Longitude,
+					Time:      pos.RecordedAt.UTC().Format(time.RFC3339),
+					Name:      *pos.Label,
+					Desc:      name,
+				})
+			}
+		}
+
+		doc.Tracks = append(doc.Tracks, Track{
+			Name:     name,
+			Segments: []Segment{seg},
+		})
+	}
+
+	return doc
+}
+
+// ToXML serializes a GPX document to indented XML with a declaration header.
+func (g *GPX) ToXML() ([]byte, error) {
+	body, err := xml.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(body, '\n')...), nil
+}
+
+// Parse reads a GPX document. Both GPX 1.0 and 1.1 namespaces are accepted.
+func Parse(data []byte) (*GPX, error) {
+	var doc GPX
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse gpx: %w", err)
+	}
+	return &doc, nil
+}
@@ -0,0 +1,188 @@
+// ABOUTME: Unit tests for GPX generation and parsing
+// ABOUTME: Tests track/waypoint building and round-tripping through XML
+
+package gpx
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/harper/position/internal/models"
+)
+
+func TestFromPositions(t *testing.T) {
+	label := "chicago"
+	itemID := uuid.New()
+	base := time.Date(2024, 12, 14, 8, 0, 0, 0, time.UTC)
+	positions := []*models.Position{
+		{ID: uuid.New(), ItemID: itemID, Latitude: 41.0, Longitude: -87.0, RecordedAt: base.Add(time.Hour)},
+		{ID: uuid.New(), ItemID: itemID, Latitude: 41.8781, Longitude: -87.6298, Label: &label, RecordedAt: base},
+	}
+
+	nameResolver := func(id string) string {
+		if id == itemID.String() {
+			return "harper"
+		}
+		return ""
+	}
+
+	doc := FromPositions(positions, nameResolver)
+
+	if doc.Version != "1.1" {
+		t.Errorf("expected version 1.1, got %s", doc.Version)
+	}
+	if len(doc.Tracks) != 1 {
+		t.Fatalf("expected 1 track, got %d", len(doc.Tracks))
+	}
+
+	trk := doc.Tracks[0]
+	if trk.Name != "harper" {
+		t.Errorf("expected track name 'harper', got %q", trk.Name)
+	}
+	if len(trk.Segments) != 1 || len(trk.Segments[0].Points) != 2 {
+		t.Fatalf("expected 1 segment with 2 points, got %+v", trk.Segments)
+	}
+
+	// Points should be chronological
+	first := trk.Segments[0].Points[0]
+	if first.Latitude != 41.8781 || first.Time != "2024-12-14T08:00:00Z" {
+		t.Errorf("expected oldest point first, got %+v", first)
+	}
+
+	if len(doc.Waypoints) != 1 {
+		t.Fatalf("expected 1 waypoint, got %d", len(doc.Waypoints))
+	}
+	if doc.Waypoints[0].Name != "chicago" {
+		t.Errorf("expected waypoint name 'chicago', got %q", doc.Waypoints[0].Name)
+	}
+	if doc.Waypoints[0].Desc != "harper" {
+		t.Errorf("expected waypoint desc 'harper', got %q", doc.Waypoints[0].Desc)
+	}
+}
+
+func TestFromPositions_MultipleItemsSortedByName(t *testing.T) {
+	carID := uuid.New()
+	harperID := uuid.New()
+	now := time.Now()
+	positions := []*models.Position{
+		{ID: uuid.New(), ItemID: harperID, Latitude: 1, Longitude: 1, RecordedAt: now},
+		{ID: uuid.New(), ItemID: carID, Latitude: 2, Longitude: 2, RecordedAt: now},
+	}
+	names := map[string]string{carID.String(): "car", harperID.String(): "harper"}
+
+	doc := FromPositions(positions, func(id string) string { return names[id] })
+
+	if len(doc.Tracks) != 2 {
+		t.Fatalf("expected 2 tracks, got %d", len(doc.Tracks))
+	}
+	if doc.Tracks[0].Name != "car" || doc.Tracks[1].Name != "harper" {
+		t.Errorf("expected tracks sorted by name, got %q, %q", doc.Tracks[0].Name, doc.Tracks[1].Name)
+	}
+}
+
+func TestFromPositions_Empty(t *testing.T) {
+	doc := FromPositions(nil, nil)
+	if len(doc.Tracks) != 0 || len(doc.Waypoints) != 0 {
+		t.Errorf("expected empty document, got %+v", doc)
+	}
+}
+
+func TestToXML(t *testing.T) {
+	itemID := uuid.New()
+	positions := []*models.Position{
+		{ID: uuid.New(), ItemID: itemID, Latitude: 41.8781, Longitude: -87.6298, RecordedAt: time.Now()},
+	}
+
+	data, err := FromPositions(positions, func(string) string { return "harper" }).ToXML()
+	if err != nil {
+		t.Fatalf("ToXML failed: %v", err)
+	}
+
+	xmlStr := string(data)
+	if !strings.HasPrefix(xmlStr, "<?xml") {
+		t.Error("expected XML declaration")
+	}
+	if !strings.Contains(xmlStr, `xmlns="http://www.topografix.com/GPX/1/1"`) {
+		t.Error("expected GPX 1.1 namespace")
+	}
+	if !strings.Contains(xmlStr, `<trkpt lat="41.8781" lon="-87.6298">`) {
+		t.Errorf("expected trkpt element, got:\n%s", xmlStr)
+	}
+}
+
+func TestParse_RoundTrip(t *testing.T) {
+	label := "home"
+	itemID := uuid.New()
+	recordedAt := time.Date(2024, 12, 14, 15, 0, 0, 0, time.UTC)
+	positions := []*models.Position{
+		{ID: uuid.New(), ItemID: itemID, Latitude: 41.8781, Longitude: -87.6298, Label: &label, RecordedAt: recordedAt},
+	}
+
+	data, err := FromPositions(positions, func(string) string { return "harper" }).ToXML()
+	if err != nil {
+		t.Fatalf("ToXML failed: %v", err)
+	}
+
+	doc, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(doc.Tracks) != 1 || doc.Tracks[0].Name != "harper" {
+		t.Fatalf("unexpected tracks: %+v", doc.Tracks)
+	}
+
+	pt := doc.Tracks[0].Segments[0].Points[0]
+	at, err := pt.RecordedAt()
+	if err != nil {
+		t.Fatalf("RecordedAt failed: %v", err)
+	}
+	if !at.Equal(recordedAt) {
+		t.Errorf("expected %v, got %v", recordedAt, at)
+	}
+	if len(doc.Waypoints) != 1 || doc.Waypoints[0].Name != "home" {
+		t.Errorf("unexpected waypoints: %+v", doc.Waypoints)
+	}
+}
+
+func TestParse_ForeignFile(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.0" creator="bike computer" xmlns="http://www.topografix.com/GPX/1/0">
+  <trk>
+    <name>Morning Ride</name>
+    <trkseg>
+      <trkpt lat="41.9" lon="-87.6"><ele>181.2</ele><time>2024-12-14T08:00:00Z</time></trkpt>
+      <trkpt lat="41.91" lon="-87.61"><time>2024-12-14T08:01:00Z</time></trkpt>
+    </trkseg>
+  </trk>
+</gpx>`)
+
+	doc, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(doc.Tracks) != 1 {
+		t.Fatalf("expected 1 track, got %d", len(doc.Tracks))
+	}
+	points := doc.Tracks[0].Segments[0].Points
+	if len(points) != 2 {
+		t.Fatalf("expected 2 points, got %d", len(points))
+	}
+	if points[0].Elevation == nil || *points[0].Elevation != 181.2 {
+		t.Errorf("expected elevation 181.2, got %v", points[0].Elevation)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	if _, err := Parse([]byte("not xml")); err == nil {
+		t.Error("expected error for invalid XML")
+	}
+}
+
+func TestPoint_RecordedAt_Missing(t *testing.T) {
+	p := Point{Latitude: 1, Longitude: 1}
+	if _, err := p.RecordedAt(); err == nil {
+		t.Error("expected error for point without time")
+	}
+}
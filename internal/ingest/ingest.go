@@ -0,0 +1,412 @@
+// ABOUTME: HTTP ingestion handler for phone tracking apps
+// ABOUTME: Accepts OwnTracks JSON, Overland GeoJSON batches, and GPSLogger/OsmAnd query pings
+
+package ingest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/harper/position/internal/models"
+	"github.com/harper/position/internal/storage"
+)
+
+// maxBodyBytes caps request bodies; Overland batches are the largest payloads.
+const maxBodyBytes = 10 << 20
+
+// Handler receives location reports over HTTP and records them as positions.
+// Each device identifier is mapped to an item, created on first sight.
+type Handler struct {
+	repo    storage.Repository
+	aliases map[string]string
+	mux     *http.ServeMux
+
+	// mu serializes writes so concurrent first reports from a device
+	// don't race to create the same item.
+	mu sync.Mutex
+
+	// Logger receives one line per request. Nil disables logging.
+	Logger *log.Logger
+}
+
+// NewHandler creates a handler writing to repo. Aliases map device
+// identifiers (OwnTracks tid, Overland device_id, OsmAnd id) to item names;
+// identifiers without an alias are used as the item name directly.
+func NewHandler(repo storage.Repository, aliases map[string]string) *Handler {
+	h := &Handler{
+		repo:    repo,
+		aliases: aliases,
+		mux:     http.NewServeMux(),
+	}
+	h.mux.HandleFunc("POST /owntracks", h.handleOwnTracks)
+	h.mux.HandleFunc("POST /overland", h.handleOverland)
+	h.mux.HandleFunc("GET /gpslogger", h.handleQuery)
+	h.mux.HandleFunc("POST /gpslogger", h.handleQuery)
+	h.mux.HandleFunc("GET /osmand", h.handleQuery)
+	h.mux.HandleFunc("POST /osmand", h.handleQuery)
+	return h
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// report is a single location extracted from any of the supported payloads.
+type report struct {
+	device     string
+	latitude   float64
+	longitude  float64
+	label      *string
+	recordedAt time.Time
+	telemetry  models.Telemetry
+}
+
+// --- OwnTracks ---
+
+type ownTracksMessage struct {
+	Type      string   `json:"_type"`
+	Latitude  *float64 `json:"lat"`
+	Longitude *float64 `json:"lon"`
+	Timestamp int64    `json:"tst"`
+	TID       string   `json:"tid"`
+	Topic     string   `json:"topic"`
+	InRegions []string `json:"inregions"`
+	Accuracy  *float64 `json:"acc"`
+	Altitude  *float64 `json:"alt"`
+	Velocity  *float64 `json:"vel"` // km/h
+	Course    *float64 `json:"cog"`
+	Battery   *float64 `json:"batt"`
+}
+
+// handleOwnTracks accepts OwnTracks HTTP mode posts. Only _type "location"
+// messages are recorded; other message types are acknowledged and ignored.
+// The device is taken from ?name, the X-Limit-D header, the topic, or tid.
+func (h *Handler) handleOwnTracks(w http.ResponseWriter, r *http.Request) {
+	var msg ownTracksMessage
+	if err := decodeBody(w, r, &msg); err != nil {
+		h.fail(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	// OwnTracks expects a JSON array in response (commands for the device).
+	if msg.Type != "location" {
+		h.logf("owntracks: ignored %q message", msg.Type)
+		writeJSON(w, []any{})
+		return
+	}
+	if msg.Latitude == nil || msg.Longitude == nil {
+		h.fail(w, r, http.StatusBadRequest, errors.New("location message missing lat/lon"))
+		return
+	}
+
+	device := firstNonEmpty(r.URL.Query().Get("name"), r.Header.Get("X-Limit-D"), topicDevice(msg.Topic), msg.TID)
+	rep := report{
+		device:     device,
+		latitude:   *msg.Latitude,
+		longitude:  *msg.Longitude,
+		recordedAt: time.Now(),
+		telemetry: models.Telemetry{
+			Accuracy: msg.Accuracy,
+			Altitude: msg.Altitude,
+			Speed:    scale(msg.Velocity, 1/3.6),
+			Heading:  msg.Course,
+			Battery:  percent(msg.Battery, 1),
+			Source:   source("owntracks"),
+		},
+	}
+	if msg.Timestamp > 0 {
+		rep.recordedAt = time.Unix(msg.Timestamp, 0).UTC()
+	}
+	if len(msg.InRegions) > 0 {
+		label := msg.InRegions[0]
+		rep.label = &label
+	}
+
+	if err := h.record(rep); err != nil {
+		h.fail(w, r, statusFor(err), err)
+		return
+	}
+	h.logf("owntracks: recorded %s", rep.device)
+	writeJSON(w, []any{})
+}
+
+// topicDevice extracts the device from an "owntracks/user/device" topic.
+func topicDevice(topic string) string {
+	parts := strings.Split(topic, "/")
+	if len(parts) < 3 {
+		return ""
+	}
+	return parts[2]
+}
+
+// --- Overland ---
+
+type overlandBatch struct {
+	Locations []struct {
+		Geometry struct {
+			Type        string    `json:"type"`
+			Coordinates []float64 `json:"coordinates"`
+		} `json:"geometry"`
+		Properties struct {
+			Timestamp          string   `json:"timestamp"`
+			DeviceID           string   `json:"device_id"`
+			HorizontalAccuracy *float64 `json:"horizontal_accuracy"`
+			Altitude           *float64 `json:"altitude"`
+			Speed              *float64 `json:"speed"`         // m/s, -1 when unknown
+			Course             *float64 `json:"course"`        // degrees, -1 when unknown
+			BatteryLevel       *float64 `json:"battery_level"` // 0-1
+		} `json:"properties"`
+	} `json:"locations"`
+}
+
+// handleOverland accepts Overland batch posts. Malformed points are skipped
+// so one bad fix doesn't make the app retry the whole batch forever.
+func (h *Handler) handleOverland(w http.ResponseWriter, r *http.Request) {
+	var batch overlandBatch
+	if err := decodeBody(w, r, &batch); err != nil {
+		h.fail(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	override := r.URL.Query().Get("name")
+	recorded, skipped := 0, 0
+	for _, loc := range batch.Locations {
+		if loc.Geometry.Type != "Point" || len(loc.Geometry.Coordinates) < 2 {
+			skipped++
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, loc.Properties.Timestamp)
+		if err != nil {
+			skipped++
+			continue
+		}
+		rep := report{
+			device:     firstNonEmpty(override, loc.Properties.DeviceID),
+			latitude:   loc.Geometry.Coordinates[1],
+			longitude:  loc.Geometry.Coordinates[0],
+			recordedAt: at,
+			telemetry: models.Telemetry{
+				Accuracy: loc.Properties.HorizontalAccuracy,
+				Altitude: loc.Properties.Altitude,
+				Speed:    loc.Properties.Speed,
+				Heading:  loc.Properties.Course,
+				Battery:  percent(loc.Properties.BatteryLevel, 100),
+				Source:   source("overland"),
+			},
+		}
+		if err := h.record(rep); err != nil {
+			if statusFor(err) == http.StatusInternalServerError {
+				h.fail(w, r, http.StatusInternalServerError, err)
+				return
+			}
+			skipped++
+			continue
+		}
+		recorded++
+	}
+
+	h.logf("overland: recorded %d, skipped %d", recorded, skipped)
+	writeJSON(w, map[string]string{"result": "ok"})
+}
+
+// --- GPSLogger / OsmAnd ---
+
+// handleQuery accepts query-string (or form) pings as sent by GPSLogger's
+// custom URL logging and the OsmAnd/Traccar protocol.
+// Recognized parameters: name|id|deviceid|device, lat|latitude, lon|lng|longitude,
+// timestamp|time (unix seconds, unix milliseconds, or RFC3339), and optionally
+// acc|accuracy, alt|altitude, spd|speed, dir|bearing|heading, and batt|battery.
+// OsmAnd reports speed in knots; GPSLogger in meters per second.
+func (h *Handler) handleQuery(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		h.fail(w, r, http.StatusBadRequest, err)
+		return
+	}
+	q := r.Form
+
+	lat, err := strconv.ParseFloat(firstNonEmpty(q.Get("lat"), q.Get("latitude")), 64)
+	if err != nil {
+		h.fail(w, r, http.StatusBadRequest, errors.New("missing or invalid lat"))
+		return
+	}
+	lng, err := strconv.ParseFloat(firstNonEmpty(q.Get("lon"), q.Get("lng"), q.Get("longitude")), 64)
+	if err != nil {
+		h.fail(w, r, http.StatusBadRequest, errors.New("missing or invalid lon"))
+		return
+	}
+
+	rep := report{
+		device:     firstNonEmpty(q.Get("name"), q.Get("id"), q.Get("deviceid"), q.Get("device")),
+		latitude:   lat,
+		longitude:  lng,
+		recordedAt: time.Now(),
+	}
+	endpoint := strings.TrimPrefix(r.URL.Path, "/")
+	speedScale := 1.0
+	if endpoint == "osmand" {
+		speedScale = 0.514444 // knots to m/s
+	}
+	rep.telemetry = models.Telemetry{
+		Accuracy: queryFloat(q.Get("acc"), q.Get("accuracy")),
+		Altitude: queryFloat(q.Get("alt"), q.Get("altitude")),
+		Speed:    scale(queryFloat(q.Get("spd"), q.Get("speed")), speedScale),
+		Heading:  queryFloat(q.Get("dir"), q.Get("bearing"), q.Get("heading")),
+		Battery:  percent(queryFloat(q.Get("batt"), q.Get("battery")), 1),
+		Source:   source(endpoint),
+	}
+	if ts := firstNonEmpty(q.Get("timestamp"), q.Get("time")); ts != "" {
+		rep.recordedAt, err = parseTimestamp(ts)
+		if err != nil {
+			h.fail(w, r, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	if err := h.record(rep); err != nil {
+		h.fail(w, r, statusFor(err), err)
+		return
+	}
+	h.logf("%s: recorded %s", endpoint, rep.device)
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = io.WriteString(w, "OK\n")
+}
+
+// parseTimestamp accepts unix seconds, unix milliseconds, or RFC3339.
+func parseTimestamp(s string) (time.Time, error) {
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		// Anything past year ~5000 in seconds is really milliseconds
+		if n > 1e11 {
+			return time.UnixMilli(int64(n)).UTC(), nil
+		}
+		sec := int64(n)
+		return time.Unix(sec, int64((n-float64(sec))*1e9)).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+	}
+	return t, nil
+}
+
+// --- Recording ---
+
+// errInvalid marks reports rejected by validation (client errors).
+var errInvalid = errors.New("invalid report")
+
+// record validates a report and writes it, creating the item if needed.
+func (h *Handler) record(rep report) error {
+	name := strings.TrimSpace(rep.device)
+	if alias, ok := h.aliases[name]; ok {
+		name = alias
+	}
+	if err := models.ValidateName(name); err != nil {
+		return fmt.Errorf("%w: device: %w", errInvalid, err)
+	}
+	if err := models.ValidateCoordinates(rep.latitude, rep.longitude); err != nil {
+		return fmt.Errorf("%w: %w", errInvalid, err)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	item, err := h.repo.GetItemByName(name)
+	if errors.Is(err, storage.ErrNotFound) {
+		item = models.NewItem(name)
+		if err := h.repo.CreateItem(item); err != nil {
+			return fmt.Errorf("failed to create item: %w", err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("failed to get item: %w", err)
+	}
+
+	pos := models.NewPositionWithRecordedAt(item.ID, rep.latitude, rep.longitude, rep.label, rep.recordedAt)
+	pos.Telemetry = rep.telemetry.Sanitize()
+	if err := h.repo.CreatePosition(pos); err != nil {
+		return fmt.Errorf("failed to create position: %w", err)
+	}
+	return nil
+}
+
+// --- Helpers ---
+
+func statusFor(err error) int {
+	if errors.Is(err, errInvalid) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, status int, err error) {
+	h.logf("%s %s: %v", r.Method, r.URL.Path, err)
+	http.Error(w, err.Error(), status)
+}
+
+func (h *Handler) logf(format string, args ...any) {
+	if h.Logger != nil {
+		h.Logger.Printf(format, args...)
+	}
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) error {
+	body := http.MaxBytesReader(w, r.Body, maxBodyBytes)
+	if err := json.NewDecoder(body).Decode(v); err != nil {
+		return fmt.Errorf("invalid JSON body: %w", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v) //nolint:errchkjson // response values are always serializable
+}
+
+// queryFloat parses the first non-empty value as a float, or returns nil.
+func queryFloat(values ...string) *float64 {
+	v, err := strconv.ParseFloat(firstNonEmpty(values...), 64)
+	if err != nil {
+		return nil
+	}
+	return &v
+}
+
+// scale multiplies a reading by factor, preserving nil.
+func scale(v *float64, factor float64) *float64 {
+	if v == nil {
+		return nil
+	}
+	scaled := *v * factor
+	return &scaled
+}
+
+// percent converts a battery reading to a whole percentage, where factor
+// converts the app's unit (a 0-1 fraction for Overland) to percent.
+func percent(v *float64, factor float64) *int {
+	if v == nil || *v < 0 {
+		return nil
+	}
+	p := int(math.Round(*v * factor))
+	return &p
+}
+
+func source(name string) *string {
+	return &name
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,288 @@
+// ABOUTME: Tests for the HTTP ingestion handler
+// ABOUTME: Exercises OwnTracks, Overland, and GPSLogger/OsmAnd payloads via httptest
+
+package ingest
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/position/internal/models"
+	"github.com/harper/position/internal/storage"
+)
+
+func testRepo(t *testing.T) *storage.SQLiteDB {
+	t.Helper()
+	db, err := storage.NewSQLiteDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create test db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func do(t *testing.T, h http.Handler, method, target, body string, headers map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func timeline(t *testing.T, repo storage.Repository, name string) []*models.Position {
+	t.Helper()
+	item, err := repo.GetItemByName(name)
+	if err != nil {
+		t.Fatalf("expected item %q: %v", name, err)
+	}
+	positions, err := repo.GetTimeline(item.ID)
+	if err != nil {
+		t.Fatalf("GetTimeline failed: %v", err)
+	}
+	return positions
+}
+
+func TestOwnTracks_Location(t *testing.T) {
+	repo := testRepo(t)
+	h := NewHandler(repo, map[string]string{"ph": "harper"})
+
+	body := `{"_type":"location","lat":41.8781,"lon":-87.6298,"tst":1734188400,"tid":"ph","inregions":["home"]}`
+	rec := do(t, h, http.MethodPost, "/owntracks", body, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if strings.TrimSpace(rec.Body.String()) != "[]" {
+		t.Errorf("expected empty JSON array response, got %q", rec.Body.String())
+	}
+
+	positions := timeline(t, repo, "harper")
+	if len(positions) != 1 {
+		t.Fatalf("expected 1 position, got %d", len(positions))
+	}
+	if !positions[0].RecordedAt.Equal(time.Unix(1734188400, 0)) {
+		t.Errorf("unexpected recorded_at: %v", positions[0].RecordedAt)
+	}
+	if positions[0].Label == nil || *positions[0].Label != "home" {
+		t.Errorf("expected region label, got %v", positions[0].Label)
+	}
+}
+
+func TestOwnTracks_DeviceFromHeader(t *testing.T) {
+	repo := testRepo(t)
+	h := NewHandler(repo, nil)
+
+	body := `{"_type":"location","lat":41.0,"lon":-87.0,"tst":1734188400,"tid":"ph"}`
+	rec := do(t, h, http.MethodPost, "/owntracks", body, map[string]string{"X-Limit-D": "iphone"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(timeline(t, repo, "iphone")) != 1 {
+		t.Error("expected position recorded under header device")
+	}
+}
+
+func TestOwnTracks_IgnoresNonLocation(t *testing.T) {
+	repo := testRepo(t)
+	h := NewHandler(repo, nil)
+
+	rec := do(t, h, http.MethodPost, "/owntracks", `{"_type":"lwt","tst":1734188400}`, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	items, _ := repo.ListItems()
+	if len(items) != 0 {
+		t.Errorf("expected no items, got %d", len(items))
+	}
+}
+
+func TestOwnTracks_BadRequests(t *testing.T) {
+	h := NewHandler(testRepo(t), nil)
+
+	tests := map[string]string{
+		"invalid json":   `{`,
+		"missing coords": `{"_type":"location","tid":"ph"}`,
+		"missing device": `{"_type":"location","lat":41.0,"lon":-87.0}`,
+		"out of range":   `{"_type":"location","lat":91.0,"lon":-87.0,"tid":"ph"}`,
+	}
+	for name, body := range tests {
+		rec := do(t, h, http.MethodPost, "/owntracks", body, nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", name, rec.Code)
+		}
+	}
+}
+
+func TestOverland_Batch(t *testing.T) {
+	repo := testRepo(t)
+	h := NewHandler(repo, nil)
+
+	body := `{"locations":[
+		{"type":"Feature","geometry":{"type":"Point","coordinates":[-87.6298,41.8781]},
+		 "properties":{"timestamp":"2024-12-14T15:00:00Z","device_id":"pixel"}},
+		{"type":"Feature","geometry":{"type":"Point","coordinates":[-87.7,41.9]},
+		 "properties":{"timestamp":"2024-12-14T15:05:00Z","device_id":"pixel"}},
+		{"type":"Feature","geometry":{"type":"Point","coordinates":[-87.7]},
+		 "properties":{"timestamp":"2024-12-14T15:10:00Z","device_id":"pixel"}}
+	]}`
+	rec := do(t, h, http.MethodPost, "/overland", body, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), `"result":"ok"`) {
+		t.Errorf("expected result ok, got %q", rec.Body.String())
+	}
+
+	positions := timeline(t, repo, "pixel")
+	if len(positions) != 2 {
+		t.Fatalf("expected 2 positions (malformed point skipped), got %d", len(positions))
+	}
+	if positions[0].Latitude != 41.9 || positions[0].Longitude != -87.7 {
+		t.Errorf("expected GeoJSON [lng, lat] order, got %+v", positions[0])
+	}
+}
+
+func TestOverland_NameOverride(t *testing.T) {
+	repo := testRepo(t)
+	h := NewHandler(repo, nil)
+
+	body := `{"locations":[{"type":"Feature","geometry":{"type":"Point","coordinates":[-87.0,41.0]},
+		"properties":{"timestamp":"2024-12-14T15:00:00Z"}}]}`
+	rec := do(t, h, http.MethodPost, "/overland?name=harper", body, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if len(timeline(t, repo, "harper")) != 1 {
+		t.Error("expected position recorded under ?name")
+	}
+}
+
+func TestQuery_GPSLogger(t *testing.T) {
+	repo := testRepo(t)
+	h := NewHandler(repo, nil)
+
+	rec := do(t, h, http.MethodGet, "/gpslogger?device=volvo&lat=41.8781&lon=-87.6298&time=2024-12-14T15:00:00Z", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	positions := timeline(t, repo, "volvo")
+	if len(positions) != 1 {
+		t.Fatalf("expected 1 position, got %d", len(positions))
+	}
+	if !positions[0].RecordedAt.Equal(time.Date(2024, 12, 14, 15, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected recorded_at: %v", positions[0].RecordedAt)
+	}
+}
+
+func TestQuery_OsmAndPostForm(t *testing.T) {
+	repo := testRepo(t)
+	h := NewHandler(repo, nil)
+
+	rec := do(t, h, http.MethodPost, "/osmand", "id=bike&lat=41.0&lon=-87.0&timestamp=1734188400000",
+		map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	positions := timeline(t, repo, "bike")
+	if len(positions) != 1 || !positions[0].RecordedAt.Equal(time.Unix(1734188400, 0)) {
+		t.Errorf("expected 1 position at millisecond timestamp, got %+v", positions)
+	}
+}
+
+func TestQuery_BadRequests(t *testing.T) {
+	h := NewHandler(testRepo(t), nil)
+
+	tests := []string{
+		"/gpslogger?device=volvo&lon=-87.0",
+		"/gpslogger?device=volvo&lat=41.0",
+		"/gpslogger?lat=41.0&lon=-87.0",
+		"/gpslogger?device=volvo&lat=41.0&lon=-87.0&time=yesterday",
+	}
+	for _, target := range tests {
+		rec := do(t, h, http.MethodGet, target, "", nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", target, rec.Code)
+		}
+	}
+}
+
+func TestTelemetry_PerApp(t *testing.T) {
+	repo := testRepo(t)
+	h := NewHandler(repo, nil)
+
+	do(t, h, http.MethodPost, "/owntracks",
+		`{"_type":"location","lat":41.0,"lon":-87.0,"tst":1734188400,"tid":"ot","acc":12,"alt":180,"vel":36,"cog":90,"batt":77}`, nil)
+	do(t, h, http.MethodPost, "/overland", `{"locations":[{"type":"Feature","geometry":{"type":"Point","coordinates":[-87.0,41.0]},
+		"properties":{"timestamp":"2024-12-14T15:00:00Z","device_id":"ov","horizontal_accuracy":5,"speed":-1,"course":-1,"battery_level":0.456}}]}`, nil)
+	do(t, h, http.MethodGet, "/gpslogger?device=gl&lat=41.0&lon=-87.0&acc=3.5&spd=10&dir=360&batt=50", "", nil)
+	do(t, h, http.MethodGet, "/osmand?id=oa&lat=41.0&lon=-87.0&speed=10&batt=150", "", nil)
+
+	tests := []struct {
+		name        string
+		wantAcc     *float64
+		wantSpeed   *float64
+		wantHeading *float64
+		wantBattery *int
+		wantSource  string
+	}{
+		{"ot", ptr(12.0), ptr(10.0), ptr(90.0), ptr(77), "owntracks"},
+		{"ov", ptr(5.0), nil, nil, ptr(46), "overland"},
+		{"gl", ptr(3.5), ptr(10.0), ptr(0.0), ptr(50), "gpslogger"},
+		{"oa", nil, ptr(5.14444), nil, nil, "osmand"},
+	}
+	for _, tt := range tests {
+		tm := timeline(t, repo, tt.name)[0].Telemetry
+		if !floatEqual(tm.Accuracy, tt.wantAcc) || !floatEqual(tm.Speed, tt.wantSpeed) || !floatEqual(tm.Heading, tt.wantHeading) {
+			t.Errorf("%s: unexpected accuracy/speed/heading: %+v", tt.name, tm)
+		}
+		if (tm.Battery == nil) != (tt.wantBattery == nil) || (tm.Battery != nil && *tm.Battery != *tt.wantBattery) {
+			t.Errorf("%s: battery = %v, want %v", tt.name, tm.Battery, tt.wantBattery)
+		}
+		if tm.Source == nil || *tm.Source != tt.wantSource {
+			t.Errorf("%s: source = %v, want %s", tt.name, tm.Source, tt.wantSource)
+		}
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func floatEqual(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return math.Abs(*a-*b) < 1e-6
+}
+
+func TestHandler_MethodAndRoute(t *testing.T) {
+	h := NewHandler(testRepo(t), nil)
+
+	if rec := do(t, h, http.MethodGet, "/owntracks", "", nil); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for GET /owntracks, got %d", rec.Code)
+	}
+	if rec := do(t, h, http.MethodGet, "/unknown", "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown route, got %d", rec.Code)
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	want := time.Date(2024, 12, 14, 15, 0, 0, 0, time.UTC)
+	for _, s := range []string{"1734188400", "1734188400000", "2024-12-14T15:00:00Z", "2024-12-14T09:00:00-06:00"} {
+		got, err := parseTimestamp(s)
+		if err != nil {
+			t.Errorf("parseTimestamp(%q) failed: %v", s, err)
+			continue
+		}
+		if !got.Equal(want) {
+			t.Errorf("parseTimestamp(%q) = %v, want %v", s, got, want)
+		}
+	}
+}
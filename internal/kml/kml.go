@@ -0,0 +1,211 @@
+// ABOUTME: KML and KMZ generation utilities for Google Earth
+// ABOUTME: Emits a styled Folder per item with timestamped Placemarks and a gx:Track path
+
+package kml
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"time"
+
+	"github.com/harper/position/internal/models"
+	"github.com/harper/position/internal/track"
+)
+
+const (
+	// Namespace is the OGC KML 2.2 namespace.
+	Namespace = "http://www.opengis.net/kml/2.2"
+	// GxNamespace is the Google extension namespace used for gx:Track.
+	GxNamespace = "http://www.google.com/kml/ext/2.2"
+)
+
+// palette holds per-item colors in KML aabbggrr order.
+var palette = []string{
+	"ff0000ff", // red
+	"ffff0000", // blue
+	"ff00aa00", // green
+	"ff00a5ff", // orange
+	"ffff00ff", // magenta
+	"ffffff00", // cyan
+	"ff800080", // purple
+	"ff00ffff", // yellow
+}
+
+// KML represents the root element of a KML document.
+type KML struct {
+	XMLName  xml.Name `xml:"kml"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	XmlnsGx  string   `xml:"xmlns:gx,attr"`
+	Document Document `xml:"Document"`
+}
+
+// Document holds shared styles and one folder per item.
+type Document struct {
+	Name    string   `xml:"name"`
+	Styles  []Style  `xml:"Style"`
+	Folders []Folder `xml:"Folder"`
+}
+
+// Style defines the icon and line color for one item.
+type Style struct {
+	ID        string    `xml:"id,attr"`
+	IconStyle IconStyle `xml:"IconStyle"`
+	LineStyle LineStyle `xml:"LineStyle"`
+}
+
+// IconStyle colors point placemarks.
+type IconStyle struct {
+	Color string `xml:"color"`
+}
+
+// LineStyle colors and sizes track placemarks.
+type LineStyle struct {
+	Color string `xml:"color"`
+	Width int    `xml:"width"`
+}
+
+// Folder groups all placemarks for a single item.
+type Folder struct {
+	Name       string      `xml:"name"`
+	TimeSpan   *TimeSpan   `xml:"TimeSpan,omitempty"`
+	Placemarks []Placemark `xml:"Placemark"`
+}
+
+// TimeSpan bounds a feature in time for the Google Earth time slider.
+type TimeSpan struct {
+	Begin string `xml:"begin"`
+	End   string `xml:"end"`
+}
+
+// TimeStamp marks a feature at a single instant.
+type TimeStamp struct {
+	When string `xml:"when"`
+}
+
+// Placemark is either a timestamped Point or a gx:Track path.
+type Placemark struct {
+	Name        string     `xml:"name,omitempty"`
+	Description string     `xml:"description,omitempty"`
+	TimeStamp   *TimeStamp `xml:"TimeStamp,omitempty"`
+	StyleURL    string     `xml:"styleUrl,omitempty"`
+	Point       *Point     `xml:"Point,omitempty"`
+	Track       *Track     `xml:"gx:Track,omitempty"`
+}
+
+// Point holds a single "lng,lat" coordinate.
+type Point struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+// Track is a gx:Track with parallel when/coord lists.
+type Track struct {
+	When   []string `xml:"when"`
+	Coords []string `xml:"gx:coord"`
+}
+
+// ItemNameResolver is a function that resolves an item ID to its name.
+type ItemNameResolver func(itemID string) string
+
+// FromPositions converts positions to a KML document.
+// Positions are grouped by item into folders sorted by name. Each folder has a
+// Placemark per position and, when the item has at least 2 positions, a gx:Track
+// so Google Earth's time slider animates movement.
+func FromPositions(positions []*models.Position, nameResolver ItemNameResolver) *KML {
+	doc := &KML{
+		Xmlns:   Namespace,
+		XmlnsGx: GxNamespace,
+		Document: Document{
+			Name: "position export",
+		},
+	}
+
+	for i, item := range track.GroupByItem(positions, nameResolver) {
+		itemPositions := item.Positions
+
+		styleID := fmt.Sprintf("item-%d", i)
+		color := palette[i%len(palette)]
+		doc.Document.Styles = append(doc.Document.Styles, Style{
+			ID:        styleID,
+			IconStyle: IconStyle{Color: color},
+			LineStyle: LineStyle{Color: color, Width: 3},
+		})
+
+		name := item.Name
+		folder := Folder{
+			Name: name,
+			TimeSpan: &TimeSpan{
+				Begin: formatTime(itemPositions[0].RecordedAt),
+				End:   formatTime(itemPositions[len(itemPositions)-1].RecordedAt),
+			},
+		}
+
+		path := &Track{}
+		for _, pos := range itemPositions {
+			when := formatTime(pos.RecordedAt)
+			placemark := Placemark{
+				Name:      when,
+				TimeStamp: &TimeStamp{When: when},
+				StyleURL:  "#" + styleID,
+				Point:     &Point{Coordinates: fmt.Sprintf("%f,%f", pos.Longitude, pos.Latitude)},
+			}
+			if pos.Label != nil && *pos.Label != "" {
+				placemark.Name = *pos.Label
+				placemark.Description = when
+			}
+			folder.Placemarks = append(folder.Placemarks, placemark)
+
+			path.When = append(path.When, when)
+			path.Coords = append(path.Coords, fmt.Sprintf("%f %f 0", pos.Longitude, pos.Latitude))
+		}
+
+		if len(itemPositions) >= 2 {
+			folder.Placemarks = append(folder.Placemarks, Placemark{
+				Name:     name + " track",
+				StyleURL: "#" + styleID,
+				Track:    path,
+			})
+		}
+
+		doc.Document.Folders = append(doc.Document.Folders, folder)
+	}
+
+	return doc
+}
+
+// formatTime formats a time as the xsd:dateTime KML expects.
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// ToXML serializes a KML document to indented XML with a declaration header.
+func (k *KML) ToXML() ([]byte, error) {
+	body, err := xml.MarshalIndent(k, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(body, '\n')...), nil
+}
+
+// ToKMZ serializes a KML document into a KMZ archive containing doc.kml.
+func (k *KML) ToKMZ() ([]byte, error) {
+	data, err := k.ToXML()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("doc.kml")
+	if err != nil {
+		return nil, fmt.Errorf("create doc.kml: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, fmt.Errorf("write doc.kml: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("close kmz: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,144 @@
+// ABOUTME: Unit tests for KML and KMZ generation
+// ABOUTME: Tests folder/placemark structure, gx:Track output, and KMZ packaging
+
+package kml
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/harper/position/internal/models"
+)
+
+func testPositions() ([]*models.Position, func(string) string) {
+	label := "chicago"
+	harperID := uuid.New()
+	carID := uuid.New()
+	base := time.Date(2024, 12, 14, 8, 0, 0, 0, time.UTC)
+	positions := []*models.Position{
+		{ID: uuid.New(), ItemID: harperID, Latitude: 42.0, Longitude: -88.0, RecordedAt: base.Add(time.Hour)},
+		{ID: uuid.New(), ItemID: harperID, Latitude: 41.8781, Longitude: -87.6298, Label: &label, RecordedAt: base},
+		{ID: uuid.New(), ItemID: carID, Latitude: 40.0, Longitude: -86.0, RecordedAt: base},
+	}
+	names := map[string]string{harperID.String(): "harper", carID.String(): "car"}
+	return positions, func(id string) string { return names[id] }
+}
+
+func TestFromPositions(t *testing.T) {
+	positions, resolver := testPositions()
+
+	doc := FromPositions(positions, resolver)
+
+	if len(doc.Document.Folders) != 2 {
+		t.Fatalf("expected 2 folders, got %d", len(doc.Document.Folders))
+	}
+	if len(doc.Document.Styles) != 2 {
+		t.Fatalf("expected 2 styles, got %d", len(doc.Document.Styles))
+	}
+	if doc.Document.Styles[0].IconStyle.Color == doc.Document.Styles[1].IconStyle.Color {
+		t.Error("expected distinct colors per item")
+	}
+
+	// Folders sorted by item name
+	car := doc.Document.Folders[0]
+	harper := doc.Document.Folders[1]
+	if car.Name != "car" || harper.Name != "harper" {
+		t.Fatalf("unexpected folder order: %q, %q", car.Name, harper.Name)
+	}
+
+	// Single position: one placemark, no track
+	if len(car.Placemarks) != 1 {
+		t.Errorf("expected 1 placemark for car, got %d", len(car.Placemarks))
+	}
+
+	// Two positions plus a track placemark
+	if len(harper.Placemarks) != 3 {
+		t.Fatalf("expected 3 placemarks for harper, got %d", len(harper.Placemarks))
+	}
+	if harper.TimeSpan == nil || harper.TimeSpan.Begin != "2024-12-14T08:00:00Z" || harper.TimeSpan.End != "2024-12-14T09:00:00Z" {
+		t.Errorf("unexpected time span: %+v", harper.TimeSpan)
+	}
+
+	first := harper.Placemarks[0]
+	if first.Name != "chicago" {
+		t.Errorf("expected labeled placemark first, got %q", first.Name)
+	}
+	if first.TimeStamp == nil || first.TimeStamp.When != "2024-12-14T08:00:00Z" {
+		t.Errorf("unexpected timestamp: %+v", first.TimeStamp)
+	}
+	if first.StyleURL != "#item-1" {
+		t.Errorf("expected style #item-1, got %q", first.StyleURL)
+	}
+
+	track := harper.Placemarks[2].Track
+	if track == nil {
+		t.Fatal("expected gx:Track placemark")
+	}
+	if len(track.When) != 2 || len(track.Coords) != 2 {
+		t.Errorf("expected 2 when/coord pairs, got %d/%d", len(track.When), len(track.Coords))
+	}
+	if track.Coords[0] != "-87.629800 41.878100 0" {
+		t.Errorf("unexpected first coord: %q", track.Coords[0])
+	}
+}
+
+func TestToXML(t *testing.T) {
+	positions, resolver := testPositions()
+
+	data, err := FromPositions(positions, resolver).ToXML()
+	if err != nil {
+		t.Fatalf("ToXML failed: %v", err)
+	}
+
+	xmlStr := string(data)
+	for _, want := range []string{
+		`<kml xmlns="http://www.opengis.net/kml/2.2" xmlns:gx="http://www.google.com/kml/ext/2.2">`,
+		"<gx:Track>",
+		"<gx:coord>",
+		"<TimeStamp>",
+		"<TimeSpan>",
+	} {
+		if !strings.Contains(xmlStr, want) {
+			t.Errorf("expected %q in output", want)
+		}
+	}
+}
+
+func TestToKMZ(t *testing.T) {
+	positions, resolver := testPositions()
+
+	data, err := FromPositions(positions, resolver).ToKMZ()
+	if err != nil {
+		t.Fatalf("ToKMZ failed: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("invalid zip: %v", err)
+	}
+	if len(zr.File) != 1 || zr.File[0].Name != "doc.kml" {
+		t.Fatalf("expected single doc.kml entry, got %v", zr.File)
+	}
+
+	rc, err := zr.File[0].Open()
+	if err != nil {
+		t.Fatalf("open doc.kml: %v", err)
+	}
+	defer rc.Close()
+	content, _ := io.ReadAll(rc)
+	if !strings.Contains(string(content), "<Folder>") {
+		t.Error("expected KML content inside KMZ")
+	}
+}
+
+func TestFromPositions_Empty(t *testing.T) {
+	doc := FromPositions(nil, nil)
+	if len(doc.Document.Folders) != 0 {
+		t.Errorf("expected no folders, got %d", len(doc.Document.Folders))
+	}
+}
@@ -0,0 +1,525 @@
+// ABOUTME: MCP spatial and analytical tools
+// ABOUTME: Distance between items or points, time-range queries, daily summaries, and GeoJSON export
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/harper/position/internal/geo"
+	"github.com/harper/position/internal/geojson"
+	"github.com/harper/position/internal/models"
+	"github.com/harper/position/internal/storage"
+	"github.com/harper/position/internal/track"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DistanceBetweenInput defines input for distance_between tool.
+type DistanceBetweenInput struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Clean  bool   `json:"clean,omitempty"`
+	Smooth bool   `json:"smooth,omitempty"`
+}
+
+// PointOutput is one end of a distance measurement: an item's current
+// position or a literal coordinate.
+type PointOutput struct {
+	Name       string     `json:"name,omitempty"`
+	Latitude   float64    `json:"latitude"`
+	Longitude  float64    `json:"longitude"`
+	RecordedAt *time.Time `json:"recorded_at,omitempty"`
+}
+
+// DistanceBetweenOutput defines output for distance_between tool.
+type DistanceBetweenOutput struct {
+	From           PointOutput `json:"from"`
+	To             PointOutput `json:"to"`
+	DistanceMeters float64     `json:"distance_meters"`
+	BearingDegrees float64     `json:"bearing_degrees"`
+	Compass        string      `json:"compass"`
+}
+
+func (s *Server) registerDistanceBetweenTool() {
+	pointProperty := func(end string) map[string]interface{} {
+		return map[string]interface{}{
+			"type":        "string",
+			"description": fmt.Sprintf("Item name (its current position is used) or \"lat,lng\" coordinates for the %s point", end),
+		}
+	}
+	addTool(s, &mcp.Tool{
+		Name: "distance_between",
+		Description: "Measure the distance (WGS84 ellipsoid) and initial bearing from one item or coordinate to another. " +
+			"Use this instead of fetching positions and computing geography yourself.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"from":   pointProperty("start"),
+				"to":     pointProperty("end"),
+				"clean":  cleanProperty,
+				"smooth": smoothProperty,
+			},
+			"required": []string{"from", "to"},
+		},
+		Annotations: queryAnnotations(),
+	}, s.handleDistanceBetween)
+}
+
+func (s *Server) handleDistanceBetween(_ context.Context, req *mcp.CallToolRequest, input DistanceBetweenInput) (*mcp.CallToolResult, DistanceBetweenOutput, error) {
+	from, err := s.resolvePoint(input.From, input.Clean, input.Smooth)
+	if err != nil {
+		return nil, DistanceBetweenOutput{}, err
+	}
+	to, err := s.resolvePoint(input.To, input.Clean, input.Smooth)
+	if err != nil {
+		return nil, DistanceBetweenOutput{}, err
+	}
+
+	output := DistanceBetweenOutput{
+		From:           from,
+		To:             to,
+		DistanceMeters: geo.Vincenty(from.Latitude, from.Longitude, to.Latitude, to.Longitude),
+	}
+	if output.DistanceMeters > 0 {
+		output.BearingDegrees = geo.InitialBearing(from.Latitude, from.Longitude, to.Latitude, to.Longitude)
+		output.Compass = geo.CompassPoint(output.BearingDegrees)
+	}
+
+	jsonBytes, _ := json.MarshalIndent(output, "", "  ") //nolint:errchkjson // output is always serializable
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(jsonBytes)}},
+	}, output, nil
+}
+
+// resolvePoint returns a "lat,lng" pair as a point, or otherwise the current
+// position of the item with that name, taken from its smoothed track with
+// smooth.
+func (s *Server) resolvePoint(arg string, clean, smooth bool) (PointOutput, error) {
+	if latStr, lngStr, ok := strings.Cut(arg, ","); ok {
+		lat, latErr := strconv.ParseFloat(strings.TrimSpace(latStr), 64)
+		lng, lngErr := strconv.ParseFloat(strings.TrimSpace(lngStr), 64)
+		if latErr == nil && lngErr == nil {
+			if err := models.ValidateCoordinates(lat, lng); err != nil {
+				return PointOutput{}, err
+			}
+			return PointOutput{Latitude: lat, Longitude: lng}, nil
+		}
+	}
+
+	if err := models.ValidateName(arg); err != nil {
+		return PointOutput{}, err
+	}
+	item, err := s.repo.GetItemByName(arg)
+	if err != nil {
+		return PointOutput{}, fmt.Errorf("item '%s' not found", arg)
+	}
+	currentPosition := s.currentPosition
+	if smooth {
+		currentPosition = s.smoothedCurrentPosition
+	}
+	pos, err := currentPosition(item.ID, clean)
+	if err != nil {
+		return PointOutput{}, fmt.Errorf("no position found for '%s'", arg)
+	}
+	return PointOutput{Name: item.Name, Latitude: pos.Latitude, Longitude: pos.Longitude, RecordedAt: &pos.RecordedAt}, nil
+}
+
+// smoothedCurrentPosition returns the latest point of the item's smoothed
+// track, or with clean of its track without positions flagged suspect.
+func (s *Server) smoothedCurrentPosition(itemID uuid.UUID, clean bool) (*models.Position, error) {
+	timeline, err := s.repo.GetTimeline(itemID)
+	if err != nil {
+		return nil, err
+	}
+	if clean {
+		timeline = storage.Clean(timeline)
+	}
+	if len(timeline) == 0 {
+		return nil, storage.ErrNotFound
+	}
+	smoothed := track.Smooth(timeline, track.DefaultSmoothOptions())
+	return smoothed[len(smoothed)-1], nil
+}
+
+// GetPositionsInRangeInput defines input for get_positions_in_range tool.
+type GetPositionsInRangeInput struct {
+	Name  string  `json:"name"`
+	From  string  `json:"from"`
+	To    *string `json:"to,omitempty"`
+	Clean bool    `json:"clean,omitempty"`
+}
+
+func (s *Server) registerGetPositionsInRangeTool() {
+	addTool(s, &mcp.Tool{
+		Name: "get_positions_in_range",
+		Description: "Get an item's positions recorded between two times, newest first. " +
+			"Prefer this over get_timeline, which returns the entire history.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"name": map[string]interface{}{
+					"type":        "string",
+					"description": "Name of the item",
+				},
+				"from": map[string]interface{}{
+					"type":        "string",
+					"description": "Start of the range in RFC3339 format",
+				},
+				"to": map[string]interface{}{
+					"type":        "string",
+					"description": "End of the range in RFC3339 format (default now)",
+				},
+				"clean": cleanProperty,
+			},
+			"required": []string{"name", "from"},
+		},
+		Annotations: queryAnnotations(),
+	}, s.handleGetPositionsInRange)
+}
+
+func (s *Server) handleGetPositionsInRange(_ context.Context, req *mcp.CallToolRequest, input GetPositionsInRangeInput) (*mcp.CallToolResult, TimelineOutput, error) {
+	if err := models.ValidateName(input.Name); err != nil {
+		return nil, TimelineOutput{}, err
+	}
+	from, to, err := parseTimeWindow(&input.From, input.To)
+	if err != nil {
+		return nil, TimelineOutput{}, err
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if to.Before(from) {
+		return nil, TimelineOutput{}, fmt.Errorf("to must not be before from")
+	}
+
+	item, err := s.repo.GetItemByName(input.Name)
+	if err != nil {
+		return nil, TimelineOutput{}, fmt.Errorf("item '%s' not found", input.Name)
+	}
+
+	positions, err := s.repo.GetPositionsInRange(item.ID, from, to)
+	if err != nil {
+		return nil, TimelineOutput{}, fmt.Errorf("failed to get positions: %w", err)
+	}
+	if input.Clean {
+		positions = storage.Clean(positions)
+	}
+	sortNewestFirst(positions)
+
+	posOutputs := make([]PositionOutput, len(positions))
+	for i, pos := range positions {
+		posOutputs[i] = newPositionOutput(input.Name, pos)
+	}
+
+	output := TimelineOutput{
+		ItemName:  input.Name,
+		Positions: posOutputs,
+		Count:     len(posOutputs),
+	}
+
+	jsonBytes, _ := json.MarshalIndent(output, "", "  ") //nolint:errchkjson // output is always serializable
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(jsonBytes)}},
+	}, output, nil
+}
+
+// SummarizeDayInput defines input for summarize_day tool.
+type SummarizeDayInput struct {
+	Name     string  `json:"name"`
+	Date     *string `json:"date,omitempty"`
+	Timezone *string `json:"timezone,omitempty"`
+	Clean    bool    `json:"clean,omitempty"`
+	Smooth   bool    `json:"smooth,omitempty"`
+}
+
+// TripOutput defines output for a single trip between stays.
+type TripOutput struct {
+	From            string    `json:"from,omitempty"`
+	To              string    `json:"to,omitempty"`
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end"`
+	DurationMinutes float64   `json:"duration_minutes"`
+	DistanceMeters  float64   `json:"distance_meters"`
+	AvgSpeedKmh     float64   `json:"avg_speed_kmh"`
+	MaxSpeedKmh     float64   `json:"max_speed_kmh"`
+}
+
+// SummarizeDayOutput defines output for summarize_day tool.
+type SummarizeDayOutput struct {
+	ItemName       string          `json:"item_name"`
+	Date           string          `json:"date"`
+	Timezone       string          `json:"timezone"`
+	PositionCount  int             `json:"position_count"`
+	First          *PositionOutput `json:"first,omitempty"`
+	Last           *PositionOutput `json:"last,omitempty"`
+	DistanceMeters float64         `json:"distance_meters"`
+	Visits         []VisitOutput   `json:"visits"`
+	Trips          []TripOutput    `json:"trips"`
+}
+
+func (s *Server) registerSummarizeDayTool() {
+	addTool(s, &mcp.Tool{
+		Name: "summarize_day",
+		Description: "Summarize one calendar day for an item: first and last positions, distance traveled, " +
+			"the places it stayed (visits), and the trips between them. " +
+			"Visits and trips are cut off at midnight.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"name": map[string]interface{}{
+					"type":        "string",
+					"description": "Name of the item",
+				},
+				"date": map[string]interface{}{
+					"type":        "string",
+					"description": "Day to summarize as YYYY-MM-DD (default today)",
+				},
+				"timezone": map[string]interface{}{
+					"type":        "string",
+					"description": "IANA time zone for the day's boundaries, e.g. 'America/Chicago' (default the server's local zone)",
+				},
+				"clean":  cleanProperty,
+				"smooth": smoothProperty,
+			},
+			"required": []string{"name"},
+		},
+		Annotations: queryAnnotations(),
+	}, s.handleSummarizeDay)
+}
+
+func (s *Server) handleSummarizeDay(_ context.Context, req *mcp.CallToolRequest, input SummarizeDayInput) (*mcp.CallToolResult, SummarizeDayOutput, error) {
+	if err := models.ValidateName(input.Name); err != nil {
+		return nil, SummarizeDayOutput{}, err
+	}
+
+	loc := time.Local
+	if input.Timezone != nil {
+		var err error
+		if loc, err = time.LoadLocation(*input.Timezone); err != nil {
+			return nil, SummarizeDayOutput{}, fmt.Errorf("invalid timezone: %w", err)
+		}
+	}
+	start := time.Now().In(loc)
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	if input.Date != nil {
+		var err error
+		if start, err = time.ParseInLocation("2006-01-02", *input.Date, loc); err != nil {
+			return nil, SummarizeDayOutput{}, fmt.Errorf("invalid date (use YYYY-MM-DD): %w", err)
+		}
+	}
+	end := start.AddDate(0, 0, 1).Add(-time.Nanosecond)
+
+	item, err := s.repo.GetItemByName(input.Name)
+	if err != nil {
+		return nil, SummarizeDayOutput{}, fmt.Errorf("item '%s' not found", input.Name)
+	}
+	positions, err := s.repo.GetPositionsInRange(item.ID, start, end)
+	if err != nil {
+		return nil, SummarizeDayOutput{}, fmt.Errorf("failed to get positions: %w", err)
+	}
+	if input.Clean {
+		positions = storage.Clean(positions)
+	}
+	if input.Smooth {
+		positions = track.Smooth(positions, track.DefaultSmoothOptions())
+	}
+	sortNewestFirst(positions)
+
+	// Oldest first for distance along the day's path
+	chronological := make([]*models.Position, len(positions))
+	for i, pos := range positions {
+		chronological[len(positions)-1-i] = pos
+	}
+
+	// A stay still going at the day's last position lasts until midnight,
+	// or until now for today
+	opts := track.DefaultVisitOptions()
+	opts.Until = end
+	if now := time.Now(); opts.Until.After(now) {
+		opts.Until = now
+	}
+	visits := track.Visits(positions, opts)
+	trips := track.Trips(positions, opts)
+
+	output := SummarizeDayOutput{
+		ItemName:       input.Name,
+		Date:           start.Format("2006-01-02"),
+		Timezone:       loc.String(),
+		PositionCount:  len(positions),
+		DistanceMeters: geo.PathLength(chronological),
+		Visits:         make([]VisitOutput, len(visits)),
+		Trips:          make([]TripOutput, len(trips)),
+	}
+	if len(chronological) > 0 {
+		first := newPositionOutput(input.Name, chronological[0])
+		last := newPositionOutput(input.Name, chronological[len(chronological)-1])
+		output.First, output.Last = &first, &last
+	}
+	for i, v := range visits {
+		output.Visits[i] = newVisitOutput(v)
+	}
+	for i, trip := range trips {
+		output.Trips[i] = newTripOutput(trip)
+	}
+
+	jsonBytes, _ := json.MarshalIndent(output, "", "  ") //nolint:errchkjson // output is always serializable
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(jsonBytes)}},
+	}, output, nil
+}
+
+// newTripOutput converts a trip for output.
+func newTripOutput(trip track.Trip) TripOutput {
+	out := TripOutput{
+		Start:           trip.Start(),
+		End:             trip.End(),
+		DurationMinutes: trip.Duration().Minutes(),
+		DistanceMeters:  trip.Distance,
+		AvgSpeedKmh:     trip.AverageSpeed() * 3.6,
+		MaxSpeedKmh:     trip.MaxSpeed * 3.6,
+	}
+	if trip.From != nil {
+		out.From = trip.From.Label
+	}
+	if trip.To != nil {
+		out.To = trip.To.Label
+	}
+	return out
+}
+
+// ExportGeoJSONInput defines input for export_geojson tool.
+type ExportGeoJSONInput struct {
+	Name           *string  `json:"name,omitempty"`
+	From           *string  `json:"from,omitempty"`
+	To             *string  `json:"to,omitempty"`
+	Geometry       string   `json:"geometry,omitempty"`
+	SimplifyMeters *float64 `json:"simplify_meters,omitempty"`
+	Clean          bool     `json:"clean,omitempty"`
+}
+
+func (s *Server) registerExportGeoJSONTool() {
+	addTool(s, &mcp.Tool{
+		Name: "export_geojson",
+		Description: "Export positions as a GeoJSON FeatureCollection: a Point per position with its time and label, " +
+			"or with geometry 'line' one LineString per item. Omit name to export every item.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"name": map[string]interface{}{
+					"type":        "string",
+					"description": "Name of the item (default all items)",
+				},
+				"from": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional start of the time window in RFC3339 format",
+				},
+				"to": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional end of the time window in RFC3339 format",
+				},
+				"geometry": map[string]interface{}{
+					"type":        "string",
+					"description": "'points' (default) or 'line'",
+					"enum":        []string{"points", "line"},
+				},
+				"simplify_meters": map[string]interface{}{
+					"type":             "number",
+					"description":      "For lines, simplify each line to within this many meters",
+					"exclusiveMinimum": 0,
+				},
+				"clean": cleanProperty,
+			},
+		},
+		Annotations: queryAnnotations(),
+	}, s.handleExportGeoJSON)
+}
+
+func (s *Server) handleExportGeoJSON(_ context.Context, req *mcp.CallToolRequest, input ExportGeoJSONInput) (*mcp.CallToolResult, geojson.FeatureCollection, error) {
+	geometry := input.Geometry
+	if geometry == "" {
+		geometry = "points"
+	}
+	if geometry != "points" && geometry != "line" {
+		return nil, geojson.FeatureCollection{}, fmt.Errorf("unknown geometry: %s (use points or line)", geometry)
+	}
+	var reduce track.Reduction
+	if input.SimplifyMeters != nil {
+		if geometry != "line" {
+			return nil, geojson.FeatureCollection{}, fmt.Errorf("simplify_meters requires geometry 'line'")
+		}
+		if *input.SimplifyMeters <= 0 {
+			return nil, geojson.FeatureCollection{}, fmt.Errorf("simplify_meters must be greater than 0")
+		}
+		reduce.Tolerance = *input.SimplifyMeters
+	}
+
+	from, to, err := parseTimeWindow(input.From, input.To)
+	if err != nil {
+		return nil, geojson.FeatureCollection{}, err
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+
+	var positions []*models.Position
+	if input.Name != nil {
+		if err := models.ValidateName(*input.Name); err != nil {
+			return nil, geojson.FeatureCollection{}, err
+		}
+		item, err := s.repo.GetItemByName(*input.Name)
+		if err != nil {
+			return nil, geojson.FeatureCollection{}, fmt.Errorf("item '%s' not found", *input.Name)
+		}
+		positions, err = s.repo.GetPositionsInRange(item.ID, from, to)
+		if err != nil {
+			return nil, geojson.FeatureCollection{}, fmt.Errorf("failed to get positions: %w", err)
+		}
+	} else {
+		positions, err = s.repo.GetAllPositionsInRange(from, to)
+		if err != nil {
+			return nil, geojson.FeatureCollection{}, fmt.Errorf("failed to get positions: %w", err)
+		}
+	}
+	if input.Clean {
+		positions = storage.Clean(positions)
+	}
+	sortNewestFirst(positions)
+
+	itemNames := make(map[string]string)
+	if items, err := s.repo.ListItems(); err == nil {
+		for _, item := range items {
+			itemNames[item.ID.String()] = item.Name
+		}
+	}
+	nameResolver := func(id string) string { return itemNames[id] }
+
+	var fc *geojson.FeatureCollection
+	if geometry == "line" {
+		fc = geojson.ToLineFeatureCollection(positions, nameResolver, reduce)
+	} else {
+		fc = geojson.ToPointsFeatureCollection(positions, nameResolver)
+	}
+
+	jsonBytes, err := fc.ToJSONIndent()
+	if err != nil {
+		return nil, geojson.FeatureCollection{}, fmt.Errorf("failed to encode GeoJSON: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(jsonBytes)}},
+	}, *fc, nil
+}
+
+// sortNewestFirst orders positions by recorded time, newest first, as the
+// timeline tools return them.
+func sortNewestFirst(positions []*models.Position) {
+	sort.SliceStable(positions, func(i, j int) bool {
+		return positions[i].RecordedAt.After(positions[j].RecordedAt)
+	})
+}
@@ -0,0 +1,112 @@
+// ABOUTME: MCP streamable HTTP transport
+// ABOUTME: Serves MCP sessions over HTTP with optional bearer-token auth and request logging
+
+package mcp
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// SessionTimeout closes HTTP sessions that have sent no requests for this
+// long, so clients that vanish without a DELETE don't accumulate.
+const SessionTimeout = 30 * time.Minute
+
+// HTTPOptions configures the HTTP transport.
+type HTTPOptions struct {
+	// Token is the bearer token clients must send. Empty disables auth.
+	Token string
+
+	// Logger receives one line per request. Nil disables logging.
+	Logger *log.Logger
+}
+
+// HTTPHandler returns a handler serving the MCP streamable HTTP transport
+// (POST for requests, GET for the server-sent event stream, DELETE to end a
+// session). Clients that lose their connection reconnect with their session
+// ID rather than restarting the server.
+func (s *Server) HTTPHandler(opts HTTPOptions) http.Handler {
+	var handler http.Handler = mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server {
+		return s.mcp
+	}, &mcp.StreamableHTTPOptions{SessionTimeout: SessionTimeout})
+
+	if opts.Token != "" {
+		handler = auth.RequireBearerToken(tokenVerifier(opts.Token), nil)(handler)
+	}
+	if opts.Logger != nil {
+		handler = logRequests(opts.Logger, handler)
+	}
+	return handler
+}
+
+// tokenVerifier accepts only the configured token. The SDK middleware
+// rejects tokens without an expiry, so a static token never expires.
+func tokenVerifier(token string) auth.TokenVerifier {
+	return func(ctx context.Context, got string, req *http.Request) (*auth.TokenInfo, error) {
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			return nil, fmt.Errorf("%w: bearer token does not match", auth.ErrInvalidToken)
+		}
+		return &auth.TokenInfo{Expiration: time.Now().Add(24 * time.Hour)}, nil
+	}
+}
+
+// statusRecorder captures the response status for logging while still
+// letting the SDK flush server-sent events.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// logRequests logs the method, path, session, status, and duration of each
+// request. Event streams are logged when they close.
+func logRequests(logger *log.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		session := r.Header.Get("Mcp-Session-Id")
+		if session == "" {
+			session = w.Header().Get("Mcp-Session-Id")
+		}
+		if session == "" {
+			session = "-"
+		}
+		logger.Printf("%s %s %s session=%s %d %s", r.RemoteAddr, r.Method, r.URL.Path, session, status, time.Since(start).Round(time.Millisecond))
+	})
+}
@@ -4,14 +4,25 @@
 package mcp
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"log"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/harper/position/internal/config"
+	"github.com/harper/position/internal/geo"
 	"github.com/harper/position/internal/models"
 	"github.com/harper/position/internal/storage"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 // mockRepo implements storage.Repository for testing.
@@ -28,6 +39,7 @@ type mockRepo struct {
 	getPositionErr    error
 	getCurrentPosErr  error
 	getTimelineErr    error
+	getNearErr        error
 }
 
 func newMockRepo() *mockRepo {
@@ -197,11 +209,82 @@ func (m *mockRepo) GetAllPositionsInRange(from, to time.Time) ([]*models.Positio
 	return positions, nil
 }
 
+func (m *mockRepo) FilterPositions(itemID uuid.UUID) (int, int, error) {
+	return 0, 0, nil
+}
+
+func (m *mockRepo) GeocodePositions(itemID uuid.UUID) (int, error) {
+	return 0, nil
+}
+
 func (m *mockRepo) DeletePosition(id uuid.UUID) error {
 	delete(m.positions, id)
 	return nil
 }
 
+func (m *mockRepo) GetPositionsNear(lat, lng, radiusMeters float64, from, to time.Time) ([]*models.Position, error) {
+	if m.getNearErr != nil {
+		return nil, m.getNearErr
+	}
+	var positions []*models.Position
+	for _, pos := range m.positions {
+		if geo.Distance(lat, lng, pos.Latitude, pos.Longitude) > radiusMeters {
+			continue
+		}
+		if (!from.IsZero() && pos.RecordedAt.Before(from)) || (!to.IsZero() && pos.RecordedAt.After(to)) {
+			continue
+		}
+		positions = append(positions, pos)
+	}
+	return positions, nil
+}
+
+func (m *mockRepo) GetPositionsInBBox(minLat, minLng, maxLat, maxLng float64, from, to time.Time) ([]*models.Position, error) {
+	var positions []*models.Position
+	for _, pos := range m.positions {
+		if pos.Latitude >= minLat && pos.Latitude <= maxLat && pos.Longitude >= minLng && pos.Longitude <= maxLng {
+			positions = append(positions, pos)
+		}
+	}
+	return positions, nil
+}
+
+func (m *mockRepo) CreateGeofence(fence *models.Geofence) error {
+	return nil
+}
+
+func (m *mockRepo) GetGeofenceByName(name string) (*models.Geofence, error) {
+	return nil, storage.ErrNotFound
+}
+
+func (m *mockRepo) ListGeofences() ([]*models.Geofence, error) {
+	return nil, nil
+}
+
+func (m *mockRepo) DeleteGeofence(id uuid.UUID) error {
+	return nil
+}
+
+func (m *mockRepo) ListGeofenceEvents(itemID, geofenceID uuid.UUID) ([]*models.GeofenceEvent, error) {
+	return nil, nil
+}
+
+func (m *mockRepo) CreatePlace(place *models.Place) error {
+	return nil
+}
+
+func (m *mockRepo) GetPlaceByName(name string) (*models.Place, error) {
+	return nil, storage.ErrNotFound
+}
+
+func (m *mockRepo) ListPlaces() ([]*models.Place, error) {
+	return nil, nil
+}
+
+func (m *mockRepo) DeletePlace(id uuid.UUID) error {
+	return nil
+}
+
 func (m *mockRepo) Sync() error {
 	return nil
 }
@@ -528,6 +611,52 @@ func TestHandleGetTimeline(t *testing.T) {
 	}
 }
 
+func TestHandleReadTools_Clean(t *testing.T) {
+	repo := newMockRepo()
+	item := models.NewItem("phone")
+	_ = repo.CreateItem(item)
+	base := time.Date(2024, 12, 14, 12, 0, 0, 0, time.UTC)
+	good := models.NewPositionWithRecordedAt(item.ID, 41.8781, -87.6298, nil, base)
+	jump := models.NewPositionWithRecordedAt(item.ID, 41.9786, -87.9048, nil, base.Add(time.Minute))
+	jump.Suspect = true
+	_ = repo.CreatePosition(good)
+	_ = repo.CreatePosition(jump)
+
+	server, _ := NewServer(repo)
+	ctx := context.Background()
+
+	_, current, err := server.handleGetCurrent(ctx, nil, GetCurrentInput{Name: "phone"})
+	if err != nil {
+		t.Fatalf("handleGetCurrent failed: %v", err)
+	}
+	if !current.Suspect {
+		t.Error("expected the suspect jump reported as current without clean")
+	}
+	_, current, err = server.handleGetCurrent(ctx, nil, GetCurrentInput{Name: "phone", Clean: true})
+	if err != nil {
+		t.Fatalf("handleGetCurrent failed: %v", err)
+	}
+	if current.Suspect || current.Latitude != good.Latitude {
+		t.Errorf("expected the trusted position with clean, got %+v", current)
+	}
+
+	_, timeline, err := server.handleGetTimeline(ctx, nil, GetCurrentInput{Name: "phone", Clean: true})
+	if err != nil {
+		t.Fatalf("handleGetTimeline failed: %v", err)
+	}
+	if timeline.Count != 1 {
+		t.Errorf("expected 1 clean position, got %d", timeline.Count)
+	}
+
+	_, nearby, err := server.handleFindNearby(ctx, nil, FindNearbyInput{Latitude: 41.9786, Longitude: -87.9048, Clean: true})
+	if err != nil {
+		t.Fatalf("handleFindNearby failed: %v", err)
+	}
+	if nearby.Count != 0 {
+		t.Errorf("expected the suspect jump excluded from nearby results, got %d", nearby.Count)
+	}
+}
+
 func TestHandleGetTimeline_ItemNotFound(t *testing.T) {
 	repo := newMockRepo()
 	server, _ := NewServer(repo)
@@ -728,3 +857,1000 @@ func TestHandleItemsResource_Error(t *testing.T) {
 		t.Error("expected error when list items fails")
 	}
 }
+
+func TestHandleFindNearby(t *testing.T) {
+	repo := newMockRepo()
+	harper := models.NewItem("harper")
+	car := models.NewItem("car")
+	_ = repo.CreateItem(harper)
+	_ = repo.CreateItem(car)
+	base := time.Date(2024, 12, 14, 8, 0, 0, 0, time.UTC)
+	_ = repo.CreatePosition(models.NewPositionWithRecordedAt(harper.ID, 41.8781, -87.6298, nil, base))
+	_ = repo.CreatePosition(models.NewPositionWithRecordedAt(harper.ID, 41.8790, -87.6298, nil, base.Add(time.Hour)))
+	_ = repo.CreatePosition(models.NewPositionWithRecordedAt(car.ID, 41.8800, -87.6300, nil, base.Add(2*time.Hour)))
+	_ = repo.CreatePosition(models.NewPositionWithRecordedAt(car.ID, 40.7128, -74.0060, nil, base.Add(3*time.Hour)))
+
+	server, _ := NewServer(repo)
+
+	input := FindNearbyInput{Latitude: 41.8781, Longitude: -87.6298}
+	result, output, err := server.handleFindNearby(context.Background(), nil, input)
+	if err != nil {
+		t.Fatalf("handleFindNearby failed: %v", err)
+	}
+	if result == nil {
+		t.Fatal("expected non-nil result")
+	}
+	if output.RadiusMeters != defaultNearbyRadius {
+		t.Errorf("expected default radius, got %v", output.RadiusMeters)
+	}
+	if output.Count != 3 {
+		t.Errorf("expected 3 positions, got %d", output.Count)
+	}
+	if len(output.Items) != 2 || output.Items[0].Name != "car" || output.Items[1].Name != "harper" {
+		t.Fatalf("expected car and harper summaries, got %+v", output.Items)
+	}
+	if output.Items[1].Count != 2 || output.Items[1].ClosestMeters != 0 {
+		t.Errorf("unexpected harper summary: %+v", output.Items[1])
+	}
+	if !output.Items[1].LastSeen.Equal(base.Add(time.Hour)) {
+		t.Errorf("unexpected harper last seen: %v", output.Items[1].LastSeen)
+	}
+}
+
+func TestHandleFindNearby_TimeWindow(t *testing.T) {
+	repo := newMockRepo()
+	item := models.NewItem("harper")
+	_ = repo.CreateItem(item)
+	base := time.Date(2024, 12, 14, 8, 0, 0, 0, time.UTC)
+	_ = repo.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 41.0, -87.0, nil, base))
+	_ = repo.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 41.0001, -87.0, nil, base.Add(48*time.Hour)))
+
+	server, _ := NewServer(repo)
+
+	from := base.Add(24 * time.Hour).Format(time.RFC3339)
+	radius := 100.0
+	input := FindNearbyInput{Latitude: 41.0, Longitude: -87.0, RadiusMeters: &radius, From: &from}
+	_, output, err := server.handleFindNearby(context.Background(), nil, input)
+	if err != nil {
+		t.Fatalf("handleFindNearby failed: %v", err)
+	}
+	if output.Count != 1 {
+		t.Errorf("expected 1 position after from, got %d", output.Count)
+	}
+}
+
+func TestHandleFindNearby_InvalidInput(t *testing.T) {
+	server, _ := NewServer(newMockRepo())
+
+	zero := 0.0
+	bad := "yesterday"
+	tests := []FindNearbyInput{
+		{Latitude: 91, Longitude: 0},
+		{Latitude: 41, Longitude: -87, RadiusMeters: &zero},
+		{Latitude: 41, Longitude: -87, From: &bad},
+		{Latitude: 41, Longitude: -87, To: &bad},
+	}
+	for _, input := range tests {
+		if _, _, err := server.handleFindNearby(context.Background(), nil, input); err == nil {
+			t.Errorf("expected error for input %+v", input)
+		}
+	}
+}
+
+func TestHandleFindNearby_RepoError(t *testing.T) {
+	repo := newMockRepo()
+	repo.getNearErr = errors.New("db error")
+	server, _ := NewServer(repo)
+
+	if _, _, err := server.handleFindNearby(context.Background(), nil, FindNearbyInput{Latitude: 41, Longitude: -87}); err == nil {
+		t.Error("expected error when repository fails")
+	}
+}
+
+func TestHandleGetVisits(t *testing.T) {
+	repo := newMockRepo()
+	item := models.NewItem("harper")
+	_ = repo.CreateItem(item)
+	home := "home"
+	base := time.Date(2024, 12, 14, 18, 0, 0, 0, time.UTC)
+	_ = repo.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 41.9500, -87.6500, &home, base))
+	_ = repo.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 41.9501, -87.6500, nil, base.Add(30*time.Minute)))
+	_ = repo.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 41.8781, -87.6298, nil, base.Add(time.Hour)))
+	_ = repo.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 41.8782, -87.6298, nil, base.Add(2*time.Hour)))
+
+	server, _ := NewServer(repo)
+
+	result, output, err := server.handleGetVisits(context.Background(), nil, GetVisitsInput{Name: "harper"})
+	if err != nil {
+		t.Fatalf("handleGetVisits failed: %v", err)
+	}
+	if result == nil {
+		t.Fatal("expected non-nil result")
+	}
+	if output.Count != 2 {
+		t.Fatalf("expected 2 visits, got %d: %+v", output.Count, output.Visits)
+	}
+	if output.Visits[0].Label != "home" || output.Visits[0].DurationMinutes != 30 || output.Visits[0].PositionCount != 2 {
+		t.Errorf("unexpected first visit: %+v", output.Visits[0])
+	}
+	if !output.Visits[1].Arrived.Equal(base.Add(time.Hour)) {
+		t.Errorf("unexpected second visit: %+v", output.Visits[1])
+	}
+
+	// A time window and a longer minimum drop the home visit
+	from := base.Add(45 * time.Minute).Format(time.RFC3339)
+	minutes := 45.0
+	_, output, err = server.handleGetVisits(context.Background(), nil, GetVisitsInput{Name: "harper", From: &from, MinDurationMinutes: &minutes})
+	if err != nil {
+		t.Fatalf("handleGetVisits failed: %v", err)
+	}
+	if output.Count != 1 || output.Visits[0].Label != "" {
+		t.Errorf("expected only the office visit, got %+v", output.Visits)
+	}
+}
+
+func TestHandleGetVisits_InvalidInput(t *testing.T) {
+	repo := newMockRepo()
+	_ = repo.CreateItem(models.NewItem("harper"))
+	server, _ := NewServer(repo)
+
+	zero := 0.0
+	negative := -1.0
+	bad := "yesterday"
+	tests := []GetVisitsInput{
+		{Name: ""},
+		{Name: "missing"},
+		{Name: "harper", RadiusMeters: &zero},
+		{Name: "harper", MinDurationMinutes: &negative},
+		{Name: "harper", To: &bad},
+	}
+	for _, input := range tests {
+		if _, _, err := server.handleGetVisits(context.Background(), nil, input); err == nil {
+			t.Errorf("expected error for input %+v", input)
+		}
+	}
+}
+
+func TestHandleGetVisits_RepoError(t *testing.T) {
+	repo := newMockRepo()
+	_ = repo.CreateItem(models.NewItem("harper"))
+	repo.getTimelineErr = errors.New("db error")
+	server, _ := NewServer(repo)
+
+	if _, _, err := server.handleGetVisits(context.Background(), nil, GetVisitsInput{Name: "harper"}); err == nil {
+		t.Error("expected error when repository fails")
+	}
+}
+
+func TestHandleAddPosition_Telemetry(t *testing.T) {
+	repo := newMockRepo()
+	server, _ := NewServer(repo)
+
+	acc := 15.0
+	battery := 42
+	source := "gps"
+	input := AddPositionInput{
+		Name:      "phone",
+		Latitude:  41.8781,
+		Longitude: -87.6298,
+		Telemetry: models.Telemetry{Accuracy: &acc, Battery: &battery, Source: &source},
+	}
+	_, output, err := server.handleAddPosition(context.Background(), nil, input)
+	if err != nil {
+		t.Fatalf("handleAddPosition failed: %v", err)
+	}
+	if output.Accuracy == nil || *output.Accuracy != 15 || output.Battery == nil || *output.Battery != 42 {
+		t.Errorf("expected telemetry in output, got %+v", output.Telemetry)
+	}
+	data, _ := json.Marshal(output)
+	var fields map[string]any
+	_ = json.Unmarshal(data, &fields)
+	if fields["accuracy"] != 15.0 || fields["source"] != "gps" {
+		t.Errorf("expected flattened telemetry in JSON, got %s", data)
+	}
+
+	item, _ := repo.GetItemByName("phone")
+	pos, _ := repo.GetCurrentPosition(item.ID)
+	if pos.Source == nil || *pos.Source != "gps" {
+		t.Errorf("expected telemetry stored, got %+v", pos.Telemetry)
+	}
+
+	bad := 120
+	input.Battery = &bad
+	if _, _, err := server.handleAddPosition(context.Background(), nil, input); err == nil {
+		t.Error("expected error for out-of-range battery")
+	}
+}
+
+func TestHandleGetPositionAt(t *testing.T) {
+	repo := newMockRepo()
+	item := models.NewItem("van")
+	_ = repo.CreateItem(item)
+	office := "office"
+	base := time.Date(2024, 12, 14, 13, 52, 0, 0, time.UTC)
+	_ = repo.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 0, 0, &office, base))
+	_ = repo.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 0, 10, nil, base.Add(20*time.Minute)))
+
+	server, _ := NewServer(repo)
+
+	input := GetPositionAtInput{Name: "van", At: base.Add(10 * time.Minute).Format(time.RFC3339)}
+	result, output, err := server.handleGetPositionAt(context.Background(), nil, input)
+	if err != nil {
+		t.Fatalf("handleGetPositionAt failed: %v", err)
+	}
+	if result == nil {
+		t.Fatal("expected non-nil result")
+	}
+	if output.Longitude != 0 || output.Interpolated || output.GapMinutes != 10 {
+		t.Errorf("expected the office position 10 minutes stale, got %+v", output)
+	}
+	if output.Label == nil || *output.Label != "office" || output.After == nil || output.After.Longitude != 10 {
+		t.Errorf("unexpected label or after position: %+v", output)
+	}
+
+	input.Interpolate = true
+	_, output, err = server.handleGetPositionAt(context.Background(), nil, input)
+	if err != nil {
+		t.Fatalf("handleGetPositionAt failed: %v", err)
+	}
+	if !output.Interpolated || math.Abs(output.Longitude-5) > 1e-9 || output.GapMinutes != 20 || output.Label != nil {
+		t.Errorf("expected midpoint with 20 minute gap, got %+v", output)
+	}
+}
+
+func TestHandleGetPositionAt_Clean(t *testing.T) {
+	repo := newMockRepo()
+	item := models.NewItem("phone")
+	_ = repo.CreateItem(item)
+	base := time.Date(2024, 12, 14, 12, 0, 0, 0, time.UTC)
+	_ = repo.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 41.8781, -87.6298, nil, base))
+	jump := models.NewPositionWithRecordedAt(item.ID, 41.9786, -87.9048, nil, base.Add(time.Minute))
+	jump.Suspect = true
+	_ = repo.CreatePosition(jump)
+	server, _ := NewServer(repo)
+
+	input := GetPositionAtInput{Name: "phone", At: base.Add(90 * time.Second).Format(time.RFC3339), Clean: true}
+	_, output, err := server.handleGetPositionAt(context.Background(), nil, input)
+	if err != nil {
+		t.Fatalf("handleGetPositionAt failed: %v", err)
+	}
+	if output.Latitude != 41.8781 {
+		t.Errorf("expected clean to skip the suspect jump, got %+v", output)
+	}
+}
+
+func TestHandleGetPositionAt_InvalidInput(t *testing.T) {
+	repo := newMockRepo()
+	item := models.NewItem("van")
+	_ = repo.CreateItem(item)
+	base := time.Date(2024, 12, 14, 12, 0, 0, 0, time.UTC)
+	_ = repo.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 41.0, -87.0, nil, base))
+	server, _ := NewServer(repo)
+
+	tests := []GetPositionAtInput{
+		{Name: "", At: base.Format(time.RFC3339)},
+		{Name: "missing", At: base.Format(time.RFC3339)},
+		{Name: "van", At: "2pm yesterday"},
+		{Name: "van", At: base.Add(-time.Hour).Format(time.RFC3339)},
+	}
+	for _, input := range tests {
+		if _, _, err := server.handleGetPositionAt(context.Background(), nil, input); err == nil {
+			t.Errorf("expected error for input %+v", input)
+		}
+	}
+}
+
+// bearerTransport adds an Authorization header to every request.
+type bearerTransport struct {
+	token string
+}
+
+func (b bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+b.token)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// connectHTTP opens a client session to an MCP HTTP endpoint.
+func connectHTTP(t *testing.T, url, token string) (*mcp.ClientSession, error) {
+	t.Helper()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	transport := &mcp.StreamableClientTransport{Endpoint: url, MaxRetries: -1}
+	if token != "" {
+		transport.HTTPClient = &http.Client{Transport: bearerTransport{token: token}}
+	}
+	return client.Connect(context.Background(), transport, nil)
+}
+
+func TestHTTPHandler(t *testing.T) {
+	repo := newMockRepo()
+	item := models.NewItem("harper")
+	_ = repo.CreateItem(item)
+	_ = repo.CreatePosition(models.NewPosition(item.ID, 41.8781, -87.6298, nil))
+	server, _ := NewServer(repo)
+
+	var logs bytes.Buffer
+	ts := httptest.NewServer(server.HTTPHandler(HTTPOptions{Token: "secret", Logger: log.New(&logs, "", 0)}))
+	defer ts.Close()
+
+	session, err := connectHTTP(t, ts.URL, "secret")
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "get_current",
+		Arguments: map[string]any{"name": "harper"},
+	})
+	if err != nil {
+		t.Fatalf("get_current failed: %v", err)
+	}
+	if result.IsError || !strings.Contains(result.Content[0].(*mcp.TextContent).Text, "41.8781") {
+		t.Errorf("unexpected result: %+v", result.Content[0])
+	}
+	_ = session.Close()
+
+	if !strings.Contains(logs.String(), "POST / session=") {
+		t.Errorf("expected request log lines, got:\n%s", logs.String())
+	}
+
+	for _, token := range []string{"", "wrong"} {
+		if _, err := connectHTTP(t, ts.URL, token); err == nil {
+			t.Errorf("expected connect with token %q to be rejected", token)
+		}
+	}
+}
+
+func TestHTTPHandler_Unauthorized(t *testing.T) {
+	server, _ := NewServer(newMockRepo())
+	ts := httptest.NewServer(server.HTTPHandler(HTTPOptions{Token: "secret"}))
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL, "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 without a token, got %d", resp.StatusCode)
+	}
+}
+
+func TestHTTPHandler_NoToken(t *testing.T) {
+	server, _ := NewServer(newMockRepo())
+	ts := httptest.NewServer(server.HTTPHandler(HTTPOptions{}))
+	defer ts.Close()
+
+	session, err := connectHTTP(t, ts.URL, "")
+	if err != nil {
+		t.Fatalf("expected connect without auth to succeed: %v", err)
+	}
+	_ = session.Close()
+}
+
+// connectInMemory opens a client session to the server over in-memory transports.
+func connectInMemory(t *testing.T, server *Server, opts *mcp.ClientOptions) *mcp.ClientSession {
+	t.Helper()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ctx := context.Background()
+	if _, err := server.mcp.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("server connect failed: %v", err)
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "1.0.0"}, opts)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect failed: %v", err)
+	}
+	t.Cleanup(func() { _ = session.Close() })
+	return session
+}
+
+// seedResourceRepo returns a repo with "harper" moving across three days and
+// an idle "car".
+func seedResourceRepo() *mockRepo {
+	repo := newMockRepo()
+	harper := models.NewItem("harper")
+	_ = repo.CreateItem(harper)
+	_ = repo.CreateItem(models.NewItem("car"))
+	now := time.Now()
+	for i, lng := range []float64{-87.70, -87.65, -87.60} {
+		_ = repo.CreatePosition(models.NewPositionWithRecordedAt(harper.ID, 41.88, lng, nil, now.Add(time.Duration(i-2)*24*time.Hour)))
+	}
+	return repo
+}
+
+func TestItemResourceTemplates(t *testing.T) {
+	server, _ := NewServer(seedResourceRepo())
+	session := connectInMemory(t, server, nil)
+	ctx := context.Background()
+
+	templates, err := session.ListResourceTemplates(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListResourceTemplates failed: %v", err)
+	}
+	if len(templates.ResourceTemplates) != 3 {
+		t.Errorf("expected 3 resource templates, got %d", len(templates.ResourceTemplates))
+	}
+
+	read := func(uri string) *mcp.ResourceContents {
+		t.Helper()
+		result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+		if err != nil {
+			t.Fatalf("ReadResource(%s) failed: %v", uri, err)
+		}
+		return result.Contents[0]
+	}
+
+	var item ItemOutput
+	if err := json.Unmarshal([]byte(read("position://items/harper").Text), &item); err != nil {
+		t.Fatal(err)
+	}
+	if item.CurrentPosition == nil || item.CurrentPosition.Longitude != -87.60 {
+		t.Errorf("expected current position, got %+v", item)
+	}
+	var car ItemOutput
+	if err := json.Unmarshal([]byte(read("position://items/car").Text), &car); err != nil {
+		t.Fatal(err)
+	}
+	if car.Name != "car" || car.CurrentPosition != nil {
+		t.Errorf("expected car without a position, got %+v", car)
+	}
+
+	tests := []struct {
+		uri  string
+		want int
+	}{
+		{"position://items/harper/timeline", 3},
+		{"position://items/harper/timeline?since=36h", 2},
+		{"position://items/harper/timeline?since=7d&clean=true", 3},
+	}
+	for _, tt := range tests {
+		var timeline TimelineOutput
+		if err := json.Unmarshal([]byte(read(tt.uri).Text), &timeline); err != nil {
+			t.Fatal(err)
+		}
+		if timeline.Count != tt.want {
+			t.Errorf("%s: expected %d positions, got %d", tt.uri, tt.want, timeline.Count)
+		}
+	}
+
+	contents := read("position://items/harper/track.geojson?since=7d")
+	if contents.MIMEType != "application/geo+json" {
+		t.Errorf("expected GeoJSON MIME type, got %q", contents.MIMEType)
+	}
+	var fc struct {
+		Features []struct {
+			Geometry struct {
+				Type string `json:"type"`
+			} `json:"geometry"`
+		} `json:"features"`
+	}
+	if err := json.Unmarshal([]byte(contents.Text), &fc); err != nil {
+		t.Fatal(err)
+	}
+	if len(fc.Features) != 4 || fc.Features[0].Geometry.Type != "LineString" || fc.Features[1].Geometry.Type != "Point" {
+		t.Errorf("expected a line and three points, got %+v", fc.Features)
+	}
+}
+
+func TestItemResourceTemplates_Errors(t *testing.T) {
+	server, _ := NewServer(seedResourceRepo())
+	session := connectInMemory(t, server, nil)
+
+	for _, uri := range []string{
+		"position://items/nobody",
+		"position://items/nobody/timeline",
+		"position://items/harper/timeline?since=yesterday",
+		"position://items/harper/timeline?from=monday",
+		"position://items/harper/track.geojson?clean=maybe",
+	} {
+		if _, err := session.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: uri}); err == nil {
+			t.Errorf("expected error reading %s", uri)
+		}
+	}
+}
+
+// updateRecorder collects resources/updated notifications.
+type updateRecorder struct {
+	ch chan string
+}
+
+func newUpdateRecorder() *updateRecorder {
+	return &updateRecorder{ch: make(chan string, 16)}
+}
+
+func (r *updateRecorder) options() *mcp.ClientOptions {
+	return &mcp.ClientOptions{
+		ResourceUpdatedHandler: func(_ context.Context, req *mcp.ResourceUpdatedNotificationRequest) {
+			r.ch <- req.Params.URI
+		},
+	}
+}
+
+// next waits for the next notification, or returns "" after a timeout.
+func (r *updateRecorder) next(timeout time.Duration) string {
+	select {
+	case uri := <-r.ch:
+		return uri
+	case <-time.After(timeout):
+		return ""
+	}
+}
+
+func TestResourceSubscriptions_Tools(t *testing.T) {
+	repo := seedResourceRepo()
+	server, _ := NewServer(repo)
+	updates := newUpdateRecorder()
+	session := connectInMemory(t, server, updates.options())
+	ctx := context.Background()
+
+	for _, uri := range []string{"position://items/harper", "position://items/car/timeline"} {
+		if err := session.Subscribe(ctx, &mcp.SubscribeParams{URI: uri}); err != nil {
+			t.Fatalf("Subscribe(%s) failed: %v", uri, err)
+		}
+	}
+	if err := session.Subscribe(ctx, &mcp.SubscribeParams{URI: "position://elsewhere"}); err == nil {
+		t.Error("expected error subscribing to an unknown resource")
+	}
+
+	if _, _, err := server.handleAddPosition(ctx, nil, AddPositionInput{Name: "harper", Latitude: 41.9, Longitude: -87.5}); err != nil {
+		t.Fatal(err)
+	}
+	if got := updates.next(time.Second); got != "position://items/harper" {
+		t.Errorf("expected update for harper after add_position, got %q", got)
+	}
+
+	if _, _, err := server.handleRemoveItem(ctx, nil, RemoveItemInput{Name: "car"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := updates.next(time.Second); got != "position://items/car/timeline" {
+		t.Errorf("expected update for car after remove_item, got %q", got)
+	}
+
+	// Unchanged resources and unsubscribed ones stay quiet
+	if err := session.Unsubscribe(ctx, &mcp.UnsubscribeParams{URI: "position://items/harper"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := server.handleAddPosition(ctx, nil, AddPositionInput{Name: "harper", Latitude: 42.0, Longitude: -87.5}); err != nil {
+		t.Fatal(err)
+	}
+	if got := updates.next(100 * time.Millisecond); got != "" {
+		t.Errorf("expected no further updates, got %q", got)
+	}
+}
+
+func TestResourceSubscriptions_OtherProcess(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "position.db")
+	serverDB, err := storage.NewSQLiteDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer serverDB.Close()
+	writerDB, err := storage.NewSQLiteDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writerDB.Close()
+
+	server, _ := NewServer(serverDB)
+	server.pollInterval = 20 * time.Millisecond
+	updates := newUpdateRecorder()
+	session := connectInMemory(t, server, updates.options())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go server.WatchChanges(ctx)
+
+	// Subscribing before the item exists is allowed; it appears later
+	if err := session.Subscribe(ctx, &mcp.SubscribeParams{URI: "position://items/driver"}); err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	if err := session.Subscribe(ctx, &mcp.SubscribeParams{URI: "position://items"}); err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	driver := models.NewItem("driver")
+	if err := writerDB.CreateItem(driver); err != nil {
+		t.Fatal(err)
+	}
+	if err := writerDB.CreatePosition(models.NewPosition(driver.ID, 41.88, -87.63, nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]bool{}
+	for len(got) < 2 {
+		uri := updates.next(2 * time.Second)
+		if uri == "" {
+			break
+		}
+		got[uri] = true
+	}
+	if !got["position://items/driver"] || !got["position://items"] {
+		t.Errorf("expected updates for the item and the items list, got %v", got)
+	}
+}
+
+func TestHandleDistanceBetween(t *testing.T) {
+	repo := newMockRepo()
+	for name, lng := range map[string]float64{"car": -87.6298, "harper": -87.6100} {
+		item := models.NewItem(name)
+		_ = repo.CreateItem(item)
+		_ = repo.CreatePosition(models.NewPosition(item.ID, 41.8781, lng, nil))
+	}
+	server, _ := NewServer(repo)
+
+	_, output, err := server.handleDistanceBetween(context.Background(), nil, DistanceBetweenInput{From: "car", To: "harper"})
+	if err != nil {
+		t.Fatalf("handleDistanceBetween failed: %v", err)
+	}
+	if math.Abs(output.DistanceMeters-1645) > 10 || output.Compass != "E" || output.From.Name != "car" || output.From.RecordedAt == nil {
+		t.Errorf("unexpected distance output: %+v", output)
+	}
+
+	_, output, err = server.handleDistanceBetween(context.Background(), nil, DistanceBetweenInput{From: "harper", To: "41.8781, -87.6100"})
+	if err != nil {
+		t.Fatalf("handleDistanceBetween failed: %v", err)
+	}
+	if output.DistanceMeters != 0 || output.Compass != "" || output.To.Name != "" {
+		t.Errorf("expected zero distance to own coordinates, got %+v", output)
+	}
+
+	for _, input := range []DistanceBetweenInput{
+		{From: "car", To: "nobody"},
+		{From: "91,0", To: "car"},
+		{From: "", To: "car"},
+	} {
+		if _, _, err := server.handleDistanceBetween(context.Background(), nil, input); err == nil {
+			t.Errorf("expected error for %+v", input)
+		}
+	}
+}
+
+// seedZigzagRide adds a ride east on 2024-12-14 UTC whose fixes alternate
+// 8 m either side of the road, ending on a fix north of it.
+func seedZigzagRide(repo *mockRepo) {
+	item := models.NewItem("bike")
+	_ = repo.CreateItem(item)
+	start := time.Date(2024, 12, 14, 8, 0, 0, 0, time.UTC)
+	for i := 0; i <= 60; i++ {
+		lat := 41.8781 + 0.000072
+		if i%2 == 0 {
+			lat = 41.8781 - 0.000072
+		}
+		_ = repo.CreatePosition(models.NewPositionWithRecordedAt(item.ID, lat, -87.6298+float64(i)*0.000169, nil, start.Add(time.Duration(i)*10*time.Second)))
+	}
+}
+
+func TestHandleDistanceBetween_Smooth(t *testing.T) {
+	repo := newMockRepo()
+	seedZigzagRide(repo)
+	server, _ := NewServer(repo)
+
+	road := "41.8781,-87.61966"
+	_, raw, err := server.handleDistanceBetween(context.Background(), nil, DistanceBetweenInput{From: "bike", To: road})
+	if err != nil {
+		t.Fatalf("handleDistanceBetween failed: %v", err)
+	}
+	_, smoothed, err := server.handleDistanceBetween(context.Background(), nil, DistanceBetweenInput{From: "bike", To: road, Smooth: true})
+	if err != nil {
+		t.Fatalf("handleDistanceBetween failed: %v", err)
+	}
+	if smoothed.DistanceMeters >= raw.DistanceMeters {
+		t.Errorf("expected the smoothed end closer to the road: raw %.1f m, smoothed %.1f m", raw.DistanceMeters, smoothed.DistanceMeters)
+	}
+}
+
+func TestHandleGetPositionsInRange(t *testing.T) {
+	repo := newMockRepo()
+	item := models.NewItem("van")
+	_ = repo.CreateItem(item)
+	base := time.Date(2024, 12, 14, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		_ = repo.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 41.0, -87.0+float64(i)/100, nil, base.Add(time.Duration(i)*time.Hour)))
+	}
+	server, _ := NewServer(repo)
+
+	from := base.Add(30 * time.Minute).Format(time.RFC3339)
+	to := base.Add(150 * time.Minute).Format(time.RFC3339)
+	_, output, err := server.handleGetPositionsInRange(context.Background(), nil, GetPositionsInRangeInput{Name: "van", From: from, To: &to})
+	if err != nil {
+		t.Fatalf("handleGetPositionsInRange failed: %v", err)
+	}
+	if output.Count != 2 || !output.Positions[0].RecordedAt.Equal(base.Add(2*time.Hour)) {
+		t.Errorf("expected the two middle positions newest first, got %+v", output.Positions)
+	}
+
+	// Without to, the range runs until now
+	_, output, _ = server.handleGetPositionsInRange(context.Background(), nil, GetPositionsInRangeInput{Name: "van", From: from})
+	if output.Count != 3 {
+		t.Errorf("expected 3 positions up to now, got %d", output.Count)
+	}
+
+	for _, input := range []GetPositionsInRangeInput{
+		{Name: "van", From: "yesterday"},
+		{Name: "van", From: to, To: &from},
+		{Name: "missing", From: from},
+	} {
+		if _, _, err := server.handleGetPositionsInRange(context.Background(), nil, input); err == nil {
+			t.Errorf("expected error for %+v", input)
+		}
+	}
+}
+
+// seedCommute adds a morning at home, a drive, and a morning at the office
+// on 2024-12-14 UTC, plus one position the day before.
+func seedCommute(repo *mockRepo) {
+	item := models.NewItem("harper")
+	_ = repo.CreateItem(item)
+	day := time.Date(2024, 12, 14, 0, 0, 0, 0, time.UTC)
+	add := func(at time.Duration, lat, lng float64) {
+		_ = repo.CreatePosition(models.NewPositionWithRecordedAt(item.ID, lat, lng, nil, day.Add(at)))
+	}
+	add(-2*time.Hour, 40.0, -80.0)
+	for m := 0; m <= 30; m += 10 {
+		add(8*time.Hour+time.Duration(m)*time.Minute, 41.8781, -87.6298)
+	}
+	for m := 5; m < 20; m += 5 {
+		add(8*time.Hour+30*time.Minute+time.Duration(m)*time.Minute, 41.8781+float64(m)*0.004, -87.6298)
+	}
+	for m := 20; m <= 60; m += 10 {
+		add(8*time.Hour+30*time.Minute+time.Duration(m)*time.Minute, 41.9581, -87.6298)
+	}
+}
+
+func TestHandleSummarizeDay(t *testing.T) {
+	repo := newMockRepo()
+	seedCommute(repo)
+	server, _ := NewServer(repo)
+
+	date, tz := "2024-12-14", "UTC"
+	_, output, err := server.handleSummarizeDay(context.Background(), nil, SummarizeDayInput{Name: "harper", Date: &date, Timezone: &tz})
+	if err != nil {
+		t.Fatalf("handleSummarizeDay failed: %v", err)
+	}
+	if output.PositionCount != 12 || output.Date != date || output.Timezone != "UTC" {
+		t.Errorf("unexpected summary header: %+v", output)
+	}
+	if output.First == nil || output.First.Longitude != -87.6298 || output.Last.Latitude != 41.9581 {
+		t.Errorf("unexpected first/last: %+v %+v", output.First, output.Last)
+	}
+	if len(output.Visits) != 2 || len(output.Trips) != 1 {
+		t.Fatalf("expected 2 visits and 1 trip, got %d and %d", len(output.Visits), len(output.Trips))
+	}
+	if math.Abs(output.DistanceMeters-8900) > 100 || math.Abs(output.Trips[0].DistanceMeters-8900) > 100 {
+		t.Errorf("expected ~8.9 km traveled, got %.0f (trip %.0f)", output.DistanceMeters, output.Trips[0].DistanceMeters)
+	}
+
+	// A zone west of UTC shifts the day to include the evening before
+	tz = "America/Chicago"
+	date = "2024-12-13"
+	_, output, _ = server.handleSummarizeDay(context.Background(), nil, SummarizeDayInput{Name: "harper", Date: &date, Timezone: &tz})
+	if output.PositionCount != 1 {
+		t.Errorf("expected 1 position on Dec 13 in Chicago, got %d", output.PositionCount)
+	}
+
+	bad := "Mars/Olympus"
+	for _, input := range []SummarizeDayInput{
+		{Name: "harper", Timezone: &bad},
+		{Name: "harper", Date: &bad},
+		{Name: "missing"},
+	} {
+		if _, _, err := server.handleSummarizeDay(context.Background(), nil, input); err == nil {
+			t.Errorf("expected error for %+v", input)
+		}
+	}
+}
+
+func TestHandleSummarizeDay_Smooth(t *testing.T) {
+	repo := newMockRepo()
+	seedZigzagRide(repo)
+	server, _ := NewServer(repo)
+
+	date, tz := "2024-12-14", "UTC"
+	_, raw, err := server.handleSummarizeDay(context.Background(), nil, SummarizeDayInput{Name: "bike", Date: &date, Timezone: &tz})
+	if err != nil {
+		t.Fatalf("handleSummarizeDay failed: %v", err)
+	}
+	_, smoothed, err := server.handleSummarizeDay(context.Background(), nil, SummarizeDayInput{Name: "bike", Date: &date, Timezone: &tz, Smooth: true})
+	if err != nil {
+		t.Fatalf("handleSummarizeDay failed: %v", err)
+	}
+	if smoothed.PositionCount != raw.PositionCount || smoothed.DistanceMeters > raw.DistanceMeters*0.85 {
+		t.Errorf("expected smoothing to remove the zig-zag distance: raw %.0f m, smoothed %.0f m", raw.DistanceMeters, smoothed.DistanceMeters)
+	}
+}
+
+func TestHandleExportGeoJSON(t *testing.T) {
+	repo := newMockRepo()
+	seedCommute(repo)
+	car := models.NewItem("car")
+	_ = repo.CreateItem(car)
+	_ = repo.CreatePosition(models.NewPositionWithRecordedAt(car.ID, 41.0, -87.0, nil, time.Date(2024, 12, 14, 9, 0, 0, 0, time.UTC)))
+	server, _ := NewServer(repo)
+
+	_, fc, err := server.handleExportGeoJSON(context.Background(), nil, ExportGeoJSONInput{})
+	if err != nil {
+		t.Fatalf("handleExportGeoJSON failed: %v", err)
+	}
+	if len(fc.Features) != 14 || fc.Features[0].Geometry.Type != "Point" {
+		t.Errorf("expected 14 points across items, got %d", len(fc.Features))
+	}
+
+	name, from, simplify := "harper", "2024-12-14T00:00:00Z", 50.0
+	_, fc, err = server.handleExportGeoJSON(context.Background(), nil, ExportGeoJSONInput{Name: &name, From: &from, Geometry: "line", SimplifyMeters: &simplify})
+	if err != nil {
+		t.Fatalf("handleExportGeoJSON failed: %v", err)
+	}
+	if len(fc.Features) != 1 || fc.Features[0].Geometry.Type != "LineString" || fc.Features[0].Properties["name"] != "harper" {
+		t.Fatalf("expected one line for harper, got %+v", fc.Features)
+	}
+	if removed, _ := fc.Features[0].Properties["removed_count"].(int); removed == 0 {
+		t.Errorf("expected simplification to remove points, got %v", fc.Features[0].Properties)
+	}
+
+	for _, input := range []ExportGeoJSONInput{
+		{Geometry: "polygon"},
+		{SimplifyMeters: &simplify},
+		{Name: new(string)},
+	} {
+		if _, _, err := server.handleExportGeoJSON(context.Background(), nil, input); err == nil {
+			t.Errorf("expected error for %+v", input)
+		}
+	}
+}
+
+func TestAnalyticsTools_StructuredOutput(t *testing.T) {
+	repo := newMockRepo()
+	seedCommute(repo)
+	server, _ := NewServer(repo)
+	session := connectInMemory(t, server, nil)
+
+	calls := map[string]map[string]any{
+		"distance_between":       {"from": "harper", "to": "41.8781,-87.6298"},
+		"get_positions_in_range": {"name": "harper", "from": "2024-12-14T08:00:00Z", "to": "2024-12-14T09:00:00Z"},
+		"summarize_day":          {"name": "harper", "date": "2024-12-14", "timezone": "UTC"},
+		"export_geojson":         {"name": "harper", "geometry": "line"},
+	}
+	for name, args := range calls {
+		result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: args})
+		if err != nil {
+			t.Fatalf("%s failed: %v", name, err)
+		}
+		if result.IsError || result.StructuredContent == nil {
+			t.Errorf("%s: expected structured output, got %+v", name, result.Content)
+		}
+	}
+}
+
+func TestToolAnnotations(t *testing.T) {
+	server, _ := NewServer(newMockRepo())
+	session := connectInMemory(t, server, nil)
+
+	result, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	if len(result.Tools) != len(server.toolNames()) {
+		t.Errorf("expected %d tools, got %d", len(server.toolNames()), len(result.Tools))
+	}
+	for _, tool := range result.Tools {
+		ann := tool.Annotations
+		if ann == nil {
+			t.Errorf("%s: expected annotations", tool.Name)
+			continue
+		}
+		switch tool.Name {
+		case "add_position":
+			if ann.ReadOnlyHint || ann.DestructiveHint == nil || *ann.DestructiveHint {
+				t.Errorf("add_position: expected a non-destructive write, got %+v", ann)
+			}
+		case "remove_item":
+			if ann.ReadOnlyHint || ann.DestructiveHint == nil || !*ann.DestructiveHint {
+				t.Errorf("remove_item: expected a destructive write, got %+v", ann)
+			}
+		default:
+			if !ann.ReadOnlyHint {
+				t.Errorf("%s: expected readOnlyHint", tool.Name)
+			}
+		}
+	}
+}
+
+func TestSetPermissions_ReadOnly(t *testing.T) {
+	repo := seedResourceRepo()
+	server, _ := NewServer(repo)
+	if err := server.SetPermissions(config.MCPPermissions{ReadOnly: true}); err != nil {
+		t.Fatalf("SetPermissions failed: %v", err)
+	}
+
+	for _, name := range server.toolNames() {
+		if name == "add_position" || name == "remove_item" {
+			t.Errorf("expected %s to be removed", name)
+		}
+	}
+
+	session := connectInMemory(t, server, nil)
+	result, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	for _, tool := range result.Tools {
+		if !tool.Annotations.ReadOnlyHint {
+			t.Errorf("expected only read-only tools, got %s", tool.Name)
+		}
+	}
+	if _, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "remove_item",
+		Arguments: map[string]any{"name": "harper"},
+	}); err == nil {
+		t.Error("expected remove_item to be unknown")
+	}
+	if result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "get_current",
+		Arguments: map[string]any{"name": "harper"},
+	}); err != nil || result.IsError {
+		t.Errorf("expected get_current to work, got %v %+v", err, result)
+	}
+
+	// Writes that bypass the tool list still fail
+	_, _, err = server.handleRemoveItem(context.Background(), nil, RemoveItemInput{Name: "harper"})
+	if !errors.Is(err, storage.ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly, got %v", err)
+	}
+	_, _, err = server.handleAddPosition(context.Background(), nil, AddPositionInput{Name: "harper", Latitude: 41.9, Longitude: -87.6})
+	if !errors.Is(err, storage.ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly, got %v", err)
+	}
+	if _, err := repo.GetItemByName("harper"); err != nil {
+		t.Errorf("expected harper to survive, got %v", err)
+	}
+}
+
+func TestSetPermissions_Allowlist(t *testing.T) {
+	server, _ := NewServer(seedResourceRepo())
+	if err := server.SetPermissions(config.MCPPermissions{Tools: []string{"list_items", "get_current", "add_position"}}); err != nil {
+		t.Fatalf("SetPermissions failed: %v", err)
+	}
+
+	got := server.toolNames()
+	want := []string{"add_position", "get_current", "list_items"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("expected tools %v, got %v", want, got)
+	}
+
+	session := connectInMemory(t, server, nil)
+	result, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	if len(result.Tools) != len(want) {
+		t.Errorf("expected %d listed tools, got %d", len(want), len(result.Tools))
+	}
+
+	// Allowed writes still work without read-only
+	if result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "add_position",
+		Arguments: map[string]any{"name": "car", "latitude": 41.9, "longitude": -87.6},
+	}); err != nil || result.IsError {
+		t.Errorf("expected add_position to work, got %v %+v", err, result)
+	}
+
+	// Resources are unaffected
+	if _, err := session.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "position://items/harper"}); err != nil {
+		t.Errorf("expected item resource to stay readable, got %v", err)
+	}
+}
+
+func TestSetPermissions_Errors(t *testing.T) {
+	tests := map[string]config.MCPPermissions{
+		"unknown tool":              {Tools: []string{"list_items", "drop_everything"}},
+		"write tool when read-only": {ReadOnly: true, Tools: []string{"list_items", "remove_item"}},
+	}
+	for name, perms := range tests {
+		t.Run(name, func(t *testing.T) {
+			server, _ := NewServer(newMockRepo())
+			if err := server.SetPermissions(perms); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
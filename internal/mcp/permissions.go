@@ -0,0 +1,73 @@
+// ABOUTME: MCP tool annotations and permission modes
+// ABOUTME: Hides write tools for read-only servers and limits agents to an allowlist of tools
+
+package mcp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/harper/position/internal/config"
+	"github.com/harper/position/internal/storage"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// addTool registers a tool and remembers its definition so permissions can
+// be checked against its annotations.
+func addTool[In, Out any](s *Server, t *mcp.Tool, h mcp.ToolHandlerFor[In, Out]) {
+	mcp.AddTool(s.mcp, t, h)
+	s.tools[t.Name] = t
+}
+
+// queryAnnotations marks a tool that only reads the store.
+func queryAnnotations() *mcp.ToolAnnotations {
+	return &mcp.ToolAnnotations{ReadOnlyHint: true, OpenWorldHint: boolPtr(false)}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// SetPermissions removes the tools p does not allow and, for a read-only
+// server, puts the repository behind storage.ReadOnly. Call it before
+// serving; permissions can only be narrowed.
+func (s *Server) SetPermissions(p config.MCPPermissions) error {
+	allowed := make(map[string]bool, len(p.Tools))
+	for _, name := range p.Tools {
+		t, ok := s.tools[name]
+		if !ok {
+			return fmt.Errorf("unknown tool %q (available: %s)", name, strings.Join(s.toolNames(), ", "))
+		}
+		if p.ReadOnly && !t.Annotations.ReadOnlyHint {
+			return fmt.Errorf("tool %q writes, but the server is read-only", name)
+		}
+		allowed[name] = true
+	}
+
+	var removed []string
+	for name, t := range s.tools {
+		if (len(allowed) > 0 && !allowed[name]) || (p.ReadOnly && !t.Annotations.ReadOnlyHint) {
+			removed = append(removed, name)
+		}
+	}
+	s.mcp.RemoveTools(removed...)
+	for _, name := range removed {
+		delete(s.tools, name)
+	}
+
+	if p.ReadOnly {
+		s.repo = storage.ReadOnly(s.repo)
+	}
+	return nil
+}
+
+// toolNames returns the names of the tools agents can call, sorted.
+func (s *Server) toolNames() []string {
+	names := make([]string, 0, len(s.tools))
+	for name := range s.tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -1,5 +1,5 @@
 // ABOUTME: MCP resource definitions
-// ABOUTME: Provides read-only views for AI agents
+// ABOUTME: Provides read-only views for AI agents: all items, one item, its timeline, and its track as GeoJSON
 
 package mcp
 
@@ -7,17 +7,56 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/harper/position/internal/geojson"
+	"github.com/harper/position/internal/models"
+	"github.com/harper/position/internal/storage"
+	"github.com/harper/position/internal/track"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+const (
+	itemsURI      = "position://items"
+	geoJSONMIME   = "application/geo+json"
+	timelinePath  = "timeline"
+	trackFileName = "track.geojson"
+)
+
 func (s *Server) registerResources() {
 	s.mcp.AddResource(&mcp.Resource{
 		Name:        "position://items",
 		Description: "All tracked items with their current positions",
-		URI:         "position://items",
+		URI:         itemsURI,
 		MIMEType:    "application/json",
 	}, s.handleItemsResource)
+
+	s.mcp.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "item",
+		Description: "One tracked item with its current position",
+		URITemplate: "position://items/{name}",
+		MIMEType:    "application/json",
+	}, s.handleItemResource)
+
+	s.mcp.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name: "item-timeline",
+		Description: "An item's position history, newest first. Narrow it with since (e.g. 24h, 7d, 2w), " +
+			"from/to (RFC3339), and clean=true to drop suspect positions.",
+		URITemplate: "position://items/{name}/timeline{?since,from,to,clean}",
+		MIMEType:    "application/json",
+	}, s.handleItemTimelineResource)
+
+	s.mcp.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name: "item-track",
+		Description: "An item's track as GeoJSON: a LineString through its positions plus a timestamped Point per position. " +
+			"Takes the same since, from, to, and clean parameters as the timeline.",
+		URITemplate: "position://items/{name}/track.geojson{?since,from,to,clean}",
+		MIMEType:    geoJSONMIME,
+	}, s.handleItemTrackResource)
 }
 
 func (s *Server) handleItemsResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
@@ -32,13 +71,8 @@ func (s *Server) handleItemsResource(ctx context.Context, req *mcp.ReadResourceR
 
 		pos, err := s.repo.GetCurrentPosition(item.ID)
 		if err == nil {
-			itemOutputs[i].CurrentPosition = &PositionOutput{
-				ItemName:   item.Name,
-				Latitude:   pos.Latitude,
-				Longitude:  pos.Longitude,
-				Label:      pos.Label,
-				RecordedAt: pos.RecordedAt,
-			}
+			current := newPositionOutput(item.Name, pos)
+			itemOutputs[i].CurrentPosition = &current
 		}
 	}
 
@@ -52,10 +86,194 @@ func (s *Server) handleItemsResource(ctx context.Context, req *mcp.ReadResourceR
 	return &mcp.ReadResourceResult{
 		Contents: []*mcp.ResourceContents{
 			{
-				URI:      "position://items",
+				URI:      itemsURI,
 				MIMEType: "application/json",
 				Text:     string(jsonBytes),
 			},
 		},
 	}, nil
 }
+
+func (s *Server) handleItemResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	item, _, err := s.itemForURI(uri, "")
+	if err != nil {
+		return nil, err
+	}
+
+	output := ItemOutput{Name: item.Name}
+	if pos, err := s.repo.GetCurrentPosition(item.ID); err == nil {
+		current := newPositionOutput(item.Name, pos)
+		output.CurrentPosition = &current
+	}
+
+	jsonBytes, _ := json.MarshalIndent(output, "", "  ") //nolint:errchkjson // output is always serializable
+	return resourceResult(uri, "application/json", jsonBytes), nil
+}
+
+func (s *Server) handleItemTimelineResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	item, query, err := s.itemForURI(uri, timelinePath)
+	if err != nil {
+		return nil, err
+	}
+	positions, err := s.windowedTimeline(item, query)
+	if err != nil {
+		return nil, err
+	}
+
+	posOutputs := make([]PositionOutput, len(positions))
+	for i, pos := range positions {
+		posOutputs[i] = newPositionOutput(item.Name, pos)
+	}
+	output := TimelineOutput{
+		ItemName:  item.Name,
+		Positions: posOutputs,
+		Count:     len(posOutputs),
+	}
+
+	jsonBytes, _ := json.MarshalIndent(output, "", "  ") //nolint:errchkjson // output is always serializable
+	return resourceResult(uri, "application/json", jsonBytes), nil
+}
+
+func (s *Server) handleItemTrackResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	item, query, err := s.itemForURI(uri, trackFileName)
+	if err != nil {
+		return nil, err
+	}
+	positions, err := s.windowedTimeline(item, query)
+	if err != nil {
+		return nil, err
+	}
+
+	nameResolver := func(string) string { return item.Name }
+	fc := geojson.ToLineFeatureCollection(positions, nameResolver, track.Reduction{})
+	fc.Features = append(fc.Features, geojson.ToPointsFeatureCollection(positions, nameResolver).Features...)
+
+	jsonBytes, err := fc.ToJSONIndent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode GeoJSON: %w", err)
+	}
+	return resourceResult(uri, geoJSONMIME, jsonBytes), nil
+}
+
+// itemForURI resolves the item named in a position://items/{name}[/suffix]
+// URI and returns the URI's query parameters. Unknown items are reported as
+// resource-not-found.
+func (s *Server) itemForURI(uri, suffix string) (*models.Item, url.Values, error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "position" || u.Host != "items" {
+		return nil, nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	// Split the escaped path so names containing an encoded "/" survive
+	segments := strings.Split(strings.TrimPrefix(u.EscapedPath(), "/"), "/")
+	want := 1
+	if suffix != "" {
+		want = 2
+	}
+	if len(segments) != want || (suffix != "" && segments[1] != suffix) {
+		return nil, nil, mcp.ResourceNotFoundError(uri)
+	}
+	name, err := url.PathUnescape(segments[0])
+	if err != nil || models.ValidateName(name) != nil {
+		return nil, nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	item, err := s.repo.GetItemByName(name)
+	if err != nil {
+		return nil, nil, mcp.ResourceNotFoundError(uri)
+	}
+	return item, u.Query(), nil
+}
+
+// windowedTimeline returns the item's positions, newest first, limited by
+// the since, from, to, and clean query parameters.
+func (s *Server) windowedTimeline(item *models.Item, query url.Values) ([]*models.Position, error) {
+	from, to, err := parseTimeWindow(optionalParam(query, "from"), optionalParam(query, "to"))
+	if err != nil {
+		return nil, err
+	}
+	if v := query.Get("since"); v != "" {
+		d, err := parseSince(v)
+		if err != nil {
+			return nil, err
+		}
+		// The later of since and from wins
+		if start := time.Now().Add(-d); start.After(from) {
+			from = start
+		}
+	}
+
+	clean := false
+	if v := query.Get("clean"); v != "" {
+		if clean, err = strconv.ParseBool(v); err != nil {
+			return nil, fmt.Errorf("invalid clean value %q", v)
+		}
+	}
+
+	timeline, err := s.repo.GetTimeline(item.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get timeline: %w", err)
+	}
+	if clean {
+		timeline = storage.Clean(timeline)
+	}
+
+	var positions []*models.Position
+	for _, pos := range timeline {
+		if (from.IsZero() || !pos.RecordedAt.Before(from)) && (to.IsZero() || !pos.RecordedAt.After(to)) {
+			positions = append(positions, pos)
+		}
+	}
+	return positions, nil
+}
+
+// optionalParam returns a pointer to the query value, or nil when it is absent.
+func optionalParam(query url.Values, key string) *string {
+	if v := query.Get(key); v != "" {
+		return &v
+	}
+	return nil
+}
+
+var sinceRegex = regexp.MustCompile(`^(\d+)([hdwm])$`)
+
+// parseSince parses a relative window like the CLI's --since: hours (h),
+// days (d), weeks (w), or 30-day months (m).
+func parseSince(s string) (time.Duration, error) {
+	matches := sinceRegex.FindStringSubmatch(s)
+	if matches == nil {
+		return 0, fmt.Errorf("invalid since value %q (use e.g. 24h, 7d, 1w)", s)
+	}
+	num, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid since value %q: %w", s, err)
+	}
+
+	day := 24 * time.Hour
+	switch matches[2] {
+	case "h":
+		return time.Duration(num) * time.Hour, nil
+	case "d":
+		return time.Duration(num) * day, nil
+	case "w":
+		return time.Duration(num) * 7 * day, nil
+	default:
+		return time.Duration(num) * 30 * day, nil
+	}
+}
+
+// resourceResult wraps a single text resource.
+func resourceResult(uri, mimeType string, data []byte) *mcp.ReadResourceResult {
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      uri,
+				MIMEType: mimeType,
+				Text:     string(data),
+			},
+		},
+	}
+}
@@ -6,6 +6,7 @@ package mcp
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/harper/position/internal/storage"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -13,8 +14,12 @@ import (
 
 // Server wraps MCP server with SQLite repository.
 type Server struct {
-	mcp  *mcp.Server
-	repo storage.Repository
+	mcp   *mcp.Server
+	repo  storage.Repository
+	tools map[string]*mcp.Tool
+
+	subs         *subscriptions
+	pollInterval time.Duration
 }
 
 // NewServer creates MCP server with all capabilities.
@@ -23,26 +28,33 @@ func NewServer(repo storage.Repository) (*Server, error) {
 		return nil, fmt.Errorf("repository is required")
 	}
 
-	mcpServer := mcp.NewServer(
+	s := &Server{
+		repo:         repo,
+		tools:        make(map[string]*mcp.Tool),
+		subs:         newSubscriptions(),
+		pollInterval: DefaultPollInterval,
+	}
+
+	s.mcp = mcp.NewServer(
 		&mcp.Implementation{
 			Name:    "position",
 			Version: "1.0.0",
 		},
-		nil,
+		&mcp.ServerOptions{
+			SubscribeHandler:   s.handleSubscribe,
+			UnsubscribeHandler: s.handleUnsubscribe,
+		},
 	)
 
-	s := &Server{
-		mcp:  mcpServer,
-		repo: repo,
-	}
-
 	s.registerTools()
 	s.registerResources()
 
 	return s, nil
 }
 
-// Serve starts the MCP server in stdio mode.
+// Serve starts the MCP server in stdio mode, watching for changes to
+// subscribed resources while it runs.
 func (s *Server) Serve(ctx context.Context) error {
+	go s.WatchChanges(ctx)
 	return s.mcp.Run(ctx, &mcp.StdioTransport{})
 }
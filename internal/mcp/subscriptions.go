@@ -0,0 +1,155 @@
+// ABOUTME: MCP resource subscriptions and change notifications
+// ABOUTME: Polls subscribed resources so writes from any process reach subscribers as resources/updated
+
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DefaultPollInterval is how often subscribed resources are re-read to catch
+// changes made by other processes sharing the store.
+const DefaultPollInterval = 2 * time.Second
+
+// missingFingerprint marks a resource that could not be read, such as an
+// item that has been removed.
+var missingFingerprint = [sha256.Size]byte{}
+
+// subscriptions tracks which resources clients are watching and what each
+// looked like when last checked.
+type subscriptions struct {
+	mu      sync.Mutex
+	counts  map[string]int
+	content map[string][sha256.Size]byte
+
+	// check serializes change checks so a tool write and a poll tick
+	// can't both notify for the same change.
+	check sync.Mutex
+}
+
+func newSubscriptions() *subscriptions {
+	return &subscriptions{
+		counts:  make(map[string]int),
+		content: make(map[string][sha256.Size]byte),
+	}
+}
+
+// handleSubscribe accepts subscriptions to the items list and item
+// resources, and records the resource's current content as the baseline.
+func (s *Server) handleSubscribe(ctx context.Context, req *mcp.SubscribeRequest) error {
+	uri := req.Params.URI
+	if s.resourceHandler(uri) == nil {
+		return mcp.ResourceNotFoundError(uri)
+	}
+	fingerprint := s.fingerprint(ctx, uri)
+
+	s.subs.mu.Lock()
+	defer s.subs.mu.Unlock()
+	if s.subs.counts[uri] == 0 {
+		s.subs.content[uri] = fingerprint
+	}
+	s.subs.counts[uri]++
+	return nil
+}
+
+func (s *Server) handleUnsubscribe(_ context.Context, req *mcp.UnsubscribeRequest) error {
+	uri := req.Params.URI
+
+	s.subs.mu.Lock()
+	defer s.subs.mu.Unlock()
+	if s.subs.counts[uri] <= 1 {
+		delete(s.subs.counts, uri)
+		delete(s.subs.content, uri)
+		return nil
+	}
+	s.subs.counts[uri]--
+	return nil
+}
+
+// WatchChanges re-reads subscribed resources every poll interval and sends
+// notifications/resources/updated for those that changed, until ctx is
+// cancelled. This catches writes from other processes using the same store;
+// writes made through this server's tools are announced immediately.
+func (s *Server) WatchChanges(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.notifyChanges(ctx)
+		}
+	}
+}
+
+// notifyChanges compares every subscribed resource with its last known
+// content and notifies subscribers of the ones that differ.
+func (s *Server) notifyChanges(ctx context.Context) {
+	s.subs.check.Lock()
+	defer s.subs.check.Unlock()
+
+	s.subs.mu.Lock()
+	uris := make([]string, 0, len(s.subs.counts))
+	for uri := range s.subs.counts {
+		uris = append(uris, uri)
+	}
+	s.subs.mu.Unlock()
+
+	for _, uri := range uris {
+		fingerprint := s.fingerprint(ctx, uri)
+
+		s.subs.mu.Lock()
+		previous, subscribed := s.subs.content[uri]
+		if subscribed {
+			s.subs.content[uri] = fingerprint
+		}
+		s.subs.mu.Unlock()
+
+		if subscribed && fingerprint != previous {
+			_ = s.mcp.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: uri})
+		}
+	}
+}
+
+// fingerprint reads a resource and hashes its contents.
+func (s *Server) fingerprint(ctx context.Context, uri string) [sha256.Size]byte {
+	handler := s.resourceHandler(uri)
+	if handler == nil {
+		return missingFingerprint
+	}
+	result, err := handler(ctx, &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: uri}})
+	if err != nil || len(result.Contents) == 0 {
+		return missingFingerprint
+	}
+	return sha256.Sum256([]byte(result.Contents[0].Text))
+}
+
+// resourceHandler returns the handler serving uri, or nil if uri is not one
+// of the server's resources.
+func (s *Server) resourceHandler(uri string) mcp.ResourceHandler {
+	if uri == itemsURI {
+		return s.handleItemsResource
+	}
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "position" || u.Host != "items" {
+		return nil
+	}
+	segments := strings.Split(strings.TrimPrefix(u.EscapedPath(), "/"), "/")
+	switch {
+	case len(segments) == 1 && segments[0] != "":
+		return s.handleItemResource
+	case len(segments) == 2 && segments[1] == timelinePath:
+		return s.handleItemTimelineResource
+	case len(segments) == 2 && segments[1] == trackFileName:
+		return s.handleItemTrackResource
+	}
+	return nil
+}
@@ -8,10 +8,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/harper/position/internal/geo"
 	"github.com/harper/position/internal/models"
 	"github.com/harper/position/internal/storage"
+	"github.com/harper/position/internal/track"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -21,6 +25,13 @@ func (s *Server) registerTools() {
 	s.registerGetTimelineTool()
 	s.registerListItemsTool()
 	s.registerRemoveItemTool()
+	s.registerFindNearbyTool()
+	s.registerGetVisitsTool()
+	s.registerGetPositionAtTool()
+	s.registerDistanceBetweenTool()
+	s.registerGetPositionsInRangeTool()
+	s.registerSummarizeDayTool()
+	s.registerExportGeoJSONTool()
 }
 
 // AddPositionInput defines input for add_position tool.
@@ -30,6 +41,7 @@ type AddPositionInput struct {
 	Longitude float64 `json:"longitude"`
 	Label     *string `json:"label,omitempty"`
 	At        *string `json:"at,omitempty"`
+	models.Telemetry
 }
 
 // PositionOutput defines output for position tools.
@@ -38,11 +50,28 @@ type PositionOutput struct {
 	Latitude   float64   `json:"latitude"`
 	Longitude  float64   `json:"longitude"`
 	Label      *string   `json:"label,omitempty"`
+	Locality   *string   `json:"locality,omitempty"`
 	RecordedAt time.Time `json:"recorded_at"`
+	models.Telemetry
+	Suspect bool `json:"suspect,omitempty"`
+}
+
+// newPositionOutput converts a stored position for output under the item's name.
+func newPositionOutput(itemName string, pos *models.Position) PositionOutput {
+	return PositionOutput{
+		ItemName:   itemName,
+		Latitude:   pos.Latitude,
+		Longitude:  pos.Longitude,
+		Label:      pos.Label,
+		Locality:   pos.Locality,
+		RecordedAt: pos.RecordedAt,
+		Telemetry:  pos.Telemetry,
+		Suspect:    pos.Suspect,
+	}
 }
 
 func (s *Server) registerAddPositionTool() {
-	mcp.AddTool(s.mcp, &mcp.Tool{
+	addTool(s, &mcp.Tool{
 		Name:        "add_position",
 		Description: "Add a position for an item (creates item if needed). Use this to track where something or someone is located.",
 		InputSchema: map[string]interface{}{
@@ -72,13 +101,48 @@ func (s *Server) registerAddPositionTool() {
 					"type":        "string",
 					"description": "Optional recorded time in RFC3339 format",
 				},
+				"accuracy": map[string]interface{}{
+					"type":        "number",
+					"description": "Optional horizontal accuracy radius in meters",
+					"minimum":     0,
+				},
+				"altitude": map[string]interface{}{
+					"type":        "number",
+					"description": "Optional altitude in meters above sea level",
+				},
+				"speed": map[string]interface{}{
+					"type":        "number",
+					"description": "Optional speed in meters per second",
+					"minimum":     0,
+				},
+				"heading": map[string]interface{}{
+					"type":             "number",
+					"description":      "Optional heading in degrees clockwise from north (0 to 360)",
+					"minimum":          0,
+					"exclusiveMaximum": 360,
+				},
+				"battery": map[string]interface{}{
+					"type":        "integer",
+					"description": "Optional device battery percentage (0 to 100)",
+					"minimum":     0,
+					"maximum":     100,
+				},
+				"source": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional origin of the fix (e.g., 'gps', 'owntracks')",
+				},
 			},
 			"required": []string{"name", "latitude", "longitude"},
 		},
+		Annotations: &mcp.ToolAnnotations{
+			// Adds a position; dedup may skip repeats, but nothing is overwritten
+			DestructiveHint: boolPtr(false),
+			OpenWorldHint:   boolPtr(false),
+		},
 	}, s.handleAddPosition)
 }
 
-func (s *Server) handleAddPosition(_ context.Context, req *mcp.CallToolRequest, input AddPositionInput) (*mcp.CallToolResult, PositionOutput, error) {
+func (s *Server) handleAddPosition(ctx context.Context, req *mcp.CallToolRequest, input AddPositionInput) (*mcp.CallToolResult, PositionOutput, error) {
 	// Validate name first
 	if err := models.ValidateName(input.Name); err != nil {
 		return nil, PositionOutput{}, err
@@ -87,6 +151,9 @@ func (s *Server) handleAddPosition(_ context.Context, req *mcp.CallToolRequest,
 	if err := models.ValidateCoordinates(input.Latitude, input.Longitude); err != nil {
 		return nil, PositionOutput{}, err
 	}
+	if err := input.Telemetry.Validate(); err != nil {
+		return nil, PositionOutput{}, err
+	}
 
 	item, err := s.repo.GetItemByName(input.Name)
 	if err != nil {
@@ -110,18 +177,14 @@ func (s *Server) handleAddPosition(_ context.Context, req *mcp.CallToolRequest,
 	} else {
 		pos = models.NewPosition(item.ID, input.Latitude, input.Longitude, input.Label)
 	}
+	pos.Telemetry = input.Telemetry
 
 	if err := s.repo.CreatePosition(pos); err != nil {
 		return nil, PositionOutput{}, fmt.Errorf("failed to create position: %w", err)
 	}
+	s.notifyChanges(ctx)
 
-	output := PositionOutput{
-		ItemName:   input.Name,
-		Latitude:   pos.Latitude,
-		Longitude:  pos.Longitude,
-		Label:      pos.Label,
-		RecordedAt: pos.RecordedAt,
-	}
+	output := newPositionOutput(input.Name, pos)
 
 	jsonBytes, _ := json.MarshalIndent(output, "", "  ") //nolint:errchkjson // output is always serializable
 	return &mcp.CallToolResult{
@@ -131,11 +194,25 @@ func (s *Server) handleAddPosition(_ context.Context, req *mcp.CallToolRequest,
 
 // GetCurrentInput defines input for get_current tool.
 type GetCurrentInput struct {
-	Name string `json:"name"`
+	Name  string `json:"name"`
+	Clean bool   `json:"clean,omitempty"`
+}
+
+// cleanProperty is the input schema for the clean option shared by read tools.
+var cleanProperty = map[string]interface{}{
+	"type":        "boolean",
+	"description": "Exclude positions flagged suspect by the outlier filter (default false)",
+}
+
+// smoothProperty is the schema for the smooth option shared by tools that
+// measure distance.
+var smoothProperty = map[string]interface{}{
+	"type":        "boolean",
+	"description": "Measure along a Kalman-smoothed track to remove GPS zig-zags (default false)",
 }
 
 func (s *Server) registerGetCurrentTool() {
-	mcp.AddTool(s.mcp, &mcp.Tool{
+	addTool(s, &mcp.Tool{
 		Name:        "get_current",
 		Description: "Get the current (most recent) position of an item.",
 		InputSchema: map[string]interface{}{
@@ -145,9 +222,11 @@ func (s *Server) registerGetCurrentTool() {
 					"type":        "string",
 					"description": "Name of the item",
 				},
+				"clean": cleanProperty,
 			},
 			"required": []string{"name"},
 		},
+		Annotations: queryAnnotations(),
 	}, s.handleGetCurrent)
 }
 
@@ -161,18 +240,12 @@ func (s *Server) handleGetCurrent(_ context.Context, req *mcp.CallToolRequest, i
 		return nil, PositionOutput{}, fmt.Errorf("item '%s' not found", input.Name)
 	}
 
-	pos, err := s.repo.GetCurrentPosition(item.ID)
+	pos, err := s.currentPosition(item.ID, input.Clean)
 	if err != nil {
 		return nil, PositionOutput{}, fmt.Errorf("no position found for '%s'", input.Name)
 	}
 
-	output := PositionOutput{
-		ItemName:   input.Name,
-		Latitude:   pos.Latitude,
-		Longitude:  pos.Longitude,
-		Label:      pos.Label,
-		RecordedAt: pos.RecordedAt,
-	}
+	output := newPositionOutput(input.Name, pos)
 
 	jsonBytes, _ := json.MarshalIndent(output, "", "  ") //nolint:errchkjson // output is always serializable
 	return &mcp.CallToolResult{
@@ -180,6 +253,28 @@ func (s *Server) handleGetCurrent(_ context.Context, req *mcp.CallToolRequest, i
 	}, output, nil
 }
 
+// currentPosition returns the item's most recent position, or with clean its
+// most recent one not flagged suspect.
+func (s *Server) currentPosition(itemID uuid.UUID, clean bool) (*models.Position, error) {
+	if !clean {
+		return s.repo.GetCurrentPosition(itemID)
+	}
+	timeline, err := s.repo.GetTimeline(itemID)
+	if err != nil {
+		return nil, err
+	}
+	var latest *models.Position
+	for _, pos := range storage.Clean(timeline) {
+		if latest == nil || pos.RecordedAt.After(latest.RecordedAt) {
+			latest = pos
+		}
+	}
+	if latest == nil {
+		return nil, storage.ErrNotFound
+	}
+	return latest, nil
+}
+
 // TimelineOutput defines output for timeline tool.
 type TimelineOutput struct {
 	ItemName  string           `json:"item_name"`
@@ -188,7 +283,7 @@ type TimelineOutput struct {
 }
 
 func (s *Server) registerGetTimelineTool() {
-	mcp.AddTool(s.mcp, &mcp.Tool{
+	addTool(s, &mcp.Tool{
 		Name:        "get_timeline",
 		Description: "Get the position history for an item, newest first.",
 		InputSchema: map[string]interface{}{
@@ -198,9 +293,11 @@ func (s *Server) registerGetTimelineTool() {
 					"type":        "string",
 					"description": "Name of the item",
 				},
+				"clean": cleanProperty,
 			},
 			"required": []string{"name"},
 		},
+		Annotations: queryAnnotations(),
 	}, s.handleGetTimeline)
 }
 
@@ -218,16 +315,13 @@ func (s *Server) handleGetTimeline(_ context.Context, req *mcp.CallToolRequest,
 	if err != nil {
 		return nil, TimelineOutput{}, fmt.Errorf("failed to get timeline: %w", err)
 	}
+	if input.Clean {
+		positions = storage.Clean(positions)
+	}
 
 	posOutputs := make([]PositionOutput, len(positions))
 	for i, pos := range positions {
-		posOutputs[i] = PositionOutput{
-			ItemName:   input.Name,
-			Latitude:   pos.Latitude,
-			Longitude:  pos.Longitude,
-			Label:      pos.Label,
-			RecordedAt: pos.RecordedAt,
-		}
+		posOutputs[i] = newPositionOutput(input.Name, pos)
 	}
 
 	output := TimelineOutput{
@@ -258,12 +352,13 @@ type ListItemsOutput struct {
 type ListItemsInput struct{}
 
 func (s *Server) registerListItemsTool() {
-	mcp.AddTool(s.mcp, &mcp.Tool{
+	addTool(s, &mcp.Tool{
 		Name:        "list_items",
 		Description: "List all tracked items with their current positions.",
 		InputSchema: map[string]interface{}{
 			"type": "object",
 		},
+		Annotations: queryAnnotations(),
 	}, s.handleListItems)
 }
 
@@ -279,13 +374,8 @@ func (s *Server) handleListItems(_ context.Context, req *mcp.CallToolRequest, in
 
 		pos, err := s.repo.GetCurrentPosition(item.ID)
 		if err == nil {
-			itemOutputs[i].CurrentPosition = &PositionOutput{
-				ItemName:   item.Name,
-				Latitude:   pos.Latitude,
-				Longitude:  pos.Longitude,
-				Label:      pos.Label,
-				RecordedAt: pos.RecordedAt,
-			}
+			current := newPositionOutput(item.Name, pos)
+			itemOutputs[i].CurrentPosition = &current
 		}
 	}
 
@@ -312,7 +402,7 @@ type RemoveItemOutput struct {
 }
 
 func (s *Server) registerRemoveItemTool() {
-	mcp.AddTool(s.mcp, &mcp.Tool{
+	addTool(s, &mcp.Tool{
 		Name:        "remove_item",
 		Description: "Remove an item and all its position history. This cannot be undone.",
 		InputSchema: map[string]interface{}{
@@ -325,10 +415,15 @@ func (s *Server) registerRemoveItemTool() {
 			},
 			"required": []string{"name"},
 		},
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: boolPtr(true),
+			IdempotentHint:  true,
+			OpenWorldHint:   boolPtr(false),
+		},
 	}, s.handleRemoveItem)
 }
 
-func (s *Server) handleRemoveItem(_ context.Context, req *mcp.CallToolRequest, input RemoveItemInput) (*mcp.CallToolResult, RemoveItemOutput, error) {
+func (s *Server) handleRemoveItem(ctx context.Context, req *mcp.CallToolRequest, input RemoveItemInput) (*mcp.CallToolResult, RemoveItemOutput, error) {
 	if err := models.ValidateName(input.Name); err != nil {
 		return nil, RemoveItemOutput{}, err
 	}
@@ -341,6 +436,7 @@ func (s *Server) handleRemoveItem(_ context.Context, req *mcp.CallToolRequest, i
 	if err := s.repo.DeleteItem(item.ID); err != nil {
 		return nil, RemoveItemOutput{}, fmt.Errorf("failed to remove item: %w", err)
 	}
+	s.notifyChanges(ctx)
 
 	output := RemoveItemOutput{
 		Success: true,
@@ -352,3 +448,427 @@ func (s *Server) handleRemoveItem(_ context.Context, req *mcp.CallToolRequest, i
 		Content: []mcp.Content{&mcp.TextContent{Text: string(jsonBytes)}},
 	}, output, nil
 }
+
+// defaultNearbyRadius is used when find_nearby is called without a radius.
+const defaultNearbyRadius = 500.0
+
+// FindNearbyInput defines input for find_nearby tool.
+type FindNearbyInput struct {
+	Latitude     float64  `json:"latitude"`
+	Longitude    float64  `json:"longitude"`
+	RadiusMeters *float64 `json:"radius_meters,omitempty"`
+	From         *string  `json:"from,omitempty"`
+	To           *string  `json:"to,omitempty"`
+	Clean        bool     `json:"clean,omitempty"`
+}
+
+// NearbyPositionOutput is a position with its distance from the search center.
+type NearbyPositionOutput struct {
+	PositionOutput
+	DistanceMeters float64 `json:"distance_meters"`
+}
+
+// NearbyItemOutput summarizes one item's positions within the search radius.
+type NearbyItemOutput struct {
+	Name          string    `json:"name"`
+	Count         int       `json:"count"`
+	FirstSeen     time.Time `json:"first_seen"`
+	LastSeen      time.Time `json:"last_seen"`
+	ClosestMeters float64   `json:"closest_meters"`
+}
+
+// FindNearbyOutput defines output for find_nearby tool.
+type FindNearbyOutput struct {
+	Latitude     float64                `json:"latitude"`
+	Longitude    float64                `json:"longitude"`
+	RadiusMeters float64                `json:"radius_meters"`
+	Items        []NearbyItemOutput     `json:"items"`
+	Positions    []NearbyPositionOutput `json:"positions"`
+	Count        int                    `json:"count"`
+}
+
+func (s *Server) registerFindNearbyTool() {
+	addTool(s, &mcp.Tool{
+		Name: "find_nearby",
+		Description: "Find which items have been within a radius of a point, optionally within a time window. " +
+			"Returns a per-item summary and the matching positions (newest first) with distances.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"latitude": map[string]interface{}{
+					"type":        "number",
+					"description": "Latitude of the search center (-90 to 90)",
+					"minimum":     -90,
+					"maximum":     90,
+				},
+				"longitude": map[string]interface{}{
+					"type":        "number",
+					"description": "Longitude of the search center (-180 to 180)",
+					"minimum":     -180,
+					"maximum":     180,
+				},
+				"radius_meters": map[string]interface{}{
+					"type":             "number",
+					"description":      "Search radius in meters (default 500)",
+					"exclusiveMinimum": 0,
+				},
+				"from": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional start of the time window in RFC3339 format",
+				},
+				"to": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional end of the time window in RFC3339 format",
+				},
+				"clean": cleanProperty,
+			},
+			"required": []string{"latitude", "longitude"},
+		},
+		Annotations: queryAnnotations(),
+	}, s.handleFindNearby)
+}
+
+func (s *Server) handleFindNearby(_ context.Context, req *mcp.CallToolRequest, input FindNearbyInput) (*mcp.CallToolResult, FindNearbyOutput, error) {
+	if err := models.ValidateCoordinates(input.Latitude, input.Longitude); err != nil {
+		return nil, FindNearbyOutput{}, err
+	}
+
+	radius := defaultNearbyRadius
+	if input.RadiusMeters != nil {
+		radius = *input.RadiusMeters
+	}
+	if radius <= 0 {
+		return nil, FindNearbyOutput{}, fmt.Errorf("radius_meters must be greater than 0")
+	}
+
+	from, to, err := parseTimeWindow(input.From, input.To)
+	if err != nil {
+		return nil, FindNearbyOutput{}, err
+	}
+
+	positions, err := s.repo.GetPositionsNear(input.Latitude, input.Longitude, radius, from, to)
+	if err != nil {
+		return nil, FindNearbyOutput{}, fmt.Errorf("failed to find nearby positions: %w", err)
+	}
+	if input.Clean {
+		positions = storage.Clean(positions)
+	}
+
+	itemNames := make(map[string]string)
+	if items, err := s.repo.ListItems(); err == nil {
+		for _, item := range items {
+			itemNames[item.ID.String()] = item.Name
+		}
+	}
+
+	posOutputs := make([]NearbyPositionOutput, len(positions))
+	summaries := make(map[string]*NearbyItemOutput)
+	for i, pos := range positions {
+		name := itemNames[pos.ItemID.String()]
+		distance := geo.Distance(input.Latitude, input.Longitude, pos.Latitude, pos.Longitude)
+		posOutputs[i] = NearbyPositionOutput{
+			PositionOutput: newPositionOutput(name, pos),
+			DistanceMeters: distance,
+		}
+
+		summary, ok := summaries[name]
+		if !ok {
+			summary = &NearbyItemOutput{Name: name, FirstSeen: pos.RecordedAt, LastSeen: pos.RecordedAt, ClosestMeters: distance}
+			summaries[name] = summary
+		}
+		summary.Count++
+		if pos.RecordedAt.Before(summary.FirstSeen) {
+			summary.FirstSeen = pos.RecordedAt
+		}
+		if pos.RecordedAt.After(summary.LastSeen) {
+			summary.LastSeen = pos.RecordedAt
+		}
+		if distance < summary.ClosestMeters {
+			summary.ClosestMeters = distance
+		}
+	}
+
+	itemOutputs := make([]NearbyItemOutput, 0, len(summaries))
+	for _, summary := range summaries {
+		itemOutputs = append(itemOutputs, *summary)
+	}
+	sort.Slice(itemOutputs, func(i, j int) bool {
+		return itemOutputs[i].Name < itemOutputs[j].Name
+	})
+
+	output := FindNearbyOutput{
+		Latitude:     input.Latitude,
+		Longitude:    input.Longitude,
+		RadiusMeters: radius,
+		Items:        itemOutputs,
+		Positions:    posOutputs,
+		Count:        len(posOutputs),
+	}
+
+	jsonBytes, _ := json.MarshalIndent(output, "", "  ") //nolint:errchkjson // output is always serializable
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(jsonBytes)}},
+	}, output, nil
+}
+
+// parseTimeWindow parses optional RFC3339 from/to bounds. Missing bounds are
+// returned as zero times.
+func parseTimeWindow(fromStr, toStr *string) (from, to time.Time, err error) {
+	if fromStr != nil {
+		if from, err = time.Parse(time.RFC3339, *fromStr); err != nil {
+			return from, to, fmt.Errorf("invalid from timestamp: %w", err)
+		}
+	}
+	if toStr != nil {
+		if to, err = time.Parse(time.RFC3339, *toStr); err != nil {
+			return from, to, fmt.Errorf("invalid to timestamp: %w", err)
+		}
+	}
+	return from, to, nil
+}
+
+// GetVisitsInput defines input for get_visits tool.
+type GetVisitsInput struct {
+	Name               string   `json:"name"`
+	From               *string  `json:"from,omitempty"`
+	To                 *string  `json:"to,omitempty"`
+	RadiusMeters       *float64 `json:"radius_meters,omitempty"`
+	MinDurationMinutes *float64 `json:"min_duration_minutes,omitempty"`
+	Clean              bool     `json:"clean,omitempty"`
+}
+
+// VisitOutput defines output for a single visit.
+type VisitOutput struct {
+	Label           string    `json:"label,omitempty"`
+	Latitude        float64   `json:"latitude"`
+	Longitude       float64   `json:"longitude"`
+	Arrived         time.Time `json:"arrived"`
+	Departed        time.Time `json:"departed"`
+	DurationMinutes float64   `json:"duration_minutes"`
+	PositionCount   int       `json:"position_count"`
+}
+
+// GetVisitsOutput defines output for get_visits tool.
+type GetVisitsOutput struct {
+	ItemName string        `json:"item_name"`
+	Visits   []VisitOutput `json:"visits"`
+	Count    int           `json:"count"`
+}
+
+// newVisitOutput converts a visit for output.
+func newVisitOutput(v track.Visit) VisitOutput {
+	return VisitOutput{
+		Label:           v.Label,
+		Latitude:        v.Center.Latitude,
+		Longitude:       v.Center.Longitude,
+		Arrived:         v.Arrived,
+		Departed:        v.Departed,
+		DurationMinutes: v.Duration().Minutes(),
+		PositionCount:   v.Count,
+	}
+}
+
+func (s *Server) registerGetVisitsTool() {
+	addTool(s, &mcp.Tool{
+		Name: "get_visits",
+		Description: "Summarize an item's history as visits: places it stayed, with arrival and departure times, oldest first. " +
+			"Prefer this over get_timeline when asking where something was or how long it stayed.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"name": map[string]interface{}{
+					"type":        "string",
+					"description": "Name of the item",
+				},
+				"from": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional start of the time window in RFC3339 format",
+				},
+				"to": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional end of the time window in RFC3339 format",
+				},
+				"radius_meters": map[string]interface{}{
+					"type":             "number",
+					"description":      "Maximum distance from the visit center in meters (default 100)",
+					"exclusiveMinimum": 0,
+				},
+				"min_duration_minutes": map[string]interface{}{
+					"type":        "number",
+					"description": "Shortest stay counted as a visit, in minutes (default 10)",
+					"minimum":     0,
+				},
+				"clean": cleanProperty,
+			},
+			"required": []string{"name"},
+		},
+		Annotations: queryAnnotations(),
+	}, s.handleGetVisits)
+}
+
+func (s *Server) handleGetVisits(_ context.Context, req *mcp.CallToolRequest, input GetVisitsInput) (*mcp.CallToolResult, GetVisitsOutput, error) {
+	if err := models.ValidateName(input.Name); err != nil {
+		return nil, GetVisitsOutput{}, err
+	}
+
+	opts := track.DefaultVisitOptions()
+	if input.RadiusMeters != nil {
+		if *input.RadiusMeters <= 0 {
+			return nil, GetVisitsOutput{}, fmt.Errorf("radius_meters must be greater than 0")
+		}
+		opts.RadiusMeters = *input.RadiusMeters
+	}
+	if input.MinDurationMinutes != nil {
+		if *input.MinDurationMinutes < 0 {
+			return nil, GetVisitsOutput{}, fmt.Errorf("min_duration_minutes must not be negative")
+		}
+		opts.MinDuration = time.Duration(*input.MinDurationMinutes * float64(time.Minute))
+	}
+
+	from, to, err := parseTimeWindow(input.From, input.To)
+	if err != nil {
+		return nil, GetVisitsOutput{}, err
+	}
+
+	item, err := s.repo.GetItemByName(input.Name)
+	if err != nil {
+		return nil, GetVisitsOutput{}, fmt.Errorf("item '%s' not found", input.Name)
+	}
+
+	timeline, err := s.repo.GetTimeline(item.ID)
+	if err != nil {
+		return nil, GetVisitsOutput{}, fmt.Errorf("failed to get timeline: %w", err)
+	}
+	if input.Clean {
+		timeline = storage.Clean(timeline)
+	}
+
+	var positions []*models.Position
+	for _, pos := range timeline {
+		if (from.IsZero() || !pos.RecordedAt.Before(from)) && (to.IsZero() || !pos.RecordedAt.After(to)) {
+			positions = append(positions, pos)
+		}
+	}
+
+	// A stay still going at the last position lasts to the end of the window
+	opts.Until = to
+	if opts.Until.IsZero() || opts.Until.After(time.Now()) {
+		opts.Until = time.Now()
+	}
+	visits := track.Visits(positions, opts)
+	visitOutputs := make([]VisitOutput, len(visits))
+	for i, v := range visits {
+		visitOutputs[i] = newVisitOutput(v)
+	}
+
+	output := GetVisitsOutput{
+		ItemName: input.Name,
+		Visits:   visitOutputs,
+		Count:    len(visitOutputs),
+	}
+
+	jsonBytes, _ := json.MarshalIndent(output, "", "  ") //nolint:errchkjson // output is always serializable
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(jsonBytes)}},
+	}, output, nil
+}
+
+// GetPositionAtInput defines input for get_position_at tool.
+type GetPositionAtInput struct {
+	Name        string `json:"name"`
+	At          string `json:"at"`
+	Interpolate bool   `json:"interpolate,omitempty"`
+	Clean       bool   `json:"clean,omitempty"`
+}
+
+// GetPositionAtOutput defines output for get_position_at tool.
+type GetPositionAtOutput struct {
+	ItemName     string          `json:"item_name"`
+	At           time.Time       `json:"at"`
+	Latitude     float64         `json:"latitude"`
+	Longitude    float64         `json:"longitude"`
+	Label        *string         `json:"label,omitempty"`
+	Interpolated bool            `json:"interpolated"`
+	GapMinutes   float64         `json:"gap_minutes"`
+	Before       PositionOutput  `json:"before"`
+	After        *PositionOutput `json:"after,omitempty"`
+}
+
+func (s *Server) registerGetPositionAtTool() {
+	addTool(s, &mcp.Tool{
+		Name: "get_position_at",
+		Description: "Get where an item was at a specific time: the latest position recorded at or before it, " +
+			"or with interpolate a great-circle estimate between the surrounding positions. " +
+			"gap_minutes is the confidence: the time between the surrounding positions when interpolated, " +
+			"otherwise how long before the requested time the position was recorded. " +
+			"Prefer this over get_timeline for questions like 'where was the van at 2pm yesterday'.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"name": map[string]interface{}{
+					"type":        "string",
+					"description": "Name of the item",
+				},
+				"at": map[string]interface{}{
+					"type":        "string",
+					"description": "Time to look up in RFC3339 format",
+				},
+				"interpolate": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Estimate the location between the surrounding positions (default false)",
+				},
+				"clean": cleanProperty,
+			},
+			"required": []string{"name", "at"},
+		},
+		Annotations: queryAnnotations(),
+	}, s.handleGetPositionAt)
+}
+
+func (s *Server) handleGetPositionAt(_ context.Context, req *mcp.CallToolRequest, input GetPositionAtInput) (*mcp.CallToolResult, GetPositionAtOutput, error) {
+	if err := models.ValidateName(input.Name); err != nil {
+		return nil, GetPositionAtOutput{}, err
+	}
+	at, err := time.Parse(time.RFC3339, input.At)
+	if err != nil {
+		return nil, GetPositionAtOutput{}, fmt.Errorf("invalid at timestamp: %w", err)
+	}
+
+	item, err := s.repo.GetItemByName(input.Name)
+	if err != nil {
+		return nil, GetPositionAtOutput{}, fmt.Errorf("item '%s' not found", input.Name)
+	}
+
+	timeline, err := s.repo.GetTimeline(item.ID)
+	if err != nil {
+		return nil, GetPositionAtOutput{}, fmt.Errorf("failed to get timeline: %w", err)
+	}
+	if input.Clean {
+		timeline = storage.Clean(timeline)
+	}
+
+	fix, ok := track.At(timeline, at, input.Interpolate)
+	if !ok {
+		return nil, GetPositionAtOutput{}, fmt.Errorf("no position for '%s' at or before %s", input.Name, input.At)
+	}
+
+	output := GetPositionAtOutput{
+		ItemName:     input.Name,
+		At:           fix.At,
+		Latitude:     fix.Latitude,
+		Longitude:    fix.Longitude,
+		Label:        fix.Label,
+		Interpolated: fix.Interpolated,
+		GapMinutes:   fix.Gap.Minutes(),
+		Before:       newPositionOutput(input.Name, fix.Before),
+	}
+	if fix.After != nil {
+		after := newPositionOutput(input.Name, fix.After)
+		output.After = &after
+	}
+
+	jsonBytes, _ := json.MarshalIndent(output, "", "  ") //nolint:errchkjson // output is always serializable
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(jsonBytes)}},
+	}, output, nil
+}
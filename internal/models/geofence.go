@@ -0,0 +1,84 @@
+// ABOUTME: Geofence and geofence event models
+// ABOUTME: Defines named areas and the enter/exit transitions recorded against them
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Coordinate is a latitude/longitude pair.
+type Coordinate struct {
+	Latitude  float64 `json:"latitude" yaml:"latitude"`
+	Longitude float64 `json:"longitude" yaml:"longitude"`
+}
+
+// Geofence is a named area whose enter and exit transitions are recorded.
+type Geofence struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Shape     `yaml:",inline"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewCircleGeofence creates a circular geofence around a center point.
+func NewCircleGeofence(name string, lat, lng, radiusMeters float64) *Geofence {
+	return &Geofence{
+		ID:        uuid.New(),
+		Name:      name,
+		Shape:     CircleShape(lat, lng, radiusMeters),
+		CreatedAt: time.Now(),
+	}
+}
+
+// NewPolygonGeofence creates a polygon geofence from its vertices.
+func NewPolygonGeofence(name string, vertices []Coordinate) *Geofence {
+	return &Geofence{
+		ID:        uuid.New(),
+		Name:      name,
+		Shape:     PolygonShape(vertices),
+		CreatedAt: time.Now(),
+	}
+}
+
+// Validate checks the geofence name and shape.
+func (g *Geofence) Validate() error {
+	if err := ValidateName(g.Name); err != nil {
+		return err
+	}
+	return g.Shape.Validate()
+}
+
+// GeofenceEventType is the direction of a geofence transition.
+type GeofenceEventType string
+
+// Geofence transition types.
+const (
+	GeofenceEnter GeofenceEventType = "enter"
+	GeofenceExit  GeofenceEventType = "exit"
+)
+
+// GeofenceEvent records an item crossing a geofence boundary.
+// OccurredAt is the recorded time of the position that triggered it.
+type GeofenceEvent struct {
+	ID         uuid.UUID         `json:"id"`
+	GeofenceID uuid.UUID         `json:"geofence_id"`
+	ItemID     uuid.UUID         `json:"item_id"`
+	PositionID uuid.UUID         `json:"position_id"`
+	Type       GeofenceEventType `json:"type"`
+	OccurredAt time.Time         `json:"occurred_at"`
+}
+
+// NewGeofenceEvent creates an event triggered by pos.
+func NewGeofenceEvent(fence *Geofence, pos *Position, eventType GeofenceEventType) *GeofenceEvent {
+	return &GeofenceEvent{
+		ID:         uuid.New(),
+		GeofenceID: fence.ID,
+		ItemID:     pos.ItemID,
+		PositionID: pos.ID,
+		Type:       eventType,
+		OccurredAt: pos.RecordedAt,
+	}
+}
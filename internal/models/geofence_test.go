@@ -0,0 +1,64 @@
+// ABOUTME: Unit tests for geofence models
+// ABOUTME: Tests constructors, shape validation, and event creation
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNewCircleGeofence(t *testing.T) {
+	g := NewCircleGeofence("garage", 41.8781, -87.6298, 50)
+	if g.ID == uuid.Nil {
+		t.Error("expected generated ID")
+	}
+	if !g.IsCircle() {
+		t.Error("expected circle geofence")
+	}
+	if g.Center.Latitude != 41.8781 || g.RadiusMeters != 50 {
+		t.Errorf("unexpected geofence: %+v", g)
+	}
+}
+
+func TestGeofence_Validate(t *testing.T) {
+	square := []Coordinate{{0, 0}, {0, 1}, {1, 1}, {1, 0}}
+	tests := []struct {
+		name    string
+		fence   *Geofence
+		wantErr bool
+	}{
+		{"valid_circle", NewCircleGeofence("home", 41.0, -87.0, 100), false},
+		{"valid_polygon", NewPolygonGeofence("park", square), false},
+		{"invalid_name", NewCircleGeofence(" ", 41.0, -87.0, 100), true},
+		{"invalid_radius", NewCircleGeofence("home", 41.0, -87.0, 0), true},
+		{"invalid_center", NewCircleGeofence("home", 91.0, -87.0, 100), true},
+		{"invalid_polygon_too_few", NewPolygonGeofence("park", square[:2]), true},
+		{"invalid_polygon_vertex", NewPolygonGeofence("park", []Coordinate{{0, 0}, {0, 181}, {1, 1}}), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.fence.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewGeofenceEvent(t *testing.T) {
+	fence := NewCircleGeofence("garage", 41.0, -87.0, 100)
+	recordedAt := time.Date(2024, 12, 14, 8, 0, 0, 0, time.UTC)
+	pos := NewPositionWithRecordedAt(uuid.New(), 41.0, -87.0, nil, recordedAt)
+
+	e := NewGeofenceEvent(fence, pos, GeofenceExit)
+	if e.GeofenceID != fence.ID || e.ItemID != pos.ItemID || e.PositionID != pos.ID {
+		t.Errorf("unexpected event references: %+v", e)
+	}
+	if e.Type != GeofenceExit || !e.OccurredAt.Equal(recordedAt) {
+		t.Errorf("unexpected event: %+v", e)
+	}
+}
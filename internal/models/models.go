@@ -58,6 +58,76 @@ type Position struct {
 	Label      *string   `json:"label,omitempty"`
 	RecordedAt time.Time `json:"recorded_at"`
 	CreatedAt  time.Time `json:"created_at"`
+	Telemetry
+
+	// Suspect marks a position the outlier filter distrusts, such as a
+	// cell-tower fix implying an impossible jump. It is kept but can be
+	// excluded from analysis.
+	Suspect bool `json:"suspect,omitempty"`
+
+	// Locality is the nearest city from the offline gazetteer, such as
+	// "Chicago, Illinois, US", when reverse geocoding is configured.
+	Locality *string `json:"locality,omitempty"`
+}
+
+// Telemetry holds optional readings reported by the device alongside a fix.
+// Nil fields were not reported.
+type Telemetry struct {
+	Accuracy *float64 `json:"accuracy,omitempty" yaml:"accuracy,omitempty"` // horizontal accuracy radius in meters
+	Altitude *float64 `json:"altitude,omitempty" yaml:"altitude,omitempty"` // meters above sea level
+	Speed    *float64 `json:"speed,omitempty" yaml:"speed,omitempty"`       // meters per second
+	Heading  *float64 `json:"heading,omitempty" yaml:"heading,omitempty"`   // degrees clockwise from true north
+	Battery  *int     `json:"battery,omitempty" yaml:"battery,omitempty"`   // device battery percentage
+	Source   *string  `json:"source,omitempty" yaml:"source,omitempty"`     // where the fix came from, e.g. "owntracks"
+}
+
+// Validate checks that any reported telemetry values are within range.
+func (t Telemetry) Validate() error {
+	for _, f := range []struct {
+		name  string
+		value *float64
+	}{{"accuracy", t.Accuracy}, {"altitude", t.Altitude}, {"speed", t.Speed}, {"heading", t.Heading}} {
+		if f.value != nil && (math.IsNaN(*f.value) || math.IsInf(*f.value, 0)) {
+			return fmt.Errorf("%s must be a finite number", f.name)
+		}
+	}
+	if t.Accuracy != nil && *t.Accuracy < 0 {
+		return fmt.Errorf("accuracy cannot be negative")
+	}
+	if t.Speed != nil && *t.Speed < 0 {
+		return fmt.Errorf("speed cannot be negative")
+	}
+	if t.Heading != nil && (*t.Heading < 0 || *t.Heading >= 360) {
+		return fmt.Errorf("heading must be between 0 and 360")
+	}
+	if t.Battery != nil && (*t.Battery < 0 || *t.Battery > 100) {
+		return fmt.Errorf("battery must be between 0 and 100")
+	}
+	return nil
+}
+
+// Sanitize drops each reading that is out of range, including the values
+// apps use as "unknown" markers (Overland sends -1), and keeps the rest. A
+// heading of 360 becomes 0.
+func (t Telemetry) Sanitize() Telemetry {
+	valid := func(v *float64, lo, hi float64) *float64 {
+		if v == nil || math.IsNaN(*v) || *v < lo || *v > hi {
+			return nil
+		}
+		return v
+	}
+	t.Accuracy = valid(t.Accuracy, 0, math.MaxFloat64)
+	t.Altitude = valid(t.Altitude, -math.MaxFloat64, math.MaxFloat64)
+	t.Speed = valid(t.Speed, 0, math.MaxFloat64)
+	t.Heading = valid(t.Heading, 0, 360)
+	if t.Heading != nil && *t.Heading == 360 {
+		north := 0.0
+		t.Heading = &north
+	}
+	if t.Battery != nil && (*t.Battery < 0 || *t.Battery > 100) {
+		t.Battery = nil
+	}
+	return t
 }
 
 // NewItem creates a new item with generated UUID and timestamp.
@@ -4,6 +4,7 @@
 package models
 
 import (
+	"encoding/json"
 	"math"
 	"strings"
 	"testing"
@@ -235,3 +236,74 @@ func TestPosition_EmptyLabel(t *testing.T) {
 		t.Errorf("expected empty label, got '%s'", *pos.Label)
 	}
 }
+
+func TestTelemetry_Validate(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	i := func(v int) *int { return &v }
+	tests := []struct {
+		name      string
+		telemetry Telemetry
+		wantErr   bool
+	}{
+		{"empty", Telemetry{}, false},
+		{"valid", Telemetry{Accuracy: f(12), Altitude: f(-30), Speed: f(3.5), Heading: f(359.9), Battery: i(100)}, false},
+		{"negative_accuracy", Telemetry{Accuracy: f(-1)}, true},
+		{"nan_altitude", Telemetry{Altitude: f(math.NaN())}, true},
+		{"negative_speed", Telemetry{Speed: f(-0.1)}, true},
+		{"heading_360", Telemetry{Heading: f(360)}, true},
+		{"battery_over", Telemetry{Battery: i(101)}, true},
+		{"battery_negative", Telemetry{Battery: i(-1)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.telemetry.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTelemetry_Sanitize(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	i := func(v int) *int { return &v }
+
+	got := Telemetry{Accuracy: f(-1), Altitude: f(180), Speed: f(-1), Heading: f(360), Battery: i(140)}.Sanitize()
+	if got.Accuracy != nil || got.Speed != nil || got.Battery != nil {
+		t.Errorf("expected out-of-range readings dropped, got %+v", got)
+	}
+	if got.Altitude == nil || *got.Altitude != 180 {
+		t.Errorf("expected valid altitude kept, got %v", got.Altitude)
+	}
+	if got.Heading == nil || *got.Heading != 0 {
+		t.Errorf("expected heading 360 to become 0, got %v", got.Heading)
+	}
+	if err := got.Validate(); err != nil {
+		t.Errorf("expected sanitized telemetry to validate, got %v", err)
+	}
+
+	got = Telemetry{Altitude: f(math.NaN()), Speed: f(math.Inf(1))}.Sanitize()
+	if got.Altitude != nil || got.Speed != nil {
+		t.Errorf("expected non-finite readings dropped, got %+v", got)
+	}
+}
+
+func TestPosition_TelemetryJSON(t *testing.T) {
+	acc := 8.5
+	source := "owntracks"
+	pos := NewPosition(uuid.New(), 41.0, -87.0, nil)
+	pos.Accuracy = &acc
+	pos.Source = &source
+
+	data, err := json.Marshal(pos)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	s := string(data)
+	if !strings.Contains(s, `"accuracy":8.5`) || !strings.Contains(s, `"source":"owntracks"`) {
+		t.Errorf("expected flattened telemetry in JSON, got %s", s)
+	}
+	if strings.Contains(s, "altitude") || strings.Contains(s, "Telemetry") {
+		t.Errorf("expected unreported telemetry omitted, got %s", s)
+	}
+}
@@ -0,0 +1,47 @@
+// ABOUTME: Named place model for automatic position labeling
+// ABOUTME: Defines a place as a named circle or polygon on the map
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Place is a named location such as "home" or "office". Positions created
+// without a label inside a place are labeled with its name.
+type Place struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Shape     `yaml:",inline"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewPlace creates a circular place around a center point.
+func NewPlace(name string, lat, lng, radiusMeters float64) *Place {
+	return &Place{
+		ID:        uuid.New(),
+		Name:      name,
+		Shape:     CircleShape(lat, lng, radiusMeters),
+		CreatedAt: time.Now(),
+	}
+}
+
+// NewPolygonPlace creates a place outlined by a polygon.
+func NewPolygonPlace(name string, vertices []Coordinate) *Place {
+	return &Place{
+		ID:        uuid.New(),
+		Name:      name,
+		Shape:     PolygonShape(vertices),
+		CreatedAt: time.Now(),
+	}
+}
+
+// Validate checks the place name and shape.
+func (p *Place) Validate() error {
+	if err := ValidateName(p.Name); err != nil {
+		return err
+	}
+	return p.Shape.Validate()
+}
@@ -0,0 +1,46 @@
+// ABOUTME: Unit tests for the place model
+// ABOUTME: Tests constructors and name and shape validation
+
+package models
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestNewPolygonPlace(t *testing.T) {
+	p := NewPolygonPlace("block", []Coordinate{{0, 0}, {0, 2}, {2, 2}, {2, 0}})
+	if p.ID == uuid.Nil {
+		t.Error("expected generated ID")
+	}
+	if p.IsCircle() || len(p.Polygon) != 4 {
+		t.Errorf("expected polygon place, got %+v", p)
+	}
+}
+
+func TestPlace_Validate(t *testing.T) {
+	square := []Coordinate{{0, 0}, {0, 1}, {1, 1}, {1, 0}}
+	tests := []struct {
+		name    string
+		place   *Place
+		wantErr bool
+	}{
+		{"valid_circle", NewPlace("home", 41.0, -87.0, 100), false},
+		{"valid_polygon", NewPolygonPlace("park", square), false},
+		{"invalid_name", NewPlace(" ", 41.0, -87.0, 100), true},
+		{"invalid_radius", NewPlace("home", 41.0, -87.0, 0), true},
+		{"invalid_center", NewPlace("home", 91.0, -87.0, 100), true},
+		{"invalid_polygon_too_few", NewPolygonPlace("park", square[:2]), true},
+		{"invalid_polygon_vertex", NewPolygonPlace("park", []Coordinate{{0, 0}, {0, 181}, {1, 1}}), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.place.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
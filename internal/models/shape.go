@@ -0,0 +1,60 @@
+// ABOUTME: Map shape shared by geofences and named places
+// ABOUTME: Defines an area as a circle around a center or a polygon outline
+
+package models
+
+import "fmt"
+
+// Shape is an area on the map: either a circle (Center + RadiusMeters)
+// or a polygon (Polygon with at least three vertices).
+type Shape struct {
+	Center       *Coordinate  `json:"center,omitempty" yaml:"center,omitempty"`
+	RadiusMeters float64      `json:"radius_meters,omitempty" yaml:"radius_meters,omitempty"`
+	Polygon      []Coordinate `json:"polygon,omitempty" yaml:"polygon,omitempty"`
+}
+
+// CircleShape returns the circle of radiusMeters around a center point.
+func CircleShape(lat, lng, radiusMeters float64) Shape {
+	return Shape{
+		Center:       &Coordinate{Latitude: lat, Longitude: lng},
+		RadiusMeters: radiusMeters,
+	}
+}
+
+// PolygonShape returns the polygon outlined by vertices.
+func PolygonShape(vertices []Coordinate) Shape {
+	return Shape{Polygon: vertices}
+}
+
+// IsCircle reports whether the shape is a circle rather than a polygon.
+func (s Shape) IsCircle() bool {
+	return len(s.Polygon) == 0
+}
+
+// Validate checks that the shape is a well-formed circle or polygon.
+func (s Shape) Validate() error {
+	if s.IsCircle() {
+		if s.Center == nil {
+			return fmt.Errorf("shape needs a center and radius or a polygon")
+		}
+		if err := ValidateCoordinates(s.Center.Latitude, s.Center.Longitude); err != nil {
+			return err
+		}
+		if s.RadiusMeters <= 0 {
+			return fmt.Errorf("radius must be greater than 0")
+		}
+		return nil
+	}
+	if s.Center != nil || s.RadiusMeters != 0 {
+		return fmt.Errorf("shape must be a circle or a polygon, not both")
+	}
+	if len(s.Polygon) < 3 {
+		return fmt.Errorf("polygon needs at least 3 vertices")
+	}
+	for _, c := range s.Polygon {
+		if err := ValidateCoordinates(c.Latitude, c.Longitude); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+// ABOUTME: Unit tests for the shape shared by geofences and places
+// ABOUTME: Tests circle and polygon construction and validation
+
+package models
+
+import "testing"
+
+func TestShape_Validate(t *testing.T) {
+	square := []Coordinate{{0, 0}, {0, 1}, {1, 1}, {1, 0}}
+	both := PolygonShape(square)
+	both.Center = &Coordinate{Latitude: 0.5, Longitude: 0.5}
+	withRadius := PolygonShape(square)
+	withRadius.RadiusMeters = 50
+	tests := []struct {
+		name    string
+		shape   Shape
+		wantErr bool
+	}{
+		{"valid_circle", CircleShape(41.0, -87.0, 100), false},
+		{"valid_polygon", PolygonShape(square), false},
+		{"invalid_empty", Shape{}, true},
+		{"invalid_radius", CircleShape(41.0, -87.0, 0), true},
+		{"invalid_center", CircleShape(91.0, -87.0, 100), true},
+		{"invalid_polygon_with_center", both, true},
+		{"invalid_polygon_with_radius", withRadius, true},
+		{"invalid_polygon_too_few", PolygonShape(square[:2]), true},
+		{"invalid_polygon_vertex", PolygonShape([]Coordinate{{0, 0}, {0, 181}, {1, 1}}), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.shape.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+// ABOUTME: Configurable position deduplication shared by storage backends
+// ABOUTME: Decides when a new position repeats the previous one and should be skipped
+
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/harper/position/internal/geo"
+	"github.com/harper/position/internal/models"
+)
+
+// DedupPolicy decides when a new position repeats the item's previous one
+// and is skipped instead of stored. The zero value skips only exact repeats.
+type DedupPolicy struct {
+	// DistanceMeters treats positions within this distance of the previous
+	// one as repeats. Zero matches only identical coordinates.
+	DistanceMeters float64 `json:"distance_m,omitempty"`
+
+	// MinGap stores a repeat anyway once this long has passed since the
+	// previous position, so a stationary device still logs a heartbeat.
+	// Zero skips repeats however far apart they are.
+	MinGap Duration `json:"min_gap,omitempty"`
+
+	// AccuracyOverlap also treats positions as repeats when both report an
+	// accuracy and their accuracy circles overlap.
+	AccuracyOverlap bool `json:"accuracy_overlap,omitempty"`
+
+	// KeepLabeled always stores positions that carry a label.
+	KeepLabeled bool `json:"keep_labeled,omitempty"`
+}
+
+// Validate checks that the policy's thresholds are usable.
+func (p DedupPolicy) Validate() error {
+	if math.IsNaN(p.DistanceMeters) || math.IsInf(p.DistanceMeters, 0) || p.DistanceMeters < 0 {
+		return fmt.Errorf("dedup distance must be a non-negative number")
+	}
+	if p.MinGap < 0 {
+		return fmt.Errorf("dedup min_gap cannot be negative")
+	}
+	return nil
+}
+
+// isRepeat reports whether pos repeats prev under the policy.
+// A nil prev (the item's first position) is never a repeat.
+func (p DedupPolicy) isRepeat(prev, pos *models.Position) bool {
+	if prev == nil {
+		return false
+	}
+	if p.KeepLabeled && pos.Label != nil && *pos.Label != "" {
+		return false
+	}
+	if p.MinGap > 0 && absDuration(pos.RecordedAt.Sub(prev.RecordedAt)) >= time.Duration(p.MinGap) {
+		return false
+	}
+	if coordsEqual(prev.Latitude, prev.Longitude, pos.Latitude, pos.Longitude) {
+		return true
+	}
+	if p.DistanceMeters == 0 && !p.AccuracyOverlap {
+		return false
+	}
+
+	distance := geo.Distance(prev.Latitude, prev.Longitude, pos.Latitude, pos.Longitude)
+	if distance <= p.DistanceMeters {
+		return true
+	}
+	return p.AccuracyOverlap && prev.Accuracy != nil && pos.Accuracy != nil &&
+		distance <= *prev.Accuracy+*pos.Accuracy
+}
+
+// repeatedSuccessors walks forward from next, the position recorded after
+// pos, collecting the positions that repeat pos under the policy: had pos
+// arrived first, they would have been skipped. It returns them and the first
+// successor that is kept, using after to step to each position's successor.
+func (p DedupPolicy) repeatedSuccessors(pos, next *models.Position, after func(*models.Position) (*models.Position, error)) (repeats []*models.Position, kept *models.Position, err error) {
+	for next != nil && p.isRepeat(pos, next) {
+		repeats = append(repeats, next)
+		if next, err = after(next); err != nil {
+			return nil, nil, err
+		}
+	}
+	return repeats, next, nil
+}
+
+// DedupConfig is the default DedupPolicy plus per-item overrides keyed by
+// item name. An item's policy replaces the default entirely.
+type DedupConfig struct {
+	DedupPolicy
+	Items map[string]DedupPolicy `json:"items,omitempty"`
+}
+
+// Validate checks the default and every per-item policy.
+func (c DedupConfig) Validate() error {
+	if err := c.DedupPolicy.Validate(); err != nil {
+		return err
+	}
+	for name, p := range c.Items {
+		if err := p.Validate(); err != nil {
+			return fmt.Errorf("item %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// policyFor returns the policy that applies to itemID. The item's name is
+// only looked up when per-item policies are configured.
+func (c DedupConfig) policyFor(items ItemRepository, itemID uuid.UUID) DedupPolicy {
+	if len(c.Items) == 0 {
+		return c.DedupPolicy
+	}
+	item, err := items.GetItemByID(itemID)
+	if err != nil {
+		return c.DedupPolicy
+	}
+	if p, ok := c.Items[item.Name]; ok {
+		return p
+	}
+	return c.DedupPolicy
+}
+
+// Duration is a time.Duration that reads and writes JSON as a string like
+// "15m", so config files stay readable.
+type Duration time.Duration
+
+// MarshalJSON encodes the duration as a Go duration string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON decodes a Go duration string such as "90s" or "1h30m".
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"15m\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,146 @@
+// ABOUTME: Tests for configurable position deduplication
+// ABOUTME: Covers policy thresholds, per-item overrides, and both storage backends
+
+package storage
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/harper/position/internal/models"
+)
+
+func TestDedupPolicy_IsRepeat(t *testing.T) {
+	base := time.Date(2024, 12, 14, 8, 0, 0, 0, time.UTC)
+	at := func(lat, lng float64, d time.Duration, label string, acc float64) *models.Position {
+		var l *string
+		if label != "" {
+			l = &label
+		}
+		pos := models.NewPositionWithRecordedAt(models.NewItem("x").ID, lat, lng, l, base.Add(d))
+		if acc > 0 {
+			pos.Accuracy = &acc
+		}
+		return pos
+	}
+	prev := at(41.8781, -87.6298, 0, "", 30)
+
+	tests := []struct {
+		name   string
+		policy DedupPolicy
+		pos    *models.Position
+		want   bool
+	}{
+		{"zero_exact_repeat", DedupPolicy{}, at(41.8781, -87.6298, time.Hour, "", 0), true},
+		{"zero_small_move", DedupPolicy{}, at(41.8782, -87.6298, time.Minute, "", 0), false},
+		{"within_distance", DedupPolicy{DistanceMeters: 25}, at(41.8782, -87.6298, time.Minute, "", 0), true},
+		{"beyond_distance", DedupPolicy{DistanceMeters: 25}, at(41.8790, -87.6298, time.Minute, "", 0), false},
+		{"before_min_gap", DedupPolicy{DistanceMeters: 25, MinGap: Duration(15 * time.Minute)}, at(41.8781, -87.6298, 10*time.Minute, "", 0), true},
+		{"after_min_gap", DedupPolicy{DistanceMeters: 25, MinGap: Duration(15 * time.Minute)}, at(41.8781, -87.6298, 15*time.Minute, "", 0), false},
+		{"accuracy_overlap", DedupPolicy{AccuracyOverlap: true}, at(41.8785, -87.6298, time.Minute, "", 30), true},
+		{"accuracy_apart", DedupPolicy{AccuracyOverlap: true}, at(41.8800, -87.6298, time.Minute, "", 30), false},
+		{"accuracy_unreported", DedupPolicy{AccuracyOverlap: true}, at(41.8785, -87.6298, time.Minute, "", 0), false},
+		{"labeled_kept", DedupPolicy{KeepLabeled: true}, at(41.8781, -87.6298, time.Minute, "desk", 0), false},
+		{"labeled_not_kept", DedupPolicy{}, at(41.8781, -87.6298, time.Minute, "desk", 0), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.isRepeat(prev, tt.pos); got != tt.want {
+				t.Errorf("isRepeat() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if (DedupPolicy{DistanceMeters: 1000}).isRepeat(nil, prev) {
+		t.Error("expected first position never to be a repeat")
+	}
+}
+
+func TestDedupConfig_Validate(t *testing.T) {
+	if err := (DedupConfig{DedupPolicy: DedupPolicy{DistanceMeters: 10}}).Validate(); err != nil {
+		t.Errorf("expected valid config, got %v", err)
+	}
+	if err := (DedupConfig{DedupPolicy: DedupPolicy{MinGap: Duration(-time.Second)}}).Validate(); err == nil {
+		t.Error("expected error for negative min_gap")
+	}
+	bad := DedupConfig{Items: map[string]DedupPolicy{"car": {DistanceMeters: -5}}}
+	if err := bad.Validate(); err == nil {
+		t.Error("expected error for negative per-item distance")
+	}
+}
+
+func TestDedupConfig_JSON(t *testing.T) {
+	cfg := DedupConfig{
+		DedupPolicy: DedupPolicy{DistanceMeters: 25, MinGap: Duration(90 * time.Second)},
+		Items:       map[string]DedupPolicy{"car": {KeepLabeled: true}},
+	}
+	data, err := json.Marshal(cfg)
+	mustNoError(t, err)
+	if string(data) != `{"distance_m":25,"min_gap":"1m30s","items":{"car":{"keep_labeled":true}}}` {
+		t.Errorf("unexpected JSON: %s", data)
+	}
+
+	var got DedupConfig
+	mustNoError(t, json.Unmarshal(data, &got))
+	if got.MinGap != cfg.MinGap || !got.Items["car"].KeepLabeled {
+		t.Errorf("round trip mismatch: %+v", got)
+	}
+	if err := json.Unmarshal([]byte(`{"min_gap": 90}`), &got); err == nil {
+		t.Error("expected error for numeric min_gap")
+	}
+}
+
+func TestCreatePosition_DedupPolicy(t *testing.T) {
+	backends := map[string]func(t *testing.T, cfg DedupConfig) Repository{
+		"sqlite": func(t *testing.T, cfg DedupConfig) Repository {
+			db := testDB(t)
+			db.SetDedup(cfg)
+			return db
+		},
+		"markdown": func(t *testing.T, cfg DedupConfig) Repository {
+			store := newTestMarkdownStore(t)
+			store.SetDedup(cfg)
+			return store
+		},
+	}
+	cfg := DedupConfig{
+		DedupPolicy: DedupPolicy{DistanceMeters: 50, KeepLabeled: true},
+		Items:       map[string]DedupPolicy{"car": {}},
+	}
+	base := time.Date(2024, 12, 14, 8, 0, 0, 0, time.UTC)
+
+	for name, newRepo := range backends {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t, cfg)
+			phone := models.NewItem("phone")
+			car := models.NewItem("car")
+			mustNoError(t, repo.CreateItem(phone))
+			mustNoError(t, repo.CreateItem(car))
+
+			desk := "desk"
+			for _, pos := range []*models.Position{
+				models.NewPositionWithRecordedAt(phone.ID, 41.8781, -87.6298, nil, base),
+				models.NewPositionWithRecordedAt(phone.ID, 41.8782, -87.6298, nil, base.Add(time.Minute)),     // ~11m: repeat
+				models.NewPositionWithRecordedAt(phone.ID, 41.8781, -87.6299, &desk, base.Add(2*time.Minute)), // labeled: kept
+				models.NewPositionWithRecordedAt(phone.ID, 41.8800, -87.6298, nil, base.Add(3*time.Minute)),   // ~200m: kept
+			} {
+				mustNoError(t, repo.CreatePosition(pos))
+			}
+			timeline, err := repo.GetTimeline(phone.ID)
+			mustNoError(t, err)
+			if len(timeline) != 3 {
+				t.Errorf("expected 3 phone positions, got %d", len(timeline))
+			}
+
+			// The car's own policy only skips exact repeats
+			mustNoError(t, repo.CreatePosition(models.NewPositionWithRecordedAt(car.ID, 41.8781, -87.6298, nil, base)))
+			mustNoError(t, repo.CreatePosition(models.NewPositionWithRecordedAt(car.ID, 41.8782, -87.6298, nil, base.Add(time.Minute))))
+			timeline, err = repo.GetTimeline(car.ID)
+			mustNoError(t, err)
+			if len(timeline) != 2 {
+				t.Errorf("expected per-item policy to keep 2 car positions, got %d", len(timeline))
+			}
+		})
+	}
+}
@@ -10,3 +10,9 @@ var ErrNotFound = errors.New("not found")
 
 // ErrReadOnly is returned when attempting to write to a read-only store.
 var ErrReadOnly = errors.New("storage is read-only")
+
+// ErrSchemaTooNew is returned when a database was written by a newer version.
+var ErrSchemaTooNew = errors.New("database schema is newer than this version supports")
+
+// ErrNoGeocoder is returned when geocoding stored positions without a geocoder.
+var ErrNoGeocoder = errors.New("reverse geocoding is not configured")
@@ -25,6 +25,10 @@ type Backup struct {
 	Tool       string           `yaml:"tool"`
 	Items      []ItemBackup     `yaml:"items"`
 	Positions  []PositionBackup `yaml:"positions"`
+
+	Geofences      []GeofenceBackup      `yaml:"geofences,omitempty"`
+	GeofenceEvents []GeofenceEventBackup `yaml:"geofence_events,omitempty"`
+	Places         []PlaceBackup         `yaml:"places,omitempty"`
 }
 
 // ItemBackup represents an item in the backup format.
@@ -43,6 +47,36 @@ type PositionBackup struct {
 	Label      string    `yaml:"label,omitempty"`
 	RecordedAt time.Time `yaml:"recorded_at"`
 	CreatedAt  time.Time `yaml:"created_at"`
+	Suspect    bool      `yaml:"suspect,omitempty"`
+	Locality   string    `yaml:"locality,omitempty"`
+
+	models.Telemetry `yaml:",inline"`
+}
+
+// GeofenceBackup represents a geofence in the backup format.
+type GeofenceBackup struct {
+	ID           string `yaml:"id"`
+	Name         string `yaml:"name"`
+	models.Shape `yaml:",inline"`
+	CreatedAt    time.Time `yaml:"created_at"`
+}
+
+// GeofenceEventBackup represents a geofence event in the backup format.
+type GeofenceEventBackup struct {
+	ID         string    `yaml:"id"`
+	GeofenceID string    `yaml:"geofence_id"`
+	ItemID     string    `yaml:"item_id"`
+	PositionID string    `yaml:"position_id"`
+	Type       string    `yaml:"type"`
+	OccurredAt time.Time `yaml:"occurred_at"`
+}
+
+// PlaceBackup represents a named place in the backup format.
+type PlaceBackup struct {
+	ID           string `yaml:"id"`
+	Name         string `yaml:"name"`
+	models.Shape `yaml:",inline"`
+	CreatedAt    time.Time `yaml:"created_at"`
 }
 
 // ItemWithPositions groups an item with its positions.
@@ -87,15 +121,59 @@ func ExportToYAML(repo Repository) ([]byte, error) {
 			Longitude:  pos.Longitude,
 			RecordedAt: pos.RecordedAt,
 			CreatedAt:  pos.CreatedAt,
+			Telemetry:  pos.Telemetry,
+			Suspect:    pos.Suspect,
 		}
 		if pos.Label != nil {
 			backup.Positions[i].Label = *pos.Label
 		}
+		if pos.Locality != nil {
+			backup.Positions[i].Locality = *pos.Locality
+		}
+	}
+
+	if err := exportGeofences(repo, &backup); err != nil {
+		return nil, err
+	}
+	if err := exportPlaces(repo, &backup); err != nil {
+		return nil, err
 	}
 
 	return yaml.Marshal(backup)
 }
 
+// exportGeofences adds geofences and their recorded events to the backup.
+func exportGeofences(repo Repository, backup *Backup) error {
+	fences, err := repo.ListGeofences()
+	if err != nil {
+		return fmt.Errorf("list geofences: %w", err)
+	}
+	for _, fence := range fences {
+		backup.Geofences = append(backup.Geofences, GeofenceBackup{
+			ID:        fence.ID.String(),
+			Name:      fence.Name,
+			Shape:     fence.Shape,
+			CreatedAt: fence.CreatedAt,
+		})
+	}
+
+	events, err := repo.ListGeofenceEvents(uuid.Nil, uuid.Nil)
+	if err != nil {
+		return fmt.Errorf("list geofence events: %w", err)
+	}
+	for _, event := range events {
+		backup.GeofenceEvents = append(backup.GeofenceEvents, GeofenceEventBackup{
+			ID:         event.ID.String(),
+			GeofenceID: event.GeofenceID.String(),
+			ItemID:     event.ItemID.String(),
+			PositionID: event.PositionID.String(),
+			Type:       string(event.Type),
+			OccurredAt: event.OccurredAt,
+		})
+	}
+	return nil
+}
+
 // ImportFromYAML imports data from YAML format.
 // This is a restore operation and does NOT deduplicate positions.
 func ImportFromYAML(repo Repository, data []byte) error {
@@ -146,6 +224,10 @@ func ImportFromYAML(repo Repository, data []byte) error {
 		if posBackup.Label != "" {
 			label = &posBackup.Label
 		}
+		var locality *string
+		if posBackup.Locality != "" {
+			locality = &posBackup.Locality
+		}
 
 		pos := &models.Position{
 			ID:         id,
@@ -155,6 +237,9 @@ func ImportFromYAML(repo Repository, data []byte) error {
 			Label:      label,
 			RecordedAt: posBackup.RecordedAt,
 			CreatedAt:  posBackup.CreatedAt,
+			Telemetry:  posBackup.Telemetry,
+			Suspect:    posBackup.Suspect,
+			Locality:   locality,
 		}
 
 		// Direct insert to bypass deduplication
@@ -163,6 +248,95 @@ func ImportFromYAML(repo Repository, data []byte) error {
 		}
 	}
 
+	if err := importGeofences(repo, backup); err != nil {
+		return err
+	}
+	return importPlaces(repo, backup)
+}
+
+// exportPlaces adds named places to the backup.
+func exportPlaces(repo Repository, backup *Backup) error {
+	places, err := repo.ListPlaces()
+	if err != nil {
+		return fmt.Errorf("list places: %w", err)
+	}
+	for _, place := range places {
+		backup.Places = append(backup.Places, PlaceBackup{
+			ID:        place.ID.String(),
+			Name:      place.Name,
+			Shape:     place.Shape,
+			CreatedAt: place.CreatedAt,
+		})
+	}
+	return nil
+}
+
+// importPlaces restores named places. Restored positions keep the labels
+// they were backed up with.
+func importPlaces(repo Repository, backup Backup) error {
+	for _, pb := range backup.Places {
+		id, err := uuid.Parse(pb.ID)
+		if err != nil {
+			return fmt.Errorf("invalid place ID %s: %w", pb.ID, err)
+		}
+		place := &models.Place{
+			ID:        id,
+			Name:      pb.Name,
+			Shape:     pb.Shape,
+			CreatedAt: pb.CreatedAt,
+		}
+		if err := repo.CreatePlace(place); err != nil {
+			return fmt.Errorf("create place %s: %w", pb.Name, err)
+		}
+	}
+	return nil
+}
+
+// importGeofences restores geofences and their events. Events are restored
+// as recorded rather than recomputed, since positions bypass CreatePosition.
+func importGeofences(repo Repository, backup Backup) error {
+	for _, fb := range backup.Geofences {
+		id, err := uuid.Parse(fb.ID)
+		if err != nil {
+			return fmt.Errorf("invalid geofence ID %s: %w", fb.ID, err)
+		}
+		fence := &models.Geofence{
+			ID:        id,
+			Name:      fb.Name,
+			Shape:     fb.Shape,
+			CreatedAt: fb.CreatedAt,
+		}
+		if err := repo.CreateGeofence(fence); err != nil {
+			return fmt.Errorf("create geofence %s: %w", fb.Name, err)
+		}
+	}
+
+	events := make([]*models.GeofenceEvent, len(backup.GeofenceEvents))
+	for i, eb := range backup.GeofenceEvents {
+		var ids [4]uuid.UUID
+		for j, raw := range []string{eb.ID, eb.GeofenceID, eb.ItemID, eb.PositionID} {
+			id, err := uuid.Parse(raw)
+			if err != nil {
+				return fmt.Errorf("invalid geofence event ID %s: %w", raw, err)
+			}
+			ids[j] = id
+		}
+		eventType := models.GeofenceEventType(eb.Type)
+		if eventType != models.GeofenceEnter && eventType != models.GeofenceExit {
+			return fmt.Errorf("invalid geofence event type %q", eb.Type)
+		}
+		events[i] = &models.GeofenceEvent{
+			ID:         ids[0],
+			GeofenceID: ids[1],
+			ItemID:     ids[2],
+			PositionID: ids[3],
+			Type:       eventType,
+			OccurredAt: eb.OccurredAt,
+		}
+	}
+	if err := insertGeofenceEventsDirect(repo, events); err != nil {
+		return fmt.Errorf("create geofence events: %w", err)
+	}
 	return nil
 }
 
@@ -179,6 +353,22 @@ func importPositionDirectAny(repo Repository, pos *models.Position) error {
 	}
 }
 
+// insertGeofenceEventsDirect stores already-recorded geofence events for any
+// backend. Other repositories have no way to store events and skip them.
+func insertGeofenceEventsDirect(repo Repository, events []*models.GeofenceEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	switch r := repo.(type) {
+	case *SQLiteDB:
+		return r.insertGeofenceEvents(r.db, events)
+	case *MarkdownStore:
+		return r.appendGeofenceEvents(events)
+	default:
+		return nil
+	}
+}
+
 // importPositionDirectMarkdown writes a position file directly without deduplication.
 func importPositionDirectMarkdown(store *MarkdownStore, pos *models.Position) error {
 	itemDir, err := store.resolveItemDir(pos.ItemID)
@@ -195,16 +385,7 @@ func importPositionDirectMarkdown(store *MarkdownStore, pos *models.Position) er
 
 // importPositionDirect inserts a position directly without deduplication.
 func importPositionDirect(db *SQLiteDB, pos *models.Position) error {
-	_, err := db.db.Exec(
-		`INSERT INTO positions (id, item_id, latitude, longitude, label, recorded_at, created_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
-		pos.ID.String(), pos.ItemID.String(), pos.Latitude, pos.Longitude,
-		pos.Label, pos.RecordedAt, pos.CreatedAt,
-	)
-	if err != nil {
-		return fmt.Errorf("insert position: %w", err)
-	}
-	return nil
+	return db.insertPosition(db.db, pos)
 }
 
 // ExportToMarkdown exports data to markdown format.
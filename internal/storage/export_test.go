@@ -1,5 +1,5 @@
 // ABOUTME: Tests for export and import functionality
-// ABOUTME: Covers YAML backup format and markdown export
+// ABOUTME: Covers YAML backup format, including geofences and places, and markdown export
 
 package storage
 
@@ -471,3 +471,129 @@ positions: []
 		t.Errorf("got %d items, want 1", len(items))
 	}
 }
+
+func TestBackupRoundTrip_Geofences(t *testing.T) {
+	for name, newRepo := range geofenceBackends() {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+			base := time.Date(2024, 12, 14, 8, 0, 0, 0, time.UTC)
+
+			item := models.NewItem("phone")
+			mustNoError(t, repo.CreateItem(item))
+			circle := models.NewCircleGeofence("garage", 41.8781, -87.6298, 50)
+			polygon := models.NewPolygonGeofence("park", []models.Coordinate{
+				{Latitude: 41.0, Longitude: -88.0},
+				{Latitude: 41.0, Longitude: -87.0},
+				{Latitude: 42.0, Longitude: -87.0},
+			})
+			mustNoError(t, repo.CreateGeofence(circle))
+			mustNoError(t, repo.CreateGeofence(polygon))
+
+			// Enter the garage, then leave it
+			mustNoError(t, repo.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 41.8781, -87.6298, nil, base)))
+			mustNoError(t, repo.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 40.7128, -74.0060, nil, base.Add(time.Hour))))
+
+			wantFences, err := repo.ListGeofences()
+			mustNoError(t, err)
+			wantEvents, err := repo.ListGeofenceEvents(uuid.Nil, uuid.Nil)
+			mustNoError(t, err)
+			if len(wantEvents) == 0 {
+				t.Fatal("expected geofence events to back up")
+			}
+
+			data, err := ExportBackup(repo)
+			mustNoError(t, err)
+			mustNoError(t, repo.Reset())
+			mustNoError(t, ImportBackup(repo, data))
+
+			gotFences, err := repo.ListGeofences()
+			mustNoError(t, err)
+			if len(gotFences) != len(wantFences) {
+				t.Fatalf("expected %d geofences, got %d", len(wantFences), len(gotFences))
+			}
+			for i, want := range wantFences {
+				got := gotFences[i]
+				if got.ID != want.ID || got.Name != want.Name || got.RadiusMeters != want.RadiusMeters ||
+					got.IsCircle() != want.IsCircle() || len(got.Polygon) != len(want.Polygon) {
+					t.Errorf("geofence %d: expected %+v, got %+v", i, want, got)
+				}
+			}
+
+			gotEvents, err := repo.ListGeofenceEvents(uuid.Nil, uuid.Nil)
+			mustNoError(t, err)
+			if len(gotEvents) != len(wantEvents) {
+				t.Fatalf("expected %d geofence events, got %d", len(wantEvents), len(gotEvents))
+			}
+			for i, want := range wantEvents {
+				got := gotEvents[i]
+				if got.ID != want.ID || got.GeofenceID != want.GeofenceID || got.PositionID != want.PositionID ||
+					got.Type != want.Type || !got.OccurredAt.Equal(want.OccurredAt) {
+					t.Errorf("event %d: expected %+v, got %+v", i, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestImportFromYAML_InvalidGeofenceEvent(t *testing.T) {
+	db := testDB(t)
+	data := `version: "1.0"
+tool: position
+items: []
+positions: []
+geofence_events:
+  - id: ` + uuid.New().String() + `
+    geofence_id: ` + uuid.New().String() + `
+    item_id: ` + uuid.New().String() + `
+    position_id: ` + uuid.New().String() + `
+    type: hover
+    occurred_at: 2024-12-14T08:00:00Z
+`
+	if err := ImportFromYAML(db, []byte(data)); err == nil {
+		t.Error("expected error for unknown event type")
+	}
+}
+
+func TestBackupRoundTrip_Places(t *testing.T) {
+	for name, newRepo := range geofenceBackends() {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+			mustNoError(t, repo.CreatePlace(models.NewPlace("home", 41.8781, -87.6298, 150)))
+			mustNoError(t, repo.CreatePlace(models.NewPolygonPlace("campus", []models.Coordinate{
+				{Latitude: 41.88, Longitude: -87.63},
+				{Latitude: 41.88, Longitude: -87.62},
+				{Latitude: 41.87, Longitude: -87.62},
+			})))
+			want, err := repo.ListPlaces()
+			mustNoError(t, err)
+
+			data, err := ExportBackup(repo)
+			mustNoError(t, err)
+			mustNoError(t, repo.Reset())
+			mustNoError(t, ImportBackup(repo, data))
+
+			got, err := repo.ListPlaces()
+			mustNoError(t, err)
+			if len(got) != len(want) {
+				t.Fatalf("expected %d places, got %d", len(want), len(got))
+			}
+			for i := range want {
+				if got[i].ID != want[i].ID || got[i].Name != want[i].Name || got[i].IsCircle() != want[i].IsCircle() ||
+					got[i].RadiusMeters != want[i].RadiusMeters || len(got[i].Polygon) != len(want[i].Polygon) {
+					t.Errorf("place %d: expected %+v, got %+v", i, want[i], got[i])
+				}
+			}
+
+			// Restored places label new positions again
+			item := models.NewItem("phone")
+			mustNoError(t, repo.CreateItem(item))
+			pos := models.NewPosition(item.ID, 41.8781, -87.6298, nil)
+			mustNoError(t, repo.CreatePosition(pos))
+			stored, err := repo.GetPosition(pos.ID)
+			mustNoError(t, err)
+			if stored.Label == nil || *stored.Label != "home" {
+				t.Errorf("expected restored place to label positions, got %v", stored.Label)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+// ABOUTME: Outlier filtering shared by storage backends
+// ABOUTME: Flags positions implying impossible speed or reporting poor accuracy as suspect
+
+package storage
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/harper/position/internal/geo"
+	"github.com/harper/position/internal/models"
+)
+
+// FilterPolicy decides when a new position is suspect: stored, but flagged
+// so readers can exclude it. The zero value flags nothing.
+type FilterPolicy struct {
+	// MaxSpeedKmh flags positions that could only be reached from the
+	// previous trusted position by moving faster than this. Zero disables
+	// the check.
+	MaxSpeedKmh float64 `json:"max_speed_kmh,omitempty"`
+
+	// MaxAccuracy flags positions reporting an accuracy radius larger than
+	// this many meters, such as cell-tower fixes. Zero disables the check.
+	MaxAccuracy float64 `json:"max_accuracy_m,omitempty"`
+}
+
+// Validate checks that the policy's thresholds are usable.
+func (p FilterPolicy) Validate() error {
+	if math.IsNaN(p.MaxSpeedKmh) || math.IsInf(p.MaxSpeedKmh, 0) || p.MaxSpeedKmh < 0 {
+		return fmt.Errorf("filter max_speed_kmh must be a non-negative number")
+	}
+	if math.IsNaN(p.MaxAccuracy) || math.IsInf(p.MaxAccuracy, 0) || p.MaxAccuracy < 0 {
+		return fmt.Errorf("filter max_accuracy_m must be a non-negative number")
+	}
+	return nil
+}
+
+// isSuspect reports whether pos is suspect under the policy, given the
+// latest trusted position recorded before it. A nil prev (no trusted history
+// yet) only applies the accuracy check.
+func (p FilterPolicy) isSuspect(prev, pos *models.Position) bool {
+	if p.MaxAccuracy > 0 && pos.Accuracy != nil && *pos.Accuracy > p.MaxAccuracy {
+		return true
+	}
+	if p.MaxSpeedKmh == 0 || prev == nil {
+		return false
+	}
+
+	distance := geo.Distance(prev.Latitude, prev.Longitude, pos.Latitude, pos.Longitude)
+	hours := absDuration(pos.RecordedAt.Sub(prev.RecordedAt)).Hours()
+	if hours == 0 {
+		// Two places at once: only a jump beyond GPS jitter counts
+		return distance > coordJitterMeters
+	}
+	return distance/1000/hours > p.MaxSpeedKmh
+}
+
+// coordJitterMeters is how far apart simultaneous fixes may be before the
+// second is treated as a jump.
+const coordJitterMeters = 100
+
+// Flag returns the suspect flag each of positions should carry under the
+// policy, keyed by position ID. Positions are checked oldest first, each
+// against the latest one before it that is not itself suspect, so a single
+// bad fix doesn't taint the ones after it.
+func (p FilterPolicy) Flag(positions []*models.Position) map[uuid.UUID]bool {
+	sorted := make([]*models.Position, len(positions))
+	copy(sorted, positions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].RecordedAt.Before(sorted[j].RecordedAt)
+	})
+
+	flags := make(map[uuid.UUID]bool, len(positions))
+	var trusted *models.Position
+	for _, pos := range sorted {
+		suspect := p.isSuspect(trusted, pos)
+		flags[pos.ID] = suspect
+		if !suspect {
+			trusted = pos
+		}
+	}
+	return flags
+}
+
+// FilterConfig is the default FilterPolicy plus policies per item type.
+// Items maps item names to a type in Types; an item's type policy replaces
+// the default entirely.
+type FilterConfig struct {
+	FilterPolicy
+	Types map[string]FilterPolicy `json:"types,omitempty"`
+	Items map[string]string       `json:"items,omitempty"`
+}
+
+// Validate checks the default and every type policy, and that every item
+// names a configured type.
+func (c FilterConfig) Validate() error {
+	if err := c.FilterPolicy.Validate(); err != nil {
+		return err
+	}
+	for name, p := range c.Types {
+		if err := p.Validate(); err != nil {
+			return fmt.Errorf("type %q: %w", name, err)
+		}
+	}
+	for name, typ := range c.Items {
+		if _, ok := c.Types[typ]; !ok {
+			return fmt.Errorf("item %q: unknown type %q", name, typ)
+		}
+	}
+	return nil
+}
+
+// policyFor returns the policy that applies to itemID. The item's name is
+// only looked up when per-item types are configured.
+func (c FilterConfig) policyFor(items ItemRepository, itemID uuid.UUID) FilterPolicy {
+	if len(c.Items) == 0 {
+		return c.FilterPolicy
+	}
+	item, err := items.GetItemByID(itemID)
+	if err != nil {
+		return c.FilterPolicy
+	}
+	if typ, ok := c.Items[item.Name]; ok {
+		return c.Types[typ]
+	}
+	return c.FilterPolicy
+}
+
+// Clean returns positions without those flagged suspect, in the same order.
+func Clean(positions []*models.Position) []*models.Position {
+	clean := make([]*models.Position, 0, len(positions))
+	for _, pos := range positions {
+		if !pos.Suspect {
+			clean = append(clean, pos)
+		}
+	}
+	return clean
+}
@@ -0,0 +1,194 @@
+// ABOUTME: Tests for outlier filtering of positions
+// ABOUTME: Covers speed and accuracy checks, per-type policies, and both storage backends
+
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/harper/position/internal/models"
+)
+
+func TestFilterPolicy_IsSuspect(t *testing.T) {
+	base := time.Date(2024, 12, 14, 8, 0, 0, 0, time.UTC)
+	at := func(lat, lng float64, d time.Duration, acc float64) *models.Position {
+		pos := models.NewPositionWithRecordedAt(models.NewItem("x").ID, lat, lng, nil, base.Add(d))
+		if acc > 0 {
+			pos.Accuracy = &acc
+		}
+		return pos
+	}
+	prev := at(41.8781, -87.6298, 0, 0)
+
+	tests := []struct {
+		name   string
+		policy FilterPolicy
+		prev   *models.Position
+		pos    *models.Position
+		want   bool
+	}{
+		{"zero_policy", FilterPolicy{}, prev, at(40.7128, -74.0060, time.Minute, 5000), false},
+		{"walking_pace", FilterPolicy{MaxSpeedKmh: 200}, prev, at(41.8881, -87.6298, 10*time.Minute, 0), false},
+		{"teleport", FilterPolicy{MaxSpeedKmh: 200}, prev, at(41.9781, -87.6298, time.Minute, 0), true},
+		{"simultaneous_jitter", FilterPolicy{MaxSpeedKmh: 200}, prev, at(41.8782, -87.6298, 0, 0), false},
+		{"simultaneous_jump", FilterPolicy{MaxSpeedKmh: 200}, prev, at(41.8881, -87.6298, 0, 0), true},
+		{"backdated_teleport", FilterPolicy{MaxSpeedKmh: 200}, prev, at(41.9781, -87.6298, -time.Minute, 0), true},
+		{"no_history", FilterPolicy{MaxSpeedKmh: 200}, nil, at(41.9781, -87.6298, 0, 0), false},
+		{"poor_accuracy", FilterPolicy{MaxAccuracy: 500}, nil, at(41.8781, -87.6298, time.Minute, 1500), true},
+		{"good_accuracy", FilterPolicy{MaxAccuracy: 500}, nil, at(41.8781, -87.6298, time.Minute, 20), false},
+		{"accuracy_unreported", FilterPolicy{MaxAccuracy: 500}, nil, at(41.8781, -87.6298, time.Minute, 0), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.isSuspect(tt.prev, tt.pos); got != tt.want {
+				t.Errorf("isSuspect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterPolicy_Flag(t *testing.T) {
+	base := time.Date(2024, 12, 14, 8, 0, 0, 0, time.UTC)
+	itemID := models.NewItem("phone").ID
+	positions := []*models.Position{
+		models.NewPositionWithRecordedAt(itemID, 41.8781, -87.6298, nil, base),
+		models.NewPositionWithRecordedAt(itemID, 41.9781, -87.6298, nil, base.Add(time.Minute)), // 11 km in a minute
+		models.NewPositionWithRecordedAt(itemID, 41.8791, -87.6298, nil, base.Add(2*time.Minute)),
+	}
+
+	flags := FilterPolicy{MaxSpeedKmh: 200}.Flag(positions)
+	if flags[positions[0].ID] || !flags[positions[1].ID] || flags[positions[2].ID] {
+		t.Errorf("expected only the jump flagged, got %v", flags)
+	}
+}
+
+func TestFilterConfig(t *testing.T) {
+	cfg := FilterConfig{
+		FilterPolicy: FilterPolicy{MaxAccuracy: 1000},
+		Types:        map[string]FilterPolicy{"person": {MaxSpeedKmh: 200}},
+		Items:        map[string]string{"harper": "person"},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected valid config, got %v", err)
+	}
+	if err := (FilterConfig{FilterPolicy: FilterPolicy{MaxSpeedKmh: -1}}).Validate(); err == nil {
+		t.Error("expected error for negative max speed")
+	}
+	if err := (FilterConfig{Types: map[string]FilterPolicy{"car": {MaxAccuracy: -1}}}).Validate(); err == nil {
+		t.Error("expected error for negative per-type accuracy")
+	}
+
+	db := testDB(t)
+	harper := models.NewItem("harper")
+	van := models.NewItem("van")
+	mustNoError(t, db.CreateItem(harper))
+	mustNoError(t, db.CreateItem(van))
+	if got := cfg.policyFor(db, harper.ID); got.MaxSpeedKmh != 200 || got.MaxAccuracy != 0 {
+		t.Errorf("expected the person policy for harper, got %+v", got)
+	}
+	if got := cfg.policyFor(db, van.ID); got.MaxAccuracy != 1000 {
+		t.Errorf("expected the default policy for van, got %+v", got)
+	}
+}
+
+func TestCreatePosition_Filter(t *testing.T) {
+	for name, newRepo := range geofenceBackends() {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+			repo.(interface{ SetFilter(FilterConfig) }).SetFilter(FilterConfig{
+				FilterPolicy: FilterPolicy{MaxSpeedKmh: 200},
+			})
+			base := time.Date(2024, 12, 14, 8, 0, 0, 0, time.UTC)
+
+			phone := models.NewItem("phone")
+			mustNoError(t, repo.CreateItem(phone))
+			mustNoError(t, repo.CreateGeofence(models.NewCircleGeofence("airport", 41.9786, -87.9048, 2000)))
+
+			home := models.NewPositionWithRecordedAt(phone.ID, 41.8781, -87.6298, nil, base)
+			jump := models.NewPositionWithRecordedAt(phone.ID, 41.9786, -87.9048, nil, base.Add(time.Minute))
+			back := models.NewPositionWithRecordedAt(phone.ID, 41.8791, -87.6298, nil, base.Add(2*time.Minute))
+			for _, pos := range []*models.Position{home, jump, back} {
+				mustNoError(t, repo.CreatePosition(pos))
+			}
+
+			timeline, err := repo.GetTimeline(phone.ID)
+			mustNoError(t, err)
+			if len(timeline) != 3 {
+				t.Fatalf("expected suspect positions kept, got %d", len(timeline))
+			}
+			if !timeline[1].Suspect || timeline[0].Suspect || timeline[2].Suspect {
+				t.Errorf("expected only the jump flagged, got %v %v %v",
+					timeline[2].Suspect, timeline[1].Suspect, timeline[0].Suspect)
+			}
+			if clean := Clean(timeline); len(clean) != 2 || clean[0].ID != back.ID {
+				t.Errorf("expected Clean to drop the jump, got %d positions", len(clean))
+			}
+
+			events, err := repo.ListGeofenceEvents(phone.ID, uuid.Nil)
+			mustNoError(t, err)
+			if len(events) != 0 {
+				t.Errorf("expected no geofence events from the suspect jump, got %d", len(events))
+			}
+		})
+	}
+}
+
+func TestFilterPositions(t *testing.T) {
+	for name, newRepo := range geofenceBackends() {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+			base := time.Date(2024, 12, 14, 8, 0, 0, 0, time.UTC)
+			phone := models.NewItem("phone")
+			mustNoError(t, repo.CreateItem(phone))
+			mustNoError(t, repo.CreateGeofence(models.NewCircleGeofence("airport", 41.9786, -87.9048, 2000)))
+			eventCount := func() int {
+				t.Helper()
+				events, err := repo.ListGeofenceEvents(phone.ID, uuid.Nil)
+				mustNoError(t, err)
+				return len(events)
+			}
+
+			// Stored before any filter was configured
+			jump := models.NewPositionWithRecordedAt(phone.ID, 41.9786, -87.9048, nil, base.Add(time.Minute))
+			for _, pos := range []*models.Position{
+				models.NewPositionWithRecordedAt(phone.ID, 41.8781, -87.6298, nil, base),
+				jump,
+				models.NewPositionWithRecordedAt(phone.ID, 41.8791, -87.6298, nil, base.Add(2*time.Minute)),
+			} {
+				mustNoError(t, repo.CreatePosition(pos))
+			}
+			if n := eventCount(); n != 2 {
+				t.Fatalf("expected the jump to enter and leave the airport, got %d events", n)
+			}
+
+			setFilter := repo.(interface{ SetFilter(FilterConfig) }).SetFilter
+			setFilter(FilterConfig{FilterPolicy: FilterPolicy{MaxSpeedKmh: 200}})
+			flagged, cleared, err := repo.FilterPositions(phone.ID)
+			mustNoError(t, err)
+			if flagged != 1 || cleared != 0 {
+				t.Errorf("expected 1 flagged and 0 cleared, got %d and %d", flagged, cleared)
+			}
+			got, err := repo.GetPosition(jump.ID)
+			mustNoError(t, err)
+			if !got.Suspect {
+				t.Error("expected the stored jump flagged suspect")
+			}
+			if n := eventCount(); n != 0 {
+				t.Errorf("expected the suspect jump's events removed, got %d", n)
+			}
+
+			// Loosening the filter clears the flag again
+			setFilter(FilterConfig{})
+			flagged, cleared, err = repo.FilterPositions(phone.ID)
+			mustNoError(t, err)
+			if flagged != 0 || cleared != 1 {
+				t.Errorf("expected 0 flagged and 1 cleared, got %d and %d", flagged, cleared)
+			}
+			if n := eventCount(); n != 2 {
+				t.Errorf("expected the cleared jump's events restored, got %d", n)
+			}
+		})
+	}
+}
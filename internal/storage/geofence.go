@@ -0,0 +1,102 @@
+// ABOUTME: Geofence transition detection shared by storage backends
+// ABOUTME: Compares a new position and its chronological neighbours against every geofence
+
+package storage
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/harper/position/internal/geo"
+	"github.com/harper/position/internal/models"
+)
+
+// geofenceTransitions returns the enter/exit events caused by moving from prev
+// to pos, where prev is pos's chronological predecessor. A nil prev (the
+// item's earliest position) counts as outside every fence, so a first fix
+// inside a fence records an enter.
+func geofenceTransitions(fences []*models.Geofence, prev, pos *models.Position) []*models.GeofenceEvent {
+	var events []*models.GeofenceEvent
+	for _, fence := range fences {
+		wasInside := prev != nil && geo.InShape(fence.Shape, prev.Latitude, prev.Longitude)
+		isInside := geo.InShape(fence.Shape, pos.Latitude, pos.Longitude)
+		switch {
+		case !wasInside && isInside:
+			events = append(events, models.NewGeofenceEvent(fence, pos, models.GeofenceEnter))
+		case wasInside && !isInside:
+			events = append(events, models.NewGeofenceEvent(fence, pos, models.GeofenceExit))
+		}
+	}
+	return events
+}
+
+// geofenceChanges works out how an item's geofence events change when its
+// positions go from before to after, as when positions are deleted or
+// re-flagged suspect. Only trusted positions fire events, each against its
+// trusted predecessor. It returns the positions whose recorded events are
+// stale and the events that replace them; every other event stands.
+func geofenceChanges(fences []*models.Geofence, before, after []*models.Position) (stale []uuid.UUID, events []*models.GeofenceEvent) {
+	was := trustedPredecessors(before)
+	now := trustedPredecessors(after)
+	for _, pos := range before {
+		prev, trusted := was[pos.ID]
+		if !trusted {
+			continue
+		}
+		if nowPrev, ok := now[pos.ID]; !ok || !samePosition(prev, nowPrev) {
+			stale = append(stale, pos.ID)
+		}
+	}
+	for _, pos := range chronological(after) {
+		prev, trusted := now[pos.ID]
+		if !trusted {
+			continue
+		}
+		if wasPrev, ok := was[pos.ID]; !ok || !samePosition(prev, wasPrev) {
+			events = append(events, geofenceTransitions(fences, prev, pos)...)
+		}
+	}
+	return stale, events
+}
+
+// trustedPredecessors maps each trusted position's ID to the trusted
+// position recorded before it, or nil for the earliest.
+func trustedPredecessors(positions []*models.Position) map[uuid.UUID]*models.Position {
+	preds := make(map[uuid.UUID]*models.Position, len(positions))
+	var prev *models.Position
+	for _, pos := range chronological(Clean(positions)) {
+		preds[pos.ID] = prev
+		prev = pos
+	}
+	return preds
+}
+
+// withoutPosition returns positions with the one with id left out.
+func withoutPosition(positions []*models.Position, id uuid.UUID) []*models.Position {
+	remaining := make([]*models.Position, 0, len(positions))
+	for _, pos := range positions {
+		if pos.ID != id {
+			remaining = append(remaining, pos)
+		}
+	}
+	return remaining
+}
+
+// samePosition reports whether a and b are the same stored position.
+func samePosition(a, b *models.Position) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.ID == b.ID
+}
+
+// chronological returns positions sorted oldest first without reordering
+// the slice passed in.
+func chronological(positions []*models.Position) []*models.Position {
+	sorted := make([]*models.Position, len(positions))
+	copy(sorted, positions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].RecordedAt.Before(sorted[j].RecordedAt)
+	})
+	return sorted
+}
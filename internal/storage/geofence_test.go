@@ -0,0 +1,224 @@
+// ABOUTME: Tests for geofence storage and enter/exit event detection
+// ABOUTME: Runs the same scenarios against both SQLite and markdown backends
+
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/harper/position/internal/models"
+)
+
+// geofenceBackends returns constructors for each backend under test.
+func geofenceBackends() map[string]func(t *testing.T) Repository {
+	return map[string]func(t *testing.T) Repository{
+		"sqlite":   func(t *testing.T) Repository { return testDB(t) },
+		"markdown": func(t *testing.T) Repository { return newTestMarkdownStore(t) },
+	}
+}
+
+func TestGeofenceCRUD(t *testing.T) {
+	for name, newRepo := range geofenceBackends() {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+
+			circle := models.NewCircleGeofence("garage", 41.8781, -87.6298, 50)
+			polygon := models.NewPolygonGeofence("park", []models.Coordinate{
+				{Latitude: 41.0, Longitude: -88.0},
+				{Latitude: 41.0, Longitude: -87.0},
+				{Latitude: 42.0, Longitude: -87.0},
+			})
+			mustNoError(t, repo.CreateGeofence(circle))
+			mustNoError(t, repo.CreateGeofence(polygon))
+
+			if err := repo.CreateGeofence(models.NewCircleGeofence("garage", 0, 0, 10)); err == nil {
+				t.Error("expected error for duplicate geofence name")
+			}
+
+			got, err := repo.GetGeofenceByName("garage")
+			mustNoError(t, err)
+			if got.ID != circle.ID || !got.IsCircle() || got.RadiusMeters != 50 || got.Center.Latitude != 41.8781 {
+				t.Errorf("unexpected circle geofence: %+v", got)
+			}
+
+			got, err = repo.GetGeofenceByName("park")
+			mustNoError(t, err)
+			if got.IsCircle() || len(got.Polygon) != 3 || got.Polygon[2].Latitude != 42.0 {
+				t.Errorf("unexpected polygon geofence: %+v", got)
+			}
+
+			fences, err := repo.ListGeofences()
+			mustNoError(t, err)
+			if len(fences) != 2 || fences[0].Name != "garage" || fences[1].Name != "park" {
+				t.Errorf("expected geofences sorted by name, got %v", fences)
+			}
+
+			mustNoError(t, repo.DeleteGeofence(circle.ID))
+			if _, err := repo.GetGeofenceByName("garage"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected ErrNotFound after delete, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCreatePosition_GeofenceEvents(t *testing.T) {
+	for name, newRepo := range geofenceBackends() {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+
+			item := models.NewItem("car")
+			mustNoError(t, repo.CreateItem(item))
+			garage := models.NewCircleGeofence("garage", 41.8781, -87.6298, 100)
+			mustNoError(t, repo.CreateGeofence(garage))
+
+			base := time.Date(2024, 12, 14, 8, 0, 0, 0, time.UTC)
+			steps := []struct {
+				lat, lng float64
+				offset   time.Duration
+			}{
+				{41.8781, -87.6298, 0},                // first fix inside: enter
+				{41.8782, -87.6298, time.Hour},        // still inside: nothing
+				{41.9000, -87.6298, 2 * time.Hour},    // left: exit
+				{41.9100, -87.6298, 3 * time.Hour},    // still outside: nothing
+				{41.8780, -87.6298, 4 * time.Hour},    // back: enter
+				{41.9500, -87.6298, 30 * time.Minute}, // backdated outside: exit, and the 1h fix re-enters
+			}
+			for _, s := range steps {
+				pos := models.NewPositionWithRecordedAt(item.ID, s.lat, s.lng, nil, base.Add(s.offset))
+				mustNoError(t, repo.CreatePosition(pos))
+			}
+
+			events, err := repo.ListGeofenceEvents(item.ID, uuid.Nil)
+			mustNoError(t, err)
+			if len(events) != 5 {
+				t.Fatalf("expected 5 events, got %d", len(events))
+			}
+			want := []struct {
+				eventType models.GeofenceEventType
+				at        time.Time
+			}{
+				{models.GeofenceEnter, base.Add(4 * time.Hour)},
+				{models.GeofenceExit, base.Add(2 * time.Hour)},
+				{models.GeofenceEnter, base.Add(time.Hour)},
+				{models.GeofenceExit, base.Add(30 * time.Minute)},
+				{models.GeofenceEnter, base},
+			}
+			for i, w := range want {
+				if events[i].Type != w.eventType || !events[i].OccurredAt.Equal(w.at) {
+					t.Errorf("event %d: got %s at %v, want %s at %v",
+						i, events[i].Type, events[i].OccurredAt, w.eventType, w.at)
+				}
+				if events[i].GeofenceID != garage.ID {
+					t.Errorf("event %d: unexpected geofence ID", i)
+				}
+			}
+		})
+	}
+}
+
+func TestDeletePosition_GeofenceEvents(t *testing.T) {
+	for name, newRepo := range geofenceBackends() {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+
+			item := models.NewItem("car")
+			mustNoError(t, repo.CreateItem(item))
+			mustNoError(t, repo.CreateGeofence(models.NewCircleGeofence("garage", 41.8781, -87.6298, 100)))
+
+			base := time.Date(2024, 12, 14, 8, 0, 0, 0, time.UTC)
+			outside := models.NewPositionWithRecordedAt(item.ID, 41.9000, -87.6298, nil, base)
+			inside := models.NewPositionWithRecordedAt(item.ID, 41.8781, -87.6298, nil, base.Add(time.Hour))
+			left := models.NewPositionWithRecordedAt(item.ID, 41.9100, -87.6298, nil, base.Add(2*time.Hour))
+			back := models.NewPositionWithRecordedAt(item.ID, 41.8780, -87.6298, nil, base.Add(3*time.Hour))
+			for _, pos := range []*models.Position{outside, inside, left, back} {
+				mustNoError(t, repo.CreatePosition(pos))
+			}
+
+			// Without the visit at 1h the car never left, so only the 3h enter stands
+			mustNoError(t, repo.DeletePosition(inside.ID))
+
+			events, err := repo.ListGeofenceEvents(item.ID, uuid.Nil)
+			mustNoError(t, err)
+			if len(events) != 1 {
+				t.Fatalf("expected 1 event, got %d", len(events))
+			}
+			if events[0].Type != models.GeofenceEnter || events[0].PositionID != back.ID {
+				t.Errorf("expected the enter at 3h, got %s at %v", events[0].Type, events[0].OccurredAt)
+			}
+		})
+	}
+}
+
+func TestListGeofenceEvents_Filters(t *testing.T) {
+	for name, newRepo := range geofenceBackends() {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+
+			car := models.NewItem("car")
+			bike := models.NewItem("bike")
+			mustNoError(t, repo.CreateItem(car))
+			mustNoError(t, repo.CreateItem(bike))
+			home := models.NewCircleGeofence("home", 41.0, -87.0, 100)
+			work := models.NewCircleGeofence("work", 42.0, -88.0, 100)
+			mustNoError(t, repo.CreateGeofence(home))
+			mustNoError(t, repo.CreateGeofence(work))
+
+			base := time.Date(2024, 12, 14, 8, 0, 0, 0, time.UTC)
+			mustNoError(t, repo.CreatePosition(models.NewPositionWithRecordedAt(car.ID, 41.0, -87.0, nil, base)))
+			mustNoError(t, repo.CreatePosition(models.NewPositionWithRecordedAt(car.ID, 42.0, -88.0, nil, base.Add(time.Hour))))
+			mustNoError(t, repo.CreatePosition(models.NewPositionWithRecordedAt(bike.ID, 41.0, -87.0, nil, base)))
+
+			all, err := repo.ListGeofenceEvents(uuid.Nil, uuid.Nil)
+			mustNoError(t, err)
+			if len(all) != 4 {
+				t.Errorf("expected 4 events total, got %d", len(all))
+			}
+
+			carHome, err := repo.ListGeofenceEvents(car.ID, home.ID)
+			mustNoError(t, err)
+			if len(carHome) != 2 || carHome[0].Type != models.GeofenceExit {
+				t.Errorf("expected car exit+enter at home, got %v", carHome)
+			}
+
+			// Deleting the item or geofence removes its events
+			mustNoError(t, repo.DeleteItem(bike.ID))
+			mustNoError(t, repo.DeleteGeofence(work.ID))
+			remaining, err := repo.ListGeofenceEvents(uuid.Nil, uuid.Nil)
+			mustNoError(t, err)
+			if len(remaining) != 2 {
+				t.Errorf("expected 2 events after deletes, got %d", len(remaining))
+			}
+		})
+	}
+}
+
+func TestMigrateData_Geofences(t *testing.T) {
+	src := testDB(t)
+	dst := newTestMarkdownStore(t)
+
+	item := models.NewItem("car")
+	mustNoError(t, src.CreateItem(item))
+	fence := models.NewCircleGeofence("garage", 41.0, -87.0, 100)
+	mustNoError(t, src.CreateGeofence(fence))
+	mustNoError(t, src.CreatePosition(models.NewPosition(item.ID, 41.0, -87.0, nil)))
+
+	summary, err := MigrateData(src, dst)
+	mustNoError(t, err)
+	if summary.Geofences != 1 {
+		t.Errorf("expected 1 geofence migrated, got %d", summary.Geofences)
+	}
+
+	got, err := dst.GetGeofenceByName("garage")
+	mustNoError(t, err)
+	if got.ID != fence.ID {
+		t.Errorf("expected geofence ID preserved")
+	}
+	events, err := dst.ListGeofenceEvents(uuid.Nil, uuid.Nil)
+	mustNoError(t, err)
+	if len(events) != 1 || events[0].Type != models.GeofenceEnter {
+		t.Errorf("expected migrated enter event, got %v", events)
+	}
+}
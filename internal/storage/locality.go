@@ -0,0 +1,57 @@
+// ABOUTME: Reverse geocoding hook shared by storage backends
+// ABOUTME: Fills in the locality of new and stored positions from an offline geocoder
+
+package storage
+
+import (
+	"fmt"
+
+	"github.com/harper/position/internal/models"
+)
+
+// Geocoder resolves coordinates to a human-readable locality, returning ""
+// when it has none for that point.
+type Geocoder interface {
+	Locality(lat, lng float64) (string, error)
+}
+
+// applyLocality sets the locality of pos from g. A nil geocoder, or a
+// position that already has a locality, is left alone.
+func applyLocality(g Geocoder, pos *models.Position) error {
+	if g == nil || pos.Locality != nil {
+		return nil
+	}
+	locality, err := g.Locality(pos.Latitude, pos.Longitude)
+	if err != nil {
+		return fmt.Errorf("reverse geocode: %w", err)
+	}
+	if locality != "" {
+		pos.Locality = &locality
+	}
+	return nil
+}
+
+// geocodePositions fills in the locality of positions that have none,
+// passing each one it names to save. It returns how many were saved.
+func geocodePositions(g Geocoder, positions []*models.Position, save func(*models.Position) error) (int, error) {
+	if g == nil {
+		return 0, ErrNoGeocoder
+	}
+	geocoded := 0
+	for _, pos := range positions {
+		if pos.Locality != nil {
+			continue
+		}
+		if err := applyLocality(g, pos); err != nil {
+			return geocoded, err
+		}
+		if pos.Locality == nil {
+			continue
+		}
+		if err := save(pos); err != nil {
+			return geocoded, err
+		}
+		geocoded++
+	}
+	return geocoded, nil
+}
@@ -0,0 +1,157 @@
+// ABOUTME: Tests for reverse geocoded position localities
+// ABOUTME: Uses a stub geocoder against both backends and checks backfill, backups, and migration
+
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/harper/position/internal/models"
+)
+
+// stubGeocoder names everything north of the equator and counts lookups.
+type stubGeocoder struct {
+	calls int
+	err   error
+}
+
+func (g *stubGeocoder) Locality(lat, lng float64) (string, error) {
+	g.calls++
+	if g.err != nil {
+		return "", g.err
+	}
+	if lat > 0 {
+		return "Chicago, Illinois, US", nil
+	}
+	return "", nil
+}
+
+func TestCreatePosition_Locality(t *testing.T) {
+	for name, newRepo := range geofenceBackends() {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+			g := &stubGeocoder{}
+			repo.(interface{ SetGeocoder(Geocoder) }).SetGeocoder(g)
+
+			item := models.NewItem("phone")
+			mustNoError(t, repo.CreateItem(item))
+
+			north := models.NewPosition(item.ID, 41.8781, -87.6298, nil)
+			mustNoError(t, repo.CreatePosition(north))
+			got, err := repo.GetPosition(north.ID)
+			mustNoError(t, err)
+			if got.Locality == nil || *got.Locality != "Chicago, Illinois, US" {
+				t.Errorf("expected locality, got %v", got.Locality)
+			}
+
+			south := models.NewPosition(item.ID, -36.8, 174.7, nil)
+			mustNoError(t, repo.CreatePosition(south))
+			got, err = repo.GetPosition(south.ID)
+			mustNoError(t, err)
+			if got.Locality != nil {
+				t.Errorf("expected no locality outside the gazetteer, got %q", *got.Locality)
+			}
+
+			// A locality that is already set is kept and not looked up again
+			preset := "Somewhere"
+			kept := models.NewPosition(item.ID, 42.0, -88.0, nil)
+			kept.Locality = &preset
+			calls := g.calls
+			mustNoError(t, repo.CreatePosition(kept))
+			if g.calls != calls {
+				t.Error("expected no lookup for a position with a locality")
+			}
+
+			g.err = errors.New("gazetteer unreadable")
+			if err := repo.CreatePosition(models.NewPosition(item.ID, 43.0, -89.0, nil)); err == nil {
+				t.Error("expected geocoder error to fail the insert")
+			}
+		})
+	}
+}
+
+func TestGeocodePositions(t *testing.T) {
+	for name, newRepo := range geofenceBackends() {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+			item := models.NewItem("phone")
+			mustNoError(t, repo.CreateItem(item))
+
+			// History recorded before geocoding was configured
+			old := models.NewPosition(item.ID, 41.8781, -87.6298, nil)
+			south := models.NewPosition(item.ID, -36.8, 174.7, nil)
+			mustNoError(t, repo.CreatePosition(old))
+			mustNoError(t, repo.CreatePosition(south))
+
+			if _, err := repo.GeocodePositions(item.ID); !errors.Is(err, ErrNoGeocoder) {
+				t.Fatalf("expected ErrNoGeocoder without a geocoder, got %v", err)
+			}
+
+			g := &stubGeocoder{}
+			repo.(interface{ SetGeocoder(Geocoder) }).SetGeocoder(g)
+			geocoded, err := repo.GeocodePositions(item.ID)
+			mustNoError(t, err)
+			if geocoded != 1 {
+				t.Errorf("expected 1 position geocoded, got %d", geocoded)
+			}
+			got, err := repo.GetPosition(old.ID)
+			mustNoError(t, err)
+			if got.Locality == nil || *got.Locality != "Chicago, Illinois, US" {
+				t.Errorf("expected backfilled locality, got %v", got.Locality)
+			}
+
+			// Positions that already have a locality are not looked up again
+			calls := g.calls
+			geocoded, err = repo.GeocodePositions(item.ID)
+			mustNoError(t, err)
+			if geocoded != 0 || g.calls != calls+1 {
+				t.Errorf("expected only the unnamed position looked up, got %d geocoded in %d calls", geocoded, g.calls-calls)
+			}
+		})
+	}
+}
+
+func TestPositionLocality_Backup(t *testing.T) {
+	src := testDB(t)
+	src.SetGeocoder(&stubGeocoder{})
+	item := models.NewItem("phone")
+	mustNoError(t, src.CreateItem(item))
+	mustNoError(t, src.CreatePosition(models.NewPosition(item.ID, 41.8781, -87.6298, nil)))
+
+	data, err := ExportToYAML(src)
+	mustNoError(t, err)
+
+	dst := newTestMarkdownStore(t)
+	mustNoError(t, ImportFromYAML(dst, data))
+	got, err := dst.GetCurrentPosition(item.ID)
+	mustNoError(t, err)
+	if got.Locality == nil || *got.Locality != "Chicago, Illinois, US" {
+		t.Errorf("expected locality preserved in backup, got %v", got.Locality)
+	}
+}
+
+func TestSQLiteMigrate_AddsLocalityColumn(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.db")
+	db, err := NewSQLiteDB(path)
+	mustNoError(t, err)
+	_, err = db.db.Exec(`ALTER TABLE positions DROP COLUMN locality; PRAGMA user_version = 4;`)
+	mustNoError(t, err)
+	mustNoError(t, db.Close())
+
+	db, err = NewSQLiteDB(path)
+	mustNoError(t, err)
+	defer db.Close()
+	db.SetGeocoder(&stubGeocoder{})
+
+	item := models.NewItem("phone")
+	mustNoError(t, db.CreateItem(item))
+	pos := models.NewPosition(item.ID, 41.8781, -87.6298, nil)
+	mustNoError(t, db.CreatePosition(pos))
+	got, err := db.GetPosition(pos.ID)
+	mustNoError(t, err)
+	if got.Locality == nil {
+		t.Error("expected locality after migrating to the new schema")
+	}
+}
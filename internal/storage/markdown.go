@@ -12,14 +12,19 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/harper/position/internal/geo"
 	"github.com/harper/position/internal/models"
+	"github.com/harper/position/internal/track"
 	"github.com/harperreed/mdstore"
 	"gopkg.in/yaml.v3"
 )
 
 // MarkdownStore provides file-based storage for position data using markdown files and YAML.
 type MarkdownStore struct {
-	dataDir string
+	dataDir  string
+	dedup    DedupConfig
+	filter   FilterConfig
+	geocoder Geocoder
 }
 
 // Compile-time check that MarkdownStore implements Repository.
@@ -234,6 +239,10 @@ func (s *MarkdownStore) DeleteItem(id uuid.UUID) error {
 			return err
 		}
 
+		if err := s.pruneGeofenceEvents(func(e geofenceEventEntry) bool { return e.ItemID == id.String() }); err != nil {
+			return err
+		}
+
 		// Remove item directory with all positions
 		itemDir := s.itemDirPath(itemName)
 		if _, err := os.Stat(itemDir); err == nil {
@@ -254,6 +263,10 @@ type positionFrontmatter struct {
 	Label      string  `yaml:"label,omitempty"`
 	RecordedAt string  `yaml:"recorded_at"`
 	CreatedAt  string  `yaml:"created_at"`
+	Suspect    bool    `yaml:"suspect,omitempty"`
+	Locality   string  `yaml:"locality,omitempty"`
+
+	models.Telemetry `yaml:",inline"`
 }
 
 // toModel converts a positionFrontmatter to a models.Position.
@@ -279,6 +292,10 @@ func (fm *positionFrontmatter) toModel() (*models.Position, error) {
 	if fm.Label != "" {
 		label = &fm.Label
 	}
+	var locality *string
+	if fm.Locality != "" {
+		locality = &fm.Locality
+	}
 
 	return &models.Position{
 		ID:         id,
@@ -288,6 +305,9 @@ func (fm *positionFrontmatter) toModel() (*models.Position, error) {
 		Label:      label,
 		RecordedAt: recordedAt,
 		CreatedAt:  createdAt,
+		Telemetry:  fm.Telemetry,
+		Suspect:    fm.Suspect,
+		Locality:   locality,
 	}, nil
 }
 
@@ -300,10 +320,15 @@ func fromPositionModel(pos *models.Position) positionFrontmatter {
 		Longitude:  pos.Longitude,
 		RecordedAt: mdstore.FormatTime(pos.RecordedAt.UTC()),
 		CreatedAt:  mdstore.FormatTime(pos.CreatedAt.UTC()),
+		Telemetry:  pos.Telemetry,
+		Suspect:    pos.Suspect,
 	}
 	if pos.Label != nil {
 		fm.Label = *pos.Label
 	}
+	if pos.Locality != nil {
+		fm.Locality = *pos.Locality
+	}
 	return fm
 }
 
@@ -399,19 +424,59 @@ func readAllPositionsInDir(dir string) ([]*models.Position, error) {
 
 // --- Position operations ---
 
+// SetDedup sets the policy CreatePosition uses to skip repeated positions.
+func (s *MarkdownStore) SetDedup(cfg DedupConfig) {
+	s.dedup = cfg
+}
+
+// SetFilter sets the policy CreatePosition uses to flag suspect positions.
+func (s *MarkdownStore) SetFilter(cfg FilterConfig) {
+	s.filter = cfg
+}
+
+// SetGeocoder sets the geocoder CreatePosition uses to fill in localities.
+// Nil turns reverse geocoding off.
+func (s *MarkdownStore) SetGeocoder(g Geocoder) {
+	s.geocoder = g
+}
+
 // CreatePosition creates a new position with deduplication.
-// If the new position matches the current position for the item, it's silently skipped.
+// The position is compared with its chronological neighbours rather than the
+// item's latest position, so backdated and out-of-order inserts are handled
+// the same as live ones. Suspect positions are not neighbours. If it repeats
+// the position recorded just before it under the dedup policy, it's silently
+// skipped; if the outlier filter distrusts it, it's stored flagged suspect.
+// Only the caller's label counts for dedup: a stored position without one is
+// then labeled with the name of the place containing it, and gets a locality
+// when a geocoder is set. A trusted position also removes the positions
+// after it that repeat it, which live pings would have skipped. Geofence
+// enter/exit events are recorded for trusted positions that are stored, and
+// the following position's events are recomputed against the new one.
 func (s *MarkdownStore) CreatePosition(pos *models.Position) error {
-	// Check for duplicate against current position
-	current, err := s.GetCurrentPosition(pos.ItemID)
-	if err == nil && coordsEqual(current.Latitude, current.Longitude, pos.Latitude, pos.Longitude) {
-		// Same location as current position - skip
+	itemDir, err := s.resolveItemDir(pos.ItemID)
+	if err != nil {
+		return fmt.Errorf("resolve item directory: %w", err)
+	}
+
+	existing, err := readAllPositionsInDir(itemDir)
+	if err != nil {
+		return err
+	}
+	trusted := Clean(existing)
+	prev, next := track.Neighbours(trusted, pos.RecordedAt)
+	dedup := s.dedup.policyFor(s, pos.ItemID)
+	if dedup.isRepeat(prev, pos) {
 		return nil
 	}
+	pos.Suspect = s.filter.policyFor(s, pos.ItemID).isSuspect(prev, pos)
 
-	itemDir, err := s.resolveItemDir(pos.ItemID)
+	places, err := s.ListPlaces()
 	if err != nil {
-		return fmt.Errorf("resolve item directory: %w", err)
+		return err
+	}
+	applyPlaceLabel(places, pos)
+	if err := applyLocality(s.geocoder, pos); err != nil {
+		return err
 	}
 
 	if err := mdstore.EnsureDir(itemDir); err != nil {
@@ -421,7 +486,40 @@ func (s *MarkdownStore) CreatePosition(pos *models.Position) error {
 	filename := positionFileName(pos)
 	path := filepath.Join(itemDir, filename)
 
-	return writePositionFile(path, pos)
+	if err := writePositionFile(path, pos); err != nil {
+		return err
+	}
+	if pos.Suspect {
+		return nil
+	}
+
+	fences, err := s.ListGeofences()
+	if err != nil {
+		return err
+	}
+	events := geofenceTransitions(fences, prev, pos)
+	repeats, next, err := dedup.repeatedSuccessors(pos, next, func(p *models.Position) (*models.Position, error) {
+		_, after := track.Neighbours(trusted, p.RecordedAt)
+		return after, nil
+	})
+	if err != nil {
+		return err
+	}
+	replaced := make([]uuid.UUID, 0, len(repeats)+1)
+	for _, repeat := range repeats {
+		if err := os.Remove(filepath.Join(itemDir, positionFileName(repeat))); err != nil {
+			return fmt.Errorf("remove repeated position: %w", err)
+		}
+		replaced = append(replaced, repeat.ID)
+	}
+	if next != nil {
+		replaced = append(replaced, next.ID)
+		events = append(events, geofenceTransitions(fences, pos, next)...)
+	}
+	if len(replaced) == 0 {
+		return s.appendGeofenceEvents(events)
+	}
+	return s.replaceGeofenceEvents(replaced, events)
 }
 
 // GetPosition retrieves a position by its UUID.
@@ -604,7 +702,104 @@ func (s *MarkdownStore) GetAllPositionsInRange(from, to time.Time) ([]*models.Po
 	return filtered, nil
 }
 
-// DeletePosition removes a single position.
+// GetPositionsNear returns positions across all items within radiusMeters of a point, newest first.
+func (s *MarkdownStore) GetPositionsNear(lat, lng, radiusMeters float64, from, to time.Time) ([]*models.Position, error) {
+	minLat, minLng, maxLat, maxLng := geo.BoundingBox(lat, lng, radiusMeters)
+	candidates, err := s.GetPositionsInBBox(minLat, minLng, maxLat, maxLng, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return filterNear(candidates, lat, lng, radiusMeters), nil
+}
+
+// GetPositionsInBBox returns positions across all items inside a bounding box, newest first.
+func (s *MarkdownStore) GetPositionsInBBox(minLat, minLng, maxLat, maxLng float64, from, to time.Time) ([]*models.Position, error) {
+	all, err := s.GetAllPositions()
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []*models.Position
+	for _, pos := range all {
+		if inBBox(pos.Latitude, pos.Longitude, minLat, minLng, maxLat, maxLng) &&
+			inTimeWindow(pos.RecordedAt, from, to) {
+			filtered = append(filtered, pos)
+		}
+	}
+
+	return filtered, nil
+}
+
+// FilterPositions re-applies the outlier filter to an item's stored
+// positions, updating their suspect flags. Geofence events are recomputed
+// for the positions whose trusted predecessor changed, so a teleport newly
+// flagged suspect no longer enters or exits fences.
+func (s *MarkdownStore) FilterPositions(itemID uuid.UUID) (flagged, cleared int, err error) {
+	itemDir, err := s.resolveItemDir(itemID)
+	if err != nil {
+		return 0, 0, err
+	}
+	positions, err := readAllPositionsInDir(itemDir)
+	if err != nil {
+		return 0, 0, err
+	}
+	fences, err := s.ListGeofences()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	flags := s.filter.policyFor(s, itemID).Flag(positions)
+	refiltered := make([]*models.Position, len(positions))
+	for i, pos := range positions {
+		c := *pos
+		refiltered[i] = &c
+		suspect := flags[pos.ID]
+		if suspect == pos.Suspect {
+			continue
+		}
+		c.Suspect = suspect
+		if err := writePositionFile(filepath.Join(itemDir, positionFileName(&c)), &c); err != nil {
+			return flagged, cleared, err
+		}
+		if suspect {
+			flagged++
+		} else {
+			cleared++
+		}
+	}
+	if flagged+cleared == 0 {
+		return 0, 0, nil
+	}
+
+	stale, events := geofenceChanges(fences, positions, refiltered)
+	if err := s.replaceGeofenceEvents(stale, events); err != nil {
+		return flagged, cleared, err
+	}
+	return flagged, cleared, nil
+}
+
+// GeocodePositions fills in the locality of an item's stored positions that
+// have none, using the geocoder set by SetGeocoder.
+func (s *MarkdownStore) GeocodePositions(itemID uuid.UUID) (geocoded int, err error) {
+	if s.geocoder == nil {
+		return 0, ErrNoGeocoder
+	}
+	itemDir, err := s.resolveItemDir(itemID)
+	if err != nil {
+		return 0, err
+	}
+	positions, err := readAllPositionsInDir(itemDir)
+	if err != nil {
+		return 0, err
+	}
+	return geocodePositions(s.geocoder, positions, func(pos *models.Position) error {
+		return writePositionFile(filepath.Join(itemDir, positionFileName(pos)), pos)
+	})
+}
+
+// DeletePosition removes a single position. Geofence events are
+// recomputed around it, so the fences it entered or exited no longer list it
+// and the next trusted position is compared with the one before.
 func (s *MarkdownStore) DeletePosition(id uuid.UUID) error {
 	items, err := s.readItems()
 	if err != nil {
@@ -613,24 +808,462 @@ func (s *MarkdownStore) DeletePosition(id uuid.UUID) error {
 
 	for _, item := range items {
 		dir := s.itemDirPath(item.Name)
-		entries, err := os.ReadDir(dir)
+		positions, err := readAllPositionsInDir(dir)
 		if err != nil {
 			continue
 		}
-		for _, entry := range entries {
-			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
+		for _, pos := range positions {
+			if pos.ID != id {
 				continue
 			}
-			path := filepath.Join(dir, entry.Name())
-			pos, err := readPositionFile(path)
+			fences, err := s.ListGeofences()
 			if err != nil {
-				continue
+				return err
 			}
-			if pos.ID == id {
-				return os.Remove(path)
+			if err := os.Remove(filepath.Join(dir, positionFileName(pos))); err != nil {
+				return err
 			}
+			stale, events := geofenceChanges(fences, positions, withoutPosition(positions, id))
+			return s.replaceGeofenceEvents(stale, events)
 		}
 	}
 
 	return nil
 }
+
+// --- Geofence YAML types ---
+
+// geofenceEntry represents a single geofence in the _geofences.yaml file.
+type geofenceEntry struct {
+	ID           string `yaml:"id"`
+	Name         string `yaml:"name"`
+	models.Shape `yaml:",inline"`
+	CreatedAt    string `yaml:"created_at"`
+}
+
+// toModel converts a geofenceEntry to a models.Geofence.
+func (e *geofenceEntry) toModel() (*models.Geofence, error) {
+	id, err := uuid.Parse(e.ID)
+	if err != nil {
+		return nil, fmt.Errorf("parse geofence ID %q: %w", e.ID, err)
+	}
+	createdAt, err := mdstore.ParseTime(e.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("parse geofence created_at %q: %w", e.CreatedAt, err)
+	}
+	return &models.Geofence{
+		ID:        id,
+		Name:      e.Name,
+		Shape:     e.Shape,
+		CreatedAt: createdAt,
+	}, nil
+}
+
+// fromGeofenceModel converts a models.Geofence to a geofenceEntry.
+func fromGeofenceModel(fence *models.Geofence) geofenceEntry {
+	return geofenceEntry{
+		ID:        fence.ID.String(),
+		Name:      fence.Name,
+		Shape:     fence.Shape,
+		CreatedAt: mdstore.FormatTime(fence.CreatedAt.UTC()),
+	}
+}
+
+// geofenceEventEntry represents a single event in the _geofence_events.yaml file.
+type geofenceEventEntry struct {
+	ID         string `yaml:"id"`
+	GeofenceID string `yaml:"geofence_id"`
+	ItemID     string `yaml:"item_id"`
+	PositionID string `yaml:"position_id"`
+	Type       string `yaml:"type"`
+	OccurredAt string `yaml:"occurred_at"`
+}
+
+// toModel converts a geofenceEventEntry to a models.GeofenceEvent.
+func (e *geofenceEventEntry) toModel() (*models.GeofenceEvent, error) {
+	id, err := uuid.Parse(e.ID)
+	if err != nil {
+		return nil, fmt.Errorf("parse geofence event ID %q: %w", e.ID, err)
+	}
+	fenceID, err := uuid.Parse(e.GeofenceID)
+	if err != nil {
+		return nil, fmt.Errorf("parse geofence ID %q: %w", e.GeofenceID, err)
+	}
+	itemID, err := uuid.Parse(e.ItemID)
+	if err != nil {
+		return nil, fmt.Errorf("parse item ID %q: %w", e.ItemID, err)
+	}
+	posID, err := uuid.Parse(e.PositionID)
+	if err != nil {
+		return nil, fmt.Errorf("parse position ID %q: %w", e.PositionID, err)
+	}
+	occurredAt, err := mdstore.ParseTime(e.OccurredAt)
+	if err != nil {
+		return nil, fmt.Errorf("parse occurred_at %q: %w", e.OccurredAt, err)
+	}
+	return &models.GeofenceEvent{
+		ID:         id,
+		GeofenceID: fenceID,
+		ItemID:     itemID,
+		PositionID: posID,
+		Type:       models.GeofenceEventType(e.Type),
+		OccurredAt: occurredAt,
+	}, nil
+}
+
+// fromGeofenceEventModel converts a models.GeofenceEvent to a geofenceEventEntry.
+func fromGeofenceEventModel(e *models.GeofenceEvent) geofenceEventEntry {
+	return geofenceEventEntry{
+		ID:         e.ID.String(),
+		GeofenceID: e.GeofenceID.String(),
+		ItemID:     e.ItemID.String(),
+		PositionID: e.PositionID.String(),
+		Type:       string(e.Type),
+		OccurredAt: mdstore.FormatTime(e.OccurredAt.UTC()),
+	}
+}
+
+// --- Geofence file paths ---
+
+// geofencesFilePath returns the path to the _geofences.yaml file.
+func (s *MarkdownStore) geofencesFilePath() string {
+	return filepath.Join(s.dataDir, "_geofences.yaml")
+}
+
+// geofenceEventsFilePath returns the path to the _geofence_events.yaml file.
+func (s *MarkdownStore) geofenceEventsFilePath() string {
+	return filepath.Join(s.dataDir, "_geofence_events.yaml")
+}
+
+// readGeofences reads the _geofences.yaml file.
+func (s *MarkdownStore) readGeofences() ([]geofenceEntry, error) {
+	var entries []geofenceEntry
+	if err := mdstore.ReadYAML(s.geofencesFilePath(), &entries); err != nil {
+		return nil, fmt.Errorf("read geofences file: %w", err)
+	}
+	return entries, nil
+}
+
+// readGeofenceEvents reads the _geofence_events.yaml file.
+func (s *MarkdownStore) readGeofenceEvents() ([]geofenceEventEntry, error) {
+	var entries []geofenceEventEntry
+	if err := mdstore.ReadYAML(s.geofenceEventsFilePath(), &entries); err != nil {
+		return nil, fmt.Errorf("read geofence events file: %w", err)
+	}
+	return entries, nil
+}
+
+// appendGeofenceEvents adds events to the _geofence_events.yaml file.
+func (s *MarkdownStore) appendGeofenceEvents(events []*models.GeofenceEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return mdstore.WithLock(s.dataDir, func() error {
+		entries, err := s.readGeofenceEvents()
+		if err != nil {
+			return err
+		}
+		for _, e := range events {
+			entries = append(entries, fromGeofenceEventModel(e))
+		}
+		return mdstore.WriteYAML(s.geofenceEventsFilePath(), entries)
+	})
+}
+
+// replaceGeofenceEvents drops the events recorded for positionIDs and adds events.
+func (s *MarkdownStore) replaceGeofenceEvents(positionIDs []uuid.UUID, events []*models.GeofenceEvent) error {
+	drop := make(map[string]bool, len(positionIDs))
+	for _, id := range positionIDs {
+		drop[id.String()] = true
+	}
+	return mdstore.WithLock(s.dataDir, func() error {
+		entries, err := s.readGeofenceEvents()
+		if err != nil {
+			return err
+		}
+		var remaining []geofenceEventEntry
+		for _, e := range entries {
+			if !drop[e.PositionID] {
+				remaining = append(remaining, e)
+			}
+		}
+		for _, e := range events {
+			remaining = append(remaining, fromGeofenceEventModel(e))
+		}
+		if len(entries) == 0 && len(remaining) == 0 {
+			return nil
+		}
+		return mdstore.WriteYAML(s.geofenceEventsFilePath(), remaining)
+	})
+}
+
+// pruneGeofenceEvents removes events matching drop. Callers must hold the lock.
+func (s *MarkdownStore) pruneGeofenceEvents(drop func(geofenceEventEntry) bool) error {
+	entries, err := s.readGeofenceEvents()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	var remaining []geofenceEventEntry
+	for _, e := range entries {
+		if !drop(e) {
+			remaining = append(remaining, e)
+		}
+	}
+	return mdstore.WriteYAML(s.geofenceEventsFilePath(), remaining)
+}
+
+// --- Geofence operations ---
+
+// CreateGeofence creates a new geofence. Names must be unique.
+func (s *MarkdownStore) CreateGeofence(fence *models.Geofence) error {
+	return mdstore.WithLock(s.dataDir, func() error {
+		entries, err := s.readGeofences()
+		if err != nil {
+			return err
+		}
+
+		for _, e := range entries {
+			if e.Name == fence.Name {
+				return fmt.Errorf("geofence with name %q already exists", fence.Name)
+			}
+		}
+
+		entries = append(entries, fromGeofenceModel(fence))
+		return mdstore.WriteYAML(s.geofencesFilePath(), entries)
+	})
+}
+
+// GetGeofenceByName retrieves a geofence by its name.
+func (s *MarkdownStore) GetGeofenceByName(name string) (*models.Geofence, error) {
+	entries, err := s.readGeofences()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		if e.Name == name {
+			return e.toModel()
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// ListGeofences returns all geofences sorted by name.
+func (s *MarkdownStore) ListGeofences() ([]*models.Geofence, error) {
+	entries, err := s.readGeofences()
+	if err != nil {
+		return nil, err
+	}
+
+	var fences []*models.Geofence
+	for _, e := range entries {
+		fence, err := e.toModel()
+		if err != nil {
+			// Skip malformed entries
+			continue
+		}
+		fences = append(fences, fence)
+	}
+
+	sort.Slice(fences, func(i, j int) bool {
+		return fences[i].Name < fences[j].Name
+	})
+
+	return fences, nil
+}
+
+// DeleteGeofence removes a geofence and its events.
+func (s *MarkdownStore) DeleteGeofence(id uuid.UUID) error {
+	return mdstore.WithLock(s.dataDir, func() error {
+		entries, err := s.readGeofences()
+		if err != nil {
+			return err
+		}
+
+		var remaining []geofenceEntry
+		for _, e := range entries {
+			if e.ID != id.String() {
+				remaining = append(remaining, e)
+			}
+		}
+
+		if len(remaining) == len(entries) {
+			// Geofence not found, but match SQLite behavior (no error)
+			return nil
+		}
+
+		if err := mdstore.WriteYAML(s.geofencesFilePath(), remaining); err != nil {
+			return err
+		}
+		return s.pruneGeofenceEvents(func(e geofenceEventEntry) bool { return e.GeofenceID == id.String() })
+	})
+}
+
+// ListGeofenceEvents returns geofence events, newest first.
+// A uuid.Nil itemID or geofenceID matches any item or geofence.
+func (s *MarkdownStore) ListGeofenceEvents(itemID, geofenceID uuid.UUID) ([]*models.GeofenceEvent, error) {
+	entries, err := s.readGeofenceEvents()
+	if err != nil {
+		return nil, err
+	}
+
+	var events []*models.GeofenceEvent
+	for _, entry := range entries {
+		e, err := entry.toModel()
+		if err != nil {
+			// Skip malformed entries
+			continue
+		}
+		if itemID != uuid.Nil && e.ItemID != itemID {
+			continue
+		}
+		if geofenceID != uuid.Nil && e.GeofenceID != geofenceID {
+			continue
+		}
+		events = append(events, e)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].OccurredAt.After(events[j].OccurredAt)
+	})
+
+	return events, nil
+}
+
+// --- Place YAML types ---
+
+// placeEntry represents a single place in the _places.yaml file.
+type placeEntry struct {
+	ID           string `yaml:"id"`
+	Name         string `yaml:"name"`
+	models.Shape `yaml:",inline"`
+	CreatedAt    string `yaml:"created_at"`
+}
+
+// toModel converts a placeEntry to a models.Place.
+func (e *placeEntry) toModel() (*models.Place, error) {
+	id, err := uuid.Parse(e.ID)
+	if err != nil {
+		return nil, fmt.Errorf("parse place ID %q: %w", e.ID, err)
+	}
+	createdAt, err := mdstore.ParseTime(e.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("parse place created_at %q: %w", e.CreatedAt, err)
+	}
+	return &models.Place{
+		ID:        id,
+		Name:      e.Name,
+		Shape:     e.Shape,
+		CreatedAt: createdAt,
+	}, nil
+}
+
+// fromPlaceModel converts a models.Place to a placeEntry.
+func fromPlaceModel(place *models.Place) placeEntry {
+	return placeEntry{
+		ID:        place.ID.String(),
+		Name:      place.Name,
+		Shape:     place.Shape,
+		CreatedAt: mdstore.FormatTime(place.CreatedAt.UTC()),
+	}
+}
+
+// placesFilePath returns the path to the _places.yaml file.
+func (s *MarkdownStore) placesFilePath() string {
+	return filepath.Join(s.dataDir, "_places.yaml")
+}
+
+// readPlaces reads the _places.yaml file.
+func (s *MarkdownStore) readPlaces() ([]placeEntry, error) {
+	var entries []placeEntry
+	if err := mdstore.ReadYAML(s.placesFilePath(), &entries); err != nil {
+		return nil, fmt.Errorf("read places file: %w", err)
+	}
+	return entries, nil
+}
+
+// --- Place operations ---
+
+// CreatePlace creates a new place. Names must be unique.
+func (s *MarkdownStore) CreatePlace(place *models.Place) error {
+	return mdstore.WithLock(s.dataDir, func() error {
+		entries, err := s.readPlaces()
+		if err != nil {
+			return err
+		}
+
+		for _, e := range entries {
+			if e.Name == place.Name {
+				return fmt.Errorf("place with name %q already exists", place.Name)
+			}
+		}
+
+		entries = append(entries, fromPlaceModel(place))
+		return mdstore.WriteYAML(s.placesFilePath(), entries)
+	})
+}
+
+// GetPlaceByName retrieves a place by its name.
+func (s *MarkdownStore) GetPlaceByName(name string) (*models.Place, error) {
+	entries, err := s.readPlaces()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		if e.Name == name {
+			return e.toModel()
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// ListPlaces returns all places sorted by name.
+func (s *MarkdownStore) ListPlaces() ([]*models.Place, error) {
+	entries, err := s.readPlaces()
+	if err != nil {
+		return nil, err
+	}
+
+	var places []*models.Place
+	for _, e := range entries {
+		place, err := e.toModel()
+		if err != nil {
+			// Skip malformed entries
+			continue
+		}
+		places = append(places, place)
+	}
+
+	sort.Slice(places, func(i, j int) bool {
+		return places[i].Name < places[j].Name
+	})
+
+	return places, nil
+}
+
+// DeletePlace removes a place. Positions it already labeled keep their label.
+func (s *MarkdownStore) DeletePlace(id uuid.UUID) error {
+	return mdstore.WithLock(s.dataDir, func() error {
+		entries, err := s.readPlaces()
+		if err != nil {
+			return err
+		}
+
+		var remaining []placeEntry
+		for _, e := range entries {
+			if e.ID != id.String() {
+				remaining = append(remaining, e)
+			}
+		}
+
+		if len(remaining) == len(entries) {
+			// Place not found, but match SQLite behavior (no error)
+			return nil
+		}
+		return mdstore.WriteYAML(s.placesFilePath(), remaining)
+	})
+}
@@ -1,5 +1,5 @@
 // ABOUTME: Data migration between position storage backends
-// ABOUTME: Copies items and positions from source to destination repository
+// ABOUTME: Copies items, positions, geofences, and places from source to destination repository
 
 package storage
 
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/google/uuid"
 	"github.com/harper/position/internal/models"
 	"github.com/harperreed/mdstore"
 )
@@ -16,6 +17,8 @@ import (
 type MigrateSummary struct {
 	Items     int
 	Positions int
+	Geofences int
+	Places    int
 }
 
 // MigrateData copies all data from src to dst storage.
@@ -55,9 +58,45 @@ func MigrateData(src, dst Repository) (*MigrateSummary, error) {
 		}
 	}
 
+	if err := migrateGeofences(src, dst, summary); err != nil {
+		return nil, err
+	}
+
+	places, err := src.ListPlaces()
+	if err != nil {
+		return nil, fmt.Errorf("list source places: %w", err)
+	}
+	for _, place := range places {
+		if err := dst.CreatePlace(place); err != nil {
+			return nil, fmt.Errorf("create place %q: %w", place.Name, err)
+		}
+		summary.Places++
+	}
+
 	return summary, nil
 }
 
+// migrateGeofences copies geofences and their recorded events. Events are
+// copied as-is rather than recomputed, since positions bypass CreatePosition.
+func migrateGeofences(src, dst Repository, summary *MigrateSummary) error {
+	fences, err := src.ListGeofences()
+	if err != nil {
+		return fmt.Errorf("list source geofences: %w", err)
+	}
+	for _, fence := range fences {
+		if err := dst.CreateGeofence(fence); err != nil {
+			return fmt.Errorf("create geofence %q: %w", fence.Name, err)
+		}
+		summary.Geofences++
+	}
+
+	events, err := src.ListGeofenceEvents(uuid.Nil, uuid.Nil)
+	if err != nil {
+		return fmt.Errorf("list source geofence events: %w", err)
+	}
+	return insertGeofenceEventsDirect(dst, events)
+}
+
 // createPositionDirect creates a position without deduplication.
 // For SQLiteDB, it uses direct SQL insert.
 // For MarkdownStore, it writes the file directly.
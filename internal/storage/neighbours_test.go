@@ -0,0 +1,161 @@
+// ABOUTME: Tests for out-of-order and backdated position inserts
+// ABOUTME: Verifies dedup and geofence events use chronological neighbours on both backends
+
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/harper/position/internal/models"
+)
+
+func TestCreatePosition_BackdatedDedup(t *testing.T) {
+	for name, newRepo := range geofenceBackends() {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+			item := models.NewItem("phone")
+			mustNoError(t, repo.CreateItem(item))
+
+			base := time.Date(2024, 12, 14, 8, 0, 0, 0, time.UTC)
+			mustNoError(t, repo.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 41.0, -87.0, nil, base)))
+			mustNoError(t, repo.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 42.0, -88.0, nil, base.Add(2*time.Hour))))
+
+			// Repeats the current position, but its predecessor is elsewhere: kept
+			mustNoError(t, repo.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 42.0, -88.0, nil, base.Add(-time.Hour))))
+			// Repeats its predecessor at 08:00: skipped
+			mustNoError(t, repo.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 41.0, -87.0, nil, base.Add(time.Hour))))
+
+			timeline, err := repo.GetTimeline(item.ID)
+			mustNoError(t, err)
+			if len(timeline) != 3 {
+				t.Fatalf("expected 3 positions, got %d", len(timeline))
+			}
+			if !timeline[2].RecordedAt.Equal(base.Add(-time.Hour)) {
+				t.Errorf("expected backdated position kept as earliest, got %v", timeline[2].RecordedAt)
+			}
+		})
+	}
+}
+
+func TestCreatePosition_BackdatedRepeatOfNext(t *testing.T) {
+	for name, newRepo := range geofenceBackends() {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+			item := models.NewItem("phone")
+			mustNoError(t, repo.CreateItem(item))
+			mustNoError(t, repo.CreateGeofence(models.NewCircleGeofence("office", 42.0, -88.0, 100)))
+
+			base := time.Date(2024, 12, 14, 8, 0, 0, 0, time.UTC)
+			mustNoError(t, repo.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 41.0, -87.0, nil, base)))
+			mustNoError(t, repo.CreatePosition(models.NewPositionWithRecordedAt(item.ID, 42.0, -88.0, nil, base.Add(2*time.Hour))))
+
+			// Arrives late, just before an identical fix. Live pings in
+			// order would have kept it and skipped the 10:00 repeat.
+			early := models.NewPositionWithRecordedAt(item.ID, 42.0, -88.0, nil, base.Add(time.Hour))
+			mustNoError(t, repo.CreatePosition(early))
+
+			timeline, err := repo.GetTimeline(item.ID)
+			mustNoError(t, err)
+			if len(timeline) != 2 {
+				t.Fatalf("expected 2 positions, got %d", len(timeline))
+			}
+			if timeline[0].ID != early.ID {
+				t.Errorf("expected the backdated fix to replace its repeat, got %v", timeline[0].RecordedAt)
+			}
+
+			events, err := repo.ListGeofenceEvents(item.ID, uuid.Nil)
+			mustNoError(t, err)
+			if len(events) != 1 || events[0].PositionID != early.ID || events[0].Type != models.GeofenceEnter {
+				t.Errorf("expected one enter at the backdated fix, got %+v", events)
+			}
+		})
+	}
+}
+
+// TestCreatePosition_LateBatchMatchesLive checks that a batch arriving after
+// later live pings yields the same history as receiving every fix in order.
+func TestCreatePosition_LateBatchMatchesLive(t *testing.T) {
+	base := time.Date(2024, 12, 14, 8, 0, 0, 0, time.UTC)
+	type fix struct {
+		lat, lng float64
+		offset   time.Duration
+	}
+	fixes := []fix{
+		{41.8781, -87.6298, 0},                // home: enter
+		{41.8782, -87.6298, 10 * time.Minute}, // ~11m from home: repeat
+		{41.9000, -87.6298, 20 * time.Minute}, // away: exit
+		{41.9001, -87.6298, 30 * time.Minute}, // ~11m from away: repeat
+		{41.8790, -87.6298, 40 * time.Minute}, // back: enter
+	}
+	live := []int{0, 4}
+	batch := []int{1, 2, 3}
+
+	type entry struct {
+		at        time.Time
+		eventType models.GeofenceEventType
+	}
+	history := func(t *testing.T, repo Repository, order []int) ([]time.Time, []entry) {
+		item := models.NewItem("phone")
+		mustNoError(t, repo.CreateItem(item))
+		mustNoError(t, repo.CreateGeofence(models.NewCircleGeofence("home", 41.8781, -87.6298, 200)))
+		for _, i := range order {
+			f := fixes[i]
+			mustNoError(t, repo.CreatePosition(models.NewPositionWithRecordedAt(item.ID, f.lat, f.lng, nil, base.Add(f.offset))))
+		}
+		timeline, err := repo.GetTimeline(item.ID)
+		mustNoError(t, err)
+		var times []time.Time
+		for _, p := range timeline {
+			times = append(times, p.RecordedAt)
+		}
+		events, err := repo.ListGeofenceEvents(item.ID, uuid.Nil)
+		mustNoError(t, err)
+		var entries []entry
+		for _, e := range events {
+			entries = append(entries, entry{e.OccurredAt, e.Type})
+		}
+		return times, entries
+	}
+
+	backends := map[string]func(t *testing.T, cfg DedupConfig) Repository{
+		"sqlite": func(t *testing.T, cfg DedupConfig) Repository {
+			db := testDB(t)
+			db.SetDedup(cfg)
+			return db
+		},
+		"markdown": func(t *testing.T, cfg DedupConfig) Repository {
+			store := newTestMarkdownStore(t)
+			store.SetDedup(cfg)
+			return store
+		},
+	}
+	cfg := DedupConfig{DedupPolicy: DedupPolicy{DistanceMeters: 25}}
+	for name, newRepo := range backends {
+		t.Run(name, func(t *testing.T) {
+			wantTimes, wantEvents := history(t, newRepo(t, cfg), []int{0, 1, 2, 3, 4})
+			gotTimes, gotEvents := history(t, newRepo(t, cfg), append(live, batch...))
+
+			if len(wantTimes) != 3 || len(wantEvents) != 3 {
+				t.Fatalf("unexpected in-order history: %v %v", wantTimes, wantEvents)
+			}
+			if len(gotTimes) != len(wantTimes) {
+				t.Fatalf("timeline: got %v, want %v", gotTimes, wantTimes)
+			}
+			for i := range wantTimes {
+				if !gotTimes[i].Equal(wantTimes[i]) {
+					t.Errorf("timeline[%d]: got %v, want %v", i, gotTimes[i], wantTimes[i])
+				}
+			}
+			if len(gotEvents) != len(wantEvents) {
+				t.Fatalf("events: got %v, want %v", gotEvents, wantEvents)
+			}
+			for i := range wantEvents {
+				if !gotEvents[i].at.Equal(wantEvents[i].at) || gotEvents[i].eventType != wantEvents[i].eventType {
+					t.Errorf("event[%d]: got %v, want %v", i, gotEvents[i], wantEvents[i])
+				}
+			}
+		})
+	}
+}
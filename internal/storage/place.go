@@ -0,0 +1,21 @@
+// ABOUTME: Automatic position labeling from named places shared by storage backends
+// ABOUTME: Fills in the label of unlabeled positions with the name of the place containing them
+
+package storage
+
+import (
+	"github.com/harper/position/internal/geo"
+	"github.com/harper/position/internal/models"
+)
+
+// applyPlaceLabel labels pos with the name of the smallest place containing
+// it. Positions that already carry a label are left alone.
+func applyPlaceLabel(places []*models.Place, pos *models.Position) {
+	if pos.Label != nil {
+		return
+	}
+	if place := geo.MatchPlace(places, pos.Latitude, pos.Longitude); place != nil {
+		name := place.Name
+		pos.Label = &name
+	}
+}
@@ -0,0 +1,156 @@
+// ABOUTME: Tests for named place storage and automatic position labeling
+// ABOUTME: Runs the same scenarios against both SQLite and markdown backends
+
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/harper/position/internal/models"
+)
+
+func TestPlaceCRUD(t *testing.T) {
+	for name, newRepo := range geofenceBackends() {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+
+			home := models.NewPlace("home", 41.8781, -87.6298, 100)
+			park := models.NewPolygonPlace("park", []models.Coordinate{
+				{Latitude: 41.0, Longitude: -88.0},
+				{Latitude: 41.0, Longitude: -87.0},
+				{Latitude: 42.0, Longitude: -87.0},
+			})
+			mustNoError(t, repo.CreatePlace(home))
+			mustNoError(t, repo.CreatePlace(park))
+
+			if err := repo.CreatePlace(models.NewPlace("home", 0, 0, 10)); err == nil {
+				t.Error("expected error for duplicate place name")
+			}
+
+			got, err := repo.GetPlaceByName("home")
+			mustNoError(t, err)
+			if got.ID != home.ID || got.RadiusMeters != 100 || got.Center.Latitude != 41.8781 || len(got.Polygon) != 0 {
+				t.Errorf("unexpected circular place: %+v", got)
+			}
+
+			got, err = repo.GetPlaceByName("park")
+			mustNoError(t, err)
+			if len(got.Polygon) != 3 || got.Polygon[2].Latitude != 42.0 || got.IsCircle() {
+				t.Errorf("unexpected polygon place: %+v", got)
+			}
+
+			places, err := repo.ListPlaces()
+			mustNoError(t, err)
+			if len(places) != 2 || places[0].Name != "home" || places[1].Name != "park" {
+				t.Errorf("expected places sorted by name, got %v", places)
+			}
+
+			mustNoError(t, repo.DeletePlace(home.ID))
+			if _, err := repo.GetPlaceByName("home"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected ErrNotFound after delete, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCreatePosition_PlaceLabel(t *testing.T) {
+	for name, newRepo := range geofenceBackends() {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+			base := time.Date(2024, 12, 14, 8, 0, 0, 0, time.UTC)
+
+			phone := models.NewItem("phone")
+			mustNoError(t, repo.CreateItem(phone))
+			mustNoError(t, repo.CreatePlace(models.NewPlace("campus", 41.8781, -87.6298, 2000)))
+			mustNoError(t, repo.CreatePlace(models.NewPlace("office", 41.8800, -87.6300, 50)))
+
+			gym := "gym"
+			tests := []struct {
+				name     string
+				lat, lng float64
+				label    *string
+				want     string
+			}{
+				{"inside", 41.8781, -87.6298, nil, "campus"},
+				{"nested", 41.8800, -87.6300, nil, "office"},
+				{"explicit_label", 41.8800, -87.6301, &gym, "gym"},
+				{"outside", 40.7128, -74.0060, nil, ""},
+			}
+			for i, tt := range tests {
+				pos := models.NewPositionWithRecordedAt(phone.ID, tt.lat, tt.lng, tt.label, base.Add(time.Duration(i)*time.Hour))
+				mustNoError(t, repo.CreatePosition(pos))
+
+				got, err := repo.GetPosition(pos.ID)
+				mustNoError(t, err)
+				var label string
+				if got.Label != nil {
+					label = *got.Label
+				}
+				if label != tt.want {
+					t.Errorf("%s: expected label %q, got %q", tt.name, tt.want, label)
+				}
+			}
+		})
+	}
+}
+
+func TestCreatePosition_PlaceLabelKeepsDedup(t *testing.T) {
+	for name, newRepo := range geofenceBackends() {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+			repo.(interface{ SetDedup(DedupConfig) }).SetDedup(DedupConfig{
+				DedupPolicy: DedupPolicy{DistanceMeters: 50, KeepLabeled: true},
+			})
+			base := time.Date(2024, 12, 14, 8, 0, 0, 0, time.UTC)
+
+			phone := models.NewItem("phone")
+			mustNoError(t, repo.CreateItem(phone))
+			mustNoError(t, repo.CreatePlace(models.NewPlace("home", 41.8781, -87.6298, 100)))
+
+			// A phone sitting at home reports every few minutes
+			for i := 0; i < 5; i++ {
+				pos := models.NewPositionWithRecordedAt(phone.ID, 41.8781+float64(i)*0.00001, -87.6298, nil, base.Add(time.Duration(i)*5*time.Minute))
+				mustNoError(t, repo.CreatePosition(pos))
+			}
+			timeline, err := repo.GetTimeline(phone.ID)
+			mustNoError(t, err)
+			if len(timeline) != 1 {
+				t.Fatalf("expected place labels not to defeat dedup, got %d positions", len(timeline))
+			}
+			if timeline[0].Label == nil || *timeline[0].Label != "home" {
+				t.Errorf("expected the kept position to be labeled home, got %v", timeline[0].Label)
+			}
+
+			// A label the caller supplies is still always kept
+			note := "home"
+			mustNoError(t, repo.CreatePosition(models.NewPositionWithRecordedAt(phone.ID, 41.8781, -87.6298, &note, base.Add(time.Hour))))
+			timeline, err = repo.GetTimeline(phone.ID)
+			mustNoError(t, err)
+			if len(timeline) != 2 {
+				t.Errorf("expected an explicitly labeled position to be kept, got %d positions", len(timeline))
+			}
+		})
+	}
+}
+
+func TestMigrateData_Places(t *testing.T) {
+	src := testDB(t)
+	dst := newTestMarkdownStore(t)
+
+	place := models.NewPlace("home", 41.0, -87.0, 100)
+	mustNoError(t, src.CreatePlace(place))
+
+	summary, err := MigrateData(src, dst)
+	mustNoError(t, err)
+	if summary.Places != 1 {
+		t.Errorf("expected 1 place migrated, got %d", summary.Places)
+	}
+
+	got, err := dst.GetPlaceByName("home")
+	mustNoError(t, err)
+	if got.ID != place.ID || got.RadiusMeters != 100 {
+		t.Errorf("expected place preserved, got %+v", got)
+	}
+}
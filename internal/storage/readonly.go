@@ -0,0 +1,41 @@
+// ABOUTME: Read-only repository wrapper
+// ABOUTME: Passes reads through to any backend and rejects every write with ErrReadOnly
+
+package storage
+
+import (
+	"github.com/google/uuid"
+	"github.com/harper/position/internal/models"
+)
+
+// readOnlyRepository wraps a Repository, rejecting writes.
+type readOnlyRepository struct {
+	Repository
+}
+
+// ReadOnly returns a view of repo whose writes fail with ErrReadOnly. Reads,
+// Sync, and Close go to repo.
+func ReadOnly(repo Repository) Repository {
+	if _, ok := repo.(readOnlyRepository); ok {
+		return repo
+	}
+	return readOnlyRepository{Repository: repo}
+}
+
+func (readOnlyRepository) Reset() error                          { return ErrReadOnly }
+func (readOnlyRepository) CreateItem(*models.Item) error         { return ErrReadOnly }
+func (readOnlyRepository) DeleteItem(uuid.UUID) error            { return ErrReadOnly }
+func (readOnlyRepository) CreatePosition(*models.Position) error { return ErrReadOnly }
+func (readOnlyRepository) DeletePosition(uuid.UUID) error        { return ErrReadOnly }
+func (readOnlyRepository) CreateGeofence(*models.Geofence) error { return ErrReadOnly }
+func (readOnlyRepository) DeleteGeofence(uuid.UUID) error        { return ErrReadOnly }
+func (readOnlyRepository) CreatePlace(*models.Place) error       { return ErrReadOnly }
+func (readOnlyRepository) DeletePlace(uuid.UUID) error           { return ErrReadOnly }
+
+func (readOnlyRepository) FilterPositions(uuid.UUID) (flagged, cleared int, err error) {
+	return 0, 0, ErrReadOnly
+}
+
+func (readOnlyRepository) GeocodePositions(uuid.UUID) (geocoded int, err error) {
+	return 0, ErrReadOnly
+}
@@ -0,0 +1,72 @@
+// ABOUTME: Tests for the read-only repository wrapper
+// ABOUTME: Checks that reads pass through and every write fails with ErrReadOnly
+
+package storage
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/harper/position/internal/models"
+)
+
+func TestReadOnly(t *testing.T) {
+	for name, newRepo := range geofenceBackends() {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+			item := models.NewItem("phone")
+			mustNoError(t, repo.CreateItem(item))
+			pos := models.NewPosition(item.ID, 41.8781, -87.6298, nil)
+			mustNoError(t, repo.CreatePosition(pos))
+
+			ro := ReadOnly(repo)
+			if ReadOnly(ro) != ro {
+				t.Error("expected wrapping twice to return the same view")
+			}
+
+			got, err := ro.GetItemByName("phone")
+			mustNoError(t, err)
+			if got.ID != item.ID {
+				t.Errorf("expected item %s, got %s", item.ID, got.ID)
+			}
+			timeline, err := ro.GetTimeline(item.ID)
+			mustNoError(t, err)
+			if len(timeline) != 1 {
+				t.Errorf("expected 1 position, got %d", len(timeline))
+			}
+
+			_, _, filterErr := ro.FilterPositions(item.ID)
+			_, geocodeErr := ro.GeocodePositions(item.ID)
+			writes := map[string]error{
+				"Reset":            ro.Reset(),
+				"CreateItem":       ro.CreateItem(models.NewItem("keys")),
+				"DeleteItem":       ro.DeleteItem(item.ID),
+				"CreatePosition":   ro.CreatePosition(models.NewPosition(item.ID, 42.0, -88.0, nil)),
+				"DeletePosition":   ro.DeletePosition(pos.ID),
+				"CreateGeofence":   ro.CreateGeofence(models.NewCircleGeofence("garage", 41.8781, -87.6298, 50)),
+				"DeleteGeofence":   ro.DeleteGeofence(item.ID),
+				"CreatePlace":      ro.CreatePlace(models.NewPlace("home", 41.8781, -87.6298, 50)),
+				"DeletePlace":      ro.DeletePlace(item.ID),
+				"FilterPositions":  filterErr,
+				"GeocodePositions": geocodeErr,
+			}
+			for method, err := range writes {
+				if !errors.Is(err, ErrReadOnly) {
+					t.Errorf("%s: expected ErrReadOnly, got %v", method, err)
+				}
+			}
+
+			// Nothing reached the underlying store
+			items, err := repo.ListItems()
+			mustNoError(t, err)
+			if len(items) != 1 {
+				t.Errorf("expected 1 item, got %d", len(items))
+			}
+			timeline, err = repo.GetTimeline(item.ID)
+			mustNoError(t, err)
+			if len(timeline) != 1 {
+				t.Errorf("expected 1 position, got %d", len(timeline))
+			}
+		})
+	}
+}
@@ -30,13 +30,49 @@ type PositionRepository interface {
 	GetAllPositions() ([]*models.Position, error)
 	GetAllPositionsSince(since time.Time) ([]*models.Position, error)
 	GetAllPositionsInRange(from, to time.Time) ([]*models.Position, error)
+	// GetPositionsNear returns positions across all items within radiusMeters
+	// of a point, newest first. Zero from/to times leave that end unbounded.
+	GetPositionsNear(lat, lng, radiusMeters float64, from, to time.Time) ([]*models.Position, error)
+	// GetPositionsInBBox returns positions across all items inside a bounding
+	// box, newest first. minLng > maxLng selects a box crossing the antimeridian.
+	GetPositionsInBBox(minLat, minLng, maxLat, maxLng float64, from, to time.Time) ([]*models.Position, error)
+	// FilterPositions re-applies the outlier filter to an item's stored
+	// positions, reporting how many were newly flagged suspect or cleared.
+	FilterPositions(itemID uuid.UUID) (flagged, cleared int, err error)
+	// GeocodePositions fills in the locality of an item's stored positions
+	// that have none, reporting how many were named. It returns
+	// ErrNoGeocoder when reverse geocoding is off.
+	GeocodePositions(itemID uuid.UUID) (geocoded int, err error)
 	DeletePosition(id uuid.UUID) error
 }
 
+// GeofenceRepository defines operations for managing geofences and the
+// enter/exit events recorded when positions cross them.
+type GeofenceRepository interface {
+	CreateGeofence(fence *models.Geofence) error
+	GetGeofenceByName(name string) (*models.Geofence, error)
+	ListGeofences() ([]*models.Geofence, error)
+	DeleteGeofence(id uuid.UUID) error
+	// ListGeofenceEvents returns events newest first. A uuid.Nil itemID or
+	// geofenceID matches any item or geofence.
+	ListGeofenceEvents(itemID, geofenceID uuid.UUID) ([]*models.GeofenceEvent, error)
+}
+
+// PlaceRepository defines operations for managing the named places used to
+// label new positions.
+type PlaceRepository interface {
+	CreatePlace(place *models.Place) error
+	GetPlaceByName(name string) (*models.Place, error)
+	ListPlaces() ([]*models.Place, error)
+	DeletePlace(id uuid.UUID) error
+}
+
 // Repository combines all repository operations with lifecycle management.
 type Repository interface {
 	ItemRepository
 	PositionRepository
+	GeofenceRepository
+	PlaceRepository
 	Close() error
 	Sync() error
 	Reset() error
@@ -0,0 +1,274 @@
+// ABOUTME: Versioned schema migrations for the SQLite backend
+// ABOUTME: Tracks the applied version in PRAGMA user_version and applies ordered up-steps
+
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// schemaMigrations are the ordered schema steps. Applying schemaMigrations[i]
+// brings a database to user_version i+1. Steps are append-only: never edit a
+// released step, add a new one instead.
+var schemaMigrations = []func(tx *sql.Tx) error{
+	// 1: items, positions, geofences, and the positions spatial index.
+	// Databases from before versioning report user_version 0 but already
+	// hold these tables, so this step must stay idempotent.
+	func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS items (
+				id TEXT PRIMARY KEY,
+				name TEXT NOT NULL UNIQUE,
+				created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+			);
+
+			CREATE TABLE IF NOT EXISTS positions (
+				id TEXT PRIMARY KEY,
+				item_id TEXT NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+				latitude REAL NOT NULL,
+				longitude REAL NOT NULL,
+				label TEXT,
+				recorded_at DATETIME NOT NULL,
+				created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_positions_item_id ON positions(item_id);
+			CREATE INDEX IF NOT EXISTS idx_positions_recorded_at ON positions(recorded_at);
+
+			CREATE TABLE IF NOT EXISTS geofences (
+				id TEXT PRIMARY KEY,
+				name TEXT NOT NULL UNIQUE,
+				center_lat REAL,
+				center_lng REAL,
+				radius_m REAL,
+				polygon TEXT,
+				created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+			);
+
+			CREATE TABLE IF NOT EXISTS geofence_events (
+				id TEXT PRIMARY KEY,
+				geofence_id TEXT NOT NULL REFERENCES geofences(id) ON DELETE CASCADE,
+				item_id TEXT NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+				position_id TEXT NOT NULL,
+				type TEXT NOT NULL,
+				occurred_at DATETIME NOT NULL
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_geofence_events_item_id ON geofence_events(item_id);
+			CREATE INDEX IF NOT EXISTS idx_geofence_events_occurred_at ON geofence_events(occurred_at);
+
+			-- Spatial index over positions, keyed by positions.rowid and kept in sync by triggers
+			CREATE VIRTUAL TABLE IF NOT EXISTS positions_rtree USING rtree(
+				id, min_lat, max_lat, min_lng, max_lng
+			);
+
+			CREATE TRIGGER IF NOT EXISTS positions_rtree_insert AFTER INSERT ON positions BEGIN
+				INSERT INTO positions_rtree VALUES (NEW.rowid, NEW.latitude, NEW.latitude, NEW.longitude, NEW.longitude);
+			END;
+
+			CREATE TRIGGER IF NOT EXISTS positions_rtree_update AFTER UPDATE OF latitude, longitude ON positions BEGIN
+				UPDATE positions_rtree SET min_lat = NEW.latitude, max_lat = NEW.latitude,
+					min_lng = NEW.longitude, max_lng = NEW.longitude
+				WHERE id = NEW.rowid;
+			END;
+
+			CREATE TRIGGER IF NOT EXISTS positions_rtree_delete AFTER DELETE ON positions BEGIN
+				DELETE FROM positions_rtree WHERE id = OLD.rowid;
+			END;
+
+			-- Index positions that predate the spatial index
+			INSERT INTO positions_rtree
+				SELECT rowid, latitude, latitude, longitude, longitude FROM positions
+				WHERE rowid NOT IN (SELECT id FROM positions_rtree);
+		`)
+		return err
+	},
+
+	// 2: optional position telemetry. Unversioned builds may already have
+	// added some of these columns, so only missing ones are created.
+	func(tx *sql.Tx) error {
+		return addMissingColumns(tx, "positions", []struct{ name, decl string }{
+			{"accuracy", "REAL"},
+			{"altitude", "REAL"},
+			{"speed", "REAL"},
+			{"heading", "REAL"},
+			{"battery", "INTEGER"},
+			{"source", "TEXT"},
+		})
+	},
+
+	// 3: the outlier filter's suspect flag.
+	func(tx *sql.Tx) error {
+		return addMissingColumns(tx, "positions", []struct{ name, decl string }{
+			{"suspect", "INTEGER NOT NULL DEFAULT 0"},
+		})
+	},
+
+	// 4: named places for automatic labeling.
+	func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS places (
+				id TEXT PRIMARY KEY,
+				name TEXT NOT NULL UNIQUE,
+				center_lat REAL,
+				center_lng REAL,
+				radius_m REAL,
+				polygon TEXT,
+				created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+			);
+		`)
+		return err
+	},
+
+	// 5: reverse-geocoded locality.
+	func(tx *sql.Tx) error {
+		return addMissingColumns(tx, "positions", []struct{ name, decl string }{
+			{"locality", "TEXT"},
+		})
+	},
+
+	// 6: timestamps in UTC. Older builds stored times with the local offset,
+	// and range and neighbour queries compare the stored text, so mixed
+	// offsets sort and filter wrongly.
+	func(tx *sql.Tx) error {
+		for _, col := range []struct{ table, column string }{
+			{"positions", "recorded_at"},
+			{"positions", "created_at"},
+			{"geofence_events", "occurred_at"},
+		} {
+			if err := normalizeTimesToUTC(tx, col.table, col.column); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// schemaVersion is the schema version this binary writes.
+var schemaVersion = len(schemaMigrations)
+
+// migrate brings the database schema up to schemaVersion, one step per
+// transaction. It refuses databases written by a newer binary rather than
+// risk misreading columns it doesn't know about.
+func (s *SQLiteDB) migrate() error {
+	for {
+		done, err := s.migrateStep()
+		if err != nil || done {
+			return err
+		}
+	}
+}
+
+// migrateStep applies the next pending migration, if any. The version is
+// read inside the transaction so concurrent openers don't apply a step twice.
+func (s *SQLiteDB) migrateStep() (done bool, err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("begin migration: %w", err)
+	}
+	defer func() {
+		if err != nil || done {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var version int
+	if err := tx.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return false, fmt.Errorf("read schema version: %w", err)
+	}
+	if version > schemaVersion {
+		return false, fmt.Errorf("%w: database is at version %d, this binary supports up to %d",
+			ErrSchemaTooNew, version, schemaVersion)
+	}
+	if version == schemaVersion {
+		return true, nil
+	}
+
+	if err := schemaMigrations[version](tx); err != nil {
+		return false, fmt.Errorf("migration %d: %w", version+1, err)
+	}
+	// PRAGMA doesn't accept bound parameters; version is an int we control.
+	if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", version+1)); err != nil {
+		return false, fmt.Errorf("set schema version %d: %w", version+1, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("commit migration %d: %w", version+1, err)
+	}
+	return false, nil
+}
+
+// SchemaVersion returns the schema version recorded in the database.
+func (s *SQLiteDB) SchemaVersion() (int, error) {
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return 0, fmt.Errorf("read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// normalizeTimesToUTC rewrites the times in table.column that aren't stored
+// in UTC. The driver writes times as "2006-01-02 15:04:05 -0700 MST".
+func normalizeTimesToUTC(tx *sql.Tx, table, column string) error {
+	rows, err := tx.Query(fmt.Sprintf(
+		"SELECT rowid, %s FROM %s WHERE %s NOT LIKE '%% +0000 UTC'", column, table, column))
+	if err != nil {
+		return fmt.Errorf("read %s.%s: %w", table, column, err)
+	}
+	type stamp struct {
+		rowid int64
+		at    time.Time
+	}
+	var stamps []stamp
+	for rows.Next() {
+		var st stamp
+		if err := rows.Scan(&st.rowid, &st.at); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("read %s.%s: %w", table, column, err)
+		}
+		stamps = append(stamps, st)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("read %s.%s: %w", table, column, err)
+	}
+
+	for _, st := range stamps {
+		if _, err := tx.Exec(fmt.Sprintf("UPDATE %s SET %s = ? WHERE rowid = ?", table, column), st.at.UTC(), st.rowid); err != nil {
+			return fmt.Errorf("update %s.%s: %w", table, column, err)
+		}
+	}
+	return nil
+}
+
+// addMissingColumns adds any of columns not yet present on table.
+func addMissingColumns(tx *sql.Tx, table string, columns []struct{ name, decl string }) error {
+	rows, err := tx.Query("SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		return fmt.Errorf("read %s columns: %w", table, err)
+	}
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("read %s columns: %w", table, err)
+		}
+		existing[name] = true
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("read %s columns: %w", table, err)
+	}
+
+	for _, col := range columns {
+		if existing[col.name] {
+			continue
+		}
+		if _, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, col.name, col.decl)); err != nil {
+			return fmt.Errorf("add %s.%s: %w", table, col.name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+// ABOUTME: Tests for versioned SQLite schema migrations
+// ABOUTME: Covers fresh databases, upgrades, rollback on failure, and newer-schema refusal
+
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/harper/position/internal/models"
+)
+
+func TestMigrate_FreshDatabase(t *testing.T) {
+	db := testDB(t)
+
+	version, err := db.SchemaVersion()
+	mustNoError(t, err)
+	if version != schemaVersion {
+		t.Errorf("expected schema version %d, got %d", schemaVersion, version)
+	}
+}
+
+func TestMigrate_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := NewSQLiteDB(path)
+	mustNoError(t, err)
+	item := models.NewItem("harper")
+	mustNoError(t, db.CreateItem(item))
+	mustNoError(t, db.Close())
+
+	db, err = NewSQLiteDB(path)
+	mustNoError(t, err)
+	defer db.Close()
+	if _, err := db.GetItemByID(item.ID); err != nil {
+		t.Errorf("expected item to survive reopen: %v", err)
+	}
+}
+
+func TestMigrate_UnversionedDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := NewSQLiteDB(path)
+	mustNoError(t, err)
+	item := models.NewItem("harper")
+	mustNoError(t, db.CreateItem(item))
+	mustNoError(t, db.CreatePosition(models.NewPosition(item.ID, 41.0, -87.0, nil)))
+	// Databases from before versioning have every table but no version
+	_, err = db.db.Exec("PRAGMA user_version = 0")
+	mustNoError(t, err)
+	mustNoError(t, db.Close())
+
+	db, err = NewSQLiteDB(path)
+	mustNoError(t, err)
+	defer db.Close()
+
+	version, err := db.SchemaVersion()
+	mustNoError(t, err)
+	if version != schemaVersion {
+		t.Errorf("expected schema version %d, got %d", schemaVersion, version)
+	}
+	timeline, err := db.GetTimeline(item.ID)
+	mustNoError(t, err)
+	if len(timeline) != 1 {
+		t.Errorf("expected existing position preserved, got %d", len(timeline))
+	}
+	near, err := db.GetPositionsNear(41.0, -87.0, 10, time.Time{}, time.Time{})
+	mustNoError(t, err)
+	if len(near) != 1 {
+		t.Errorf("expected spatial index intact, got %d", len(near))
+	}
+}
+
+func TestMigrate_NewerSchemaRefused(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := NewSQLiteDB(path)
+	mustNoError(t, err)
+	_, err = db.db.Exec("PRAGMA user_version = 9999")
+	mustNoError(t, err)
+	mustNoError(t, db.Close())
+
+	_, err = NewSQLiteDB(path)
+	if !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("expected ErrSchemaTooNew, got %v", err)
+	}
+}
+
+func TestMigrate_FailedStepRollsBack(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := NewSQLiteDB(path)
+	mustNoError(t, err)
+	mustNoError(t, db.Close())
+
+	saved := schemaMigrations
+	savedVersion := schemaVersion
+	t.Cleanup(func() {
+		schemaMigrations = saved
+		schemaVersion = savedVersion
+	})
+	schemaMigrations = append(append([]func(*sql.Tx) error{}, saved...), func(tx *sql.Tx) error {
+		if _, err := tx.Exec("CREATE TABLE half_done (id TEXT)"); err != nil {
+			return err
+		}
+		return errors.New("boom")
+	})
+	schemaVersion = len(schemaMigrations)
+
+	if _, err := NewSQLiteDB(path); err == nil {
+		t.Fatal("expected failing migration to abort open")
+	}
+
+	schemaMigrations = saved
+	schemaVersion = savedVersion
+	db, err = NewSQLiteDB(path)
+	mustNoError(t, err)
+	defer db.Close()
+
+	version, err := db.SchemaVersion()
+	mustNoError(t, err)
+	if version != savedVersion {
+		t.Errorf("expected version to stay at %d, got %d", savedVersion, version)
+	}
+	var count int
+	mustNoError(t, db.db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'half_done'").Scan(&count))
+	if count != 0 {
+		t.Error("expected partial migration to be rolled back")
+	}
+}
+
+func TestMigrate_TimestampsNormalisedToUTC(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := NewSQLiteDB(path)
+	mustNoError(t, err)
+	item := models.NewItem("harper")
+	mustNoError(t, db.CreateItem(item))
+
+	// An older build wrote 08:00 CST (14:00 UTC) with its local offset,
+	// which sorts as text before 13:00 UTC even though it is later
+	cst := time.FixedZone("CST", -6*60*60)
+	legacy := models.NewPosition(item.ID, 41.0, -87.0, nil)
+	legacy.RecordedAt = time.Date(2024, 1, 1, 8, 0, 0, 0, cst)
+	mustNoError(t, db.CreatePosition(legacy))
+	_, err = db.db.Exec("UPDATE positions SET recorded_at = ? WHERE id = ?", legacy.RecordedAt, legacy.ID.String())
+	mustNoError(t, err)
+	current := models.NewPosition(item.ID, 42.0, -88.0, nil)
+	current.RecordedAt = time.Date(2024, 1, 1, 13, 0, 0, 0, time.UTC)
+	mustNoError(t, db.CreatePosition(current))
+	_, err = db.db.Exec("PRAGMA user_version = 5")
+	mustNoError(t, err)
+	mustNoError(t, db.Close())
+
+	db, err = NewSQLiteDB(path)
+	mustNoError(t, err)
+	defer db.Close()
+
+	var raw string
+	mustNoError(t, db.db.QueryRow("SELECT recorded_at || '' FROM positions WHERE id = ?", legacy.ID.String()).Scan(&raw))
+	if raw != "2024-01-01 14:00:00 +0000 UTC" {
+		t.Errorf("expected legacy time rewritten in UTC, got %q", raw)
+	}
+	timeline, err := db.GetTimeline(item.ID)
+	mustNoError(t, err)
+	if len(timeline) != 2 || timeline[0].ID != legacy.ID {
+		t.Errorf("expected the 14:00 UTC fix to be latest, got %+v", timeline)
+	}
+	inRange, err := db.GetPositionsInRange(item.ID, time.Date(2024, 1, 1, 13, 30, 0, 0, time.UTC), time.Date(2024, 1, 1, 15, 0, 0, 0, time.UTC))
+	mustNoError(t, err)
+	if len(inRange) != 1 || inRange[0].ID != legacy.ID {
+		t.Errorf("expected only the legacy fix in range, got %+v", inRange)
+	}
+}
@@ -0,0 +1,45 @@
+// ABOUTME: Spatial filtering helpers shared by storage backends
+// ABOUTME: Applies exact bounding-box, radius, and time-window checks to positions
+
+package storage
+
+import (
+	"time"
+
+	"github.com/harper/position/internal/geo"
+	"github.com/harper/position/internal/models"
+)
+
+// inTimeWindow reports whether t falls within [from, to]; zero bounds are open.
+func inTimeWindow(t, from, to time.Time) bool {
+	if !from.IsZero() && t.Before(from) {
+		return false
+	}
+	if !to.IsZero() && t.After(to) {
+		return false
+	}
+	return true
+}
+
+// inBBox reports whether a point lies inside the box. A box with
+// minLng > maxLng crosses the antimeridian.
+func inBBox(lat, lng, minLat, minLng, maxLat, maxLng float64) bool {
+	if lat < minLat || lat > maxLat {
+		return false
+	}
+	if minLng <= maxLng {
+		return lng >= minLng && lng <= maxLng
+	}
+	return lng >= minLng || lng <= maxLng
+}
+
+// filterNear keeps positions within radiusMeters of a point.
+func filterNear(positions []*models.Position, lat, lng, radiusMeters float64) []*models.Position {
+	var near []*models.Position
+	for _, pos := range positions {
+		if geo.Distance(lat, lng, pos.Latitude, pos.Longitude) <= radiusMeters {
+			near = append(near, pos)
+		}
+	}
+	return near
+}
@@ -0,0 +1,163 @@
+// ABOUTME: Tests for nearby and bounding-box position queries
+// ABOUTME: Runs against both backends and checks the SQLite R*Tree stays in sync
+
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/harper/position/internal/models"
+)
+
+// seedSpatialData creates two items with positions around Chicago and one far away.
+func seedSpatialData(t *testing.T, repo Repository) (office, home *models.Position) {
+	t.Helper()
+
+	harper := models.NewItem("harper")
+	car := models.NewItem("car")
+	mustNoError(t, repo.CreateItem(harper))
+	mustNoError(t, repo.CreateItem(car))
+
+	base := time.Date(2024, 12, 14, 8, 0, 0, 0, time.UTC)
+	office = models.NewPositionWithRecordedAt(harper.ID, 41.8781, -87.6298, nil, base)
+	home = models.NewPositionWithRecordedAt(harper.ID, 41.9500, -87.6500, nil, base.Add(time.Hour))
+	nearOffice := models.NewPositionWithRecordedAt(car.ID, 41.8800, -87.6300, nil, base.Add(2*time.Hour))
+	farAway := models.NewPositionWithRecordedAt(car.ID, 40.7128, -74.0060, nil, base.Add(3*time.Hour))
+
+	for _, pos := range []*models.Position{office, home, nearOffice, farAway} {
+		mustNoError(t, repo.CreatePosition(pos))
+	}
+	return office, home
+}
+
+func TestGetPositionsNear(t *testing.T) {
+	for name, newRepo := range geofenceBackends() {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+			office, _ := seedSpatialData(t, repo)
+
+			near, err := repo.GetPositionsNear(office.Latitude, office.Longitude, 500, time.Time{}, time.Time{})
+			mustNoError(t, err)
+			if len(near) != 2 {
+				t.Fatalf("expected 2 positions within 500m, got %d", len(near))
+			}
+			// Newest first
+			if !near[0].RecordedAt.After(near[1].RecordedAt) {
+				t.Error("expected results sorted newest first")
+			}
+
+			// Time window excludes the car's later visit
+			to := office.RecordedAt.Add(30 * time.Minute)
+			near, err = repo.GetPositionsNear(office.Latitude, office.Longitude, 500, time.Time{}, to)
+			mustNoError(t, err)
+			if len(near) != 1 || near[0].ID != office.ID {
+				t.Errorf("expected only the office position before %v, got %v", to, near)
+			}
+
+			near, err = repo.GetPositionsNear(office.Latitude, office.Longitude, 10000, time.Time{}, time.Time{})
+			mustNoError(t, err)
+			if len(near) != 3 {
+				t.Errorf("expected 3 positions within 10km, got %d", len(near))
+			}
+		})
+	}
+}
+
+func TestGetPositionsInBBox(t *testing.T) {
+	for name, newRepo := range geofenceBackends() {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+			_, home := seedSpatialData(t, repo)
+
+			inBox, err := repo.GetPositionsInBBox(41.9, -87.7, 42.0, -87.6, time.Time{}, time.Time{})
+			mustNoError(t, err)
+			if len(inBox) != 1 || inBox[0].ID != home.ID {
+				t.Errorf("expected only the home position, got %v", inBox)
+			}
+
+			from := home.RecordedAt.Add(time.Minute)
+			inBox, err = repo.GetPositionsInBBox(40, -90, 42, -70, from, time.Time{})
+			mustNoError(t, err)
+			if len(inBox) != 2 {
+				t.Errorf("expected 2 positions after %v, got %d", from, len(inBox))
+			}
+		})
+	}
+}
+
+func TestGetPositionsInBBox_Antimeridian(t *testing.T) {
+	for name, newRepo := range geofenceBackends() {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+
+			boat := models.NewItem("boat")
+			mustNoError(t, repo.CreateItem(boat))
+			base := time.Date(2024, 12, 14, 8, 0, 0, 0, time.UTC)
+			mustNoError(t, repo.CreatePosition(models.NewPositionWithRecordedAt(boat.ID, -17.0, 179.5, nil, base)))
+			mustNoError(t, repo.CreatePosition(models.NewPositionWithRecordedAt(boat.ID, -17.0, -179.5, nil, base.Add(time.Hour))))
+			mustNoError(t, repo.CreatePosition(models.NewPositionWithRecordedAt(boat.ID, -17.0, 0, nil, base.Add(2*time.Hour))))
+
+			inBox, err := repo.GetPositionsInBBox(-18, 179, -16, -179, time.Time{}, time.Time{})
+			mustNoError(t, err)
+			if len(inBox) != 2 {
+				t.Errorf("expected 2 positions across the antimeridian, got %d", len(inBox))
+			}
+		})
+	}
+}
+
+func TestSQLiteRTree_StaysInSync(t *testing.T) {
+	db := testDB(t)
+
+	item := models.NewItem("harper")
+	mustNoError(t, db.CreateItem(item))
+	pos := models.NewPosition(item.ID, 41.8781, -87.6298, nil)
+	mustNoError(t, db.CreatePosition(pos))
+
+	var count int
+	mustNoError(t, db.db.QueryRow("SELECT COUNT(*) FROM positions_rtree").Scan(&count))
+	if count != 1 {
+		t.Fatalf("expected 1 rtree entry after insert, got %d", count)
+	}
+
+	mustNoError(t, db.DeletePosition(pos.ID))
+	mustNoError(t, db.db.QueryRow("SELECT COUNT(*) FROM positions_rtree").Scan(&count))
+	if count != 0 {
+		t.Errorf("expected rtree entry removed with position, got %d", count)
+	}
+
+	// Cascade deletes from items also clean up the index
+	mustNoError(t, db.CreatePosition(models.NewPosition(item.ID, 41.0, -87.0, nil)))
+	mustNoError(t, db.DeleteItem(item.ID))
+	mustNoError(t, db.db.QueryRow("SELECT COUNT(*) FROM positions_rtree").Scan(&count))
+	if count != 0 {
+		t.Errorf("expected rtree entry removed by cascade, got %d", count)
+	}
+}
+
+func TestSQLiteRTree_BackfillsExistingPositions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := NewSQLiteDB(path)
+	mustNoError(t, err)
+
+	item := models.NewItem("harper")
+	mustNoError(t, db.CreateItem(item))
+	mustNoError(t, db.CreatePosition(models.NewPosition(item.ID, 41.8781, -87.6298, nil)))
+
+	// Simulate an unversioned database created before the spatial index existed
+	_, err = db.db.Exec("DELETE FROM positions_rtree; PRAGMA user_version = 0")
+	mustNoError(t, err)
+	mustNoError(t, db.Close())
+
+	db, err = NewSQLiteDB(path)
+	mustNoError(t, err)
+	defer db.Close()
+
+	near, err := db.GetPositionsNear(41.8781, -87.6298, 100, time.Time{}, time.Time{})
+	mustNoError(t, err)
+	if len(near) != 1 {
+		t.Errorf("expected backfilled position to be found, got %d", len(near))
+	}
+}
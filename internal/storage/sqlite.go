@@ -4,7 +4,10 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"os"
@@ -12,6 +15,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/harper/position/internal/geo"
 	"github.com/harper/position/internal/models"
 	_ "modernc.org/sqlite"
 )
@@ -21,9 +25,15 @@ import (
 const coordEpsilon = 0.0000001
 
 // SQLiteDB implements Repository with a local SQLite database.
+// Timestamps are written in UTC: the driver stores time.Time via String(),
+// which can't be parsed back for unnamed fixed offsets like "-05:00" and
+// doesn't compare correctly across zones.
 type SQLiteDB struct {
-	db   *sql.DB
-	path string
+	db       *sql.DB
+	path     string
+	dedup    DedupConfig
+	filter   FilterConfig
+	geocoder Geocoder
 }
 
 // Compile-time check that SQLiteDB implements Repository.
@@ -66,32 +76,6 @@ func NewSQLiteDB(path string) (*SQLiteDB, error) {
 	return s, nil
 }
 
-// migrate creates or updates the database schema.
-func (s *SQLiteDB) migrate() error {
-	schema := `
-		CREATE TABLE IF NOT EXISTS items (
-			id TEXT PRIMARY KEY,
-			name TEXT NOT NULL UNIQUE,
-			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
-		);
-
-		CREATE TABLE IF NOT EXISTS positions (
-			id TEXT PRIMARY KEY,
-			item_id TEXT NOT NULL REFERENCES items(id) ON DELETE CASCADE,
-			latitude REAL NOT NULL,
-			longitude REAL NOT NULL,
-			label TEXT,
-			recorded_at DATETIME NOT NULL,
-			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
-		);
-
-		CREATE INDEX IF NOT EXISTS idx_positions_item_id ON positions(item_id);
-		CREATE INDEX IF NOT EXISTS idx_positions_recorded_at ON positions(recorded_at);
-	`
-	_, err := s.db.Exec(schema)
-	return err
-}
-
 // Close closes the database connection.
 func (s *SQLiteDB) Close() error {
 	return s.db.Close()
@@ -104,7 +88,7 @@ func (s *SQLiteDB) Sync() error {
 
 // Reset clears all data from the database.
 func (s *SQLiteDB) Reset() error {
-	_, err := s.db.Exec("DELETE FROM positions; DELETE FROM items;")
+	_, err := s.db.Exec("DELETE FROM geofence_events; DELETE FROM geofences; DELETE FROM places; DELETE FROM positions; DELETE FROM items;")
 	return err
 }
 
@@ -112,7 +96,7 @@ func (s *SQLiteDB) Reset() error {
 func (s *SQLiteDB) CreateItem(item *models.Item) error {
 	_, err := s.db.Exec(
 		"INSERT INTO items (id, name, created_at) VALUES (?, ?, ?)",
-		item.ID.String(), item.Name, item.CreatedAt,
+		item.ID.String(), item.Name, item.CreatedAt.UTC(),
 	)
 	if err != nil {
 		return fmt.Errorf("insert item: %w", err)
@@ -188,21 +172,162 @@ func (s *SQLiteDB) scanItemFromRows(rows *sql.Rows) (*models.Item, error) {
 	return &item, nil
 }
 
+// SetDedup sets the policy CreatePosition uses to skip repeated positions.
+func (s *SQLiteDB) SetDedup(cfg DedupConfig) {
+	s.dedup = cfg
+}
+
+// SetFilter sets the policy CreatePosition uses to flag suspect positions.
+func (s *SQLiteDB) SetFilter(cfg FilterConfig) {
+	s.filter = cfg
+}
+
+// SetGeocoder sets the geocoder CreatePosition uses to fill in localities.
+// Nil turns reverse geocoding off.
+func (s *SQLiteDB) SetGeocoder(g Geocoder) {
+	s.geocoder = g
+}
+
 // CreatePosition creates a new position with deduplication.
-// If the new position matches the current position for the item, it's silently skipped.
+// The position is compared with its chronological neighbours rather than the
+// item's latest position, so backdated and out-of-order inserts are handled
+// the same as live ones. Suspect positions are not neighbours. If it repeats
+// the position recorded just before it under the dedup policy, it's silently
+// skipped; if the outlier filter distrusts it, it's stored flagged suspect.
+// Only the caller's label counts for dedup: a stored position without one is
+// then labeled with the name of the place containing it, and gets a locality
+// when a geocoder is set. A trusted position also removes the positions
+// after it that repeat it, which live pings would have skipped. Geofence
+// enter/exit events are recorded for trusted positions that are stored, and
+// the following position's events are recomputed against the new one. It
+// all happens in one transaction.
 func (s *SQLiteDB) CreatePosition(pos *models.Position) error {
-	// Check for duplicate against current position
-	current, err := s.GetCurrentPosition(pos.ItemID)
-	if err == nil && coordsEqual(current.Latitude, current.Longitude, pos.Latitude, pos.Longitude) {
-		// Same location as current position - skip
+	dedup := s.dedup.policyFor(s, pos.ItemID)
+	filter := s.filter.policyFor(s, pos.ItemID)
+	places, err := s.ListPlaces()
+	if err != nil {
+		return err
+	}
+	fences, err := s.ListGeofences()
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	prev, next, err := s.positionNeighbours(tx, pos.ItemID, pos.RecordedAt)
+	if err != nil {
+		return err
+	}
+	if dedup.isRepeat(prev, pos) {
 		return nil
 	}
+	pos.Suspect = filter.isSuspect(prev, pos)
 
-	_, err = s.db.Exec(
-		`INSERT INTO positions (id, item_id, latitude, longitude, label, recorded_at, created_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+	applyPlaceLabel(places, pos)
+	if err := applyLocality(s.geocoder, pos); err != nil {
+		return err
+	}
+
+	if err := s.insertPosition(tx, pos); err != nil {
+		return err
+	}
+	if pos.Suspect {
+		return tx.Commit()
+	}
+
+	events := geofenceTransitions(fences, prev, pos)
+	repeats, next, err := dedup.repeatedSuccessors(pos, next, func(p *models.Position) (*models.Position, error) {
+		_, after, err := s.positionNeighbours(tx, p.ItemID, p.RecordedAt)
+		return after, err
+	})
+	if err != nil {
+		return err
+	}
+	for _, repeat := range repeats {
+		if err := s.deletePositionAndEvents(tx, repeat.ID); err != nil {
+			return err
+		}
+	}
+	if next != nil {
+		if _, err := tx.Exec("DELETE FROM geofence_events WHERE position_id = ?", next.ID.String()); err != nil {
+			return fmt.Errorf("delete geofence events: %w", err)
+		}
+		events = append(events, geofenceTransitions(fences, pos, next)...)
+	}
+	if err := s.insertGeofenceEvents(tx, events); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// execQuerier is the part of *sql.DB and *sql.Tx that position writes use,
+// so they can run inside CreatePosition's transaction.
+type execQuerier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// positionNeighbours returns the item's trusted positions immediately around
+// at: prev is the latest recorded at or before it, next the earliest recorded
+// after it. Suspect positions are skipped.
+func (s *SQLiteDB) positionNeighbours(q execQuerier, itemID uuid.UUID, at time.Time) (prev, next *models.Position, err error) {
+	prev, err = s.scanPosition(q.QueryRow(
+		`SELECT `+positionColumns+`
+		 FROM positions WHERE item_id = ? AND recorded_at <= ? AND suspect = 0
+		 ORDER BY recorded_at DESC LIMIT 1`,
+		itemID.String(), at.UTC(),
+	))
+	if errors.Is(err, ErrNotFound) {
+		prev, err = nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	next, err = s.scanPosition(q.QueryRow(
+		`SELECT `+positionColumns+`
+		 FROM positions WHERE item_id = ? AND recorded_at > ? AND suspect = 0
+		 ORDER BY recorded_at ASC LIMIT 1`,
+		itemID.String(), at.UTC(),
+	))
+	if errors.Is(err, ErrNotFound) {
+		next, err = nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return prev, next, nil
+}
+
+// deletePositionAndEvents removes a position and the geofence events it triggered.
+func (s *SQLiteDB) deletePositionAndEvents(q execQuerier, id uuid.UUID) error {
+	if _, err := q.Exec("DELETE FROM geofence_events WHERE position_id = ?", id.String()); err != nil {
+		return fmt.Errorf("delete geofence events: %w", err)
+	}
+	if _, err := q.Exec("DELETE FROM positions WHERE id = ?", id.String()); err != nil {
+		return fmt.Errorf("delete position: %w", err)
+	}
+	return nil
+}
+
+// positionColumns lists the positions columns in the order scanned by
+// scanPosition and scanPositions.
+const positionColumns = `id, item_id, latitude, longitude, label, recorded_at, created_at,
+			accuracy, altitude, speed, heading, battery, source, suspect, locality`
+
+// insertPosition writes a position row without deduplication or geofence checks.
+func (s *SQLiteDB) insertPosition(q execQuerier, pos *models.Position) error {
+	_, err := q.Exec(
+		`INSERT INTO positions (`+positionColumns+`)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		pos.ID.String(), pos.ItemID.String(), pos.Latitude, pos.Longitude,
-		pos.Label, pos.RecordedAt, pos.CreatedAt,
+		pos.Label, pos.RecordedAt.UTC(), pos.CreatedAt.UTC(),
+		pos.Accuracy, pos.Altitude, pos.Speed, pos.Heading, pos.Battery, pos.Source, pos.Suspect, pos.Locality,
 	)
 	if err != nil {
 		return fmt.Errorf("insert position: %w", err)
@@ -218,7 +343,7 @@ func coordsEqual(lat1, lng1, lat2, lng2 float64) bool {
 // GetPosition retrieves a position by its UUID.
 func (s *SQLiteDB) GetPosition(id uuid.UUID) (*models.Position, error) {
 	row := s.db.QueryRow(
-		`SELECT id, item_id, latitude, longitude, label, recorded_at, created_at
+		`SELECT `+positionColumns+`
 		 FROM positions WHERE id = ?`,
 		id.String(),
 	)
@@ -228,7 +353,7 @@ func (s *SQLiteDB) GetPosition(id uuid.UUID) (*models.Position, error) {
 // GetCurrentPosition returns the most recent position for an item.
 func (s *SQLiteDB) GetCurrentPosition(itemID uuid.UUID) (*models.Position, error) {
 	row := s.db.QueryRow(
-		`SELECT id, item_id, latitude, longitude, label, recorded_at, created_at
+		`SELECT `+positionColumns+`
 		 FROM positions WHERE item_id = ? ORDER BY recorded_at DESC LIMIT 1`,
 		itemID.String(),
 	)
@@ -238,7 +363,7 @@ func (s *SQLiteDB) GetCurrentPosition(itemID uuid.UUID) (*models.Position, error
 // GetTimeline returns all positions for an item, sorted by recorded_at descending (newest first).
 func (s *SQLiteDB) GetTimeline(itemID uuid.UUID) ([]*models.Position, error) {
 	rows, err := s.db.Query(
-		`SELECT id, item_id, latitude, longitude, label, recorded_at, created_at
+		`SELECT `+positionColumns+`
 		 FROM positions WHERE item_id = ? ORDER BY recorded_at DESC`,
 		itemID.String(),
 	)
@@ -253,9 +378,9 @@ func (s *SQLiteDB) GetTimeline(itemID uuid.UUID) ([]*models.Position, error) {
 // GetPositionsSince returns positions for an item recorded after the given time.
 func (s *SQLiteDB) GetPositionsSince(itemID uuid.UUID, since time.Time) ([]*models.Position, error) {
 	rows, err := s.db.Query(
-		`SELECT id, item_id, latitude, longitude, label, recorded_at, created_at
+		`SELECT `+positionColumns+`
 		 FROM positions WHERE item_id = ? AND recorded_at > ? ORDER BY recorded_at DESC`,
-		itemID.String(), since,
+		itemID.String(), since.UTC(),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("query positions: %w", err)
@@ -268,10 +393,10 @@ func (s *SQLiteDB) GetPositionsSince(itemID uuid.UUID, since time.Time) ([]*mode
 // GetPositionsInRange returns positions for an item within a time range.
 func (s *SQLiteDB) GetPositionsInRange(itemID uuid.UUID, from, to time.Time) ([]*models.Position, error) {
 	rows, err := s.db.Query(
-		`SELECT id, item_id, latitude, longitude, label, recorded_at, created_at
+		`SELECT `+positionColumns+`
 		 FROM positions WHERE item_id = ? AND recorded_at >= ? AND recorded_at <= ?
 		 ORDER BY recorded_at DESC`,
-		itemID.String(), from, to,
+		itemID.String(), from.UTC(), to.UTC(),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("query positions: %w", err)
@@ -284,7 +409,7 @@ func (s *SQLiteDB) GetPositionsInRange(itemID uuid.UUID, from, to time.Time) ([]
 // GetAllPositions returns all positions across all items.
 func (s *SQLiteDB) GetAllPositions() ([]*models.Position, error) {
 	rows, err := s.db.Query(
-		`SELECT id, item_id, latitude, longitude, label, recorded_at, created_at
+		`SELECT ` + positionColumns + `
 		 FROM positions ORDER BY recorded_at DESC`,
 	)
 	if err != nil {
@@ -298,9 +423,9 @@ func (s *SQLiteDB) GetAllPositions() ([]*models.Position, error) {
 // GetAllPositionsSince returns all positions across all items after the given time.
 func (s *SQLiteDB) GetAllPositionsSince(since time.Time) ([]*models.Position, error) {
 	rows, err := s.db.Query(
-		`SELECT id, item_id, latitude, longitude, label, recorded_at, created_at
+		`SELECT `+positionColumns+`
 		 FROM positions WHERE recorded_at > ? ORDER BY recorded_at DESC`,
-		since,
+		since.UTC(),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("query positions: %w", err)
@@ -313,9 +438,9 @@ func (s *SQLiteDB) GetAllPositionsSince(since time.Time) ([]*models.Position, er
 // GetAllPositionsInRange returns all positions across all items within a time range.
 func (s *SQLiteDB) GetAllPositionsInRange(from, to time.Time) ([]*models.Position, error) {
 	rows, err := s.db.Query(
-		`SELECT id, item_id, latitude, longitude, label, recorded_at, created_at
+		`SELECT `+positionColumns+`
 		 FROM positions WHERE recorded_at >= ? AND recorded_at <= ? ORDER BY recorded_at DESC`,
-		from, to,
+		from.UTC(), to.UTC(),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("query positions: %w", err)
@@ -325,17 +450,168 @@ func (s *SQLiteDB) GetAllPositionsInRange(from, to time.Time) ([]*models.Positio
 	return s.scanPositions(rows)
 }
 
-// DeletePosition removes a single position.
+// GetPositionsNear returns positions across all items within radiusMeters of a
+// point, newest first. The R*Tree narrows candidates to the enclosing bounding
+// box; exact distances are then checked with the haversine formula.
+func (s *SQLiteDB) GetPositionsNear(lat, lng, radiusMeters float64, from, to time.Time) ([]*models.Position, error) {
+	minLat, minLng, maxLat, maxLng := geo.BoundingBox(lat, lng, radiusMeters)
+	candidates, err := s.GetPositionsInBBox(minLat, minLng, maxLat, maxLng, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return filterNear(candidates, lat, lng, radiusMeters), nil
+}
+
+// GetPositionsInBBox returns positions across all items inside a bounding box,
+// newest first, using the positions_rtree spatial index.
+func (s *SQLiteDB) GetPositionsInBBox(minLat, minLng, maxLat, maxLng float64, from, to time.Time) ([]*models.Position, error) {
+	// R*Tree coordinates are 32-bit floats rounded outward, so the index is
+	// used for overlap and the exact bounds are rechecked on the real columns.
+	query := `SELECT p.id, p.item_id, p.latitude, p.longitude, p.label, p.recorded_at, p.created_at,
+			p.accuracy, p.altitude, p.speed, p.heading, p.battery, p.source, p.suspect, p.locality
+		 FROM positions_rtree r JOIN positions p ON p.rowid = r.id
+		 WHERE r.max_lat >= ? AND r.min_lat <= ? AND p.latitude BETWEEN ? AND ?`
+	args := []any{minLat, maxLat, minLat, maxLat}
+
+	if minLng <= maxLng {
+		query += ` AND r.max_lng >= ? AND r.min_lng <= ? AND p.longitude BETWEEN ? AND ?`
+		args = append(args, minLng, maxLng, minLng, maxLng)
+	} else {
+		query += ` AND (r.max_lng >= ? OR r.min_lng <= ?) AND (p.longitude >= ? OR p.longitude <= ?)`
+		args = append(args, minLng, maxLng, minLng, maxLng)
+	}
+	if !from.IsZero() {
+		query += ` AND p.recorded_at >= ?`
+		args = append(args, from.UTC())
+	}
+	if !to.IsZero() {
+		query += ` AND p.recorded_at <= ?`
+		args = append(args, to.UTC())
+	}
+	query += ` ORDER BY p.recorded_at DESC`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query positions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return s.scanPositions(rows)
+}
+
+// FilterPositions re-applies the outlier filter to an item's stored
+// positions, updating their suspect flags. Geofence events are recomputed
+// for the positions whose trusted predecessor changed, so a teleport newly
+// flagged suspect no longer enters or exits fences. It all happens in one
+// transaction.
+func (s *SQLiteDB) FilterPositions(itemID uuid.UUID) (flagged, cleared int, err error) {
+	positions, err := s.GetTimeline(itemID)
+	if err != nil {
+		return 0, 0, err
+	}
+	fences, err := s.ListGeofences()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	flags := s.filter.policyFor(s, itemID).Flag(positions)
+	refiltered := make([]*models.Position, len(positions))
+	for i, pos := range positions {
+		c := *pos
+		refiltered[i] = &c
+		suspect := flags[pos.ID]
+		if suspect == pos.Suspect {
+			continue
+		}
+		c.Suspect = suspect
+		if _, err := tx.Exec("UPDATE positions SET suspect = ? WHERE id = ?", suspect, pos.ID.String()); err != nil {
+			return 0, 0, fmt.Errorf("update position: %w", err)
+		}
+		if suspect {
+			flagged++
+		} else {
+			cleared++
+		}
+	}
+	if flagged+cleared == 0 {
+		return 0, 0, nil
+	}
+
+	if err := s.replaceGeofenceEvents(tx, fences, positions, refiltered); err != nil {
+		return 0, 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("commit transaction: %w", err)
+	}
+	return flagged, cleared, nil
+}
+
+// GeocodePositions fills in the locality of an item's stored positions that
+// have none, using the geocoder set by SetGeocoder.
+func (s *SQLiteDB) GeocodePositions(itemID uuid.UUID) (geocoded int, err error) {
+	if s.geocoder == nil {
+		return 0, ErrNoGeocoder
+	}
+	positions, err := s.GetTimeline(itemID)
+	if err != nil {
+		return 0, err
+	}
+	return geocodePositions(s.geocoder, positions, func(pos *models.Position) error {
+		if _, err := s.db.Exec("UPDATE positions SET locality = ? WHERE id = ?", pos.Locality, pos.ID.String()); err != nil {
+			return fmt.Errorf("update position: %w", err)
+		}
+		return nil
+	})
+}
+
+// DeletePosition removes a single position. Geofence events are
+// recomputed around it, so the fences it entered or exited no longer list it
+// and the next trusted position is compared with the one before. It all
+// happens in one transaction.
 func (s *SQLiteDB) DeletePosition(id uuid.UUID) error {
-	_, err := s.db.Exec("DELETE FROM positions WHERE id = ?", id.String())
-	return err
+	pos, err := s.GetPosition(id)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	positions, err := s.GetTimeline(pos.ItemID)
+	if err != nil {
+		return err
+	}
+	fences, err := s.ListGeofences()
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec("DELETE FROM positions WHERE id = ?", id.String()); err != nil {
+		return fmt.Errorf("delete position: %w", err)
+	}
+	if err := s.replaceGeofenceEvents(tx, fences, positions, withoutPosition(positions, id)); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (s *SQLiteDB) scanPosition(row *sql.Row) (*models.Position, error) {
 	var idStr, itemIDStr string
 	var pos models.Position
 	err := row.Scan(&idStr, &itemIDStr, &pos.Latitude, &pos.Longitude,
-		&pos.Label, &pos.RecordedAt, &pos.CreatedAt)
+		&pos.Label, &pos.RecordedAt, &pos.CreatedAt,
+		&pos.Accuracy, &pos.Altitude, &pos.Speed, &pos.Heading, &pos.Battery, &pos.Source, &pos.Suspect, &pos.Locality)
 	if err == sql.ErrNoRows {
 		return nil, ErrNotFound
 	}
@@ -353,7 +629,8 @@ func (s *SQLiteDB) scanPositions(rows *sql.Rows) ([]*models.Position, error) {
 		var idStr, itemIDStr string
 		var pos models.Position
 		err := rows.Scan(&idStr, &itemIDStr, &pos.Latitude, &pos.Longitude,
-			&pos.Label, &pos.RecordedAt, &pos.CreatedAt)
+			&pos.Label, &pos.RecordedAt, &pos.CreatedAt,
+			&pos.Accuracy, &pos.Altitude, &pos.Speed, &pos.Heading, &pos.Battery, &pos.Source, &pos.Suspect, &pos.Locality)
 		if err != nil {
 			return nil, fmt.Errorf("scan position: %w", err)
 		}
@@ -363,3 +640,271 @@ func (s *SQLiteDB) scanPositions(rows *sql.Rows) ([]*models.Position, error) {
 	}
 	return positions, rows.Err()
 }
+
+// --- Geofences ---
+
+// CreateGeofence creates a new geofence. Names must be unique.
+func (s *SQLiteDB) CreateGeofence(fence *models.Geofence) error {
+	var shape shapeColumns
+	if err := shape.set(fence.Shape); err != nil {
+		return err
+	}
+
+	_, err := s.db.Exec(
+		`INSERT INTO geofences (id, name, center_lat, center_lng, radius_m, polygon, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		fence.ID.String(), fence.Name, shape.centerLat, shape.centerLng, shape.radius, shape.polygon,
+		fence.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("insert geofence: %w", err)
+	}
+	return nil
+}
+
+// GetGeofenceByName retrieves a geofence by its name.
+func (s *SQLiteDB) GetGeofenceByName(name string) (*models.Geofence, error) {
+	rows, err := s.db.Query(
+		`SELECT id, name, center_lat, center_lng, radius_m, polygon, created_at
+		 FROM geofences WHERE name = ?`,
+		name,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query geofences: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	fences, err := s.scanGeofences(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(fences) == 0 {
+		return nil, ErrNotFound
+	}
+	return fences[0], nil
+}
+
+// ListGeofences returns all geofences sorted by name.
+func (s *SQLiteDB) ListGeofences() ([]*models.Geofence, error) {
+	rows, err := s.db.Query(
+		`SELECT id, name, center_lat, center_lng, radius_m, polygon, created_at
+		 FROM geofences ORDER BY name`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query geofences: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return s.scanGeofences(rows)
+}
+
+// DeleteGeofence removes a geofence (its events cascade delete automatically).
+func (s *SQLiteDB) DeleteGeofence(id uuid.UUID) error {
+	_, err := s.db.Exec("DELETE FROM geofences WHERE id = ?", id.String())
+	return err
+}
+
+// ListGeofenceEvents returns geofence events, newest first.
+// A uuid.Nil itemID or geofenceID matches any item or geofence.
+func (s *SQLiteDB) ListGeofenceEvents(itemID, geofenceID uuid.UUID) ([]*models.GeofenceEvent, error) {
+	query := `SELECT id, geofence_id, item_id, position_id, type, occurred_at
+		 FROM geofence_events WHERE 1 = 1`
+	var args []any
+	if itemID != uuid.Nil {
+		query += " AND item_id = ?"
+		args = append(args, itemID.String())
+	}
+	if geofenceID != uuid.Nil {
+		query += " AND geofence_id = ?"
+		args = append(args, geofenceID.String())
+	}
+	query += " ORDER BY occurred_at DESC"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query geofence events: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var events []*models.GeofenceEvent
+	for rows.Next() {
+		var idStr, fenceIDStr, itemIDStr, posIDStr, eventType string
+		var e models.GeofenceEvent
+		if err := rows.Scan(&idStr, &fenceIDStr, &itemIDStr, &posIDStr, &eventType, &e.OccurredAt); err != nil {
+			return nil, fmt.Errorf("scan geofence event: %w", err)
+		}
+		e.ID, _ = uuid.Parse(idStr)
+		e.GeofenceID, _ = uuid.Parse(fenceIDStr)
+		e.ItemID, _ = uuid.Parse(itemIDStr)
+		e.PositionID, _ = uuid.Parse(posIDStr)
+		e.Type = models.GeofenceEventType(eventType)
+		events = append(events, &e)
+	}
+	return events, rows.Err()
+}
+
+// replaceGeofenceEvents brings an item's stored geofence events in line with
+// its positions going from before to after.
+func (s *SQLiteDB) replaceGeofenceEvents(q execQuerier, fences []*models.Geofence, before, after []*models.Position) error {
+	stale, events := geofenceChanges(fences, before, after)
+	for _, id := range stale {
+		if _, err := q.Exec("DELETE FROM geofence_events WHERE position_id = ?", id.String()); err != nil {
+			return fmt.Errorf("delete geofence events: %w", err)
+		}
+	}
+	return s.insertGeofenceEvents(q, events)
+}
+
+// insertGeofenceEvents stores geofence events.
+func (s *SQLiteDB) insertGeofenceEvents(q execQuerier, events []*models.GeofenceEvent) error {
+	for _, e := range events {
+		_, err := q.Exec(
+			`INSERT INTO geofence_events (id, geofence_id, item_id, position_id, type, occurred_at)
+			 VALUES (?, ?, ?, ?, ?, ?)`,
+			e.ID.String(), e.GeofenceID.String(), e.ItemID.String(), e.PositionID.String(),
+			string(e.Type), e.OccurredAt.UTC(),
+		)
+		if err != nil {
+			return fmt.Errorf("insert geofence event: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteDB) scanGeofences(rows *sql.Rows) ([]*models.Geofence, error) {
+	var fences []*models.Geofence
+	for rows.Next() {
+		var idStr string
+		var shape shapeColumns
+		var fence models.Geofence
+		err := rows.Scan(&idStr, &fence.Name, &shape.centerLat, &shape.centerLng, &shape.radius,
+			&shape.polygon, &fence.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scan geofence: %w", err)
+		}
+		fence.ID, _ = uuid.Parse(idStr)
+		if fence.Shape, err = shape.shape(); err != nil {
+			return nil, fmt.Errorf("geofence %q: %w", fence.Name, err)
+		}
+		fences = append(fences, &fence)
+	}
+	return fences, rows.Err()
+}
+
+// --- Places ---
+
+// placeColumns lists the places columns in the order scanned by scanPlaces.
+const placeColumns = `id, name, center_lat, center_lng, radius_m, polygon, created_at`
+
+// CreatePlace creates a new place. Names must be unique.
+func (s *SQLiteDB) CreatePlace(place *models.Place) error {
+	var shape shapeColumns
+	if err := shape.set(place.Shape); err != nil {
+		return err
+	}
+
+	_, err := s.db.Exec(
+		`INSERT INTO places (`+placeColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		place.ID.String(), place.Name, shape.centerLat, shape.centerLng, shape.radius, shape.polygon,
+		place.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("insert place: %w", err)
+	}
+	return nil
+}
+
+// GetPlaceByName retrieves a place by its name.
+func (s *SQLiteDB) GetPlaceByName(name string) (*models.Place, error) {
+	rows, err := s.db.Query(`SELECT `+placeColumns+` FROM places WHERE name = ?`, name)
+	if err != nil {
+		return nil, fmt.Errorf("query places: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	places, err := s.scanPlaces(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(places) == 0 {
+		return nil, ErrNotFound
+	}
+	return places[0], nil
+}
+
+// ListPlaces returns all places sorted by name.
+func (s *SQLiteDB) ListPlaces() ([]*models.Place, error) {
+	rows, err := s.db.Query(`SELECT ` + placeColumns + ` FROM places ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("query places: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return s.scanPlaces(rows)
+}
+
+// DeletePlace removes a place. Positions it already labeled keep their label.
+func (s *SQLiteDB) DeletePlace(id uuid.UUID) error {
+	_, err := s.db.Exec("DELETE FROM places WHERE id = ?", id.String())
+	return err
+}
+
+func (s *SQLiteDB) scanPlaces(rows *sql.Rows) ([]*models.Place, error) {
+	var places []*models.Place
+	for rows.Next() {
+		var idStr string
+		var shape shapeColumns
+		var place models.Place
+		err := rows.Scan(&idStr, &place.Name, &shape.centerLat, &shape.centerLng, &shape.radius,
+			&shape.polygon, &place.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scan place: %w", err)
+		}
+		place.ID, _ = uuid.Parse(idStr)
+		if place.Shape, err = shape.shape(); err != nil {
+			return nil, fmt.Errorf("place %q: %w", place.Name, err)
+		}
+		places = append(places, &place)
+	}
+	return places, rows.Err()
+}
+
+// shapeColumns holds the center_lat, center_lng, radius_m, and polygon
+// columns that geofences and places store their shape in. A circle leaves
+// polygon NULL; a polygon leaves the rest NULL.
+type shapeColumns struct {
+	centerLat, centerLng, radius sql.NullFloat64
+	polygon                      sql.NullString
+}
+
+// set fills the columns from shape.
+func (c *shapeColumns) set(shape models.Shape) error {
+	if shape.Center != nil {
+		c.centerLat = sql.NullFloat64{Float64: shape.Center.Latitude, Valid: true}
+		c.centerLng = sql.NullFloat64{Float64: shape.Center.Longitude, Valid: true}
+		c.radius = sql.NullFloat64{Float64: shape.RadiusMeters, Valid: true}
+	}
+	if len(shape.Polygon) > 0 {
+		data, err := json.Marshal(shape.Polygon)
+		if err != nil {
+			return fmt.Errorf("encode polygon: %w", err)
+		}
+		c.polygon = sql.NullString{String: string(data), Valid: true}
+	}
+	return nil
+}
+
+// shape decodes the scanned columns.
+func (c *shapeColumns) shape() (models.Shape, error) {
+	var shape models.Shape
+	if c.centerLat.Valid && c.centerLng.Valid {
+		shape.Center = &models.Coordinate{Latitude: c.centerLat.Float64, Longitude: c.centerLng.Float64}
+		shape.RadiusMeters = c.radius.Float64
+	}
+	if c.polygon.Valid {
+		if err := json.Unmarshal([]byte(c.polygon.String), &shape.Polygon); err != nil {
+			return shape, fmt.Errorf("decode polygon: %w", err)
+		}
+	}
+	return shape, nil
+}
@@ -232,6 +232,30 @@ func TestCreatePosition(t *testing.T) {
 	}
 }
 
+func TestCreatePosition_FixedOffsetTime(t *testing.T) {
+	db := testDB(t)
+
+	item := models.NewItem("harper")
+	if err := db.CreateItem(item); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+
+	// Importers parse RFC3339 offsets into unnamed fixed zones
+	recordedAt := time.Date(2024, 1, 1, 8, 0, 0, 0, time.FixedZone("", -5*3600))
+	pos := models.NewPositionWithRecordedAt(item.ID, 41.8781, -87.6298, nil, recordedAt)
+	if err := db.CreatePosition(pos); err != nil {
+		t.Fatalf("failed to create position: %v", err)
+	}
+
+	got, err := db.GetPosition(pos.ID)
+	if err != nil {
+		t.Fatalf("failed to get position: %v", err)
+	}
+	if !got.RecordedAt.Equal(recordedAt) {
+		t.Errorf("got recorded_at %v, want %v", got.RecordedAt, recordedAt)
+	}
+}
+
 func TestCreatePosition_Deduplication(t *testing.T) {
 	db := testDB(t)
 
@@ -0,0 +1,129 @@
+// ABOUTME: Tests for optional position telemetry persistence
+// ABOUTME: Round-trips accuracy, altitude, speed, heading, battery, and source through each backend
+
+package storage
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/harper/position/internal/models"
+)
+
+// newTelemetryPosition returns a position with every telemetry field set.
+func newTelemetryPosition(item *models.Item) *models.Position {
+	acc, alt, speed, heading := 8.5, 181.0, 12.25, 270.0
+	battery := 64
+	source := "owntracks"
+	pos := models.NewPosition(item.ID, 41.8781, -87.6298, nil)
+	pos.Telemetry = models.Telemetry{
+		Accuracy: &acc, Altitude: &alt, Speed: &speed, Heading: &heading,
+		Battery: &battery, Source: &source,
+	}
+	return pos
+}
+
+func assertTelemetry(t *testing.T, got *models.Position) {
+	t.Helper()
+	tm := got.Telemetry
+	if tm.Accuracy == nil || *tm.Accuracy != 8.5 || tm.Altitude == nil || *tm.Altitude != 181 ||
+		tm.Speed == nil || *tm.Speed != 12.25 || tm.Heading == nil || *tm.Heading != 270 ||
+		tm.Battery == nil || *tm.Battery != 64 || tm.Source == nil || *tm.Source != "owntracks" {
+		t.Errorf("telemetry not preserved: %+v", tm)
+	}
+}
+
+func TestPositionTelemetry_RoundTrip(t *testing.T) {
+	for name, newRepo := range geofenceBackends() {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+
+			item := models.NewItem("phone")
+			mustNoError(t, repo.CreateItem(item))
+			pos := newTelemetryPosition(item)
+			mustNoError(t, repo.CreatePosition(pos))
+
+			got, err := repo.GetCurrentPosition(item.ID)
+			mustNoError(t, err)
+			assertTelemetry(t, got)
+
+			// Positions without telemetry keep every field nil
+			bare := models.NewPosition(item.ID, 42.0, -88.0, nil)
+			mustNoError(t, repo.CreatePosition(bare))
+			got, err = repo.GetPosition(bare.ID)
+			mustNoError(t, err)
+			if got.Telemetry != (models.Telemetry{}) {
+				t.Errorf("expected empty telemetry, got %+v", got.Telemetry)
+			}
+
+			timeline, err := repo.GetTimeline(item.ID)
+			mustNoError(t, err)
+			assertTelemetry(t, timeline[1])
+		})
+	}
+}
+
+func TestPositionTelemetry_Backup(t *testing.T) {
+	src := testDB(t)
+	item := models.NewItem("phone")
+	mustNoError(t, src.CreateItem(item))
+	mustNoError(t, src.CreatePosition(newTelemetryPosition(item)))
+
+	data, err := ExportToYAML(src)
+	mustNoError(t, err)
+	if !strings.Contains(string(data), "accuracy: 8.5") || !strings.Contains(string(data), "source: owntracks") {
+		t.Errorf("expected telemetry in backup YAML:\n%s", data)
+	}
+
+	dst := newTestMarkdownStore(t)
+	mustNoError(t, ImportFromYAML(dst, data))
+	got, err := dst.GetCurrentPosition(item.ID)
+	mustNoError(t, err)
+	assertTelemetry(t, got)
+}
+
+func TestSQLiteMigrate_AddsTelemetryColumns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.db")
+	db, err := NewSQLiteDB(path)
+	mustNoError(t, err)
+
+	// Recreate positions with the original schema, as an unversioned build left it
+	_, err = db.db.Exec(`
+		PRAGMA user_version = 0;
+		PRAGMA foreign_keys = OFF;
+		DROP TABLE positions;
+		CREATE TABLE positions (
+			id TEXT PRIMARY KEY,
+			item_id TEXT NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+			latitude REAL NOT NULL,
+			longitude REAL NOT NULL,
+			label TEXT,
+			recorded_at DATETIME NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		PRAGMA foreign_keys = ON;`)
+	mustNoError(t, err)
+	item := models.NewItem("phone")
+	mustNoError(t, db.CreateItem(item))
+	_, err = db.db.Exec(`INSERT INTO positions (id, item_id, latitude, longitude, recorded_at)
+		VALUES ('00000000-0000-0000-0000-000000000001', ?, 41.0, -87.0, '2024-12-14 08:00:00')`, item.ID.String())
+	mustNoError(t, err)
+	mustNoError(t, db.Close())
+
+	db, err = NewSQLiteDB(path)
+	mustNoError(t, err)
+	defer db.Close()
+
+	old, err := db.GetCurrentPosition(item.ID)
+	mustNoError(t, err)
+	if old.Telemetry != (models.Telemetry{}) {
+		t.Errorf("expected migrated row to have empty telemetry, got %+v", old.Telemetry)
+	}
+
+	pos := newTelemetryPosition(item)
+	mustNoError(t, db.CreatePosition(pos))
+	got, err := db.GetPosition(pos.ID)
+	mustNoError(t, err)
+	assertTelemetry(t, got)
+}
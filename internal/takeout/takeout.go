@@ -0,0 +1,375 @@
+// ABOUTME: Google Takeout location history parsing
+// ABOUTME: Reads Records.json, semantic Timeline.json and location-history.json exports into points
+
+package takeout
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Point is a single location extracted from a Takeout export.
+// Label is set for semantic place visits; Accuracy, Altitude, Speed, and
+// Heading only for Records.json entries that report them.
+type Point struct {
+	Latitude   float64
+	Longitude  float64
+	RecordedAt time.Time
+	Label      *string
+	Accuracy   *float64 // meters
+	Altitude   *float64 // meters
+	Speed      *float64 // meters per second
+	Heading    *float64 // degrees
+}
+
+// Parse detects the Takeout export flavor and extracts points sorted chronologically.
+// Supported inputs:
+//   - Records.json: {"locations": [{"latitudeE7", "longitudeE7", "timestamp"}]}
+//   - Timeline.json (on-device, Android): {"semanticSegments": [...], "rawSignals": [...]}
+//   - location-history.json (on-device, iOS): [{"startTime", "visit" | "activity" | "timelinePath"}]
+//   - Semantic Location History monthly files: {"timelineObjects": [...]}
+func Parse(data []byte) ([]Point, error) {
+	trimmed := strings.TrimSpace(string(data))
+	if strings.HasPrefix(trimmed, "[") {
+		return parseLocationHistory(data)
+	}
+
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("parse takeout json: %w", err)
+	}
+
+	var points []Point
+	var err error
+	switch {
+	case probe["locations"] != nil:
+		points, err = parseRecords(data)
+	case probe["semanticSegments"] != nil || probe["rawSignals"] != nil:
+		points, err = parseTimeline(data)
+	case probe["timelineObjects"] != nil:
+		points, err = parseTimelineObjects(data)
+	default:
+		return nil, fmt.Errorf("unrecognized takeout format (expected Records.json, Timeline.json, or location-history.json)")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sortPoints(points)
+	return points, nil
+}
+
+func sortPoints(points []Point) {
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].RecordedAt.Before(points[j].RecordedAt)
+	})
+}
+
+// --- Records.json ---
+
+type recordsFile struct {
+	Locations []struct {
+		LatitudeE7  *int64   `json:"latitudeE7"`
+		LongitudeE7 *int64   `json:"longitudeE7"`
+		Timestamp   string   `json:"timestamp"`
+		TimestampMs string   `json:"timestampMs"`
+		Accuracy    *float64 `json:"accuracy"`
+		Altitude    *float64 `json:"altitude"`
+		Velocity    *float64 `json:"velocity"`
+		Heading     *float64 `json:"heading"`
+	} `json:"locations"`
+}
+
+func parseRecords(data []byte) ([]Point, error) {
+	var f recordsFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse Records.json: %w", err)
+	}
+
+	points := make([]Point, 0, len(f.Locations))
+	for _, loc := range f.Locations {
+		if loc.LatitudeE7 == nil || loc.LongitudeE7 == nil {
+			continue
+		}
+		at, err := parseTimestamp(loc.Timestamp, loc.TimestampMs)
+		if err != nil {
+			continue
+		}
+		points = append(points, Point{
+			Latitude:   fromE7(*loc.LatitudeE7),
+			Longitude:  fromE7(*loc.LongitudeE7),
+			RecordedAt: at,
+			Accuracy:   loc.Accuracy,
+			Altitude:   loc.Altitude,
+			Speed:      loc.Velocity,
+			Heading:    loc.Heading,
+		})
+	}
+	return points, nil
+}
+
+// --- Timeline.json (Android on-device export) ---
+
+type timelineFile struct {
+	SemanticSegments []struct {
+		StartTime    string `json:"startTime"`
+		EndTime      string `json:"endTime"`
+		TimelinePath []struct {
+			Point string `json:"point"`
+			Time  string `json:"time"`
+		} `json:"timelinePath"`
+		Visit *struct {
+			TopCandidate struct {
+				SemanticType  string `json:"semanticType"`
+				PlaceLocation struct {
+					LatLng string `json:"latLng"`
+				} `json:"placeLocation"`
+			} `json:"topCandidate"`
+		} `json:"visit"`
+	} `json:"semanticSegments"`
+	RawSignals []struct {
+		Position *struct {
+			LatLng    string `json:"LatLng"`
+			Timestamp string `json:"timestamp"`
+		} `json:"position"`
+	} `json:"rawSignals"`
+}
+
+func parseTimeline(data []byte) ([]Point, error) {
+	var f timelineFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse Timeline.json: %w", err)
+	}
+
+	var points []Point
+	for _, seg := range f.SemanticSegments {
+		if seg.Visit != nil {
+			lat, lng, err := parseLatLng(seg.Visit.TopCandidate.PlaceLocation.LatLng)
+			at, terr := time.Parse(time.RFC3339, seg.StartTime)
+			if err == nil && terr == nil {
+				label := visitLabel(seg.Visit.TopCandidate.SemanticType)
+				points = append(points, Point{Latitude: lat, Longitude: lng, RecordedAt: at, Label: &label})
+			}
+		}
+		for _, p := range seg.TimelinePath {
+			lat, lng, err := parseLatLng(p.Point)
+			if err != nil {
+				continue
+			}
+			at, err := time.Parse(time.RFC3339, p.Time)
+			if err != nil {
+				continue
+			}
+			points = append(points, Point{Latitude: lat, Longitude: lng, RecordedAt: at})
+		}
+	}
+
+	for _, sig := range f.RawSignals {
+		if sig.Position == nil {
+			continue
+		}
+		lat, lng, err := parseLatLng(sig.Position.LatLng)
+		if err != nil {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, sig.Position.Timestamp)
+		if err != nil {
+			continue
+		}
+		points = append(points, Point{Latitude: lat, Longitude: lng, RecordedAt: at})
+	}
+
+	return points, nil
+}
+
+// --- location-history.json (iOS on-device export) ---
+
+type locationHistoryEntry struct {
+	StartTime string `json:"startTime"`
+	EndTime   string `json:"endTime"`
+	Visit     *struct {
+		TopCandidate struct {
+			SemanticType  string `json:"semanticType"`
+			PlaceLocation string `json:"placeLocation"`
+		} `json:"topCandidate"`
+	} `json:"visit"`
+	Activity *struct {
+		Start string `json:"start"`
+		End   string `json:"end"`
+	} `json:"activity"`
+	TimelinePath []struct {
+		Point         string `json:"point"`
+		OffsetMinutes string `json:"durationMinutesOffsetFromStartTime"`
+	} `json:"timelinePath"`
+}
+
+func parseLocationHistory(data []byte) ([]Point, error) {
+	var entries []locationHistoryEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("parse location-history.json: %w", err)
+	}
+
+	var points []Point
+	for _, e := range entries {
+		start, err := time.Parse(time.RFC3339, e.StartTime)
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case e.Visit != nil:
+			lat, lng, err := parseLatLng(e.Visit.TopCandidate.PlaceLocation)
+			if err != nil {
+				continue
+			}
+			label := visitLabel(e.Visit.TopCandidate.SemanticType)
+			points = append(points, Point{Latitude: lat, Longitude: lng, RecordedAt: start, Label: &label})
+		case e.Activity != nil:
+			if lat, lng, err := parseLatLng(e.Activity.Start); err == nil {
+				points = append(points, Point{Latitude: lat, Longitude: lng, RecordedAt: start})
+			}
+			end, err := time.Parse(time.RFC3339, e.EndTime)
+			if err != nil {
+				continue
+			}
+			if lat, lng, err := parseLatLng(e.Activity.End); err == nil {
+				points = append(points, Point{Latitude: lat, Longitude: lng, RecordedAt: end})
+			}
+		default:
+			for _, p := range e.TimelinePath {
+				lat, lng, err := parseLatLng(p.Point)
+				if err != nil {
+					continue
+				}
+				offset, _ := strconv.ParseFloat(p.OffsetMinutes, 64)
+				at := start.Add(time.Duration(offset * float64(time.Minute)))
+				points = append(points, Point{Latitude: lat, Longitude: lng, RecordedAt: at})
+			}
+		}
+	}
+
+	sortPoints(points)
+	return points, nil
+}
+
+// --- Semantic Location History monthly files ---
+
+type e7Location struct {
+	LatitudeE7  *int64 `json:"latitudeE7"`
+	LongitudeE7 *int64 `json:"longitudeE7"`
+	Name        string `json:"name"`
+}
+
+type e7Duration struct {
+	StartTimestamp   string `json:"startTimestamp"`
+	StartTimestampMs string `json:"startTimestampMs"`
+	EndTimestamp     string `json:"endTimestamp"`
+	EndTimestampMs   string `json:"endTimestampMs"`
+}
+
+type timelineObjectsFile struct {
+	TimelineObjects []struct {
+		PlaceVisit *struct {
+			Location e7Location `json:"location"`
+			Duration e7Duration `json:"duration"`
+		} `json:"placeVisit"`
+		ActivitySegment *struct {
+			StartLocation e7Location `json:"startLocation"`
+			EndLocation   e7Location `json:"endLocation"`
+			Duration      e7Duration `json:"duration"`
+		} `json:"activitySegment"`
+	} `json:"timelineObjects"`
+}
+
+func parseTimelineObjects(data []byte) ([]Point, error) {
+	var f timelineObjectsFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse semantic location history: %w", err)
+	}
+
+	var points []Point
+	add := func(loc e7Location, ts, tsMs string, label *string) {
+		if loc.LatitudeE7 == nil || loc.LongitudeE7 == nil {
+			return
+		}
+		at, err := parseTimestamp(ts, tsMs)
+		if err != nil {
+			return
+		}
+		points = append(points, Point{
+			Latitude:   fromE7(*loc.LatitudeE7),
+			Longitude:  fromE7(*loc.LongitudeE7),
+			RecordedAt: at,
+			Label:      label,
+		})
+	}
+
+	for _, obj := range f.TimelineObjects {
+		if v := obj.PlaceVisit; v != nil {
+			label := v.Location.Name
+			if label == "" {
+				label = "visit"
+			}
+			add(v.Location, v.Duration.StartTimestamp, v.Duration.StartTimestampMs, &label)
+		}
+		if a := obj.ActivitySegment; a != nil {
+			add(a.StartLocation, a.Duration.StartTimestamp, a.Duration.StartTimestampMs, nil)
+			add(a.EndLocation, a.Duration.EndTimestamp, a.Duration.EndTimestampMs, nil)
+		}
+	}
+
+	return points, nil
+}
+
+// --- Helpers ---
+
+// fromE7 converts an E7 integer coordinate to degrees.
+func fromE7(v int64) float64 {
+	return float64(v) / 1e7
+}
+
+// parseTimestamp parses either an RFC3339 timestamp or a millisecond epoch string.
+func parseTimestamp(ts, tsMs string) (time.Time, error) {
+	if ts != "" {
+		return time.Parse(time.RFC3339, ts)
+	}
+	if tsMs != "" {
+		ms, err := strconv.ParseInt(tsMs, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid timestampMs %q", tsMs)
+		}
+		return time.UnixMilli(ms).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("missing timestamp")
+}
+
+// latLngRegex matches "48.1°, 11.5°" and "geo:48.1,11.5" coordinate strings.
+var latLngRegex = regexp.MustCompile(`^(?:geo:)?\s*(-?\d+(?:\.\d+)?)°?\s*,\s*(-?\d+(?:\.\d+)?)°?\s*$`)
+
+// parseLatLng parses the coordinate strings used by on-device Timeline exports.
+func parseLatLng(s string) (float64, float64, error) {
+	m := latLngRegex.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, 0, fmt.Errorf("invalid coordinates %q", s)
+	}
+	lat, _ := strconv.ParseFloat(m[1], 64)
+	lng, _ := strconv.ParseFloat(m[2], 64)
+	return lat, lng, nil
+}
+
+// visitLabel converts a semantic place type (e.g. "INFERRED_HOME") to a label.
+func visitLabel(semanticType string) string {
+	t := strings.ToLower(strings.TrimSpace(semanticType))
+	t = strings.TrimPrefix(t, "inferred_")
+	switch t {
+	case "", "unknown":
+		return "visit"
+	default:
+		return strings.ReplaceAll(t, "_", " ")
+	}
+}
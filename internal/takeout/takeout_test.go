@@ -0,0 +1,186 @@
+// ABOUTME: Unit tests for Google Takeout location history parsing
+// ABOUTME: Covers Records.json, Timeline.json, location-history.json, and monthly semantic files
+
+package takeout
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse_Records(t *testing.T) {
+	data := []byte(`{"locations": [
+		{"latitudeE7": 418781000, "longitudeE7": -876298000, "accuracy": 20, "timestamp": "2022-01-01T12:00:00.000Z"},
+		{"latitudeE7": 407128000, "longitudeE7": -740060000, "timestampMs": "1640995200000"},
+		{"latitudeE7": 1, "timestamp": "2022-01-01T13:00:00Z"}
+	]}`)
+
+	points, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(points) != 2 {
+		t.Fatalf("expected 2 points (incomplete entry skipped), got %d", len(points))
+	}
+
+	// Sorted chronologically: timestampMs entry is 2022-01-01T00:00:00Z
+	if points[0].Latitude != 40.7128 || points[0].Longitude != -74.006 {
+		t.Errorf("unexpected first point: %+v", points[0])
+	}
+	if !points[0].RecordedAt.Equal(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected first time: %v", points[0].RecordedAt)
+	}
+	if points[1].Latitude != 41.8781 || points[1].Label != nil {
+		t.Errorf("unexpected second point: %+v", points[1])
+	}
+}
+
+func TestParse_Timeline(t *testing.T) {
+	data := []byte(`{
+		"semanticSegments": [
+			{
+				"startTime": "2024-01-01T08:00:00.000+01:00",
+				"endTime": "2024-01-01T09:00:00.000+01:00",
+				"visit": {"topCandidate": {"semanticType": "HOME", "placeLocation": {"latLng": "48.1351°, 11.5820°"}}}
+			},
+			{
+				"startTime": "2024-01-01T09:00:00.000+01:00",
+				"endTime": "2024-01-01T10:00:00.000+01:00",
+				"timelinePath": [
+					{"point": "48.14°, 11.59°", "time": "2024-01-01T09:10:00.000+01:00"},
+					{"point": "bogus", "time": "2024-01-01T09:20:00.000+01:00"}
+				]
+			}
+		],
+		"rawSignals": [
+			{"position": {"LatLng": "48.15°, 11.60°", "accuracyMeters": 10, "timestamp": "2024-01-01T09:30:00.000+01:00"}},
+			{"wifiScan": {}}
+		]
+	}`)
+
+	points, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(points) != 3 {
+		t.Fatalf("expected 3 points, got %d", len(points))
+	}
+	if points[0].Label == nil || *points[0].Label != "home" {
+		t.Errorf("expected visit labeled 'home', got %v", points[0].Label)
+	}
+	if points[0].Latitude != 48.1351 || points[0].Longitude != 11.582 {
+		t.Errorf("unexpected visit coordinates: %+v", points[0])
+	}
+	if points[2].Latitude != 48.15 {
+		t.Errorf("expected raw signal last, got %+v", points[2])
+	}
+}
+
+func TestParse_LocationHistory(t *testing.T) {
+	data := []byte(`[
+		{
+			"startTime": "2024-01-01T08:00:00.000-05:00",
+			"endTime": "2024-01-01T09:00:00.000-05:00",
+			"visit": {"hierarchyLevel": "0", "topCandidate": {"semanticType": "Work", "placeLocation": "geo:41.878100,-87.629800"}}
+		},
+		{
+			"startTime": "2024-01-01T09:00:00.000-05:00",
+			"endTime": "2024-01-01T09:30:00.000-05:00",
+			"activity": {"start": "geo:41.878100,-87.629800", "end": "geo:41.900000,-87.650000", "topCandidate": {"type": "walking"}}
+		},
+		{
+			"startTime": "2024-01-01T10:00:00.000-05:00",
+			"endTime": "2024-01-01T12:00:00.000-05:00",
+			"timelinePath": [
+				{"point": "geo:41.910000,-87.660000", "durationMinutesOffsetFromStartTime": "15"}
+			]
+		}
+	]`)
+
+	points, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(points) != 4 {
+		t.Fatalf("expected 4 points, got %d", len(points))
+	}
+	if points[0].Label == nil || *points[0].Label != "work" {
+		t.Errorf("expected visit labeled 'work', got %v", points[0].Label)
+	}
+	want := time.Date(2024, 1, 1, 15, 15, 0, 0, time.UTC)
+	if !points[3].RecordedAt.Equal(want) {
+		t.Errorf("expected timeline path point at %v, got %v", want, points[3].RecordedAt)
+	}
+}
+
+func TestParse_TimelineObjects(t *testing.T) {
+	data := []byte(`{"timelineObjects": [
+		{"placeVisit": {
+			"location": {"latitudeE7": 418781000, "longitudeE7": -876298000, "name": "Willis Tower"},
+			"duration": {"startTimestamp": "2019-06-01T10:00:00Z", "endTimestamp": "2019-06-01T11:00:00Z"}
+		}},
+		{"activitySegment": {
+			"startLocation": {"latitudeE7": 418781000, "longitudeE7": -876298000},
+			"endLocation": {"latitudeE7": 419000000, "longitudeE7": -876500000},
+			"duration": {"startTimestampMs": "1559386800000", "endTimestampMs": "1559388600000"}
+		}}
+	]}`)
+
+	points, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(points) != 3 {
+		t.Fatalf("expected 3 points, got %d", len(points))
+	}
+	if points[0].Label == nil || *points[0].Label != "Willis Tower" {
+		t.Errorf("expected place visit labeled 'Willis Tower', got %v", points[0].Label)
+	}
+}
+
+func TestParse_Unrecognized(t *testing.T) {
+	if _, err := Parse([]byte(`{"something": []}`)); err == nil {
+		t.Error("expected error for unrecognized format")
+	}
+	if _, err := Parse([]byte(`not json`)); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestParseLatLng(t *testing.T) {
+	tests := []struct {
+		in       string
+		lat, lng float64
+		wantErr  bool
+	}{
+		{"48.1351°, 11.5820°", 48.1351, 11.582, false},
+		{"-33.8688°, 151.2093°", -33.8688, 151.2093, false},
+		{"geo:41.878100,-87.629800", 41.8781, -87.6298, false},
+		{"nowhere", 0, 0, true},
+	}
+	for _, tt := range tests {
+		lat, lng, err := parseLatLng(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseLatLng(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if lat != tt.lat || lng != tt.lng {
+			t.Errorf("parseLatLng(%q) = %v, %v, want %v, %v", tt.in, lat, lng, tt.lat, tt.lng)
+		}
+	}
+}
+
+func TestVisitLabel(t *testing.T) {
+	tests := map[string]string{
+		"HOME":             "home",
+		"INFERRED_WORK":    "work",
+		"SEARCHED_ADDRESS": "searched address",
+		"UNKNOWN":          "visit",
+		"":                 "visit",
+	}
+	for in, want := range tests {
+		if got := visitLabel(in); got != want {
+			t.Errorf("visitLabel(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
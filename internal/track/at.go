@@ -0,0 +1,78 @@
+// ABOUTME: Point-in-time position lookup for position histories
+// ABOUTME: Finds the position in effect at a timestamp, optionally interpolating between fixes
+
+package track
+
+import (
+	"time"
+
+	"github.com/harper/position/internal/geo"
+	"github.com/harper/position/internal/models"
+)
+
+// Fix is an item's estimated location at a moment in time.
+type Fix struct {
+	At        time.Time
+	Latitude  float64
+	Longitude float64
+	Label     *string // Before's label; nil when interpolated
+
+	Before *models.Position // latest position recorded at or before At
+	After  *models.Position // earliest position recorded after At; nil if none
+
+	Interpolated bool
+
+	// Gap measures how much to trust the fix. When interpolated it is the time
+	// between Before and After; otherwise it is how long before At the fix
+	// was recorded. Zero means a position was recorded exactly at At.
+	Gap time.Duration
+}
+
+// At returns the position in effect at the given time: the latest position
+// recorded at or before it. With interpolate set and a later position
+// available, the location is instead placed along the great circle between
+// the two, in proportion to the time elapsed. Positions may be in any order.
+// It returns false when at precedes every position.
+func At(positions []*models.Position, at time.Time, interpolate bool) (Fix, bool) {
+	before, after := Neighbours(positions, at)
+	if before == nil {
+		return Fix{}, false
+	}
+
+	fix := Fix{
+		At:        at,
+		Latitude:  before.Latitude,
+		Longitude: before.Longitude,
+		Label:     before.Label,
+		Before:    before,
+		After:     after,
+		Gap:       at.Sub(before.RecordedAt),
+	}
+	if !interpolate || after == nil || fix.Gap == 0 {
+		return fix, true
+	}
+
+	span := after.RecordedAt.Sub(before.RecordedAt)
+	f := float64(fix.Gap) / float64(span)
+	fix.Latitude, fix.Longitude = geo.Interpolate(before.Latitude, before.Longitude, after.Latitude, after.Longitude, f)
+	fix.Interpolated = true
+	fix.Gap = span
+	fix.Label = nil
+	return fix, true
+}
+
+// Neighbours returns the positions immediately around at: before is the
+// latest recorded at or before it, after the earliest recorded after it.
+// Either is nil when there is none. Positions may be in any order.
+func Neighbours(positions []*models.Position, at time.Time) (before, after *models.Position) {
+	for _, p := range positions {
+		if p.RecordedAt.After(at) {
+			if after == nil || p.RecordedAt.Before(after.RecordedAt) {
+				after = p
+			}
+		} else if before == nil || p.RecordedAt.After(before.RecordedAt) {
+			before = p
+		}
+	}
+	return before, after
+}
@@ -0,0 +1,101 @@
+// ABOUTME: Unit tests for point-in-time position lookup
+// ABOUTME: Verifies the position in effect, interpolation, gap reporting, and neighbour search
+
+package track
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/harper/position/internal/models"
+)
+
+func TestAt(t *testing.T) {
+	base := time.Date(2024, 12, 14, 18, 0, 0, 0, time.UTC)
+	minute := func(m int) time.Time { return base.Add(time.Duration(m) * time.Minute) }
+	// Newest first, as GetTimeline returns them
+	positions := []*models.Position{
+		ping(0, 20, 40, ""),
+		ping(0, 10, 20, ""),
+		ping(0, 0, 0, "home"),
+	}
+
+	t.Run("before_history", func(t *testing.T) {
+		if _, ok := At(positions, minute(-1), true); ok {
+			t.Error("expected no fix before the first position")
+		}
+	})
+
+	t.Run("in_effect", func(t *testing.T) {
+		fix, ok := At(positions, minute(5), false)
+		if !ok {
+			t.Fatal("expected a fix")
+		}
+		if fix.Latitude != 0 || fix.Longitude != 0 || fix.Interpolated {
+			t.Errorf("expected the 18:00 position, got %+v", fix)
+		}
+		if fix.Label == nil || *fix.Label != "home" {
+			t.Errorf("expected label home, got %v", fix.Label)
+		}
+		if fix.Gap != 5*time.Minute || fix.After != positions[1] {
+			t.Errorf("expected 5m gap with the 18:20 fix after, got %v / %v", fix.Gap, fix.After)
+		}
+	})
+
+	t.Run("interpolated", func(t *testing.T) {
+		fix, ok := At(positions, minute(30), true)
+		if !ok {
+			t.Fatal("expected a fix")
+		}
+		if !fix.Interpolated || math.Abs(fix.Latitude) > 1e-9 || math.Abs(fix.Longitude-15) > 1e-9 {
+			t.Errorf("expected halfway between 18:20 and 18:40, got %+v", fix)
+		}
+		if fix.Gap != 20*time.Minute || fix.Label != nil {
+			t.Errorf("expected 20m gap and no label, got %v / %v", fix.Gap, fix.Label)
+		}
+	})
+
+	t.Run("exact_match", func(t *testing.T) {
+		fix, _ := At(positions, minute(20), true)
+		if fix.Interpolated || fix.Gap != 0 || fix.Longitude != 10 {
+			t.Errorf("expected the 18:20 position exactly, got %+v", fix)
+		}
+	})
+
+	t.Run("after_history", func(t *testing.T) {
+		fix, _ := At(positions, minute(100), true)
+		if fix.Interpolated || fix.After != nil || fix.Longitude != 20 || fix.Gap != time.Hour {
+			t.Errorf("expected the last position an hour stale, got %+v", fix)
+		}
+	})
+}
+
+func TestNeighbours(t *testing.T) {
+	base := time.Date(2024, 12, 14, 8, 0, 0, 0, time.UTC)
+	itemID := uuid.New()
+	at := func(d time.Duration) *models.Position {
+		return models.NewPositionWithRecordedAt(itemID, 41.0, -87.0, nil, base.Add(d))
+	}
+	early, middle, late := at(0), at(time.Hour), at(2*time.Hour)
+	positions := []*models.Position{late, early, middle}
+
+	prev, next := Neighbours(positions, base.Add(90*time.Minute))
+	if prev != middle || next != late {
+		t.Errorf("expected middle/late neighbours, got %v/%v", prev, next)
+	}
+	// A position at the same instant counts as the predecessor
+	prev, next = Neighbours(positions, base.Add(time.Hour))
+	if prev != middle || next != late {
+		t.Errorf("expected equal time to be prev, got %v/%v", prev, next)
+	}
+	prev, next = Neighbours(positions, base.Add(-time.Hour))
+	if prev != nil || next != early {
+		t.Errorf("expected no prev before earliest, got %v/%v", prev, next)
+	}
+	prev, next = Neighbours(nil, base)
+	if prev != nil || next != nil {
+		t.Error("expected no neighbours in empty history")
+	}
+}
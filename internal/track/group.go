@@ -0,0 +1,47 @@
+// ABOUTME: Per-item grouping of mixed position lists for exporters
+// ABOUTME: Splits positions into one chronological track per item, ordered by item name
+
+package track
+
+import (
+	"sort"
+
+	"github.com/harper/position/internal/models"
+)
+
+// ItemTrack is one item's positions in chronological order.
+type ItemTrack struct {
+	ItemID    string
+	Name      string
+	Positions []*models.Position
+}
+
+// GroupByItem splits positions into one track per item, ordered by the name
+// resolve returns for each item ID so output is stable. Items with equal
+// names keep the order they first appear in. A nil resolve names every
+// item "".
+func GroupByItem(positions []*models.Position, resolve func(itemID string) string) []ItemTrack {
+	index := make(map[string]int)
+	var tracks []ItemTrack
+	for _, pos := range positions {
+		key := pos.ItemID.String()
+		i, ok := index[key]
+		if !ok {
+			i = len(tracks)
+			index[key] = i
+			tracks = append(tracks, ItemTrack{ItemID: key})
+		}
+		tracks[i].Positions = append(tracks[i].Positions, pos)
+	}
+
+	for i := range tracks {
+		if resolve != nil {
+			tracks[i].Name = resolve(tracks[i].ItemID)
+		}
+		tracks[i].Positions = chronological(tracks[i].Positions)
+	}
+	sort.SliceStable(tracks, func(i, j int) bool {
+		return tracks[i].Name < tracks[j].Name
+	})
+	return tracks
+}
@@ -0,0 +1,44 @@
+// ABOUTME: Unit tests for per-item grouping
+// ABOUTME: Verifies name ordering, chronological tracks, and nil name resolvers
+
+package track
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/harper/position/internal/models"
+)
+
+func TestGroupByItem(t *testing.T) {
+	base := time.Date(2024, 12, 14, 8, 0, 0, 0, time.UTC)
+	car, phone := uuid.New(), uuid.New()
+	names := map[string]string{car.String(): "car", phone.String(): "phone"}
+	at := func(itemID uuid.UUID, d time.Duration) *models.Position {
+		return models.NewPositionWithRecordedAt(itemID, 41.0, -87.0, nil, base.Add(d))
+	}
+	phoneLate, phoneEarly := at(phone, time.Hour), at(phone, 0)
+	carOnly := at(car, 30*time.Minute)
+
+	tracks := GroupByItem([]*models.Position{phoneLate, carOnly, phoneEarly}, func(id string) string { return names[id] })
+	if len(tracks) != 2 {
+		t.Fatalf("expected 2 tracks, got %d", len(tracks))
+	}
+	if tracks[0].Name != "car" || tracks[0].ItemID != car.String() || len(tracks[0].Positions) != 1 {
+		t.Errorf("expected car first, got %+v", tracks[0])
+	}
+	if tracks[1].Name != "phone" || tracks[1].Positions[0] != phoneEarly || tracks[1].Positions[1] != phoneLate {
+		t.Errorf("expected phone positions in time order, got %+v", tracks[1])
+	}
+
+	// Without names, items keep the order they first appear in
+	tracks = GroupByItem([]*models.Position{phoneLate, carOnly}, nil)
+	if len(tracks) != 2 || tracks[0].ItemID != phone.String() || tracks[0].Name != "" {
+		t.Errorf("expected unnamed tracks in appearance order, got %+v", tracks)
+	}
+
+	if tracks := GroupByItem(nil, nil); len(tracks) != 0 {
+		t.Errorf("expected no tracks, got %+v", tracks)
+	}
+}
@@ -0,0 +1,259 @@
+// ABOUTME: Track simplification and resampling for position histories
+// ABOUTME: Thins dense tracks with Douglas-Peucker or Visvalingam and fixed-interval resampling
+
+package track
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/harper/position/internal/geo"
+	"github.com/harper/position/internal/models"
+)
+
+// SimplifyMethod selects a line simplification algorithm.
+type SimplifyMethod string
+
+const (
+	// DouglasPeucker keeps the points a line strays more than the tolerance
+	// from when reduced to its endpoints, recursively.
+	DouglasPeucker SimplifyMethod = "douglas-peucker"
+	// Visvalingam repeatedly drops the point forming the smallest triangle
+	// with its neighbours, until every remaining triangle covers at least the
+	// tolerance squared.
+	Visvalingam SimplifyMethod = "visvalingam"
+)
+
+// Reduction thins a track, typically before export. The zero value keeps
+// every position.
+type Reduction struct {
+	Interval  time.Duration  // keep at most one position per interval; 0 disables resampling
+	Tolerance float64        // meters the simplified line may stray; 0 disables simplification
+	Method    SimplifyMethod // empty means DouglasPeucker
+}
+
+// Enabled reports whether the reduction removes anything at all.
+func (r Reduction) Enabled() bool {
+	return r.Interval > 0 || r.Tolerance > 0
+}
+
+// Validate checks the reduction settings.
+func (r Reduction) Validate() error {
+	if r.Interval < 0 {
+		return fmt.Errorf("resample interval must not be negative")
+	}
+	if r.Tolerance < 0 {
+		return fmt.Errorf("simplify tolerance must not be negative")
+	}
+	switch r.Method {
+	case "", DouglasPeucker, Visvalingam:
+		return nil
+	default:
+		return fmt.Errorf("unknown simplify method %q (use %q or %q)", r.Method, DouglasPeucker, Visvalingam)
+	}
+}
+
+// Reduce resamples and then simplifies one item's positions, returning the
+// positions kept, oldest first. The first and last positions are always kept.
+// Positions may be in any order.
+func Reduce(positions []*models.Position, r Reduction) []*models.Position {
+	sorted := chronological(positions)
+	if r.Interval > 0 {
+		sorted = Resample(sorted, r.Interval)
+	}
+	if r.Tolerance > 0 {
+		sorted = Simplify(sorted, r.Tolerance, r.Method)
+	}
+	return sorted
+}
+
+// ReduceAll applies Reduce to each item's positions separately and returns
+// the positions kept in their original order.
+func ReduceAll(positions []*models.Position, r Reduction) []*models.Position {
+	if !r.Enabled() {
+		return positions
+	}
+
+	byItem := make(map[string][]*models.Position)
+	for _, pos := range positions {
+		key := pos.ItemID.String()
+		byItem[key] = append(byItem[key], pos)
+	}
+	kept := make(map[*models.Position]bool, len(positions))
+	for _, itemPositions := range byItem {
+		for _, pos := range Reduce(itemPositions, r) {
+			kept[pos] = true
+		}
+	}
+
+	reduced := make([]*models.Position, 0, len(kept))
+	for _, pos := range positions {
+		if kept[pos] {
+			reduced = append(reduced, pos)
+		}
+	}
+	return reduced
+}
+
+// Resample keeps the first of chronological positions and then each position
+// recorded at least interval after the previous one kept, plus the last.
+func Resample(positions []*models.Position, interval time.Duration) []*models.Position {
+	if len(positions) < 3 || interval <= 0 {
+		return positions
+	}
+
+	kept := []*models.Position{positions[0]}
+	last := len(positions) - 1
+	for _, pos := range positions[1:last] {
+		if pos.RecordedAt.Sub(kept[len(kept)-1].RecordedAt) >= interval {
+			kept = append(kept, pos)
+		}
+	}
+	return append(kept, positions[last])
+}
+
+// Simplify reduces chronological positions to those needed to keep the line
+// within tolerance meters of the original, using the given method.
+func Simplify(positions []*models.Position, tolerance float64, method SimplifyMethod) []*models.Position {
+	if len(positions) < 3 || tolerance <= 0 {
+		return positions
+	}
+
+	var keep []bool
+	if method == Visvalingam {
+		keep = visvalingam(positions, tolerance*tolerance)
+	} else {
+		keep = douglasPeucker(positions, tolerance)
+	}
+
+	kept := make([]*models.Position, 0, len(positions))
+	for i, pos := range positions {
+		if keep[i] {
+			kept = append(kept, pos)
+		}
+	}
+	return kept
+}
+
+// douglasPeucker marks the positions to keep so that no dropped position is
+// more than tolerance meters from the simplified line.
+func douglasPeucker(positions []*models.Position, tolerance float64) []bool {
+	keep := make([]bool, len(positions))
+	keep[0], keep[len(positions)-1] = true, true
+
+	type span struct{ first, last int }
+	stack := []span{{0, len(positions) - 1}}
+	for len(stack) > 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		farthest, maxDist := -1, tolerance
+		for i := s.first + 1; i < s.last; i++ {
+			if d := segmentDistance(positions[i], positions[s.first], positions[s.last]); d > maxDist {
+				farthest, maxDist = i, d
+			}
+		}
+		if farthest < 0 {
+			continue
+		}
+		keep[farthest] = true
+		stack = append(stack, span{s.first, farthest}, span{farthest, s.last})
+	}
+	return keep
+}
+
+// vertex is a point in a Visvalingam linked list, ordered in a min-heap by
+// the area of the triangle it forms with its neighbours.
+type vertex struct {
+	i, prev, next int
+	area          float64
+	index         int // position in the heap
+}
+
+type vertexHeap []*vertex
+
+func (h vertexHeap) Len() int           { return len(h) }
+func (h vertexHeap) Less(i, j int) bool { return h[i].area < h[j].area }
+func (h vertexHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index, h[j].index = i, j
+}
+func (h *vertexHeap) Push(x interface{}) {
+	v := x.(*vertex)
+	v.index = len(*h)
+	*h = append(*h, v)
+}
+func (h *vertexHeap) Pop() interface{} {
+	old := *h
+	v := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return v
+}
+
+// visvalingam marks the positions to keep after repeatedly dropping the one
+// whose triangle with its neighbours is smallest, while that triangle is
+// below minArea square meters.
+func visvalingam(positions []*models.Position, minArea float64) []bool {
+	n := len(positions)
+	keep := make([]bool, n)
+	vertices := make([]*vertex, n)
+	h := make(vertexHeap, 0, n-2)
+	for i := range positions {
+		keep[i] = true
+		vertices[i] = &vertex{i: i, prev: i - 1, next: i + 1}
+		if i > 0 && i < n-1 {
+			vertices[i].area = triangleArea(positions[i-1], positions[i], positions[i+1])
+			heap.Push(&h, vertices[i])
+		}
+	}
+
+	for h.Len() > 0 && h[0].area < minArea {
+		v := heap.Pop(&h).(*vertex)
+		keep[v.i] = false
+		prev, next := vertices[v.prev], vertices[v.next]
+		prev.next, next.prev = next.i, prev.i
+		for _, u := range []*vertex{prev, next} {
+			if u.prev < 0 || u.next >= n {
+				continue
+			}
+			u.area = triangleArea(positions[u.prev], positions[u.i], positions[u.next])
+			heap.Fix(&h, u.index)
+		}
+	}
+	return keep
+}
+
+// offset projects p onto a local plane centred on origin, returning east and
+// north offsets in meters. It is accurate over the short distances between
+// neighbouring fixes.
+func offset(origin, p *models.Position) (x, y float64) {
+	dLng := p.Longitude - origin.Longitude
+	if dLng > 180 {
+		dLng -= 360
+	} else if dLng < -180 {
+		dLng += 360
+	}
+	perDegree := geo.EarthRadiusMeters * math.Pi / 180
+	return dLng * perDegree * math.Cos(origin.Latitude*math.Pi/180), (p.Latitude - origin.Latitude) * perDegree
+}
+
+// segmentDistance returns the distance in meters from p to the segment a-b.
+func segmentDistance(p, a, b *models.Position) float64 {
+	px, py := offset(a, p)
+	bx, by := offset(a, b)
+	lengthSq := bx*bx + by*by
+	if lengthSq == 0 {
+		return math.Hypot(px, py)
+	}
+	t := math.Max(0, math.Min(1, (px*bx+py*by)/lengthSq))
+	return math.Hypot(px-t*bx, py-t*by)
+}
+
+// triangleArea returns the area in square meters of the triangle a-b-c.
+func triangleArea(a, b, c *models.Position) float64 {
+	ax, ay := offset(b, a)
+	cx, cy := offset(b, c)
+	return math.Abs(ax*cy-cx*ay) / 2
+}
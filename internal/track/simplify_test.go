@@ -0,0 +1,127 @@
+// ABOUTME: Unit tests for track simplification and resampling
+// ABOUTME: Verifies Douglas-Peucker, Visvalingam, interval resampling, and per-item reduction
+
+package track
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/harper/position/internal/models"
+)
+
+// zigzag returns a straight eastward track with a small wobble on every
+// other fix and one 500 m detour in the middle.
+func zigzag() []*models.Position {
+	var positions []*models.Position
+	for i := 0; i <= 20; i++ {
+		lat := 41.8781
+		if i%2 == 1 {
+			lat += 0.00003 // ~3 m
+		}
+		if i == 10 {
+			lat += 0.0045 // ~500 m
+		}
+		positions = append(positions, ping(lat, -87.6298+float64(i)*0.001, i, ""))
+	}
+	return positions
+}
+
+func TestSimplify(t *testing.T) {
+	for _, method := range []SimplifyMethod{DouglasPeucker, Visvalingam} {
+		t.Run(string(method), func(t *testing.T) {
+			positions := zigzag()
+			// Visvalingam compares triangle areas, so its tolerance is looser
+			tolerance := 10.0
+			if method == Visvalingam {
+				tolerance = 20
+			}
+			got := Simplify(positions, tolerance, method)
+
+			// The detour's corners survive; the wobble between them does not
+			if len(got) > 7 {
+				t.Fatalf("expected the wobble removed, got %d of %d positions", len(got), len(positions))
+			}
+			if got[0] != positions[0] || got[len(got)-1] != positions[20] {
+				t.Error("expected endpoints kept")
+			}
+			found := false
+			for _, pos := range got {
+				if pos == positions[10] {
+					found = true
+				}
+			}
+			if !found {
+				t.Error("expected the detour kept")
+			}
+		})
+	}
+
+	positions := zigzag()
+	if got := Simplify(positions, 0, DouglasPeucker); len(got) != len(positions) {
+		t.Errorf("expected zero tolerance to keep everything, got %d", len(got))
+	}
+	if got := Simplify(positions, 1, DouglasPeucker); len(got) != len(positions) {
+		t.Errorf("expected 1 m tolerance to keep the 3 m wobble, got %d", len(got))
+	}
+}
+
+func TestResample(t *testing.T) {
+	var positions []*models.Position
+	for _, m := range []int{0, 1, 2, 5, 6, 11, 12} {
+		positions = append(positions, ping(41.8781, -87.6298, m, ""))
+	}
+
+	got := Resample(positions, 5*time.Minute)
+	var minutes []int
+	for _, pos := range got {
+		minutes = append(minutes, int(pos.RecordedAt.Sub(positions[0].RecordedAt).Minutes()))
+	}
+	want := []int{0, 5, 11, 12}
+	if len(minutes) != len(want) {
+		t.Fatalf("expected minutes %v, got %v", want, minutes)
+	}
+	for i := range want {
+		if minutes[i] != want[i] {
+			t.Fatalf("expected minutes %v, got %v", want, minutes)
+		}
+	}
+}
+
+func TestReduceAll(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	var positions []*models.Position
+	for i := 0; i < 6; i++ {
+		for _, id := range []uuid.UUID{a, b} {
+			pos := ping(41.8781, -87.6298+float64(i)*0.001, i*2, "")
+			pos.ItemID = id
+			// Newest first, as storage returns them
+			positions = append([]*models.Position{pos}, positions...)
+		}
+	}
+
+	r := Reduction{Tolerance: 5}
+	if err := r.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	got := ReduceAll(positions, r)
+	if len(got) != 4 {
+		t.Fatalf("expected each straight track reduced to its endpoints, got %d", len(got))
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i].RecordedAt.After(got[i-1].RecordedAt) {
+			t.Error("expected input order preserved")
+		}
+	}
+
+	if got := ReduceAll(positions, Reduction{}); len(got) != len(positions) {
+		t.Errorf("expected zero reduction to keep everything, got %d", len(got))
+	}
+	if err := (Reduction{Method: "spline"}).Validate(); err == nil {
+		t.Error("expected error for unknown method")
+	}
+	if err := (Reduction{Interval: -time.Minute}).Validate(); err == nil {
+		t.Error("expected error for negative interval")
+	}
+}
@@ -0,0 +1,190 @@
+// ABOUTME: Kalman smoothing for noisy position histories
+// ABOUTME: Fits a constant-velocity model to a track, weighting fixes by reported accuracy
+
+package track
+
+import (
+	"math"
+
+	"github.com/harper/position/internal/geo"
+	"github.com/harper/position/internal/models"
+)
+
+// Default smoothing parameters, suited to walking and cycling.
+const (
+	DefaultSmoothAcceleration = 0.1  // m/s²
+	DefaultSmoothAccuracy     = 10.0 // meters
+)
+
+// SmoothOptions tune the smoother.
+type SmoothOptions struct {
+	// Acceleration is the typical unmodelled acceleration in m/s². Lower
+	// values trust the constant-velocity model more and smooth harder.
+	Acceleration float64
+	// DefaultAccuracy is the measurement error in meters assumed for fixes
+	// that don't report an accuracy.
+	DefaultAccuracy float64
+}
+
+// DefaultSmoothOptions returns the default smoothing parameters.
+func DefaultSmoothOptions() SmoothOptions {
+	return SmoothOptions{
+		Acceleration:    DefaultSmoothAcceleration,
+		DefaultAccuracy: DefaultSmoothAccuracy,
+	}
+}
+
+// Smooth runs a constant-velocity Kalman filter forward over one item's
+// positions and a Rauch-Tung-Striebel pass back, returning smoothed copies
+// oldest first. Fixes reporting a larger accuracy radius move more. The
+// positions passed in are not modified; copies keep their IDs, times, labels,
+// and telemetry. Positions may be in any order.
+func Smooth(positions []*models.Position, opts SmoothOptions) []*models.Position {
+	sorted := chronological(positions)
+	smoothed := make([]*models.Position, len(sorted))
+	for i, pos := range sorted {
+		c := *pos
+		smoothed[i] = &c
+	}
+	if len(sorted) < 3 {
+		return smoothed
+	}
+
+	origin := sorted[0]
+	xs := make([]float64, len(sorted))
+	ys := make([]float64, len(sorted))
+	variances := make([]float64, len(sorted))
+	times := make([]float64, len(sorted))
+	for i, pos := range sorted {
+		xs[i], ys[i] = offset(origin, pos)
+		sigma := opts.DefaultAccuracy
+		if pos.Accuracy != nil && *pos.Accuracy > 0 {
+			sigma = *pos.Accuracy
+		}
+		variances[i] = sigma * sigma
+		times[i] = pos.RecordedAt.Sub(origin.RecordedAt).Seconds()
+	}
+
+	q := opts.Acceleration * opts.Acceleration
+	xs = smoothAxis(xs, variances, times, q)
+	ys = smoothAxis(ys, variances, times, q)
+	for i, pos := range smoothed {
+		pos.Latitude, pos.Longitude = unoffset(origin, xs[i], ys[i])
+	}
+	return smoothed
+}
+
+// SmoothAll applies Smooth to each item's positions separately and returns
+// the smoothed copies in the original order.
+func SmoothAll(positions []*models.Position, opts SmoothOptions) []*models.Position {
+	byItem := make(map[string][]*models.Position)
+	for _, pos := range positions {
+		key := pos.ItemID.String()
+		byItem[key] = append(byItem[key], pos)
+	}
+	replaced := make(map[*models.Position]*models.Position, len(positions))
+	for _, itemPositions := range byItem {
+		sorted := chronological(itemPositions)
+		for i, s := range Smooth(sorted, opts) {
+			replaced[sorted[i]] = s
+		}
+	}
+
+	smoothed := make([]*models.Position, len(positions))
+	for i, pos := range positions {
+		smoothed[i] = replaced[pos]
+	}
+	return smoothed
+}
+
+// kalmanState is the position/velocity estimate along one axis and its
+// covariance.
+type kalmanState struct {
+	p, v          float64
+	ppp, ppv, pvv float64 // covariance entries; symmetric, so pvp == ppv
+}
+
+// smoothAxis smooths measurements z with the given variances, taken at times
+// in seconds, under white-noise acceleration with spectral density q.
+func smoothAxis(z, variances, times []float64, q float64) []float64 {
+	n := len(z)
+	filtered := make([]kalmanState, n)
+	predicted := make([]kalmanState, n)
+
+	// Start at the first fix, at rest but with a loose velocity
+	s := kalmanState{p: z[0], ppp: variances[0], pvv: 100}
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			s = predict(s, times[i]-times[i-1], q)
+		}
+		predicted[i] = s
+
+		// Update with the measured position
+		r := variances[i]
+		innovation := s.ppp + r
+		kp, kv := s.ppp/innovation, s.ppv/innovation
+		residual := z[i] - s.p
+		s = kalmanState{
+			p:   s.p + kp*residual,
+			v:   s.v + kv*residual,
+			ppp: (1 - kp) * s.ppp,
+			ppv: (1 - kp) * s.ppv,
+			pvv: s.pvv - kv*s.ppv,
+		}
+		filtered[i] = s
+	}
+
+	// Rauch-Tung-Striebel backward pass
+	out := make([]float64, n)
+	out[n-1] = filtered[n-1].p
+	smoothP, smoothV := filtered[n-1].p, filtered[n-1].v
+	for i := n - 2; i >= 0; i-- {
+		f, pr := filtered[i], predicted[i+1]
+		dt := times[i+1] - times[i]
+
+		// C = P_f · Fᵀ · P_pred⁻¹
+		det := pr.ppp*pr.pvv - pr.ppv*pr.ppv
+		if det <= 0 || math.IsNaN(det) {
+			smoothP, smoothV = f.p, f.v
+			out[i] = smoothP
+			continue
+		}
+		// P_f · Fᵀ
+		a, b := f.ppp+dt*f.ppv, f.ppv
+		c, d := f.ppv+dt*f.pvv, f.pvv
+		// multiplied by P_pred⁻¹
+		inv00, inv01, inv11 := pr.pvv/det, -pr.ppv/det, pr.ppp/det
+		c00, c01 := a*inv00+b*inv01, a*inv01+b*inv11
+		c10, c11 := c*inv00+d*inv01, c*inv01+d*inv11
+
+		dp, dv := smoothP-pr.p, smoothV-pr.v
+		smoothP, smoothV = f.p+c00*dp+c01*dv, f.v+c10*dp+c11*dv
+		out[i] = smoothP
+	}
+	return out
+}
+
+// predict advances a state dt seconds under the constant-velocity model.
+func predict(s kalmanState, dt, q float64) kalmanState {
+	return kalmanState{
+		p:   s.p + dt*s.v,
+		v:   s.v,
+		ppp: s.ppp + 2*dt*s.ppv + dt*dt*s.pvv + q*dt*dt*dt/3,
+		ppv: s.ppv + dt*s.pvv + q*dt*dt/2,
+		pvv: s.pvv + q*dt,
+	}
+}
+
+// unoffset is the inverse of offset, returning the coordinates x meters east
+// and y meters north of origin.
+func unoffset(origin *models.Position, x, y float64) (lat, lng float64) {
+	perDegree := geo.EarthRadiusMeters * math.Pi / 180
+	lat = origin.Latitude + y/perDegree
+	lng = origin.Longitude + x/(perDegree*math.Cos(origin.Latitude*math.Pi/180))
+	if lng > 180 {
+		lng -= 360
+	} else if lng < -180 {
+		lng += 360
+	}
+	return lat, lng
+}
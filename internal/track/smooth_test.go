@@ -0,0 +1,114 @@
+// ABOUTME: Unit tests for Kalman track smoothing
+// ABOUTME: Verifies zig-zag removal, accuracy weighting, and that raw positions are untouched
+
+package track
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/harper/position/internal/geo"
+	"github.com/harper/position/internal/models"
+)
+
+// noisyWalk returns an eastward walk at ~1.4 m/s with a fix every 10
+// seconds, alternating 8 m north and south of the true path, and that true
+// length.
+func noisyWalk() ([]*models.Position, float64) {
+	base := time.Date(2024, 12, 14, 18, 0, 0, 0, time.UTC)
+	step := 0.000169 // ~14 m of longitude
+	var positions []*models.Position
+	for i := 0; i <= 60; i++ {
+		lat := 41.8781 + 0.000072 // ~8 m north
+		if i%2 == 1 {
+			lat = 41.8781 - 0.000072
+		}
+		positions = append(positions, models.NewPositionWithRecordedAt(uuid.Nil, lat, -87.6298+float64(i)*step, nil,
+			base.Add(time.Duration(i)*10*time.Second)))
+	}
+	return positions, geo.Distance(41.8781, -87.6298, 41.8781, -87.6298+60*step)
+}
+
+func TestSmooth(t *testing.T) {
+	positions, truth := noisyWalk()
+	raw := geo.PathLength(positions)
+	if raw < truth*1.2 {
+		t.Fatalf("test track should inflate distance by over 20%%: raw %.0f, true %.0f", raw, truth)
+	}
+
+	smoothed := Smooth(positions, DefaultSmoothOptions())
+	if len(smoothed) != len(positions) {
+		t.Fatalf("expected %d smoothed positions, got %d", len(positions), len(smoothed))
+	}
+	if got := geo.PathLength(smoothed); got > truth*1.05 {
+		t.Errorf("expected smoothed length within 5%% of %.0f m, got %.0f m (raw %.0f m)", truth, got, raw)
+	}
+	for i, pos := range smoothed {
+		if pos.ID != positions[i].ID || !pos.RecordedAt.Equal(positions[i].RecordedAt) {
+			t.Fatalf("expected position %d to keep its ID and time", i)
+		}
+		if pos == positions[i] {
+			t.Fatal("expected copies, not the raw positions")
+		}
+	}
+	if positions[1].Latitude != 41.8781-0.000072 {
+		t.Error("expected raw positions unchanged")
+	}
+}
+
+func TestSmooth_AccuracyWeighting(t *testing.T) {
+	base := time.Date(2024, 12, 14, 18, 0, 0, 0, time.UTC)
+	var positions []*models.Position
+	for i := 0; i <= 20; i++ {
+		positions = append(positions, models.NewPositionWithRecordedAt(uuid.Nil, 41.8781, -87.6298+float64(i)*0.000169, nil,
+			base.Add(time.Duration(i)*10*time.Second)))
+	}
+	// The same 30 m excursion, once reported precise and once vague
+	precise, vague := 3.0, 100.0
+	jumpLat := 41.8781 + 0.00027
+	run := func(acc *float64) float64 {
+		copies := make([]*models.Position, len(positions))
+		for i, pos := range positions {
+			c := *pos
+			copies[i] = &c
+		}
+		copies[10].Latitude = jumpLat
+		copies[10].Accuracy = acc
+		smoothed := Smooth(copies, DefaultSmoothOptions())
+		return geo.Distance(41.8781, smoothed[10].Longitude, smoothed[10].Latitude, smoothed[10].Longitude)
+	}
+
+	if p, v := run(&precise), run(&vague); v >= p/2 {
+		t.Errorf("expected a vague fix pulled back harder: precise %.1f m, vague %.1f m off the line", p, v)
+	}
+}
+
+func TestSmoothAll(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	walk, _ := noisyWalk()
+	var positions []*models.Position
+	for _, pos := range walk {
+		other := *pos
+		other.ID = uuid.New()
+		other.ItemID = b
+		pos.ItemID = a
+		// Newest first, interleaved, as storage returns them
+		positions = append([]*models.Position{pos, &other}, positions...)
+	}
+
+	smoothed := SmoothAll(positions, DefaultSmoothOptions())
+	if len(smoothed) != len(positions) {
+		t.Fatalf("expected %d positions, got %d", len(positions), len(smoothed))
+	}
+	for i := range positions {
+		if smoothed[i].ID != positions[i].ID {
+			t.Fatal("expected input order preserved")
+		}
+	}
+	// Mid-walk fixes sit ~8 m off the path before smoothing
+	if mid := smoothed[len(smoothed)/2]; math.Abs(mid.Latitude-41.8781) > 0.00003 {
+		t.Errorf("expected a mid-walk fix pulled toward the path, got %.6f", mid.Latitude)
+	}
+}
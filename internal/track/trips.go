@@ -0,0 +1,95 @@
+// ABOUTME: Trip segmentation for position histories
+// ABOUTME: Splits movement between stays into trips with distance and speed stats
+
+package track
+
+import (
+	"time"
+
+	"github.com/harper/position/internal/geo"
+	"github.com/harper/position/internal/models"
+)
+
+// Trip is a stretch of movement between two stays.
+type Trip struct {
+	From      *Visit             // stay the trip departed from; nil if history begins mid-trip
+	To        *Visit             // stay the trip arrived at; nil if history ends mid-trip
+	Positions []*models.Position // oldest first, including the departure and arrival fixes
+	Distance  float64            // meters along Positions
+	MaxSpeed  float64            // fastest leg in meters per second
+}
+
+// Start returns when the trip began.
+func (t Trip) Start() time.Time {
+	return t.Positions[0].RecordedAt
+}
+
+// End returns when the trip finished.
+func (t Trip) End() time.Time {
+	return t.Positions[len(t.Positions)-1].RecordedAt
+}
+
+// Duration returns how long the trip took.
+func (t Trip) Duration() time.Duration {
+	return t.End().Sub(t.Start())
+}
+
+// AverageSpeed returns the trip's average speed in meters per second.
+func (t Trip) AverageSpeed() float64 {
+	if d := t.Duration(); d > 0 {
+		return t.Distance / d.Seconds()
+	}
+	return 0
+}
+
+// Trips splits positions into trips bounded by the stays Visits would find
+// with the same options. Each trip runs from the last fix of one stay to the
+// first fix of the next, so consecutive trips never overlap. A trip can
+// therefore start before its From stay's Departed time, which is usually the
+// first fix after the stay. Movement before the first stay or after the last
+// becomes a trip with a nil From or To, and a history with no stays at all is
+// a single trip. Segments shorter than opts.RadiusMeters are GPS jitter
+// between stays, not trips, and are dropped. Trips are returned oldest first.
+func Trips(positions []*models.Position, opts VisitOptions) []Trip {
+	sorted := chronological(positions)
+	if len(sorted) < 2 {
+		return nil
+	}
+
+	var trips []Trip
+	start := 0
+	var from *Visit
+	for _, s := range findStays(sorted, opts) {
+		to := s.visit
+		if trip, ok := newTrip(sorted[start:s.start+1], from, &to, opts); ok {
+			trips = append(trips, trip)
+		}
+		from = &to
+		start = s.end - 1
+	}
+	if trip, ok := newTrip(sorted[start:], from, nil, opts); ok {
+		trips = append(trips, trip)
+	}
+	return trips
+}
+
+// newTrip computes stats for a segment, reporting false when it is too short
+// to count as a trip.
+func newTrip(segment []*models.Position, from, to *Visit, opts VisitOptions) (Trip, bool) {
+	if len(segment) < 2 {
+		return Trip{}, false
+	}
+
+	trip := Trip{From: from, To: to, Positions: segment}
+	for i := 1; i < len(segment); i++ {
+		leg := geo.NewLeg(segment[i-1], segment[i])
+		trip.Distance += leg.Distance
+		if leg.Speed > trip.MaxSpeed {
+			trip.MaxSpeed = leg.Speed
+		}
+	}
+	if trip.Distance < opts.RadiusMeters {
+		return Trip{}, false
+	}
+	return trip, true
+}
@@ -0,0 +1,90 @@
+// ABOUTME: Unit tests for trip segmentation
+// ABOUTME: Verifies trip boundaries, open-ended trips, and distance/speed stats
+
+package track
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/harper/position/internal/geo"
+	"github.com/harper/position/internal/models"
+)
+
+func TestTrips(t *testing.T) {
+	positions := []*models.Position{
+		// home, 18:00-18:40
+		ping(41.9500, -87.6500, 0, "home"),
+		ping(41.9500, -87.6500, 40, "home"),
+		// driving
+		ping(41.9200, -87.6400, 50, ""),
+		ping(41.9000, -87.6350, 55, ""),
+		// office, 19:00-19:30
+		ping(41.8781, -87.6298, 60, "office"),
+		ping(41.8781, -87.6298, 90, "office"),
+		// heading out again, history ends mid-trip
+		ping(41.8900, -87.6200, 95, ""),
+	}
+
+	trips := Trips(positions, DefaultVisitOptions())
+	if len(trips) != 2 {
+		t.Fatalf("expected 2 trips, got %d: %+v", len(trips), trips)
+	}
+
+	commute := trips[0]
+	if commute.From == nil || commute.From.Label != "home" || commute.To == nil || commute.To.Label != "office" {
+		t.Fatalf("unexpected commute endpoints: from %+v to %+v", commute.From, commute.To)
+	}
+	if len(commute.Positions) != 4 || commute.Duration() != 20*time.Minute {
+		t.Errorf("expected commute from 18:40 to 19:00 over 4 fixes, got %d fixes over %v",
+			len(commute.Positions), commute.Duration())
+	}
+	if want := geo.PathLength(positions[1:5]); math.Abs(commute.Distance-want) > 1e-6 {
+		t.Errorf("Distance = %v, want %v", commute.Distance, want)
+	}
+	if math.Abs(commute.AverageSpeed()-commute.Distance/1200) > 1e-9 {
+		t.Errorf("unexpected average speed %v", commute.AverageSpeed())
+	}
+	if commute.MaxSpeed <= commute.AverageSpeed() {
+		t.Errorf("expected max speed %v above average %v", commute.MaxSpeed, commute.AverageSpeed())
+	}
+
+	outbound := trips[1]
+	if outbound.From == nil || outbound.From.Label != "office" || outbound.To != nil {
+		t.Errorf("expected open-ended trip from office, got from %+v to %+v", outbound.From, outbound.To)
+	}
+}
+
+func TestTrips_NoStays(t *testing.T) {
+	positions := []*models.Position{
+		ping(41.90, -87.60, 0, ""),
+		ping(41.91, -87.60, 1, ""),
+		ping(41.92, -87.60, 2, ""),
+	}
+	trips := Trips(positions, DefaultVisitOptions())
+	if len(trips) != 1 || trips[0].From != nil || trips[0].To != nil || len(trips[0].Positions) != 3 {
+		t.Errorf("expected one open trip, got %+v", trips)
+	}
+}
+
+func TestTrips_DropsJitter(t *testing.T) {
+	// The second fix drifts toward the third, so the hop that breaks the
+	// stay is shorter than the visit radius
+	positions := []*models.Position{
+		ping(41.95000, -87.65, 0, ""),
+		ping(41.95080, -87.65, 30, ""),
+		ping(41.95131, -87.65, 31, ""),
+		ping(41.95131, -87.65, 60, ""),
+	}
+	if visits := Visits(positions, DefaultVisitOptions()); len(visits) != 2 {
+		t.Fatalf("expected 2 stays, got %+v", visits)
+	}
+	if trips := Trips(positions, DefaultVisitOptions()); len(trips) != 0 {
+		t.Errorf("expected jitter between stays to be dropped, got %+v", trips)
+	}
+
+	if trips := Trips(positions[:1], DefaultVisitOptions()); trips != nil {
+		t.Errorf("expected no trips for a single position, got %+v", trips)
+	}
+}
@@ -0,0 +1,160 @@
+// ABOUTME: Stay-point detection for position histories
+// ABOUTME: Clusters consecutive nearby positions into visits with arrival and departure times
+
+package track
+
+import (
+	"sort"
+	"time"
+
+	"github.com/harper/position/internal/geo"
+	"github.com/harper/position/internal/models"
+)
+
+// Default thresholds for visit detection.
+const (
+	DefaultVisitRadius      = 100.0 // meters
+	DefaultVisitMinDuration = 10 * time.Minute
+)
+
+// lingerSpeed is the fastest average speed, in meters per second, from a
+// stay's last fix to the next fix that still counts the gap as part of the
+// stay. A slower pace means the item sat still and then left; a faster one
+// means it was already travelling.
+const lingerSpeed = 1.0
+
+// Visit is a period during which an item stayed within a small area.
+// Departed is usually the time of the first position outside the area: the
+// item was still there at its last fix inside, and had left by the next one.
+// When that next fix implies the item was already travelling, Departed is
+// the last fix inside.
+type Visit struct {
+	Center   models.Coordinate
+	Arrived  time.Time
+	Departed time.Time
+	Label    string // most common position label in the cluster, if any
+	Count    int    // number of positions in the cluster
+}
+
+// Duration returns how long the visit lasted.
+func (v Visit) Duration() time.Duration {
+	return v.Departed.Sub(v.Arrived)
+}
+
+// VisitOptions controls how positions are clustered into visits.
+type VisitOptions struct {
+	// RadiusMeters is how far a position may be from the cluster's center
+	// and still belong to the same visit.
+	RadiusMeters float64
+	// MinDuration is the shortest stay reported as a visit.
+	MinDuration time.Duration
+	// Until is the end of the period the positions cover, such as the end of
+	// the queried range. A stay with no position after it lasts until then.
+	// Zero ends it at its last position.
+	Until time.Time
+}
+
+// DefaultVisitOptions returns the default visit thresholds.
+func DefaultVisitOptions() VisitOptions {
+	return VisitOptions{
+		RadiusMeters: DefaultVisitRadius,
+		MinDuration:  DefaultVisitMinDuration,
+	}
+}
+
+// Visits clusters consecutive positions that stay within opts.RadiusMeters of
+// their running centroid for at least opts.MinDuration. Positions may be in
+// any order (GetTimeline returns newest first); visits are returned oldest
+// first. Positions between visits are treated as travel and dropped.
+func Visits(positions []*models.Position, opts VisitOptions) []Visit {
+	sorted := chronological(positions)
+	var visits []Visit
+	for _, s := range findStays(sorted, opts) {
+		visits = append(visits, s.visit)
+	}
+	return visits
+}
+
+// stay is a visit along with its [start, end) index range in the sorted
+// positions it was found in.
+type stay struct {
+	visit      Visit
+	start, end int
+}
+
+// findStays runs stay-point detection over positions sorted oldest first.
+func findStays(sorted []*models.Position, opts VisitOptions) []stay {
+	var stays []stay
+	for i := 0; i < len(sorted); {
+		latSum, lngSum := sorted[i].Latitude, sorted[i].Longitude
+		j := i + 1
+		for ; j < len(sorted); j++ {
+			n := float64(j - i)
+			if geo.Distance(latSum/n, lngSum/n, sorted[j].Latitude, sorted[j].Longitude) > opts.RadiusMeters {
+				break
+			}
+			latSum += sorted[j].Latitude
+			lngSum += sorted[j].Longitude
+		}
+
+		// Dedup collapses a stationary device to few fixes, so a stay lasts
+		// until the next fix elsewhere rather than its own last fix
+		cluster := sorted[i:j]
+		last := cluster[len(cluster)-1]
+		departed := last.RecordedAt
+		switch {
+		case j < len(sorted):
+			if geo.NewLeg(last, sorted[j]).Speed <= lingerSpeed {
+				departed = sorted[j].RecordedAt
+			}
+		case opts.Until.After(departed):
+			departed = opts.Until
+		}
+		if departed.Sub(cluster[0].RecordedAt) < opts.MinDuration {
+			i++
+			continue
+		}
+
+		n := float64(len(cluster))
+		stays = append(stays, stay{
+			visit: Visit{
+				Center:   models.Coordinate{Latitude: latSum / n, Longitude: lngSum / n},
+				Arrived:  cluster[0].RecordedAt,
+				Departed: departed,
+				Label:    dominantLabel(cluster),
+				Count:    len(cluster),
+			},
+			start: i,
+			end:   j,
+		})
+		i = j
+	}
+	return stays
+}
+
+// chronological returns a copy of positions sorted oldest first.
+func chronological(positions []*models.Position) []*models.Position {
+	sorted := make([]*models.Position, len(positions))
+	copy(sorted, positions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].RecordedAt.Before(sorted[j].RecordedAt)
+	})
+	return sorted
+}
+
+// dominantLabel returns the most frequent non-empty label, preferring the
+// earliest on ties.
+func dominantLabel(positions []*models.Position) string {
+	counts := make(map[string]int)
+	best := ""
+	for _, pos := range positions {
+		if pos.Label == nil || *pos.Label == "" {
+			continue
+		}
+		counts[*pos.Label]++
+		if counts[*pos.Label] > counts[best] {
+			best = *pos.Label
+		}
+	}
+	return best
+}
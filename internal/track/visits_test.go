@@ -0,0 +1,146 @@
+// ABOUTME: Unit tests for stay-point detection
+// ABOUTME: Verifies visit clustering, thresholds, and label selection
+
+package track
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/harper/position/internal/models"
+)
+
+// ping builds a position offset minutes after base.
+func ping(lat, lng float64, minutes int, label string) *models.Position {
+	base := time.Date(2024, 12, 14, 18, 0, 0, 0, time.UTC)
+	var l *string
+	if label != "" {
+		l = &label
+	}
+	return models.NewPositionWithRecordedAt(uuid.Nil, lat, lng, l, base.Add(time.Duration(minutes)*time.Minute))
+}
+
+func TestVisits(t *testing.T) {
+	positions := []*models.Position{
+		// home, 18:00-18:40
+		ping(41.9500, -87.6500, 0, "home"),
+		ping(41.9501, -87.6501, 20, ""),
+		ping(41.9500, -87.6499, 40, "home"),
+		// driving
+		ping(41.9200, -87.6400, 50, ""),
+		ping(41.9000, -87.6350, 55, ""),
+		// office, 19:00-19:30
+		ping(41.8781, -87.6298, 60, "office"),
+		ping(41.8782, -87.6297, 90, ""),
+	}
+	// GetTimeline returns newest first
+	reversed := make([]*models.Position, len(positions))
+	for i, p := range positions {
+		reversed[len(positions)-1-i] = p
+	}
+
+	visits := Visits(reversed, DefaultVisitOptions())
+	if len(visits) != 2 {
+		t.Fatalf("expected 2 visits, got %d: %+v", len(visits), visits)
+	}
+
+	home := visits[0]
+	if home.Label != "home" || home.Count != 3 || home.Duration() != 40*time.Minute {
+		t.Errorf("unexpected home visit: %+v", home)
+	}
+	if !home.Arrived.Equal(positions[0].RecordedAt) || !home.Departed.Equal(positions[2].RecordedAt) {
+		t.Errorf("unexpected home times: %v - %v", home.Arrived, home.Departed)
+	}
+	if math.Abs(home.Center.Latitude-41.95003) > 0.0001 {
+		t.Errorf("unexpected home center: %+v", home.Center)
+	}
+
+	office := visits[1]
+	if office.Label != "office" || office.Duration() != 30*time.Minute {
+		t.Errorf("unexpected office visit: %+v", office)
+	}
+}
+
+func TestVisits_Thresholds(t *testing.T) {
+	positions := []*models.Position{
+		ping(41.9500, -87.6500, 0, ""),
+		ping(41.9500, -87.6500, 5, ""),
+		ping(41.9520, -87.6500, 8, ""), // ~220m away
+	}
+
+	if visits := Visits(positions, DefaultVisitOptions()); len(visits) != 0 {
+		t.Errorf("expected short stay to be dropped, got %+v", visits)
+	}
+
+	wide := VisitOptions{RadiusMeters: 500, MinDuration: 5 * time.Minute}
+	if visits := Visits(positions, wide); len(visits) != 1 || visits[0].Count != 3 {
+		t.Errorf("expected one wide visit, got %+v", visits)
+	}
+
+	brief := VisitOptions{RadiusMeters: 100, MinDuration: time.Minute}
+	if visits := Visits(positions, brief); len(visits) != 1 || visits[0].Count != 2 {
+		t.Errorf("expected one brief visit, got %+v", visits)
+	}
+}
+
+func TestVisits_DepartureAfterGap(t *testing.T) {
+	// Dedup leaves one fix for a morning at home; the next fix is hours later
+	positions := []*models.Position{
+		ping(41.9500, -87.6500, 0, "home"),
+		ping(41.8781, -87.6298, 180, "office"),
+		ping(41.8782, -87.6297, 190, ""),
+	}
+
+	visits := Visits(positions, DefaultVisitOptions())
+	if len(visits) != 2 {
+		t.Fatalf("expected 2 visits, got %d: %+v", len(visits), visits)
+	}
+	home := visits[0]
+	if home.Label != "home" || home.Count != 1 || home.Duration() != 3*time.Hour {
+		t.Errorf("expected a 3h single-fix home visit, got %+v", home)
+	}
+	if !home.Departed.Equal(positions[1].RecordedAt) {
+		t.Errorf("expected departure at the next fix, got %v", home.Departed)
+	}
+}
+
+func TestVisits_Until(t *testing.T) {
+	positions := []*models.Position{ping(41.9500, -87.6500, 0, "home")}
+
+	if visits := Visits(positions, DefaultVisitOptions()); len(visits) != 0 {
+		t.Errorf("expected no visit without a range end, got %+v", visits)
+	}
+
+	opts := DefaultVisitOptions()
+	opts.Until = positions[0].RecordedAt.Add(2 * time.Hour)
+	visits := Visits(positions, opts)
+	if len(visits) != 1 || !visits[0].Departed.Equal(opts.Until) {
+		t.Errorf("expected the last stay to run until the range end, got %+v", visits)
+	}
+}
+
+func TestVisits_Empty(t *testing.T) {
+	if visits := Visits(nil, DefaultVisitOptions()); visits != nil {
+		t.Errorf("expected no visits, got %+v", visits)
+	}
+}
+
+func TestDominantLabel(t *testing.T) {
+	positions := []*models.Position{
+		ping(0, 0, 0, "cafe"),
+		ping(0, 0, 1, "office"),
+		ping(0, 0, 2, "office"),
+		ping(0, 0, 3, ""),
+	}
+	if got := dominantLabel(positions); got != "office" {
+		t.Errorf("dominantLabel = %q, want office", got)
+	}
+	if got := dominantLabel(positions[:2]); got != "cafe" {
+		t.Errorf("expected earliest label on tie, got %q", got)
+	}
+	if got := dominantLabel(positions[3:]); got != "" {
+		t.Errorf("expected empty label, got %q", got)
+	}
+}
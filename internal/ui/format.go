@@ -5,10 +5,13 @@ package ui
 
 import (
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/fatih/color"
+	"github.com/harper/position/internal/geo"
 	"github.com/harper/position/internal/models"
+	"github.com/harper/position/internal/track"
 )
 
 // FormatPosition formats a position for terminal display.
@@ -19,9 +22,9 @@ func FormatPosition(pos *models.Position) string {
 	coords := fmt.Sprintf("(%.4f, %.4f)", pos.Latitude, pos.Longitude)
 	relTime := FormatRelativeTime(pos.RecordedAt)
 
-	if pos.Label != nil && *pos.Label != "" {
+	if name := positionName(pos); name != "" {
 		return fmt.Sprintf("%s %s - %s",
-			color.CyanString(*pos.Label),
+			color.CyanString(name),
 			color.New(color.Faint).Sprint(coords),
 			color.New(color.Faint).Sprint(relTime))
 	}
@@ -37,10 +40,13 @@ func FormatPositionForTimeline(pos *models.Position) string {
 	}
 	coords := fmt.Sprintf("(%.4f, %.4f)", pos.Latitude, pos.Longitude)
 	timeStr := pos.RecordedAt.Format("Jan 2, 3:04 PM")
+	if pos.Suspect {
+		timeStr += " " + color.YellowString("(suspect)")
+	}
 
-	if pos.Label != nil && *pos.Label != "" {
+	if name := positionName(pos); name != "" {
 		return fmt.Sprintf("  %s %s - %s",
-			color.CyanString(*pos.Label),
+			color.CyanString(name),
 			color.New(color.Faint).Sprint(coords),
 			timeStr)
 	}
@@ -60,10 +66,8 @@ func FormatItemWithPosition(item *models.Item, pos *models.Position) string {
 			color.New(color.Faint).Sprint("no position"))
 	}
 
-	var posStr string
-	if pos.Label != nil && *pos.Label != "" {
-		posStr = *pos.Label
-	} else {
+	posStr := positionName(pos)
+	if posStr == "" {
 		posStr = fmt.Sprintf("(%.4f, %.4f)", pos.Latitude, pos.Longitude)
 	}
 
@@ -74,6 +78,17 @@ func FormatItemWithPosition(item *models.Item, pos *models.Position) string {
 		color.New(color.Faint).Sprint(relTime))
 }
 
+// positionName returns the position's label, falling back to its locality.
+func positionName(pos *models.Position) string {
+	if pos.Label != nil && *pos.Label != "" {
+		return *pos.Label
+	}
+	if pos.Locality != nil {
+		return *pos.Locality
+	}
+	return ""
+}
+
 // FormatRelativeTime formats a time as relative to now.
 func FormatRelativeTime(t time.Time) string {
 	diff := time.Since(t)
@@ -106,3 +121,163 @@ func FormatRelativeTime(t time.Time) string {
 	}
 	return fmt.Sprintf("%d days ago", days)
 }
+
+// FormatGeofence formats a geofence with its shape for terminal display.
+func FormatGeofence(fence *models.Geofence) string {
+	if fence == nil {
+		return color.New(color.Faint).Sprint("(invalid geofence)")
+	}
+	return fmt.Sprintf("%s - %s",
+		color.GreenString(fence.Name),
+		color.New(color.Faint).Sprint(formatShape(fence.Shape)))
+}
+
+// FormatPlace formats a named place with its shape for terminal display.
+func FormatPlace(place *models.Place) string {
+	if place == nil {
+		return color.New(color.Faint).Sprint("(invalid place)")
+	}
+	return fmt.Sprintf("%s - %s",
+		color.GreenString(place.Name),
+		color.New(color.Faint).Sprint(formatShape(place.Shape)))
+}
+
+// formatShape describes a circle by its radius and center, and a polygon by
+// its vertex count.
+func formatShape(shape models.Shape) string {
+	if !shape.IsCircle() {
+		return fmt.Sprintf("polygon, %d vertices", len(shape.Polygon))
+	}
+	if shape.Center == nil {
+		return fmt.Sprintf("%.0fm", shape.RadiusMeters)
+	}
+	return fmt.Sprintf("%.0fm around (%.4f, %.4f)",
+		shape.RadiusMeters, shape.Center.Latitude, shape.Center.Longitude)
+}
+
+// FormatGeofenceEvent formats an enter/exit event for terminal display.
+func FormatGeofenceEvent(e *models.GeofenceEvent, itemName, fenceName string) string {
+	verb := color.GreenString("entered")
+	if e.Type == models.GeofenceExit {
+		verb = color.YellowString("left")
+	}
+	return fmt.Sprintf("  %s %s %s - %s",
+		itemName, verb, color.CyanString(fenceName),
+		e.OccurredAt.Local().Format("Jan 2, 3:04 PM"))
+}
+
+// FormatDistance formats a distance in meters as "120 m" or "3.4 km".
+func FormatDistance(meters float64) string {
+	if math.Round(meters) < 1000 {
+		return fmt.Sprintf("%.0f m", meters)
+	}
+	return fmt.Sprintf("%.1f km", meters/1000)
+}
+
+// FormatBearing formats a bearing in degrees as "45° NE".
+func FormatBearing(degrees float64) string {
+	degrees = math.Mod(math.Round(degrees), 360)
+	return fmt.Sprintf("%.0f° %s", degrees, geo.CompassPoint(degrees))
+}
+
+// FormatSpeed formats a speed in meters per second as km/h.
+func FormatSpeed(metersPerSecond float64) string {
+	return fmt.Sprintf("%.1f km/h", metersPerSecond*3.6)
+}
+
+// FormatLeg formats the distance, bearing, and speed of a timeline leg.
+func FormatLeg(leg geo.Leg) string {
+	speed := "-"
+	if leg.Duration > 0 {
+		speed = FormatSpeed(leg.Speed)
+	}
+	return color.New(color.Faint).Sprintf("%9s  %-7s  %s",
+		"+"+FormatDistance(leg.Distance), FormatBearing(leg.Bearing), speed)
+}
+
+// FormatDuration formats a duration as "45m" or "14h13m".
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// FormatVisit formats a visit as its label (or center) with arrival and
+// departure times, omitting the departure date when it matches arrival.
+func FormatVisit(v track.Visit) string {
+	place := color.CyanString(v.Label)
+	if v.Label == "" {
+		place = color.CyanString("(%.4f, %.4f)", v.Center.Latitude, v.Center.Longitude)
+	}
+
+	arrived := v.Arrived.Local()
+	departed := v.Departed.Local()
+	departedFmt := "Jan 2, 3:04 PM"
+	if arrived.YearDay() == departed.YearDay() && arrived.Year() == departed.Year() {
+		departedFmt = "3:04 PM"
+	}
+	return fmt.Sprintf("  %s %s – %s %s",
+		place,
+		arrived.Format("Jan 2, 3:04 PM"),
+		departed.Format(departedFmt),
+		color.New(color.Faint).Sprintf("(%s)", FormatDuration(v.Duration())))
+}
+
+// FormatTrip formats a trip with its endpoints, times, distance, and speeds.
+func FormatTrip(trip track.Trip) string {
+	start := trip.Start().Local()
+	end := trip.End().Local()
+	endFmt := "Jan 2, 3:04 PM"
+	if start.YearDay() == end.YearDay() && start.Year() == end.Year() {
+		endFmt = "3:04 PM"
+	}
+	return fmt.Sprintf("  %s → %s %s – %s %s\n    %s",
+		tripPlace(trip.From, trip.Positions[0]),
+		tripPlace(trip.To, trip.Positions[len(trip.Positions)-1]),
+		start.Format("Jan 2, 3:04 PM"),
+		end.Format(endFmt),
+		color.New(color.Faint).Sprintf("(%s)", FormatDuration(trip.Duration())),
+		color.New(color.Faint).Sprintf("%s, avg %s, max %s",
+			FormatDistance(trip.Distance), FormatSpeed(trip.AverageSpeed()), FormatSpeed(trip.MaxSpeed)))
+}
+
+// tripPlace names a trip endpoint by its stay's label, falling back to the
+// stay's center or, for open-ended trips, the endpoint position.
+func tripPlace(v *track.Visit, fallback *models.Position) string {
+	switch {
+	case v != nil && v.Label != "":
+		return color.CyanString(v.Label)
+	case v != nil:
+		return color.CyanString("(%.4f, %.4f)", v.Center.Latitude, v.Center.Longitude)
+	default:
+		return color.CyanString("(%.4f, %.4f)", fallback.Latitude, fallback.Longitude)
+	}
+}
+
+// FormatFix formats a point-in-time fix with its coordinates and how it was
+// derived: interpolated between two fixes or carried forward from the last one.
+func FormatFix(fix track.Fix) string {
+	coords := fmt.Sprintf("(%.4f, %.4f)", fix.Latitude, fix.Longitude)
+	place := color.CyanString(coords)
+	if fix.Label != nil && *fix.Label != "" {
+		place = fmt.Sprintf("%s %s", color.CyanString(*fix.Label), color.New(color.Faint).Sprint(coords))
+	}
+
+	var basis string
+	switch {
+	case fix.Interpolated:
+		basis = fmt.Sprintf("interpolated between %s and %s (%s apart)",
+			fix.Before.RecordedAt.Local().Format("Jan 2, 3:04 PM"),
+			fix.After.RecordedAt.Local().Format("Jan 2, 3:04 PM"),
+			FormatDuration(fix.Gap))
+	case fix.Gap == 0:
+		basis = "recorded at this time"
+	default:
+		basis = fmt.Sprintf("last fix %s (%s earlier)",
+			fix.Before.RecordedAt.Local().Format("Jan 2, 3:04 PM"), FormatDuration(fix.Gap))
+	}
+	return fmt.Sprintf("%s - %s\n  %s",
+		place, fix.At.Local().Format("Jan 2, 3:04 PM"), color.New(color.Faint).Sprint(basis))
+}
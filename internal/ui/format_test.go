@@ -9,7 +9,9 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/harper/position/internal/geo"
 	"github.com/harper/position/internal/models"
+	"github.com/harper/position/internal/track"
 )
 
 func TestFormatPosition(t *testing.T) {
@@ -49,6 +51,32 @@ func TestFormatPosition_NoLabel(t *testing.T) {
 	}
 }
 
+func TestFormatPosition_Locality(t *testing.T) {
+	locality := "Chicago, Illinois, US"
+	pos := &models.Position{
+		ID:         uuid.New(),
+		Latitude:   41.8781,
+		Longitude:  -87.6298,
+		Locality:   &locality,
+		RecordedAt: time.Now(),
+	}
+
+	for _, output := range []string{FormatPosition(pos), FormatPositionForTimeline(pos)} {
+		if !strings.Contains(output, locality) {
+			t.Errorf("expected locality without a label, got %q", output)
+		}
+	}
+	if !strings.Contains(FormatItemWithPosition(models.NewItem("phone"), pos), locality) {
+		t.Error("expected item output to show locality")
+	}
+
+	label := "office"
+	pos.Label = &label
+	if output := FormatPosition(pos); !strings.Contains(output, "office") || strings.Contains(output, "Chicago") {
+		t.Errorf("expected label to take precedence over locality, got %q", output)
+	}
+}
+
 func TestFormatPosition_NilPosition(t *testing.T) {
 	output := FormatPosition(nil)
 	if !strings.Contains(output, "no position") {
@@ -111,6 +139,22 @@ func TestFormatPositionForTimeline_NoLabel(t *testing.T) {
 	}
 }
 
+func TestFormatPositionForTimeline_Suspect(t *testing.T) {
+	pos := &models.Position{
+		ID:         uuid.New(),
+		Latitude:   40.7128,
+		Longitude:  -74.0060,
+		RecordedAt: time.Date(2024, 12, 15, 14, 30, 0, 0, time.Local),
+	}
+	if strings.Contains(FormatPositionForTimeline(pos), "suspect") {
+		t.Error("expected no suspect marker on a trusted position")
+	}
+	pos.Suspect = true
+	if !strings.Contains(FormatPositionForTimeline(pos), "(suspect)") {
+		t.Error("expected a suspect marker")
+	}
+}
+
 func TestFormatPositionForTimeline_NilPosition(t *testing.T) {
 	output := FormatPositionForTimeline(nil)
 	if !strings.Contains(output, "no position") {
@@ -293,3 +337,192 @@ func TestFormatItemWithPosition_EmptyLabel(t *testing.T) {
 		t.Error("expected output to contain coordinates for empty label")
 	}
 }
+
+func TestFormatGeofence(t *testing.T) {
+	circle := FormatGeofence(models.NewCircleGeofence("garage", 41.8781, -87.6298, 50))
+	if !strings.Contains(circle, "garage") || !strings.Contains(circle, "50m") {
+		t.Errorf("unexpected circle output: %q", circle)
+	}
+
+	polygon := FormatGeofence(models.NewPolygonGeofence("park", []models.Coordinate{{}, {}, {}}))
+	if !strings.Contains(polygon, "3 vertices") {
+		t.Errorf("unexpected polygon output: %q", polygon)
+	}
+
+	if !strings.Contains(FormatGeofence(nil), "invalid") {
+		t.Error("expected placeholder for nil geofence")
+	}
+}
+
+func TestFormatPlace(t *testing.T) {
+	circle := FormatPlace(models.NewPlace("home", 41.8781, -87.6298, 100))
+	if !strings.Contains(circle, "home") || !strings.Contains(circle, "100m") || !strings.Contains(circle, "41.8781") {
+		t.Errorf("unexpected circular place output: %q", circle)
+	}
+
+	polygon := FormatPlace(models.NewPolygonPlace("park", []models.Coordinate{{}, {}, {}}))
+	if !strings.Contains(polygon, "3 vertices") {
+		t.Errorf("unexpected polygon place output: %q", polygon)
+	}
+
+	if !strings.Contains(FormatPlace(nil), "invalid") {
+		t.Error("expected placeholder for nil place")
+	}
+}
+
+func TestFormatGeofenceEvent(t *testing.T) {
+	fence := models.NewCircleGeofence("garage", 41.0, -87.0, 50)
+	pos := models.NewPosition(uuid.New(), 41.0, -87.0, nil)
+
+	enter := FormatGeofenceEvent(models.NewGeofenceEvent(fence, pos, models.GeofenceEnter), "car", "garage")
+	if !strings.Contains(enter, "car") || !strings.Contains(enter, "entered") || !strings.Contains(enter, "garage") {
+		t.Errorf("unexpected enter output: %q", enter)
+	}
+
+	exit := FormatGeofenceEvent(models.NewGeofenceEvent(fence, pos, models.GeofenceExit), "car", "garage")
+	if !strings.Contains(exit, "left") {
+		t.Errorf("unexpected exit output: %q", exit)
+	}
+}
+
+func TestFormatDistance(t *testing.T) {
+	tests := map[float64]string{
+		0:       "0 m",
+		12.4:    "12 m",
+		999.4:   "999 m",
+		999.6:   "1.0 km",
+		1000:    "1.0 km",
+		3456.78: "3.5 km",
+	}
+	for meters, want := range tests {
+		if got := FormatDistance(meters); got != want {
+			t.Errorf("FormatDistance(%v) = %q, want %q", meters, got, want)
+		}
+	}
+}
+
+func TestFormatBearing(t *testing.T) {
+	tests := map[float64]string{
+		0:     "0° N",
+		22.4:  "22° N",
+		45:    "45° NE",
+		180:   "180° S",
+		292.5: "293° NW",
+		359.7: "0° N",
+	}
+	for degrees, want := range tests {
+		if got := FormatBearing(degrees); got != want {
+			t.Errorf("FormatBearing(%v) = %q, want %q", degrees, got, want)
+		}
+	}
+}
+
+func TestFormatSpeed(t *testing.T) {
+	if got := FormatSpeed(10); got != "36.0 km/h" {
+		t.Errorf("FormatSpeed(10) = %q, want %q", got, "36.0 km/h")
+	}
+}
+
+func TestFormatLeg(t *testing.T) {
+	leg := geo.Leg{Distance: 1500, Bearing: 90, Duration: time.Minute, Speed: 25}
+	got := FormatLeg(leg)
+	for _, want := range []string{"+1.5 km", "90° E", "90.0 km/h"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("FormatLeg missing %q: %q", want, got)
+		}
+	}
+
+	leg.Duration = 0
+	if got := FormatLeg(leg); strings.Contains(got, "km/h") {
+		t.Errorf("expected no speed for zero duration, got %q", got)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                             "0m",
+		45 * time.Minute:              "45m",
+		time.Hour:                     "1h00m",
+		14*time.Hour + 13*time.Minute: "14h13m",
+		90*time.Second + time.Hour:    "1h02m",
+	}
+	for d, want := range tests {
+		if got := FormatDuration(d); got != want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestFormatVisit(t *testing.T) {
+	arrived := time.Date(2024, 12, 14, 18, 2, 0, 0, time.Local)
+	v := track.Visit{
+		Center:   models.Coordinate{Latitude: 41.95, Longitude: -87.65},
+		Arrived:  arrived,
+		Departed: arrived.Add(14*time.Hour + 13*time.Minute),
+		Label:    "home",
+	}
+	got := FormatVisit(v)
+	for _, want := range []string{"home", "Dec 14, 6:02 PM", "Dec 15, 8:15 AM", "14h13m"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("FormatVisit missing %q: %q", want, got)
+		}
+	}
+
+	v.Label = ""
+	v.Departed = arrived.Add(time.Hour)
+	got = FormatVisit(v)
+	if !strings.Contains(got, "(41.9500, -87.6500)") || !strings.Contains(got, "– 7:02 PM") {
+		t.Errorf("unexpected unlabeled same-day visit: %q", got)
+	}
+}
+
+func TestFormatTrip(t *testing.T) {
+	start := time.Date(2024, 12, 14, 18, 40, 0, 0, time.Local)
+	trip := track.Trip{
+		From: &track.Visit{Label: "home"},
+		To:   &track.Visit{Center: models.Coordinate{Latitude: 41.8781, Longitude: -87.6298}},
+		Positions: []*models.Position{
+			models.NewPositionWithRecordedAt(uuid.New(), 41.95, -87.65, nil, start),
+			models.NewPositionWithRecordedAt(uuid.New(), 41.88, -87.63, nil, start.Add(20*time.Minute)),
+		},
+		Distance: 8000,
+		MaxSpeed: 10,
+	}
+	got := FormatTrip(trip)
+	for _, want := range []string{"home", "(41.8781, -87.6298)", "Dec 14, 6:40 PM – 7:00 PM", "(20m)", "8.0 km", "avg 24.0 km/h", "max 36.0 km/h"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("FormatTrip missing %q: %q", want, got)
+		}
+	}
+
+	trip.From = nil
+	if got := FormatTrip(trip); !strings.Contains(got, "(41.9500, -87.6500)") {
+		t.Errorf("expected first position for open start, got %q", got)
+	}
+}
+
+func TestFormatFix(t *testing.T) {
+	at := time.Date(2024, 12, 14, 14, 0, 0, 0, time.Local)
+	label := "office"
+	before := models.NewPositionWithRecordedAt(uuid.New(), 41.8781, -87.6298, &label, at.Add(-8*time.Minute))
+	after := models.NewPositionWithRecordedAt(uuid.New(), 41.9000, -87.6298, nil, at.Add(10*time.Minute))
+
+	fix := track.Fix{At: at, Latitude: 41.8781, Longitude: -87.6298, Label: &label, Before: before, After: after, Gap: 8 * time.Minute}
+	got := FormatFix(fix)
+	for _, want := range []string{"office", "(41.8781, -87.6298)", "Dec 14, 2:00 PM", "last fix Dec 14, 1:52 PM (8m earlier)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("FormatFix missing %q: %q", want, got)
+		}
+	}
+
+	fix = track.Fix{At: at, Latitude: 41.89, Longitude: -87.6298, Before: before, After: after, Interpolated: true, Gap: 18 * time.Minute}
+	got = FormatFix(fix)
+	if !strings.Contains(got, "interpolated between Dec 14, 1:52 PM and Dec 14, 2:10 PM (18m apart)") {
+		t.Errorf("unexpected interpolated fix: %q", got)
+	}
+
+	fix = track.Fix{At: at, Before: before}
+	if got := FormatFix(fix); !strings.Contains(got, "recorded at this time") {
+		t.Errorf("unexpected exact fix: %q", got)
+	}
+}